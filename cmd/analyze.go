@@ -8,8 +8,19 @@ import (
 
 	"github.com/Legit-Labs/legitify/internal/screen"
 
+	"github.com/Legit-Labs/legitify/internal/common/data_groups"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/coverage"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/inventory"
+	"github.com/Legit-Labs/legitify/internal/outputer"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/Legit-Labs/legitify/internal/outputer/sink"
+	"github.com/Legit-Labs/legitify/internal/protection"
+	"github.com/Legit-Labs/legitify/internal/scorecard"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,14 +34,59 @@ const (
 	argRepository                 = "repo"
 	argEnterprises                = "enterprise"
 	argPoliciesPath               = "policies-path"
+	argPoliciesConfig             = "policies-config"
 	argNamespace                  = "namespace"
 	argOutputFormat               = "output-format"
 	argOutputScheme               = "output-scheme"
 	argColor                      = "color"
 	argScorecard                  = "scorecard"
+	argScorecardConcurrency       = "scorecard-concurrency"
 	argFailedOnly                 = "failed-only"
+	argOnlyFailures               = "only-failures"
+	argSeveritySummaryOnly        = "severity-summary-only"
+	argOutputProfile              = "profile"
+	argLang                       = "lang"
+	argMessagesPath               = "messages-path"
+	argCoverageFile               = "coverage-file"
+	argMaxViolationsPerPolicy     = "max-violations-per-policy"
+	argJsonIndent                 = "json-indent"
 	argSimulateSecondaryRateLimit = "simulate-secondary-rate-limit"
 	argIgnorePolicies             = "ignore-policies-file"
+	argAggregate                  = "aggregate"
+	argGitLabToken                = "gitlab-token"
+	argGitLabServerUrl            = "gitlab-server-url"
+	argCheckRateLimit             = "check-rate-limit"
+	argWebhookURL                 = "webhook-url"
+	argWebhookHeader              = "webhook-header"
+	argWebhookMode                = "webhook-mode"
+	argWebhookRetries             = "webhook-retries"
+	argJiraURL                    = "jira-url"
+	argJiraUser                   = "jira-user"
+	argJiraToken                  = "jira-token"
+	argJiraProject                = "jira-project"
+	argJiraIssueType              = "jira-issue-type"
+	argJiraMinSeverity            = "jira-min-severity"
+	argCheckRun                   = "check-run"
+	argCheckRunRepo               = "check-run-repo"
+	argCheckRunSHA                = "check-run-sha"
+	argCheckRunName               = "check-run-name"
+	argPublicSeverityBump         = "public-severity-bump"
+	argMemoryBudget               = "memory-budget"
+	argCollectActionsStorage      = "collect-actions-storage"
+	argSkipSelfHostedRunners      = "skip-self-hosted-runners"
+	argCollectWorkflowContents    = "collect-workflow-contents"
+	argCollect                    = "collect"
+	argMaxRepositories            = "max-repos"
+	argPolicySeverityThreshold    = "policy-severity-threshold"
+	argPrintPolicies              = "print-policies"
+	argMinCoverage                = "min-coverage"
+	argBaseline                   = "baseline"
+	argFailOnNew                  = "fail-on-new"
+	argInventoryFile              = "inventory-file"
+	argInventoryFormat            = "inventory-format"
+	argInventoryAttribute         = "inventory-attribute"
+	argOwnersOutputDir            = "owners-output-dir"
+	argProtectionReportFile       = "protection-report-file"
 )
 
 func toOptionsString(options []string) string {
@@ -66,11 +122,50 @@ func newAnalyzeCommand() *cobra.Command {
 	flags.StringSliceVarP(&analyzeArgs.Repositories, argRepository, "", nil, "specific repositories to collect (--repo owner/repo_name (e.g. ossf/scorecard)")
 	flags.StringSliceVarP(&analyzeArgs.Enterprises, argEnterprises, "", nil, "specific enterprises to collect (--enterprise your_enterprise_slug) this flag must be provided with a value")
 	flags.StringSliceVarP(&analyzeArgs.PoliciesPath, argPoliciesPath, "p", []string{}, "directory containing opa policies")
+	flags.StringVarP(&analyzeArgs.PoliciesConfig, argPoliciesConfig, "", "", "path to a json/yaml document that is available to the policies as data.config (e.g. to override thresholds)")
 	flags.StringSliceVarP(&analyzeArgs.Namespaces, argNamespace, "n", namespace.All, "which namespace to run")
 	flags.StringVarP(&analyzeArgs.IgnoredPolicies, argIgnorePolicies, "", "", "path to a file that contain \n separated list of policies to ignore")
 	flags.StringVarP(&analyzeArgs.ScorecardWhen, argScorecard, "", DefaultScOption, "Whether to run additional scorecard checks "+scorecardWhens)
+	flags.IntVarP(&analyzeArgs.ScorecardConcurrency, argScorecardConcurrency, "", scorecard.DefaultConcurrency, "maximum number of repositories whose scorecard is calculated at the same time")
 	flags.BoolVarP(&analyzeArgs.SimulateSecondaryRateLimit, argSimulateSecondaryRateLimit, "", false, "Simulate secondary rate limits (for testing purposes)")
 	_ = flags.MarkHidden(argSimulateSecondaryRateLimit)
+	flags.BoolVarP(&analyzeArgs.Aggregate, argAggregate, "", false, "analyze both GitHub and GitLab and combine the results into a single output")
+	flags.StringVarP(&analyzeArgs.GitLabToken, argGitLabToken, "", "", "token to authenticate with gitlab when using --"+argAggregate+" (can be set via the environment variable GITLAB_TOKEN)")
+	flags.StringVarP(&analyzeArgs.GitLabEndpoint, argGitLabServerUrl, "", "", "gitlab endpoint to use when using --"+argAggregate+" instead of the Cloud API")
+	flags.BoolVarP(&analyzeArgs.CheckRateLimit, argCheckRateLimit, "", false, "check the remaining rate limit before the scan and warn if it is likely to be exhausted (GitHub only)")
+	flags.IntVarP(&analyzeArgs.PublicSeverityBump, argPublicSeverityBump, "", 0, "raise the severity of violations on public repositories by the given number of levels (0 means disabled)")
+	flags.IntVarP(&analyzeArgs.MemoryBudget, argMemoryBudget, "", 0, "memory budget (in MB) for the results; larger results are spilled to a temporary file and streamed to the output (json/csv formats with the flattened scheme). 0 keeps all results in memory")
+	flags.BoolVarP(&analyzeArgs.CollectActionsStorage, argCollectActionsStorage, "", false, "collect the actions artifact/log retention and cache usage of each repository (requires additional API calls, GitHub only)")
+	flags.BoolVarP(&analyzeArgs.CollectWorkflowContents, argCollectWorkflowContents, "", false, "collect the contents of the workflow files of each repository for the policies that inspect the workflow definitions (requires more memory, GitHub only)")
+	flags.BoolVarP(&analyzeArgs.SkipSelfHostedRunners, argSkipSelfHostedRunners, "", false, "do not collect the self-hosted runners of each repository, to save an API call per repository (GitHub only)")
+	flags.StringSliceVarP(&analyzeArgs.Collect, argCollect, "", nil, "collect only the given repository data groups to reduce the API calls; policies that depend on other groups are skipped "+toOptionsString(data_groups.All)+" (default: all, GitHub only)")
+	flags.IntVarP(&analyzeArgs.MaxRepositories, argMaxRepositories, "", 0, "collect only the first N repositories of each organization, for a quick spot-check; the results are a sample rather than a complete scan (0 means all, GitHub only)")
+	flags.StringVarP(&analyzeArgs.PolicySeverityThreshold, argPolicySeverityThreshold, "", "", "evaluate only the policies of the given severity or higher; the other policies are not evaluated at all "+toOptionsString([]string{severity.Critical, severity.High, severity.Medium, severity.Low})+" (default: all)")
+	flags.StringVarP(&analyzeArgs.CoverageFile, argCoverageFile, "", "", "path to write a report of the collected fields each policy references and the entities for which they were not populated (e.g. due to missing permissions)")
+	flags.Float64VarP(&analyzeArgs.MinCoverage, argMinCoverage, "", 0, "fail the run if the percentage of entities collected without missing permissions is below the given value (0 means disabled)")
+	flags.StringVarP(&analyzeArgs.Baseline, argBaseline, "", "", "path to a json output of a previous scan (e.g. committed to the repository); its failed violations are reported as baselined rather than new")
+	flags.BoolVarP(&analyzeArgs.FailOnNew, argFailOnNew, "", false, "fail the run (exit code 1) if there are failed violations that are not in the --"+argBaseline+" (without a baseline, any failed violation fails the run)")
+	flags.StringVarP(&analyzeArgs.InventoryFile, argInventoryFile, "", "", "path to write an inventory of the collected entities and their key attributes (e.g. visibility, protection, admins), regardless of the policies results")
+	flags.StringVarP(&analyzeArgs.ProtectionReportFile, argProtectionReportFile, "", "", "path to write a report of the effective protection of the default branch of each repository: whether reviews, signatures, status checks, blocking force pushes and including administrators are enforced, and by which branch protection rule or rulesets (GitHub only)")
+	flags.StringVarP(&analyzeArgs.InventoryFormat, argInventoryFormat, "", inventory.FormatJson, "format of the inventory "+toOptionsString(inventory.Formats()))
+	flags.StringArrayVarP(&analyzeArgs.InventoryAttributes, argInventoryAttribute, "", nil, "attribute to record in the inventory instead of the defaults of its namespace, as namespace:[label=]path (e.g. 'repository:admins=collaborators[permissions.admin]', can be used multiple times)")
+	flags.StringVarP(&analyzeArgs.OwnersOutputDir, argOwnersOutputDir, "", "", "directory to write the results of each owner to a separate file, in the output format and scheme (the owners of a repository are the teams that administer it or its owner account on GitHub, and its group on GitLab)")
+	flags.BoolVarP(&analyzeArgs.NDJSON, argNDJSON, "", false, "stream the results as newline delimited json while the scan is running, instead of formatting them once it completes (cannot be combined with the output format, scheme and sinks)")
+	flags.BoolVarP(&analyzeArgs.PrintPolicies, argPrintPolicies, "", false, "print the policies that would be evaluated (name, namespace, severity, title and framework mappings) and exit without scanning. Use -f json for a json output")
+	flags.StringVarP(&analyzeArgs.WebhookURL, argWebhookURL, "", "", "URL to POST the results to as json (in addition to the regular output)")
+	flags.StringArrayVarP(&analyzeArgs.WebhookHeaders, argWebhookHeader, "", nil, "header to add to the webhook requests, e.g. 'Authorization: Bearer <token>' (can be used multiple times)")
+	flags.StringVarP(&analyzeArgs.WebhookMode, argWebhookMode, "", sink.WebhookBatch, "send all the results in a single request or a request per violation "+toOptionsString(sink.WebhookModes()))
+	flags.IntVarP(&analyzeArgs.WebhookRetries, argWebhookRetries, "", 3, "number of retries for failed webhook requests")
+	flags.StringVarP(&analyzeArgs.JiraURL, argJiraURL, "", "", "URL of a Jira instance to create (or update) an issue per failed violation in")
+	flags.StringVarP(&analyzeArgs.JiraUser, argJiraUser, "", "", "Jira user (email) that authenticates with the API token (Jira Cloud). Leave empty to use the token as a personal access token")
+	flags.StringVarP(&analyzeArgs.JiraToken, argJiraToken, "", "", "Jira API token or personal access token (can be set via the environment variable JIRA_TOKEN)")
+	flags.StringVarP(&analyzeArgs.JiraProject, argJiraProject, "", "", "key of the Jira project to create the issues in")
+	flags.StringVarP(&analyzeArgs.JiraIssueType, argJiraIssueType, "", sink.DefaultJiraIssueType, "type of the created Jira issues")
+	flags.StringVarP(&analyzeArgs.JiraMinSeverity, argJiraMinSeverity, "", sink.DefaultJiraMinSeverity, "minimum severity of the policies to create Jira issues for")
+	flags.BoolVarP(&analyzeArgs.CheckRun, argCheckRun, "", false, "report the results as a GitHub check run of a commit, annotating the violations found in the files of the repository (GitHub only)")
+	flags.StringVarP(&analyzeArgs.CheckRunRepo, argCheckRunRepo, "", "", "repository (owner/name) to create the check run on (detected when running in GitHub Actions)")
+	flags.StringVarP(&analyzeArgs.CheckRunSHA, argCheckRunSHA, "", "", "commit sha to create the check run on (detected when running in GitHub Actions)")
+	flags.StringVarP(&analyzeArgs.CheckRunName, argCheckRunName, "", sink.DefaultCheckRunName, "name of the check run")
 
 	return analyzeCmd
 }
@@ -84,13 +179,237 @@ func validateAnalyzeArgs() error {
 		return err
 	}
 
+	if analyzeArgs.PublicSeverityBump < 0 {
+		return fmt.Errorf("--%s must be non-negative", argPublicSeverityBump)
+	}
+
+	if analyzeArgs.ScorecardConcurrency < 1 {
+		return fmt.Errorf("--%s must be positive", argScorecardConcurrency)
+	}
+
+	if analyzeArgs.MemoryBudget < 0 {
+		return fmt.Errorf("--%s must be non-negative", argMemoryBudget)
+	}
+
+	if analyzeArgs.MinCoverage < 0 || analyzeArgs.MinCoverage > 100 {
+		return fmt.Errorf("--%s must be a percentage between 0 and 100", argMinCoverage)
+	}
+
+	if err := validateWebhookArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if err := validateJiraArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if err := validateCheckRunArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if err := validateCollectArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if err := validateMaxRepositoriesArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if err := validateInventoryArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if err := validateNDJSONArgs(&analyzeArgs); err != nil {
+		return err
+	}
+
+	if analyzeArgs.PolicySeverityThreshold != "" && !severity.IsValid(analyzeArgs.PolicySeverityThreshold) {
+		return fmt.Errorf("invalid --%s: %s", argPolicySeverityThreshold, analyzeArgs.PolicySeverityThreshold)
+	}
+
 	if len(analyzeArgs.Organizations) != 0 && len(analyzeArgs.Repositories) != 0 {
 		return fmt.Errorf("cannot use --org & --repo options together")
 	}
 
+	if analyzeArgs.Aggregate {
+		if len(analyzeArgs.Repositories) != 0 {
+			return fmt.Errorf("cannot use --%s & --%s options together", argAggregate, argRepository)
+		}
+		if analyzeArgs.GitLabToken == "" {
+			analyzeArgs.GitLabToken = viper.GetString(EnvGitLabToken)
+			if analyzeArgs.GitLabToken == "" {
+				return fmt.Errorf("--%s requires a gitlab token (--%s or GITLAB_TOKEN)", argAggregate, argGitLabToken)
+			}
+		}
+	}
+
 	return nil
 }
 
+// validateNDJSONArgs rejects the options that require the complete results, which are not kept when streaming,
+// and the output options that do not apply to the streamed records
+func validateNDJSONArgs(analyzeArgs *args) error {
+	if !analyzeArgs.NDJSON {
+		return nil
+	}
+
+	conflicts := map[string]bool{
+		argOutputFormat:           analyzeArgs.OutputFormat != formatter.Human,
+		argOutputScheme:           analyzeArgs.OutputScheme != scheme.DefaultScheme,
+		argMaxViolationsPerPolicy: analyzeArgs.MaxViolationsPerPolicy != 0,
+		argOnlyFailures:           analyzeArgs.OnlyFailures,
+		argWebhookURL:             analyzeArgs.WebhookURL != "",
+		argJiraURL:                analyzeArgs.JiraURL != "",
+		argCheckRun:               analyzeArgs.CheckRun,
+		argOwnersOutputDir:        analyzeArgs.OwnersOutputDir != "",
+		argMemoryBudget:           analyzeArgs.MemoryBudget > 0,
+		argSeveritySummaryOnly:    analyzeArgs.SeveritySummaryOnly,
+	}
+	for _, arg := range []string{argOutputFormat, argOutputScheme, argMaxViolationsPerPolicy, argOnlyFailures,
+		argWebhookURL, argJiraURL, argCheckRun, argOwnersOutputDir, argMemoryBudget, argSeveritySummaryOnly} {
+		if conflicts[arg] {
+			return fmt.Errorf("cannot use --%s & --%s options together", argNDJSON, arg)
+		}
+	}
+	return nil
+}
+
+func validateWebhookArgs(analyzeArgs *args) error {
+	if analyzeArgs.WebhookURL == "" {
+		if len(analyzeArgs.WebhookHeaders) != 0 {
+			return fmt.Errorf("--%s requires --%s", argWebhookHeader, argWebhookURL)
+		}
+		return nil
+	}
+
+	if _, err := parseWebhookHeaders(analyzeArgs.WebhookHeaders); err != nil {
+		return err
+	}
+
+	if analyzeArgs.WebhookRetries < 0 {
+		return fmt.Errorf("--%s must be non-negative", argWebhookRetries)
+	}
+
+	return sink.ValidateWebhookMode(analyzeArgs.WebhookMode)
+}
+
+func validateJiraArgs(analyzeArgs *args) error {
+	if analyzeArgs.JiraURL == "" {
+		return nil
+	}
+
+	if analyzeArgs.JiraProject == "" {
+		return fmt.Errorf("--%s requires --%s", argJiraURL, argJiraProject)
+	}
+
+	if analyzeArgs.JiraToken == "" {
+		analyzeArgs.JiraToken = viper.GetString(EnvJiraToken)
+		if analyzeArgs.JiraToken == "" {
+			return fmt.Errorf("--%s requires a token (--%s or JIRA_TOKEN)", argJiraURL, argJiraToken)
+		}
+	}
+
+	analyzeArgs.JiraMinSeverity = strings.ToUpper(analyzeArgs.JiraMinSeverity)
+	if !severity.IsValid(analyzeArgs.JiraMinSeverity) {
+		return fmt.Errorf("invalid --%s: %s", argJiraMinSeverity, analyzeArgs.JiraMinSeverity)
+	}
+
+	return nil
+}
+
+func validateCollectArgs(analyzeArgs *args) error {
+	if len(analyzeArgs.Collect) == 0 {
+		return nil
+	}
+
+	if analyzeArgs.ScmType != scm_type.GitHub || analyzeArgs.Aggregate {
+		return fmt.Errorf("--%s is supported for GitHub only", argCollect)
+	}
+
+	for _, group := range analyzeArgs.Collect {
+		if err := data_groups.Validate(group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateMaxRepositoriesArgs(analyzeArgs *args) error {
+	if analyzeArgs.MaxRepositories == 0 {
+		return nil
+	}
+
+	if analyzeArgs.MaxRepositories < 0 {
+		return fmt.Errorf("--%s must be non-negative", argMaxRepositories)
+	}
+	if analyzeArgs.ScmType != scm_type.GitHub || analyzeArgs.Aggregate {
+		return fmt.Errorf("--%s is supported for GitHub only", argMaxRepositories)
+	}
+	if len(analyzeArgs.Repositories) != 0 {
+		return fmt.Errorf("cannot use --%s & --%s options together", argMaxRepositories, argRepository)
+	}
+
+	return nil
+}
+
+func validateInventoryArgs(analyzeArgs *args) error {
+	if analyzeArgs.InventoryFile == "" {
+		if len(analyzeArgs.InventoryAttributes) != 0 {
+			return fmt.Errorf("--%s requires --%s", argInventoryAttribute, argInventoryFile)
+		}
+		return nil
+	}
+
+	if analyzeArgs.InventoryFormat != inventory.FormatJson && analyzeArgs.InventoryFormat != inventory.FormatCsv {
+		return fmt.Errorf("invalid --%s: %s", argInventoryFormat, analyzeArgs.InventoryFormat)
+	}
+
+	_, err := inventory.ParseNamespaceAttributes(analyzeArgs.InventoryAttributes)
+	return err
+}
+
+func validateCheckRunArgs(analyzeArgs *args) error {
+	if !analyzeArgs.CheckRun {
+		return nil
+	}
+
+	if analyzeArgs.ScmType != scm_type.GitHub || analyzeArgs.Aggregate {
+		return fmt.Errorf("--%s is supported for GitHub only", argCheckRun)
+	}
+
+	analyzeArgs.detectGitHubActionsCheckRun()
+	if analyzeArgs.CheckRunRepo == "" || analyzeArgs.CheckRunSHA == "" {
+		return fmt.Errorf("--%s requires a repository (--%s) and a commit sha (--%s)", argCheckRun, argCheckRunRepo, argCheckRunSHA)
+	}
+
+	if _, _, err := splitCheckRunRepo(analyzeArgs.CheckRunRepo); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func splitCheckRunRepo(repository string) (owner string, repo string, err error) {
+	owner, repo, found := strings.Cut(repository, "/")
+	if !found || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid --%s %s (expecting owner/name)", argCheckRunRepo, repository)
+	}
+	return owner, repo, nil
+}
+
+func parseWebhookHeaders(headers []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(headers))
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid webhook header %q (expecting 'Key: Value')", header)
+		}
+		parsed[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return parsed, nil
+}
+
 func setupExecutor(analyzeArgs *args) (*analyzeExecutor, error) {
 	switch analyzeArgs.ScmType {
 	case scm_type.GitHub:
@@ -107,6 +426,7 @@ func executeAnalyzeCommand(cmd *cobra.Command, _args []string) error {
 	if err := analyzeArgs.applyCommonCollectionOptions(); err != nil {
 		return err
 	}
+	analyzeArgs.detectGitHubActionsRepository()
 
 	if preExit, err := analyzeArgs.applySchemeOutputOptions(); err != nil {
 		return err
@@ -114,21 +434,111 @@ func executeAnalyzeCommand(cmd *cobra.Command, _args []string) error {
 		defer preExit()
 	}
 
+	if analyzeArgs.PrintPolicies {
+		if err := namespace.ValidateNamespaces(analyzeArgs.Namespaces); err != nil {
+			return err
+		}
+		return printPolicies(&analyzeArgs, os.Stdout)
+	}
+
 	if err := validateAnalyzeArgs(); err != nil {
 		return err
 	}
 
+	analyzeArgs.scan = newScanState(&analyzeArgs)
+
+	if analyzeArgs.CoverageFile != "" {
+		coverageFile, err := openForWrite(analyzeArgs.CoverageFile)
+		if err != nil {
+			return err
+		}
+		analyzeArgs.scan.coverage = coverage.NewReport(coverageFile)
+		defer coverageFile.Close()
+		defer analyzeArgs.scan.coverage.Flush()
+	}
+
+	if analyzeArgs.InventoryFile != "" {
+		inventoryFile, err := openForWrite(analyzeArgs.InventoryFile)
+		if err != nil {
+			return err
+		}
+		// the attributes are validated before
+		attributes, _ := inventory.ParseNamespaceAttributes(analyzeArgs.InventoryAttributes)
+		analyzeArgs.scan.inventory = inventory.NewInventory(inventoryFile, analyzeArgs.InventoryFormat, attributes)
+		defer inventoryFile.Close()
+		defer analyzeArgs.scan.inventory.Flush()
+	}
+
+	if analyzeArgs.ProtectionReportFile != "" {
+		protectionFile, err := openForWrite(analyzeArgs.ProtectionReportFile)
+		if err != nil {
+			return err
+		}
+		analyzeArgs.scan.protection = protection.NewReport(protectionFile)
+		defer protectionFile.Close()
+		defer analyzeArgs.scan.protection.Flush()
+	}
+
 	// to make sure scorecard works
 	if err := os.Setenv("GITHUB_AUTH_TOKEN", analyzeArgs.Token); err != nil {
 		return err
 	}
 
-	executor, err := setupExecutor(&analyzeArgs)
+	var executor interface {
+		Run() error
+		Failures() outputer.FailureSummary
+	}
+	var err error
+	if analyzeArgs.Aggregate {
+		executor, err = setupAggregate(&analyzeArgs)
+	} else {
+		executor, err = setupExecutor(&analyzeArgs)
+	}
 	if err != nil {
 		return err
 	}
 
 	screen.Printf("Note: to get the OpenSSF scorecard results for the organization repositories use the --scorecard option\n\n")
+	if analyzeArgs.MaxRepositories > 0 {
+		screen.Printf("Note: only the first %d repositories of each organization are collected (--%s), the results are a sample\n\n",
+			analyzeArgs.MaxRepositories, argMaxRepositories)
+	}
 
-	return executor.Run()
+	if err = executor.Run(); err != nil {
+		return err
+	}
+
+	if err = checkMinCoverage(analyzeArgs.scan.collection, analyzeArgs.MinCoverage); err != nil {
+		return err
+	}
+
+	return checkNewFailures(executor.Failures(), analyzeArgs.Baseline != "", analyzeArgs.FailOnNew)
+}
+
+// checkNewFailures reports the new failed violations (those that are not in the baseline),
+// and fails the run when there are any and --fail-on-new is set
+func checkNewFailures(failures outputer.FailureSummary, hasBaseline bool, failOnNew bool) error {
+	if hasBaseline {
+		screen.Printf("\n%d new failed violations, %d failed violations in the baseline (--%s)\n", failures.New, failures.Baselined, argBaseline)
+	}
+
+	if failOnNew && failures.New > 0 {
+		return fmt.Errorf("found %d new failed violations (--%s)", failures.New, argFailOnNew)
+	}
+	return nil
+}
+
+// checkMinCoverage fails the run when most entities were partially blocked by missing permissions,
+// since the results of such a scan are misleading
+func checkMinCoverage(collection *errlog.CollectionLog, minCoverage float64) error {
+	if minCoverage == 0 {
+		return nil
+	}
+
+	ratio, collected, blocked := collection.Coverage()
+	if ratio*100 < minCoverage {
+		return fmt.Errorf("collection coverage %.1f%% is below the minimum of %.1f%%: %d of %d collected entities are missing permissions (see --%s)",
+			ratio*100, minCoverage, blocked, collected, ArgPermissionsOutputFile)
+	}
+	return nil
 }
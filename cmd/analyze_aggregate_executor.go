@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Legit-Labs/legitify/cmd/progressbar"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer"
+)
+
+// providerPipeline is the pipeline of a single provider in an aggregated analysis
+type providerPipeline struct {
+	scmType  scm_type.ScmType
+	pipeline *analyzePipeline
+}
+
+// analyzeAggregateExecutor runs the pipelines of multiple providers and merges their results into a single output
+type analyzeAggregateExecutor struct {
+	providers []providerPipeline
+	out       outputer.Outputer
+	scan      *scanState
+}
+
+func setupAggregate(analyzeArgs *args) (*analyzeAggregateExecutor, error) {
+	githubArgs := *analyzeArgs
+	githubArgs.ScmType = scm_type.GitHub
+
+	gitlabArgs := *analyzeArgs
+	gitlabArgs.ScmType = scm_type.GitLab
+	gitlabArgs.Token = analyzeArgs.GitLabToken
+	gitlabArgs.Endpoint = analyzeArgs.GitLabEndpoint
+
+	githubPipeline, err := setupGitHubPipeline(&githubArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup %s: %v", scm_type.GitHub, err)
+	}
+
+	gitlabPipeline, err := setupGitLabPipeline(&gitlabArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup %s: %v", scm_type.GitLab, err)
+	}
+
+	return &analyzeAggregateExecutor{
+		providers: []providerPipeline{
+			{scmType: scm_type.GitHub, pipeline: githubPipeline},
+			{scmType: scm_type.GitLab, pipeline: gitlabPipeline},
+		},
+		// a single output for the merged results; its sinks of github (e.g. --check-run) use the github client
+		out:  provideOutputer(githubPipeline.ctx, githubPipeline.client, &githubArgs),
+		scan: analyzeArgs.scan,
+	}, nil
+}
+
+// merge runs the providers one after the other and tags their results with the provider.
+// The policy names are qualified by the provider since both providers may have policies with the same name.
+func (r *analyzeAggregateExecutor) merge() <-chan enricher.EnrichedData {
+	merged := make(chan enricher.EnrichedData)
+
+	go func() {
+		defer close(merged)
+		for _, p := range r.providers {
+			for data := range p.pipeline.enrich() {
+				data.ScmType = p.scmType
+				data.FullyQualifiedPolicyName = p.scmType + "/" + data.FullyQualifiedPolicyName
+				merged <- data
+			}
+		}
+	}()
+
+	return merged
+}
+
+func (r *analyzeAggregateExecutor) Run() error {
+	defer errlog.FlushAll()
+
+	// let progress bar run in the background
+	pWaiter := progressbar.Run()
+
+	outputWaiter := r.out.Digest(r.scan.withAPICalls(r.merge()))
+
+	// the providers run sequentially, so wait for all of them to be digested before waiting for the progress bars
+	outputWaiter.Wait()
+	pWaiter.Wait()
+
+	defer r.scan.reportAPICalls()
+	return r.out.Output(os.Stdout)
+}
+
+func (r *analyzeAggregateExecutor) Failures() outputer.FailureSummary {
+	return r.out.Failures()
+}
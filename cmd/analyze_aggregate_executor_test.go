@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/stretchr/testify/require"
+)
+
+type emptyManager struct{}
+
+func (emptyManager) Collect() <-chan collectors.CollectedData {
+	ch := make(chan collectors.CollectedData)
+	close(ch)
+	return ch
+}
+
+type emptyAnalyzer struct{}
+
+func (emptyAnalyzer) Analyze(dataChannel <-chan collectors.CollectedData) <-chan analyzers.AnalyzedData {
+	ch := make(chan analyzers.AnalyzedData)
+	go func() {
+		defer close(ch)
+		for range dataChannel {
+		}
+	}()
+	return ch
+}
+
+// fixedEnricherManager produces the given results once the analysis is done
+type fixedEnricherManager struct {
+	enricher.EnricherManager
+	results []enricher.EnrichedData
+}
+
+func (m fixedEnricherManager) Enrich(_ context.Context, analyzedDataChannel <-chan analyzers.AnalyzedData) <-chan enricher.EnrichedData {
+	ch := make(chan enricher.EnrichedData)
+	go func() {
+		defer close(ch)
+		for range analyzedDataChannel {
+		}
+		for _, result := range m.results {
+			ch <- result
+		}
+	}()
+	return ch
+}
+
+func newFixedPipeline(policies ...string) *analyzePipeline {
+	var results []enricher.EnrichedData
+	for _, policy := range policies {
+		results = append(results, enricher.EnrichedData{
+			PolicyName:               policy,
+			FullyQualifiedPolicyName: "data.repository." + policy,
+		})
+	}
+	return initializeAnalyzePipeline(emptyManager{}, emptyAnalyzer{}, fixedEnricherManager{results: results}, context.Background(), nil)
+}
+
+func TestAggregateExecutorMerge(t *testing.T) {
+	executor := &analyzeAggregateExecutor{
+		providers: []providerPipeline{
+			{scmType: scm_type.GitHub, pipeline: newFixedPipeline("repository_not_maintained", "code_review_not_required")},
+			{scmType: scm_type.GitLab, pipeline: newFixedPipeline("repository_not_maintained")},
+		},
+	}
+
+	var merged []enricher.EnrichedData
+	for data := range executor.merge() {
+		merged = append(merged, data)
+	}
+
+	require.Len(t, merged, 3, "expecting the results of all the providers")
+	expected := []struct {
+		scmType scm_type.ScmType
+		name    string
+	}{
+		{scm_type.GitHub, "github/data.repository.repository_not_maintained"},
+		{scm_type.GitHub, "github/data.repository.code_review_not_required"},
+		{scm_type.GitLab, "gitlab/data.repository.repository_not_maintained"},
+	}
+	for i, data := range merged {
+		require.Equal(t, expected[i].scmType, data.ScmType)
+		require.Equal(t, expected[i].name, data.FullyQualifiedPolicyName,
+			"expecting the policy names to be qualified by the provider, so the same policy of both providers is kept apart")
+	}
+}
@@ -12,25 +12,47 @@ import (
 	"github.com/Legit-Labs/legitify/internal/outputer"
 )
 
-type analyzeExecutor struct {
+// analyzePipeline collects, analyzes and enriches the entities of a single provider (the part of the analysis before the output)
+type analyzePipeline struct {
 	manager         collectors_manager.CollectorManager
 	analyzer        analyzers.Analyzer
 	enricherManager enricher.EnricherManager
-	out             outputer.Outputer
 	ctx             context.Context
+	client          Client
 }
 
-func initializeAnalyzeExecutor(manager collectors_manager.CollectorManager,
+func initializeAnalyzePipeline(manager collectors_manager.CollectorManager,
 	analyzer analyzers.Analyzer,
 	enricherManager enricher.EnricherManager,
-	outputer outputer.Outputer,
-	ctx context.Context) *analyzeExecutor {
-	return &analyzeExecutor{
+	ctx context.Context,
+	client Client) *analyzePipeline {
+	return &analyzePipeline{
 		manager:         manager,
 		analyzer:        analyzer,
 		enricherManager: enricherManager,
-		out:             outputer,
 		ctx:             ctx,
+		client:          client,
+	}
+}
+
+// enrich starts the collection, analysis and enrichment parts of the pipeline in the background
+func (p *analyzePipeline) enrich() <-chan enricher.EnrichedData {
+	collectionChan := p.manager.Collect()
+	analyzedDataChan := p.analyzer.Analyze(collectionChan)
+	return p.enricherManager.Enrich(p.ctx, analyzedDataChan)
+}
+
+type analyzeExecutor struct {
+	pipeline *analyzePipeline
+	out      outputer.Outputer
+	scan     *scanState
+}
+
+func initializeAnalyzeExecutor(pipeline *analyzePipeline, outputer outputer.Outputer, analyzeArgs *args) *analyzeExecutor {
+	return &analyzeExecutor{
+		pipeline: pipeline,
+		out:      outputer,
+		scan:     analyzeArgs.scan,
 	}
 }
 
@@ -41,10 +63,8 @@ func (r *analyzeExecutor) Run() error {
 	pWaiter := progressbar.Run()
 
 	// start all pipeline parts in the background
-	collectionChan := r.manager.Collect()
-	analyzedDataChan := r.analyzer.Analyze(collectionChan)
-	enrichedDataChan := r.enricherManager.Enrich(r.ctx, analyzedDataChan)
-	outputWaiter := r.out.Digest(enrichedDataChan)
+	enrichedDataChan := r.pipeline.enrich()
+	outputWaiter := r.out.Digest(r.scan.withAPICalls(enrichedDataChan))
 
 	// wait for progress bars to finish before outputting
 	pWaiter.Wait()
@@ -52,5 +72,10 @@ func (r *analyzeExecutor) Run() error {
 	// wait for output to be digested
 	outputWaiter.Wait()
 
+	defer r.scan.reportAPICalls()
 	return r.out.Output(os.Stdout)
 }
+
+func (r *analyzeExecutor) Failures() outputer.FailureSummary {
+	return r.out.Failures()
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNDJSONArgs(t *testing.T) {
+	streamed := func(modify func(a *args)) *args {
+		a := &args{NDJSON: true, OutputFormat: formatter.Human, OutputScheme: scheme.DefaultScheme}
+		modify(a)
+		return a
+	}
+
+	require.Nil(t, validateNDJSONArgs(streamed(func(a *args) {})))
+	require.Nil(t, validateNDJSONArgs(streamed(func(a *args) { a.FailedOnly = true })))
+	require.Nil(t, validateNDJSONArgs(&args{OnlyFailures: true, WebhookURL: "https://example.com"}), "expecting no conflicts without streaming")
+
+	conflicts := map[string]func(a *args){
+		argOutputFormat:           func(a *args) { a.OutputFormat = formatter.Json },
+		argOutputScheme:           func(a *args) { a.OutputScheme = scheme.TypeGroupByNamespace },
+		argMaxViolationsPerPolicy: func(a *args) { a.MaxViolationsPerPolicy = 10 },
+		argOnlyFailures:           func(a *args) { a.OnlyFailures = true },
+		argWebhookURL:             func(a *args) { a.WebhookURL = "https://example.com" },
+		argJiraURL:                func(a *args) { a.JiraURL = "https://example.atlassian.net" },
+		argCheckRun:               func(a *args) { a.CheckRun = true },
+		argOwnersOutputDir:        func(a *args) { a.OwnersOutputDir = "owners" },
+		argMemoryBudget:           func(a *args) { a.MemoryBudget = 100 },
+		argSeveritySummaryOnly:    func(a *args) { a.SeveritySummaryOnly = true },
+	}
+	for arg, modify := range conflicts {
+		err := validateNDJSONArgs(streamed(modify))
+		require.NotNil(t, err, "expecting --%s to conflict with --%s", arg, argNDJSON)
+		require.Contains(t, err.Error(), arg)
+	}
+}
+
+func TestValidateMaxRepositoriesArgs(t *testing.T) {
+	require.Nil(t, validateMaxRepositoriesArgs(&args{ScmType: scm_type.GitLab}), "expecting no validation without a sample")
+	require.Nil(t, validateMaxRepositoriesArgs(&args{ScmType: scm_type.GitHub, MaxRepositories: 10, Organizations: []string{"org"}}))
+
+	invalid := map[string]*args{
+		"negative":   {ScmType: scm_type.GitHub, MaxRepositories: -1},
+		"gitlab":     {ScmType: scm_type.GitLab, MaxRepositories: 10},
+		"aggregate":  {ScmType: scm_type.GitHub, MaxRepositories: 10, Aggregate: true},
+		"repository": {ScmType: scm_type.GitHub, MaxRepositories: 10, Repositories: []string{"org/repo"}},
+	}
+	for name, a := range invalid {
+		require.NotNil(t, validateMaxRepositoriesArgs(a), "expecting --%s to be rejected with %s", argMaxRepositories, name)
+	}
+}
+
+func TestCheckNewFailures(t *testing.T) {
+	baselinedOnly := outputer.FailureSummary{Baselined: 3}
+	withNew := outputer.FailureSummary{New: 1, Baselined: 3}
+
+	require.Nil(t, checkNewFailures(baselinedOnly, true, true), "expecting no error when all the failures are in the baseline")
+	require.Nil(t, checkNewFailures(withNew, true, false), "expecting no error without --fail-on-new")
+	require.NotNil(t, checkNewFailures(withNew, true, true), "expecting an error for a new failure")
+}
+
+func TestCheckMinCoverage(t *testing.T) {
+	collection := errlog.NewCollectionLog()
+	for i := 0; i < 4; i++ {
+		collection.Add(namespace.Repository)
+	}
+	collection.AddPermIssue(errlog.NewPermIssue("admin", "org/repo", namespace.Repository, "cannot read the branch protection"))
+
+	require.Nil(t, checkMinCoverage(collection, 75), "expecting the coverage of the scan to be 75%")
+	require.NotNil(t, checkMinCoverage(collection, 80))
+	require.Nil(t, checkMinCoverage(nil, 0), "expecting no check without --min-coverage")
+	require.NotNil(t, checkMinCoverage(errlog.NewCollectionLog(), 10), "expecting a scan that collected nothing to fail the check")
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
+	"github.com/Legit-Labs/legitify/internal/common/map_utils"
 	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/Legit-Labs/legitify/internal/errlog"
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
@@ -26,6 +29,7 @@ type args struct {
 	Repositories               []string
 	Enterprises                []string
 	PoliciesPath               []string
+	PoliciesConfig             string
 	Namespaces                 []string
 	IgnoredPolicies            string
 	ColorWhen                  string
@@ -34,17 +38,71 @@ type args struct {
 	OutputFormat               string
 	OutputScheme               string
 	ScorecardWhen              string
+	ScorecardConcurrency       int
 	InputFile                  string
+	NDJSON                     bool
 	FailedOnly                 bool
+	OnlyFailures               bool
+	SeveritySummaryOnly        bool
+	OutputProfile              string
+	Lang                       string
+	MessagesPath               string
 	SimulateSecondaryRateLimit bool
 	IgnoreInvalidCertificate   bool
 	PermissionsOutputFile      string
+	ManifestFile               string
+	ManifestKey                string
+	Aggregate                  bool
+	GitLabToken                string
+	GitLabEndpoint             string
+	CheckRateLimit             bool
+	MaxViolationsPerPolicy     int
+	JsonIndent                 string
+	WebhookURL                 string
+	WebhookHeaders             []string
+	WebhookMode                string
+	WebhookRetries             int
+	JiraURL                    string
+	JiraUser                   string
+	JiraToken                  string
+	JiraProject                string
+	JiraIssueType              string
+	JiraMinSeverity            string
+	CheckRun                   bool
+	CheckRunRepo               string
+	CheckRunSHA                string
+	CheckRunName               string
+	PublicSeverityBump         int
+	MemoryBudget               int
+	CollectActionsStorage      bool
+	SkipSelfHostedRunners      bool
+	CollectWorkflowContents    bool
+	Collect                    []string
+	MaxRepositories            int
+	PolicySeverityThreshold    string
+	CoverageFile               string
+	PrintPolicies              bool
+	MinCoverage                float64
+	Baseline                   string
+	FailOnNew                  bool
+	InventoryFile              string
+	InventoryFormat            string
+	InventoryAttributes        []string
+	OwnersOutputDir            string
+	ProtectionReportFile       string
+	Policy                     string
+
+	// scan is the state of the scan (nil when the results are not of a scan), e.g. its metadata at the top of the outputs.
+	// It is shared by the copies of the arguments of each provider (see --aggregate).
+	scan *scanState
 }
 
 const (
 	ArgErrorFile                = "error-file"
 	ArgOutputFile               = "output-file"
 	ArgPermissionsOutputFile    = "permissions-file"
+	ArgManifestFile             = "manifest-file"
+	ArgManifestKey              = "manifest-key"
 	ArgToken                    = "token"
 	ArgServerUrl                = "server-url"
 	ArgIgnoreInvalidCertificate = "ignore-invalid-certificate"
@@ -52,20 +110,29 @@ const (
 )
 
 const (
-	EnvToken     = "legitify_token"
-	NewEnvToken  = "scm_token"
-	EnvServerUrl = "server_url"
+	EnvToken       = "legitify_token"
+	NewEnvToken    = "scm_token"
+	EnvServerUrl   = "server_url"
+	EnvManifestKey = "manifest_key"
+	EnvGitLabToken = "gitlab_token"
+	EnvJiraToken   = "jira_token"
 )
 
 func (a *args) addOutputOptions(flags *pflag.FlagSet) {
 	colorWhens := toOptionsString(ColorOptions())
-	flags.StringVarP(&a.OutputFile, ArgOutputFile, "o", "", "output file, defaults to stdout")
+	flags.StringVarP(&a.OutputFile, ArgOutputFile, "o", "", "output file, defaults to stdout (use '-' for stdout explicitly)")
 	flags.StringVarP(&a.ErrorFile, ArgErrorFile, "e", "error.log", "error log path")
 	flags.StringVarP(&a.PermissionsOutputFile, ArgPermissionsOutputFile, "", "permissions_log.json", "permissions and skipped policies log path")
 	flags.StringVarP(&a.ColorWhen, argColor, "", DefaultColorOption, "when to use coloring "+colorWhens)
+	flags.StringVarP(&a.ManifestFile, ArgManifestFile, "", "", "path to write a signed manifest of the output file (requires --output-file)")
+	flags.StringVarP(&a.ManifestKey, ArgManifestKey, "", "", "key used to sign the output manifest (can be set via the environment variable MANIFEST_KEY)")
 }
 
 func (a *args) applyOutputOptions() (preExitHook func(), err error) {
+	if err := a.validateManifestOptions(); err != nil {
+		return nil, err
+	}
+
 	if err := setOutputFile(a.OutputFile); err != nil {
 		return nil, err
 	}
@@ -84,6 +151,12 @@ func (a *args) applyOutputOptions() (preExitHook func(), err error) {
 	}
 
 	return func() {
+		if a.ManifestFile != "" {
+			if err := writeOutputManifest(a.OutputFile, a.ManifestFile, a.ManifestKey); err != nil {
+				screen.Printf("failed to write output manifest: %v\n", err)
+			}
+		}
+
 		errlog.FlushAll()
 		if err := errFile.Close(); err != nil {
 			log.Printf("failed to close error file %s: %v", errFile.Name(), err)
@@ -99,6 +172,12 @@ func (a *args) applyOutputOptions() (preExitHook func(), err error) {
 		if errlog.HadPermIssues() {
 			buf.WriteString(fmt.Sprintf("Some policies skipped. Check %s for more details\n", permFile.Name()))
 		}
+		if failed := errlog.FailedNamespaces(); len(failed) > 0 {
+			buf.WriteString("Some namespaces could not be collected (the other namespaces were analyzed):\n")
+			for _, ns := range map_utils.ToKeySortedMap(failed).Keys() {
+				buf.WriteString(fmt.Sprintf("  - %s: %s\n", ns, failed[ns]))
+			}
+		}
 
 		if buf.Len() > 0 {
 			screen.Printf("\n\n%s\n", buf.String())
@@ -106,8 +185,27 @@ func (a *args) applyOutputOptions() (preExitHook func(), err error) {
 	}, nil
 }
 
+func (a *args) validateManifestOptions() error {
+	if a.ManifestFile == "" {
+		return nil
+	}
+
+	if a.OutputFile == "" || a.OutputFile == stdoutPath {
+		return fmt.Errorf("--%s requires --%s to be a file", ArgManifestFile, ArgOutputFile)
+	}
+
+	if a.ManifestKey == "" {
+		a.ManifestKey = viper.GetString(EnvManifestKey)
+		if a.ManifestKey == "" {
+			return fmt.Errorf("--%s requires a signing key (--%s or MANIFEST_KEY)", ArgManifestFile, ArgManifestKey)
+		}
+	}
+
+	return nil
+}
+
 func (a *args) addCommonCollectionOptions(flags *pflag.FlagSet) {
-	flags.StringVarP(&a.Token, ArgToken, "t", "", "token to authenticate with github/gitlab (required unless environment variable SCM_TOKEN is set)")
+	flags.StringVarP(&a.Token, ArgToken, "t", "", "token to authenticate with github/gitlab (required unless environment variable SCM_TOKEN is set, or GITHUB_TOKEN when running in GitHub Actions). GitHub accepts multiple comma separated tokens")
 	flags.StringVarP(&a.Endpoint, ArgServerUrl, "", "", "github/gitlab endpoint to use instead of the Cloud API (can be set via the environment variable SERVER_URL)")
 	flags.StringVarP(&a.ScmType, ScmType, "", scm_type.GitHub, "server type (GitHub, GitLab), defaults to GitHub")
 	flags.BoolVarP(&a.IgnoreInvalidCertificate, ArgIgnoreInvalidCertificate, "", false, "Ignore invalid server certificate")
@@ -130,6 +228,8 @@ func (a *args) applyCommonCollectionOptions() error {
 		a.Endpoint = viper.GetString(EnvServerUrl)
 	}
 
+	a.applyGitHubActionsEnvironment()
+
 	if a.IgnoreInvalidCertificate {
 		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	}
@@ -154,6 +254,13 @@ func (a *args) addSchemeOutputOptions(flags *pflag.FlagSet) {
 	flags.StringVarP(&a.OutputFormat, argOutputFormat, "f", formatter.Human, "output format "+formats)
 	flags.StringVarP(&a.OutputScheme, argOutputScheme, "", scheme.DefaultScheme, "output scheme "+schemeTypes)
 	flags.BoolVarP(&a.FailedOnly, argFailedOnly, "", false, "Only show violated policies (do not show succeeded/skipped)")
+	flags.BoolVarP(&a.OnlyFailures, argOnlyFailures, "", false, "drop the policies without failures from the results (the summary notes how many passed)")
+	flags.BoolVarP(&a.SeveritySummaryOnly, argSeveritySummaryOnly, "", false, "output only the aggregated counts of the results (per severity and namespace, entities and coverage) as compact json, without the violations (overrides the output format and scheme)")
+	flags.IntVarP(&a.MaxViolationsPerPolicy, argMaxViolationsPerPolicy, "", 0, "maximum number of violations to show per policy (0 means unlimited)")
+	flags.StringVarP(&a.JsonIndent, argJsonIndent, "", strconv.Itoa(len(formatter.DefaultOutputIndent)), "indentation of the json and sarif outputs: a number of spaces or "+formatter.JsonIndentCompact)
+	flags.StringVarP(&a.OutputProfile, argOutputProfile, "", scheme.DefaultProfile, "output profile "+toOptionsString(scheme.Profiles())+" (bundles the redaction and filtering of the results, see README)")
+	flags.StringVarP(&a.Lang, argLang, "", i18n.English, "language of the policies texts in the report (requires a message catalog, see --"+argMessagesPath+")")
+	flags.StringVarP(&a.MessagesPath, argMessagesPath, "", "", "directory containing the message catalogs (<lang>.yaml) that localize the policies texts")
 }
 
 func (a *args) applySchemeOutputOptions() (preExitHook func(), err error) {
@@ -161,6 +268,14 @@ func (a *args) applySchemeOutputOptions() (preExitHook func(), err error) {
 		return nil, err
 	}
 
+	if a.SeveritySummaryOnly {
+		a.OutputFormat = formatter.SeveritySummary
+		a.OutputScheme = scheme.TypeFlattened
+	}
+	if scheme.GetProfile(a.OutputProfile).FailedOnly {
+		a.FailedOnly = true
+	}
+
 	if preExitHook, err := a.applyOutputOptions(); err != nil {
 		return nil, err
 	} else {
@@ -168,6 +283,20 @@ func (a *args) applySchemeOutputOptions() (preExitHook func(), err error) {
 	}
 }
 
+// formatOptions returns the rendering settings of the output, which are passed to the formatter
+func (a *args) formatOptions() formatter.Options {
+	jsonIndent, _ := formatter.ParseJsonIndent(a.JsonIndent) // validated with the other scheme output options
+	options := formatter.Options{
+		MaxViolationsPerPolicy: a.MaxViolationsPerPolicy,
+		JsonIndent:             jsonIndent,
+	}
+	if a.scan != nil {
+		options.Metadata = a.scan.metadata
+		options.Collection = a.scan.collection
+	}
+	return options
+}
+
 func (a *args) validateSchemeOutputOptions() error {
 	if err := converter.ValidateOutputScheme(a.OutputScheme); err != nil {
 		return err
@@ -177,5 +306,25 @@ func (a *args) validateSchemeOutputOptions() error {
 		return err
 	}
 
+	if a.OutputFormat == formatter.Sqlite && (a.OutputFile == "" || a.OutputFile == stdoutPath) {
+		return fmt.Errorf("--%s %s requires --%s to be a file", argOutputFormat, formatter.Sqlite, ArgOutputFile)
+	}
+
+	if a.MaxViolationsPerPolicy < 0 {
+		return fmt.Errorf("--%s must not be negative", argMaxViolationsPerPolicy)
+	}
+
+	if _, err := formatter.ParseJsonIndent(a.JsonIndent); err != nil {
+		return fmt.Errorf("--%s: %v", argJsonIndent, err)
+	}
+
+	if err := scheme.ValidateProfile(a.OutputProfile); err != nil {
+		return err
+	}
+
+	if !i18n.IsEnglish(a.Lang) && a.MessagesPath == "" {
+		return fmt.Errorf("--%s requires the message catalogs directory (--%s)", argLang, argMessagesPath)
+	}
+
 	return nil
 }
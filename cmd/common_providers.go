@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"github.com/Legit-Labs/legitify/internal/clients/github"
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/Legit-Labs/legitify/internal/context_utils"
@@ -11,6 +13,8 @@ import (
 	"github.com/Legit-Labs/legitify/internal/opa"
 	"github.com/Legit-Labs/legitify/internal/opa/opa_engine"
 	"github.com/Legit-Labs/legitify/internal/outputer"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/Legit-Labs/legitify/internal/outputer/sink"
 	"log"
 	"os"
 	"strings"
@@ -27,18 +31,123 @@ func provideGenericClient(args *args) (Client, error) {
 	}
 }
 
-func provideOutputer(ctx context.Context, analyzeArgs *args) outputer.Outputer {
-	return outputer.NewOutputer(ctx, analyzeArgs.OutputFormat, analyzeArgs.OutputScheme, analyzeArgs.FailedOnly)
+// provideOutputer sets up the output and the sinks of the results. The client is the client of the analyzed SCM,
+// which the sinks of the same SCM reuse (nil when the results are not collected, e.g. by the evaluate command).
+func provideOutputer(ctx context.Context, client Client, analyzeArgs *args) outputer.Outputer {
+	if analyzeArgs.NDJSON {
+		return outputer.NewStreamOutputer(ctx, os.Stdout, analyzeArgs.FailedOnly, outputer.DefaultStreamBuffer)
+	}
+
+	var sinks []outputer.Sink
+	if analyzeArgs.WebhookURL != "" {
+		// arguments are validated before the setup
+		headers, _ := parseWebhookHeaders(analyzeArgs.WebhookHeaders)
+		webhook, err := sink.NewWebhook(sink.WebhookOptions{
+			URL:     analyzeArgs.WebhookURL,
+			Headers: headers,
+			Mode:    analyzeArgs.WebhookMode,
+			Retries: analyzeArgs.WebhookRetries,
+		})
+		if err != nil {
+			log.Printf("failed to setup webhook: %v", err)
+		} else {
+			sinks = append(sinks, webhook)
+		}
+	}
+
+	if analyzeArgs.JiraURL != "" {
+		jira, err := sink.NewJira(sink.JiraOptions{
+			URL:         analyzeArgs.JiraURL,
+			User:        analyzeArgs.JiraUser,
+			Token:       analyzeArgs.JiraToken,
+			ProjectKey:  analyzeArgs.JiraProject,
+			IssueType:   analyzeArgs.JiraIssueType,
+			MinSeverity: analyzeArgs.JiraMinSeverity,
+		})
+		if err != nil {
+			log.Printf("failed to setup jira: %v", err)
+		} else {
+			sinks = append(sinks, jira)
+		}
+	}
+
+	if analyzeArgs.CheckRun {
+		checkRun, err := provideCheckRun(client, analyzeArgs)
+		if err != nil {
+			log.Printf("failed to setup check run: %v", err)
+		} else {
+			sinks = append(sinks, checkRun)
+		}
+	}
+
+	if analyzeArgs.OwnersOutputDir != "" {
+		owners, err := sink.NewOwners(sink.OwnersOptions{
+			Dir:           analyzeArgs.OwnersOutputDir,
+			Format:        analyzeArgs.OutputFormat,
+			Scheme:        analyzeArgs.OutputScheme,
+			FailedOnly:    analyzeArgs.FailedOnly,
+			FormatOptions: analyzeArgs.formatOptions(),
+		})
+		if err != nil {
+			log.Printf("failed to setup owners output: %v", err)
+		} else {
+			sinks = append(sinks, owners)
+		}
+	}
+
+	return outputer.NewOutputer(ctx, analyzeArgs.OutputFormat, analyzeArgs.OutputScheme, analyzeArgs.FailedOnly, analyzeArgs.formatOptions(), sinks...)
+}
+
+func provideCheckRun(client Client, analyzeArgs *args) (*sink.CheckRun, error) {
+	// arguments are validated before the setup
+	owner, repo, _ := splitCheckRunRepo(analyzeArgs.CheckRunRepo)
+
+	// the check run is created with the client of the analysis, so it shares its endpoint, tokens and rate limit handling
+	ghClient, ok := client.(*github.Client)
+	if !ok {
+		return nil, fmt.Errorf("check run requires a github client")
+	}
+
+	return sink.NewCheckRun(sink.CheckRunOptions{
+		Client:  ghClient.Client(),
+		Owner:   owner,
+		Repo:    repo,
+		HeadSHA: analyzeArgs.CheckRunSHA,
+		Name:    analyzeArgs.CheckRunName,
+	})
 }
 
 func provideOpa(analyzeArgs *args) (opa_engine.Enginer, error) {
-	opaEngine, err := opa.Load(analyzeArgs.PoliciesPath, analyzeArgs.ScmType)
+	opaEngine, err := opa.Load(analyzeArgs.PoliciesPath, analyzeArgs.ScmType, analyzeArgs.policiesFilters()...)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := opa.LoadConfig(analyzeArgs.PoliciesConfig)
 	if err != nil {
 		return nil, err
 	}
+	opaEngine.SetConfig(config)
+
+	if analyzeArgs.scan != nil {
+		analyzeArgs.scan.coverage.AddPolicyFields(opa.PolicyInputFields(opaEngine.Modules()))
+	}
+
 	return opaEngine, nil
 }
 
+// policiesFilters drops the policies that should not be evaluated at all (see --policy-severity-threshold and explain --policy)
+func (a *args) policiesFilters() []opa.ModulesFilter {
+	var filters []opa.ModulesFilter
+	if a.PolicySeverityThreshold != "" {
+		filters = append(filters, opa.MinSeverity(a.PolicySeverityThreshold))
+	}
+	if a.Policy != "" {
+		filters = append(filters, opa.OnlyPolicy(a.Policy))
+	}
+	return filters
+}
+
 func getIgnoredPolicies(args *args) []string {
 	var result []string
 	path := args.IgnoredPolicies
@@ -64,6 +173,19 @@ func getIgnoredPolicies(args *args) []string {
 	return result
 }
 
+// loadBaseline reads the fingerprints of the accepted violations from a json output of a previous scan
+func loadBaseline(path string) (scheme.Baseline, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the baseline: %v", err)
+	}
+	return scheme.ParseBaseline(data)
+}
+
 func provideContext(client Client, args *args) (context.Context, error) {
 	ctx := context.Background()
 
@@ -84,11 +206,44 @@ func provideContext(client Client, args *args) (context.Context, error) {
 	ctx = context_utils.NewContextWithScorecard(ctx,
 		IsScorecardEnabled(args.ScorecardWhen),
 		IsScorecardVerbose(args.ScorecardWhen))
+	ctx = context_utils.NewContextWithScorecardConcurrency(ctx, args.ScorecardConcurrency)
 
 	ctx = context_utils.NewContextWithIsCloud(ctx, args.Endpoint == "")
 	ctx = context_utils.NewContextWithIgnoredPolicies(ctx, getIgnoredPolicies(args))
+	ctx = context_utils.NewContextWithPublicSeverityBump(ctx, args.PublicSeverityBump)
+	ctx = context_utils.NewContextWithMemoryBudget(ctx, int64(args.MemoryBudget)*1024*1024)
+	ctx = context_utils.NewContextWithActionsStorage(ctx, args.CollectActionsStorage)
+	ctx = context_utils.NewContextWithSkipSelfHostedRunners(ctx, args.SkipSelfHostedRunners)
+	ctx = context_utils.NewContextWithWorkflowContents(ctx, args.CollectWorkflowContents)
+	ctx = context_utils.NewContextWithDataGroups(ctx, args.Collect)
+	ctx = context_utils.NewContextWithMaxRepositories(ctx, args.MaxRepositories)
+	ctx = context_utils.NewContextWithOnlyFailures(ctx, args.OnlyFailures)
+	ctx = context_utils.NewContextWithOutputProfile(ctx, args.OutputProfile)
+
+	baseline, err := loadBaseline(args.Baseline)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context_utils.NewContextWithBaseline(ctx, baseline)
+
+	// some data is collected only when the policies have configured values to compare it with
+	config, err := opa.LoadConfig(args.PoliciesConfig)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context_utils.NewContextWithPoliciesConfig(ctx, opa.ConfigLists(config))
+
+	catalog, err := i18n.Load(args.Lang, args.MessagesPath)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context_utils.NewContextWithMessageCatalog(ctx, catalog)
+
+	ctx = context_utils.NewContextWithTokenScopes(ctx, client.Scopes())
+	ctx = args.scan.newContext(ctx)
+	recordScanMetadata(ctx, client, args)
 
-	return context_utils.NewContextWithTokenScopes(ctx, client.Scopes()), nil
+	return ctx, nil
 }
 
 func provideGPTAnalyzer(context context.Context, args *args) *gpt.Analyzer {
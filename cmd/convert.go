@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 	"github.com/spf13/cobra"
@@ -68,7 +69,26 @@ func executeConvertCommand(cmd *cobra.Command, _args []string) error {
 		return err
 	}
 
-	output, err := formatter.Format(convertArgs.OutputFormat, formatter.DefaultOutputIndent, flattened, convertArgs.FailedOnly)
+	// keep the provenance of the converted results
+	metadata, err := scheme.UnmarshalMetadata(inputData)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := i18n.Load(convertArgs.Lang, convertArgs.MessagesPath)
+	if err != nil {
+		return err
+	}
+
+	flattened = flattened.WithProfile(scheme.GetProfile(convertArgs.OutputProfile)).Localized(catalog)
+
+	options := convertArgs.formatOptions()
+	options.Metadata = metadata
+	if convertArgs.OnlyFailures {
+		flattened, options.PassedPolicies = flattened.OnlyFailedPolicies()
+	}
+
+	output, err := formatter.Format(convertArgs.OutputFormat, options, flattened, convertArgs.FailedOnly)
 	if err != nil {
 		return fmt.Errorf("failed to format: %v", err)
 	}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/analyzers/skippers"
+	"github.com/Legit-Labs/legitify/internal/collected"
+	"github.com/Legit-Labs/legitify/internal/collected/input"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/outputer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(newEvaluateCommand())
+}
+
+const (
+	stdinPath = "-"
+	argNDJSON = "ndjson"
+
+	// maxNDJSONLineSize bounds the memory used for a single entity of the stream
+	maxNDJSONLineSize = 64 * 1024 * 1024
+)
+
+var evaluateArgs args
+
+func newEvaluateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "evaluate",
+		Short:        `Evaluate the policies against entities collected by other tools (json, in the schema the policies see as input)`,
+		RunE:         executeEvaluateCommand,
+		SilenceUsage: true,
+	}
+
+	viper.AutomaticEnv()
+	flags := cmd.Flags()
+	evaluateArgs.addSchemeOutputOptions(flags)
+
+	flags.StringVarP(&evaluateArgs.ScmType, ScmType, "", scm_type.GitHub, "server type (GitHub, GitLab) of the policies to evaluate")
+	flags.StringSliceVarP(&evaluateArgs.Namespaces, argNamespace, "n", nil, "the namespace of the input entities (e.g. repository)")
+	flags.StringVar(&evaluateArgs.InputFile, argInputFile, stdinPath, "a json file with a single entity or an array of entities (use '-' for stdin)")
+	flags.StringSliceVarP(&evaluateArgs.PoliciesPath, argPoliciesPath, "p", []string{}, "directory containing opa policies")
+	flags.StringVarP(&evaluateArgs.PoliciesConfig, argPoliciesConfig, "", "", "path to a json/yaml document that is available to the policies as data.config (e.g. to override thresholds)")
+	flags.StringVarP(&evaluateArgs.IgnoredPolicies, argIgnorePolicies, "", "", "path to a file that contain \n separated list of policies to ignore")
+	flags.BoolVarP(&evaluateArgs.NDJSON, argNDJSON, "", false, "stream the input as newline delimited json (an entity per line) and stream the results as newline delimited json (cannot be combined with the output format and scheme)")
+
+	return cmd
+}
+
+func validateEvaluateArgs() error {
+	if err := scm_type.Validate(evaluateArgs.ScmType); err != nil {
+		return err
+	}
+
+	if len(evaluateArgs.Namespaces) != 1 {
+		return fmt.Errorf("please provide the namespace of the input entities (--%s)", argNamespace)
+	}
+
+	if err := validateNDJSONArgs(&evaluateArgs); err != nil {
+		return err
+	}
+
+	return namespace.ValidateNamespaces(evaluateArgs.Namespaces)
+}
+
+func readEvaluateInput(path string) ([]byte, error) {
+	if path == stdinPath {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func openEvaluateInput(path string) (io.ReadCloser, error) {
+	if path == stdinPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+func executeEvaluateCommand(cmd *cobra.Command, _args []string) error {
+	if err := validateEvaluateArgs(); err != nil {
+		return err
+	}
+
+	if preExit, err := evaluateArgs.applySchemeOutputOptions(); err != nil {
+		return err
+	} else {
+		defer preExit()
+	}
+
+	engine, err := provideOpa(&evaluateArgs)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := i18n.Load(evaluateArgs.Lang, evaluateArgs.MessagesPath)
+	if err != nil {
+		return err
+	}
+
+	ctx := context_utils.NewContextWithIgnoredPolicies(context.Background(), getIgnoredPolicies(&evaluateArgs))
+	ctx = context_utils.NewContextWithOnlyFailures(ctx, evaluateArgs.OnlyFailures)
+	ctx = context_utils.NewContextWithOutputProfile(ctx, evaluateArgs.OutputProfile)
+	ctx = context_utils.NewContextWithMessageCatalog(ctx, catalog)
+	analyzer := analyzers.NewAnalyzer(ctx, engine, skippers.NewInputSkipper(ctx))
+
+	if evaluateArgs.NDJSON {
+		reader, err := openEvaluateInput(evaluateArgs.InputFile)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %v", err)
+		}
+		defer reader.Close()
+		return evaluateStream(ctx, analyzer, evaluateArgs.ScmType, evaluateArgs.Namespaces[0], evaluateArgs.FailedOnly, reader, os.Stdout)
+	}
+
+	data, err := readEvaluateInput(evaluateArgs.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+
+	ns := evaluateArgs.Namespaces[0]
+	entities, err := input.Decode(evaluateArgs.ScmType, ns, data)
+	if err != nil {
+		return err
+	}
+
+	enrichedChan := enricher.NewEnricherManager().Enrich(ctx, analyzer.Analyze(collectedChannel(ns, entities...)))
+
+	out := provideOutputer(ctx, nil, &evaluateArgs)
+	out.Digest(enrichedChan).Wait()
+
+	return out.Output(os.Stdout)
+}
+
+func collectedChannel(ns namespace.Namespace, entities ...collected.Entity) <-chan collectors.CollectedData {
+	collectedChan := make(chan collectors.CollectedData, len(entities))
+	for _, entity := range entities {
+		collectedChan <- collectors.CollectedData{
+			Entity:        entity,
+			Namespace:     ns,
+			CanonicalLink: entity.CanonicalLink(),
+		}
+	}
+	close(collectedChan)
+	return collectedChan
+}
+
+// streamError reports an input line that could not be evaluated, so the consumer can correlate it
+type streamError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// evaluateStream evaluates each line of the input independently and writes its results before reading the next line,
+// so the memory is bounded by a single entity regardless of the stream length.
+// Invalid lines are reported in the output and do not stop the stream.
+func evaluateStream(ctx context.Context, analyzer analyzers.Analyzer, scmType scm_type.ScmType, ns namespace.Namespace, failedOnly bool,
+	reader io.Reader, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxNDJSONLineSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		entity, err := input.DecodeEntity(scmType, ns, line)
+		if err != nil {
+			log.Printf("skipping input line %d: %v", lineNumber, err)
+			if err = encoder.Encode(streamError{Line: lineNumber, Error: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		enrichedChan := enricher.NewEnricherManager().Enrich(ctx, analyzer.Analyze(collectedChannel(ns, entity)))
+		if err = outputer.Stream(ctx, enrichedChan, writer, failedOnly); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the input stream: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/analyzers/skippers"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/stretchr/testify/require"
+)
+
+type streamedLine struct {
+	Line      int    `json:"line"`
+	Error     string `json:"error"`
+	Violation *struct {
+		CanonicalLink string                 `json:"canonicalLink"`
+		Status        analyzers.PolicyStatus `json:"status"`
+	} `json:"violation"`
+}
+
+func runEvaluateStream(t *testing.T, failedOnly bool, input string) []streamedLine {
+	engine, err := opa.Load([]string{}, scm_type.GitHub)
+	require.Nil(t, err, "failed initializing opa client")
+	ctx := context.Background()
+	analyzer := analyzers.NewAnalyzer(ctx, engine, skippers.NewInputSkipper(ctx))
+
+	var output bytes.Buffer
+	require.Nil(t, evaluateStream(ctx, analyzer, scm_type.GitHub, namespace.Repository, failedOnly, strings.NewReader(input), &output))
+
+	var lines []streamedLine
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var line streamedLine
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &line), "expecting each output line to be json: %s", scanner.Text())
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestEvaluateStream(t *testing.T) {
+	input := strings.Join([]string{
+		`{"repository": {"name": "first", "Url": "https://github.com/owner/first", "DatabaseId": 1}}`,
+		``,
+		`{"repository":`,
+		`{"repository": {"name": "second", "Url": "https://github.com/owner/second", "DatabaseId": 2}}`,
+	}, "\n")
+
+	lines := runEvaluateStream(t, false, input)
+
+	var errors []streamedLine
+	results := make(map[string]int)
+	for _, line := range lines {
+		if line.Violation == nil {
+			errors = append(errors, line)
+			continue
+		}
+		results[line.Violation.CanonicalLink]++
+	}
+
+	require.Len(t, errors, 1, "expecting the invalid line to be reported")
+	require.Equal(t, 3, errors[0].Line, "expecting the line numbers to count the blank lines")
+	require.NotEmpty(t, errors[0].Error)
+
+	require.Len(t, results, 2, "expecting the results of the lines after the invalid line as well")
+	require.Equal(t, results["https://github.com/owner/first"], results["https://github.com/owner/second"],
+		"expecting each line to be evaluated by the same policies")
+}
+
+func TestEvaluateStreamFailedOnly(t *testing.T) {
+	input := `{"repository": {"name": "repo", "Url": "https://github.com/owner/repo", "DatabaseId": 1}}`
+
+	all := runEvaluateStream(t, false, input)
+	failed := runEvaluateStream(t, true, input)
+
+	require.NotEmpty(t, failed)
+	require.Less(t, len(failed), len(all), "expecting the results that did not fail to be dropped")
+	for _, line := range failed {
+		require.NotNil(t, line.Violation)
+		require.Equal(t, analyzers.PolicyFailed, line.Violation.Status)
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/Legit-Labs/legitify/cmd/progressbar"
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/analyzers/skippers"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/collectors/collectors_manager"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/coverage"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(newExplainCommand())
+}
+
+const (
+	argPolicy = "policy"
+)
+
+var explainArgs args
+
+func newExplainCommand() *cobra.Command {
+	explainCmd := &cobra.Command{
+		Use:          "explain",
+		Short:        `Explain the result of a single policy for a single repository, along with the input document the policy sees`,
+		RunE:         executeExplainCommand,
+		SilenceUsage: true,
+	}
+
+	viper.AutomaticEnv()
+	flags := explainCmd.Flags()
+	explainArgs.addOutputOptions(flags)
+	explainArgs.addCommonCollectionOptions(flags)
+
+	flags.StringVarP(&explainArgs.OutputFormat, argOutputFormat, "f", formatter.Human, "output format "+toOptionsString([]string{formatter.Human, formatter.Json}))
+	flags.StringSliceVarP(&explainArgs.Repositories, argRepository, "", nil, "the repository to collect (--repo owner/repo_name (e.g. ossf/scorecard)")
+	flags.StringVarP(&explainArgs.Policy, argPolicy, "", "", "the name of the policy to evaluate (e.g. code_review_not_required)")
+	flags.StringSliceVarP(&explainArgs.PoliciesPath, argPoliciesPath, "p", []string{}, "directory containing opa policies")
+	flags.StringVarP(&explainArgs.PoliciesConfig, argPoliciesConfig, "", "", "path to a json/yaml document that is available to the policies as data.config (e.g. to override thresholds)")
+
+	return explainCmd
+}
+
+// ExplainReport is the result of a policy for an entity, along with the input document the policy evaluated
+type ExplainReport struct {
+	Entity   string      `json:"entity"`
+	Link     string      `json:"link"`
+	Policy   string      `json:"policy"`
+	Title    string      `json:"title"`
+	Severity string      `json:"severity"`
+	Status   string      `json:"status"`
+	Reasons  []string    `json:"reasons"`
+	Input    interface{} `json:"input"`
+}
+
+func validateExplainArgs() error {
+	if len(explainArgs.Repositories) != 1 {
+		return fmt.Errorf("please provide a single repository to explain (--%s)", argRepository)
+	}
+	if explainArgs.Policy == "" {
+		return fmt.Errorf("please provide the policy to explain (--%s)", argPolicy)
+	}
+	if explainArgs.OutputFormat != formatter.Human && explainArgs.OutputFormat != formatter.Json {
+		return fmt.Errorf("invalid --%s: %s", argOutputFormat, explainArgs.OutputFormat)
+	}
+	return nil
+}
+
+func executeExplainCommand(cmd *cobra.Command, _args []string) error {
+	if err := explainArgs.applyCommonCollectionOptions(); err != nil {
+		return err
+	}
+	if err := validateExplainArgs(); err != nil {
+		return err
+	}
+	explainArgs.Namespaces = []namespace.Namespace{namespace.Repository}
+
+	if preExit, err := explainArgs.applyOutputOptions(); err != nil {
+		return err
+	} else {
+		defer preExit()
+	}
+
+	engine, err := provideOpa(&explainArgs)
+	if err != nil {
+		return err
+	}
+
+	ctx, collectorsList, err := provideExplainCollectors(&explainArgs)
+	if err != nil {
+		return err
+	}
+
+	pWaiter := progressbar.Run()
+	collected := collectors_manager.NewCollectorsManager(ctx, collectorsList).Collect()
+	var reports []ExplainReport
+	var reportErr error
+	// the results are drained even after a failure, so the collection and the progress bar finish
+	for data := range analyzers.NewAnalyzer(ctx, engine, skippers.NewSkipper(ctx, engine)).Analyze(collected) {
+		if data.PolicyName != explainArgs.Policy || reportErr != nil {
+			continue
+		}
+		report, err := newExplainReport(data)
+		if err != nil {
+			reportErr = err
+			continue
+		}
+		reports = append(reports, report)
+	}
+	pWaiter.Wait()
+	if reportErr != nil {
+		return reportErr
+	}
+
+	if len(reports) == 0 {
+		return fmt.Errorf("policy %s was not evaluated for %s (is it a %s policy?)", explainArgs.Policy, explainArgs.Repositories[0], namespace.Repository)
+	}
+
+	return writeExplainReports(reports, explainArgs.OutputFormat, os.Stdout)
+}
+
+func provideExplainCollectors(a *args) (context.Context, []collectors.Collector, error) {
+	switch a.ScmType {
+	case scm_type.GitHub:
+		client, err := provideGitHubClient(a)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx, err := provideContext(client, a)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ctx, provideGitHubCollectors(ctx, client, a), nil
+	case scm_type.GitLab:
+		client, err := provideGitLabClient(a)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx, err := provideContext(client, a)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ctx, provideGitLabCollectors(ctx, client, a), nil
+	default:
+		return nil, nil, fmt.Errorf("invalid scm type")
+	}
+}
+
+func newExplainReport(data analyzers.AnalyzedData) (ExplainReport, error) {
+	input, err := coverage.InputDocument(data.Entity)
+	if err != nil {
+		return ExplainReport{}, fmt.Errorf("failed to build the input document of %s: %v", data.Entity.Name(), err)
+	}
+
+	return ExplainReport{
+		Entity:   data.Entity.Name(),
+		Link:     data.CanonicalLink,
+		Policy:   data.FullyQualifiedPolicyName,
+		Title:    data.Title,
+		Severity: data.Severity,
+		Status:   data.Status,
+		Reasons:  explainReasons(data),
+		Input:    input,
+	}, nil
+}
+
+// explainReasons returns why the policy failed (the violating values it returned, or its description) or was skipped
+func explainReasons(data analyzers.AnalyzedData) []string {
+	reasons := []string{}
+	switch data.Status {
+	case analyzers.PolicySkipped:
+		if reason, ok := errlog.SkipReasonOf(data.PolicyName, data.Entity.Name()); ok {
+			reasons = append(reasons, reason.String())
+		}
+	case analyzers.PolicyFailed:
+		violations, ok := data.ExtraData.(map[string]interface{})
+		if !ok {
+			return append(reasons, data.Description)
+		}
+		for key, value := range violations {
+			if details, err := json.Marshal(value); err == nil && string(details) != "true" {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", key, details))
+			} else {
+				reasons = append(reasons, key)
+			}
+		}
+		sort.Strings(reasons)
+	}
+	return reasons
+}
+
+func writeExplainReports(reports []ExplainReport, format string, writer io.Writer) error {
+	if format == formatter.Json {
+		data, err := json.MarshalIndent(reports, "", formatter.DefaultOutputIndent)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(writer, "%s\n", data)
+		return err
+	}
+
+	for _, report := range reports {
+		input, err := json.MarshalIndent(report.Input, "", formatter.DefaultOutputIndent)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(writer, "Entity: %s (%s)\n", report.Entity, report.Link)
+		fmt.Fprintf(writer, "Policy: %s - %s (%s)\n", report.Policy, report.Title, report.Severity)
+		fmt.Fprintf(writer, "Status: %s\n", report.Status)
+		if len(report.Reasons) > 0 {
+			fmt.Fprintln(writer, "Reasons:")
+			for _, reason := range report.Reasons {
+				fmt.Fprintf(writer, "  - %s\n", reason)
+			}
+		}
+		fmt.Fprintf(writer, "\nInput:\n%s\n\n", input)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/screen"
+	"github.com/spf13/viper"
+)
+
+// environment variables set by the GitHub Actions runner
+const (
+	EnvGitHubActions    = "github_actions"
+	EnvGitHubToken      = "github_token"
+	EnvGitHubRepository = "github_repository"
+	EnvGitHubServerUrl  = "github_server_url"
+	EnvGitHubSHA        = "github_sha"
+	EnvGitHubEventPath  = "github_event_path"
+
+	gitHubCloudServerUrl = "https://github.com"
+)
+
+func runningInGitHubActions() bool {
+	return viper.GetString(EnvGitHubActions) == "true"
+}
+
+// applyGitHubActionsEnvironment completes the token and the endpoint missing from the command line
+// using the context of the GitHub Actions workflow.
+// Explicit flags and the legitify environment variables always take precedence.
+func (a *args) applyGitHubActionsEnvironment() {
+	if !runningInGitHubActions() || a.ScmType != scm_type.GitHub {
+		return
+	}
+
+	if a.Token == "" {
+		a.Token = viper.GetString(EnvGitHubToken)
+	}
+
+	if a.Endpoint == "" {
+		serverUrl := strings.TrimRight(viper.GetString(EnvGitHubServerUrl), "/")
+		if serverUrl != "" && serverUrl != gitHubCloudServerUrl {
+			a.Endpoint = serverUrl
+		}
+	}
+}
+
+// detectGitHubActionsRepository scans the repository of the workflow when no organization, repository or
+// enterprise was requested, so running legitify with no arguments in GitHub Actions scans the current repository.
+func (a *args) detectGitHubActionsRepository() {
+	if !runningInGitHubActions() || a.ScmType != scm_type.GitHub {
+		return
+	}
+	if a.Aggregate || len(a.Organizations) != 0 || len(a.Repositories) != 0 || len(a.Enterprises) != 0 {
+		return
+	}
+
+	if repository := viper.GetString(EnvGitHubRepository); repository != "" {
+		screen.Printf("Running in GitHub Actions: analyzing the current repository %s (use --%s/--%s to override)\n",
+			repository, argOrg, argRepository)
+		a.Repositories = []string{repository}
+	}
+}
+
+// detectGitHubActionsCheckRun completes the repository and the commit of the check run from the workflow context.
+// For pull request events, the check run is created on the head commit of the pull request,
+// since GITHUB_SHA is the (ephemeral) merge commit.
+func (a *args) detectGitHubActionsCheckRun() {
+	if !runningInGitHubActions() {
+		return
+	}
+
+	if a.CheckRunRepo == "" {
+		a.CheckRunRepo = viper.GetString(EnvGitHubRepository)
+	}
+
+	if a.CheckRunSHA == "" {
+		a.CheckRunSHA = pullRequestHeadSHA(viper.GetString(EnvGitHubEventPath))
+		if a.CheckRunSHA == "" {
+			a.CheckRunSHA = viper.GetString(EnvGitHubSHA)
+		}
+	}
+}
+
+func pullRequestHeadSHA(eventPath string) string {
+	if eventPath == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(eventPath)
+	if err != nil {
+		log.Printf("failed to read the workflow event: %v", err)
+		return ""
+	}
+
+	var event struct {
+		PullRequest *struct {
+			Head struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+	}
+	if err = json.Unmarshal(data, &event); err != nil {
+		log.Printf("failed to parse the workflow event: %v", err)
+		return ""
+	}
+	if event.PullRequest == nil {
+		return ""
+	}
+	return event.PullRequest.Head.SHA
+}
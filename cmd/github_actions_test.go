@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// setGitHubActionsEnv sets the workflow context the way the runner does, until the end of the test
+func setGitHubActionsEnv(t *testing.T, values map[string]string) {
+	for key, value := range values {
+		viper.Set(key, value)
+	}
+	t.Cleanup(func() {
+		for key := range values {
+			viper.Set(key, "")
+		}
+	})
+}
+
+func TestApplyGitHubActionsEnvironment(t *testing.T) {
+	env := map[string]string{
+		EnvGitHubActions:   "false",
+		EnvGitHubToken:     "workflow-token",
+		EnvGitHubServerUrl: "https://github.example.com/",
+	}
+
+	setGitHubActionsEnv(t, env)
+	a := &args{ScmType: scm_type.GitHub}
+	a.applyGitHubActionsEnvironment()
+	require.Equal(t, &args{ScmType: scm_type.GitHub}, a, "expecting nothing to change outside of GitHub Actions")
+
+	env[EnvGitHubActions] = "true"
+	setGitHubActionsEnv(t, env)
+
+	a.applyGitHubActionsEnvironment()
+	require.Equal(t, "workflow-token", a.Token)
+	require.Equal(t, "https://github.example.com", a.Endpoint)
+
+	a = &args{ScmType: scm_type.GitHub, Token: "explicit", Endpoint: "https://explicit.example.com"}
+	a.applyGitHubActionsEnvironment()
+	require.Equal(t, "explicit", a.Token, "expecting the explicit token to take precedence")
+	require.Equal(t, "https://explicit.example.com", a.Endpoint, "expecting the explicit endpoint to take precedence")
+
+	a = &args{ScmType: scm_type.GitLab}
+	a.applyGitHubActionsEnvironment()
+	require.Empty(t, a.Token, "expecting the workflow token not to be used for gitlab")
+
+	setGitHubActionsEnv(t, map[string]string{EnvGitHubServerUrl: gitHubCloudServerUrl})
+	a = &args{ScmType: scm_type.GitHub}
+	a.applyGitHubActionsEnvironment()
+	require.Empty(t, a.Endpoint, "expecting the cloud API to be used for github.com")
+}
+
+func TestDetectGitHubActionsRepository(t *testing.T) {
+	setGitHubActionsEnv(t, map[string]string{
+		EnvGitHubActions:    "true",
+		EnvGitHubRepository: "owner/repo",
+	})
+
+	a := &args{ScmType: scm_type.GitHub}
+	a.detectGitHubActionsRepository()
+	require.Equal(t, []string{"owner/repo"}, a.Repositories)
+
+	for name, requested := range map[string]*args{
+		"organization": {ScmType: scm_type.GitHub, Organizations: []string{"org"}},
+		"repository":   {ScmType: scm_type.GitHub, Repositories: []string{"other/repo"}},
+		"enterprise":   {ScmType: scm_type.GitHub, Enterprises: []string{"enterprise"}},
+		"aggregate":    {ScmType: scm_type.GitHub, Aggregate: true},
+		"gitlab":       {ScmType: scm_type.GitLab},
+	} {
+		repositories := requested.Repositories
+		requested.detectGitHubActionsRepository()
+		require.Equal(t, repositories, requested.Repositories, "expecting the current repository not to be scanned with %s", name)
+	}
+}
+
+func TestDetectGitHubActionsCheckRun(t *testing.T) {
+	dir := t.TempDir()
+	pullRequestEvent := filepath.Join(dir, "pull_request.json")
+	require.Nil(t, os.WriteFile(pullRequestEvent, []byte(`{"pull_request": {"head": {"sha": "head-sha"}}}`), 0600))
+	pushEvent := filepath.Join(dir, "push.json")
+	require.Nil(t, os.WriteFile(pushEvent, []byte(`{"ref": "refs/heads/main"}`), 0600))
+	invalidEvent := filepath.Join(dir, "invalid.json")
+	require.Nil(t, os.WriteFile(invalidEvent, []byte(`{`), 0600))
+
+	tests := []struct {
+		name      string
+		eventPath string
+		expected  string
+	}{
+		{name: "pull request", eventPath: pullRequestEvent, expected: "head-sha"},
+		{name: "push", eventPath: pushEvent, expected: "merge-sha"},
+		{name: "invalid event", eventPath: invalidEvent, expected: "merge-sha"},
+		{name: "missing event", eventPath: filepath.Join(dir, "missing.json"), expected: "merge-sha"},
+		{name: "no event", expected: "merge-sha"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setGitHubActionsEnv(t, map[string]string{
+				EnvGitHubActions:    "true",
+				EnvGitHubRepository: "owner/repo",
+				EnvGitHubSHA:        "merge-sha",
+				EnvGitHubEventPath:  test.eventPath,
+			})
+
+			a := &args{}
+			a.detectGitHubActionsCheckRun()
+			require.Equal(t, "owner/repo", a.CheckRunRepo)
+			require.Equal(t, test.expected, a.CheckRunSHA)
+
+			a = &args{CheckRunRepo: "other/repo", CheckRunSHA: "explicit"}
+			a.detectGitHubActionsCheckRun()
+			require.Equal(t, "other/repo", a.CheckRunRepo, "expecting the explicit repository to take precedence")
+			require.Equal(t, "explicit", a.CheckRunSHA, "expecting the explicit commit to take precedence")
+		})
+	}
+}
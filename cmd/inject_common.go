@@ -20,6 +20,7 @@ var analyzeProviderSet = wire.NewSet(
 	skippers.NewSkipper,
 	enricher.NewEnricherManager,
 	collectors_manager.NewCollectorsManager,
+	initializeAnalyzePipeline,
 	initializeAnalyzeExecutor,
 	initializeAnalyzeGPTExecutor,
 )
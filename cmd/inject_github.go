@@ -23,6 +23,17 @@ func setupGitHub(analyzeArgs *args) (*analyzeExecutor, error) {
 	return nil, nil
 }
 
+// setupGitHubPipeline sets up the analysis without its output (see --aggregate)
+func setupGitHubPipeline(analyzeArgs *args) (*analyzePipeline, error) {
+	wire.Build(
+		wire.Bind(new(Client), new(*github.Client)),
+		analyzeProviderSet,
+		provideGitHubClient,
+		provideGitHubCollectors,
+	)
+	return nil, nil
+}
+
 func setupGitHubGPTExecutor(analyzeArgs *args) (*analyzeGPTExecutor, error) {
 	wire.Build(
 		wire.Bind(new(Client), new(*github.Client)),
@@ -49,6 +60,10 @@ func provideGitHubCollectors(ctx context.Context, client *github.Client, analyze
 		result = append(result, collectorsMapping[ns](ctx, client))
 	}
 
+	if analyzeArgs.CheckRateLimit {
+		checkRateLimitBudget(client, result)
+	}
+
 	return result
 }
 
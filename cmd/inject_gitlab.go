@@ -22,6 +22,17 @@ func setupGitLab(analyzeArgs *args) (*analyzeExecutor, error) {
 	return nil, nil
 }
 
+// setupGitLabPipeline sets up the analysis without its output (see --aggregate)
+func setupGitLabPipeline(analyzeArgs *args) (*analyzePipeline, error) {
+	wire.Build(
+		wire.Bind(new(Client), new(*glclient.Client)),
+		analyzeProviderSet,
+		provideGitLabClient,
+		provideGitLabCollectors,
+	)
+	return nil, nil
+}
+
 func setupGitLabGPTExecutor(analyzeArgs *args) (*analyzeGPTExecutor, error) {
 	wire.Build(
 		wire.Bind(new(Client), new(*glclient.Client)),
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	ghcollectors "github.com/Legit-Labs/legitify/internal/collectors/github"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(newPreflightCommand())
+}
+
+var preflightArgs args
+
+func newPreflightCommand() *cobra.Command {
+	preflightCmd := &cobra.Command{
+		Use:          "preflight",
+		Short:        `Report the permissions the token is missing for a full scan, and the policies they would block (GitHub only)`,
+		RunE:         executePreflightCommand,
+		SilenceUsage: true,
+	}
+
+	viper.AutomaticEnv()
+	flags := preflightCmd.Flags()
+	preflightArgs.addOutputOptions(flags)
+	preflightArgs.addCommonCollectionOptions(flags)
+
+	flags.StringVarP(&preflightArgs.OutputFormat, argOutputFormat, "f", formatter.Human, "output format "+toOptionsString([]string{formatter.Human, formatter.Json}))
+	flags.StringSliceVarP(&preflightArgs.Organizations, argOrg, "", nil, "specific organizations to check")
+	flags.StringSliceVarP(&preflightArgs.Namespaces, argNamespace, "n", namespace.All, "which namespace to check")
+	flags.StringSliceVarP(&preflightArgs.PoliciesPath, argPoliciesPath, "p", []string{}, "directory containing opa policies")
+
+	return preflightCmd
+}
+
+// MissingScope is a scope the token lacks, and the policies that are skipped without it
+type MissingScope struct {
+	Scope      string   `json:"scope"`
+	Namespaces []string `json:"namespaces"`
+	Policies   []string `json:"policies"`
+}
+
+// ProbedPermission is a permission the collectors were denied while probing an entity, and the policies it would block
+type ProbedPermission struct {
+	Entity     string   `json:"entity"`
+	Namespace  string   `json:"namespace"`
+	Permission string   `json:"permission"`
+	Effect     string   `json:"effect"`
+	Policies   []string `json:"policies"`
+}
+
+type PreflightReport struct {
+	TokenScopes        []string           `json:"tokenScopes"`
+	MissingScopes      []MissingScope     `json:"missingScopes"`
+	MissingPermissions []ProbedPermission `json:"missingPermissions"`
+}
+
+func executePreflightCommand(cmd *cobra.Command, _args []string) error {
+	if err := preflightArgs.applyCommonCollectionOptions(); err != nil {
+		return err
+	}
+	if preflightArgs.ScmType != scm_type.GitHub {
+		return fmt.Errorf("preflight is supported for GitHub only")
+	}
+	if err := namespace.ValidateNamespaces(preflightArgs.Namespaces); err != nil {
+		return err
+	}
+	if preflightArgs.OutputFormat != formatter.Human && preflightArgs.OutputFormat != formatter.Json {
+		return fmt.Errorf("invalid --%s: %s", argOutputFormat, preflightArgs.OutputFormat)
+	}
+
+	if preExit, err := preflightArgs.applyOutputOptions(); err != nil {
+		return err
+	} else {
+		defer preExit()
+	}
+
+	policies, err := loadedPolicies(&preflightArgs)
+	if err != nil {
+		return err
+	}
+
+	client, err := provideGitHubClient(&preflightArgs)
+	if err != nil {
+		return err
+	}
+	orgs, err := client.CollectOrganizations()
+	if err != nil {
+		return err
+	}
+
+	var probed []collectors.MissingPermission
+	for _, org := range orgs {
+		probed = append(probed, ghcollectors.Preflight(context.Background(), client, org, preflightArgs.Namespaces)...)
+	}
+
+	return writePreflightReport(newPreflightReport(policies, client.Scopes(), probed), preflightArgs.OutputFormat, os.Stdout)
+}
+
+func newPreflightReport(policies []PolicyMetadata, scopes map[string]bool, probed []collectors.MissingPermission) PreflightReport {
+	report := PreflightReport{
+		TokenScopes:        []string{},
+		MissingScopes:      []MissingScope{},
+		MissingPermissions: []ProbedPermission{},
+	}
+	for scope, granted := range scopes {
+		if granted {
+			report.TokenScopes = append(report.TokenScopes, scope)
+		}
+	}
+	sort.Strings(report.TokenScopes)
+
+	missing := make(map[string]*MissingScope)
+	for _, policy := range policies {
+		for _, scope := range policy.RequiredScopes {
+			if scopes[scope] {
+				continue
+			}
+			if _, ok := missing[scope]; !ok {
+				missing[scope] = &MissingScope{Scope: scope}
+			}
+			entry := missing[scope]
+			entry.Policies = append(entry.Policies, policy.PolicyName)
+			if !contains(entry.Namespaces, policy.Namespace) {
+				entry.Namespaces = append(entry.Namespaces, policy.Namespace)
+			}
+		}
+	}
+	for _, entry := range missing {
+		report.MissingScopes = append(report.MissingScopes, *entry)
+	}
+	sort.Slice(report.MissingScopes, func(i, j int) bool {
+		return report.MissingScopes[i].Scope < report.MissingScopes[j].Scope
+	})
+
+	for _, perm := range probed {
+		blocked := []string{}
+		for _, policy := range policies {
+			if policy.Namespace == perm.Namespace && contains(policy.RequiredScopes, perm.Permission) {
+				blocked = append(blocked, policy.PolicyName)
+			}
+		}
+		report.MissingPermissions = append(report.MissingPermissions, ProbedPermission{
+			Entity:     perm.Entity,
+			Namespace:  perm.Namespace,
+			Permission: perm.Permission,
+			Effect:     perm.Effect,
+			Policies:   blocked,
+		})
+	}
+
+	return report
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writePreflightReport(report PreflightReport, format string, writer io.Writer) error {
+	if format == formatter.Json {
+		data, err := json.MarshalIndent(report, "", formatter.DefaultOutputIndent)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(writer, "%s\n", data)
+		return err
+	}
+
+	fmt.Fprintf(writer, "Token scopes: %s\n\n", strings.Join(report.TokenScopes, ", "))
+	if len(report.MissingScopes) == 0 && len(report.MissingPermissions) == 0 {
+		fmt.Fprintln(writer, "The token has all the permissions a full scan requires.")
+		return nil
+	}
+
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if len(report.MissingScopes) > 0 {
+		fmt.Fprintln(writer, "Missing scopes (the policies that require them are skipped):")
+		fmt.Fprintln(table, "SCOPE\tNAMESPACES\tBLOCKED POLICIES")
+		for _, scope := range report.MissingScopes {
+			fmt.Fprintf(table, "%s\t%s\t%d\n", scope.Scope, strings.Join(scope.Namespaces, ", "), len(scope.Policies))
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(writer)
+	}
+
+	if len(report.MissingPermissions) > 0 {
+		fmt.Fprintln(writer, "Missing permissions (probed per organization):")
+		fmt.Fprintln(table, "ENTITY\tNAMESPACE\tPERMISSION\tEFFECT\tBLOCKED POLICIES")
+		for _, perm := range report.MissingPermissions {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%d\n", perm.Entity, perm.Namespace, perm.Permission, perm.Effect, len(perm.Policies))
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(writer)
+	}
+
+	fmt.Fprintf(writer, "Use -f %s to list the blocked policies.\n", formatter.Json)
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPreflightReport(t *testing.T) {
+	policies := []PolicyMetadata{
+		{PolicyName: "repository_webhook_no_secret", Namespace: namespace.Repository, RequiredScopes: []string{"read:repo_hook", "repo"}},
+		{PolicyName: "organization_webhook_no_secret", Namespace: namespace.Organization, RequiredScopes: []string{"admin:org_hook"}},
+		{PolicyName: "repository_not_maintained", Namespace: namespace.Repository, RequiredScopes: []string{"repo"}},
+		{PolicyName: "organization_webhook_doesnt_require_ssl", Namespace: namespace.Organization, RequiredScopes: []string{"admin:org_hook"}},
+		{PolicyName: "repository_vulnerability_alerts", Namespace: namespace.Repository, RequiredScopes: []string{"repo", "admin:org_hook"}},
+	}
+	scopes := map[string]bool{"repo": true, "read:org": true, "admin:org": false}
+	probed := []collectors.MissingPermission{
+		collectors.NewMissingPermission("repo", "org/repo", "Cannot read repository collaborators", namespace.Repository),
+		collectors.NewMissingPermission("admin:org", "org", "Cannot read organization secrets", namespace.Organization),
+	}
+
+	report := newPreflightReport(policies, scopes, probed)
+
+	require.Equal(t, []string{"read:org", "repo"}, report.TokenScopes, "expecting only the granted scopes, sorted")
+	require.Equal(t, []MissingScope{
+		{
+			Scope:      "admin:org_hook",
+			Namespaces: []string{namespace.Organization, namespace.Repository},
+			Policies:   []string{"organization_webhook_no_secret", "organization_webhook_doesnt_require_ssl", "repository_vulnerability_alerts"},
+		},
+		{
+			Scope:      "read:repo_hook",
+			Namespaces: []string{namespace.Repository},
+			Policies:   []string{"repository_webhook_no_secret"},
+		},
+	}, report.MissingScopes)
+	require.Equal(t, []ProbedPermission{
+		{
+			Entity: "org/repo", Namespace: namespace.Repository, Permission: "repo", Effect: "Cannot read repository collaborators",
+			Policies: []string{"repository_webhook_no_secret", "repository_not_maintained", "repository_vulnerability_alerts"},
+		},
+		{
+			Entity: "org", Namespace: namespace.Organization, Permission: "admin:org", Effect: "Cannot read organization secrets",
+			Policies: []string{},
+		},
+	}, report.MissingPermissions)
+
+	empty := newPreflightReport(nil, nil, nil)
+	require.Equal(t, PreflightReport{TokenScopes: []string{}, MissingScopes: []MissingScope{}, MissingPermissions: []ProbedPermission{}}, empty,
+		"expecting empty lists rather than nulls in the json output")
+}
+
+func TestWritePreflightReport(t *testing.T) {
+	var output bytes.Buffer
+	require.Nil(t, writePreflightReport(newPreflightReport(nil, map[string]bool{"repo": true}, nil), formatter.Human, &output))
+	require.Equal(t, "Token scopes: repo\n\nThe token has all the permissions a full scan requires.\n", output.String())
+
+	report := PreflightReport{
+		TokenScopes:   []string{"read:org", "repo"},
+		MissingScopes: []MissingScope{{Scope: "admin:org_hook", Namespaces: []string{"organization"}, Policies: []string{"a", "b"}}},
+		MissingPermissions: []ProbedPermission{
+			{Entity: "org/repo", Namespace: "repository", Permission: "repo", Effect: "Cannot read repository webhooks", Policies: []string{"c"}},
+		},
+	}
+
+	output.Reset()
+	require.Nil(t, writePreflightReport(report, formatter.Human, &output))
+	require.Equal(t, `Token scopes: read:org, repo
+
+Missing scopes (the policies that require them are skipped):
+SCOPE           NAMESPACES    BLOCKED POLICIES
+admin:org_hook  organization  2
+
+Missing permissions (probed per organization):
+ENTITY    NAMESPACE   PERMISSION  EFFECT                           BLOCKED POLICIES
+org/repo  repository  repo        Cannot read repository webhooks  1
+
+Use -f json to list the blocked policies.
+`, output.String())
+
+	output.Reset()
+	require.Nil(t, writePreflightReport(report, formatter.Json, &output))
+	var parsed PreflightReport
+	require.Nil(t, json.Unmarshal(output.Bytes(), &parsed))
+	require.Equal(t, report, parsed)
+}
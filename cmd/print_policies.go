@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Legit-Labs/legitify/internal/common/data_groups"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/Legit-Labs/legitify/internal/opa/opa_engine"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// PolicyMetadata describes a loaded policy, as listed by --print-policies
+type PolicyMetadata struct {
+	PolicyName string   `json:"policyName"`
+	Scm        string   `json:"scm"`
+	Namespace  string   `json:"namespace"`
+	Severity   string   `json:"severity"`
+	Title      string   `json:"title"`
+	Frameworks []string `json:"frameworks,omitempty"`
+	// DataGroups are the collected data groups the policy depends on (see --collect)
+	DataGroups []string `json:"dataGroups,omitempty"`
+	// RequiredScopes are the token scopes without which the policy is skipped
+	RequiredScopes []string `json:"requiredScopes,omitempty"`
+}
+
+// loadedPolicies lists the policies of the requested namespaces that are not ignored, without collecting anything
+func loadedPolicies(a *args) ([]PolicyMetadata, error) {
+	scmTypes := []scm_type.ScmType{a.ScmType}
+	if a.Aggregate {
+		scmTypes = scm_type.All
+	}
+
+	requested := make(map[namespace.Namespace]bool, len(a.Namespaces))
+	for _, ns := range a.Namespaces {
+		requested[ns] = true
+	}
+
+	ignored := make(map[string]bool)
+	for _, policyName := range getIgnoredPolicies(a) {
+		ignored[policyName] = true
+	}
+
+	policies := []PolicyMetadata{}
+	for _, scmType := range scmTypes {
+		engine, err := opa.Load(a.PoliciesPath, scmType, a.policiesFilters()...)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policiesMetadata(engine, scmType) {
+			if requested[policy.Namespace] && !ignored[policy.PolicyName] {
+				policies = append(policies, policy)
+			}
+		}
+	}
+
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Scm != policies[j].Scm {
+			return policies[i].Scm < policies[j].Scm
+		}
+		if policies[i].Namespace != policies[j].Namespace {
+			return policies[i].Namespace < policies[j].Namespace
+		}
+		if policies[i].Severity != policies[j].Severity {
+			return severity.Less(policies[i].Severity, policies[j].Severity)
+		}
+		return policies[i].PolicyName < policies[j].PolicyName
+	})
+
+	return policies, nil
+}
+
+func policiesMetadata(engine opa_engine.Enginer, scmType scm_type.ScmType) []PolicyMetadata {
+	var result []PolicyMetadata
+	policyFields := opa.PolicyInputFields(engine.Modules())
+	for _, ref := range engine.Annotations().Flatten() {
+		rule := ref.GetRule()
+		if rule == nil || ref.Annotations == nil {
+			continue
+		}
+		policySeverity, _ := ref.Annotations.Custom["severity"].(string)
+		var dataGroups []string
+		if scmType == scm_type.GitHub {
+			fqPolicyName := rule.Module.Package.Path.String() + "." + rule.Head.Name.String()
+			dataGroups = data_groups.Required(fqPolicyName, policyFields[fqPolicyName])
+		}
+		result = append(result, PolicyMetadata{
+			PolicyName: rule.Head.Name.String(),
+			Scm:        scmType,
+			Namespace:  strings.TrimPrefix(rule.Module.Package.Path.String(), "data."),
+			Severity:   policySeverity,
+			Title:      ref.Annotations.Title,
+			Frameworks: resolveFrameworks(ref.Annotations),
+			DataGroups: dataGroups,
+
+			RequiredScopes: resolveStringArray(ref.Annotations.Custom["requiredScopes"]),
+		})
+	}
+	return result
+}
+
+// resolveFrameworks reads the optional framework mappings of a policy,
+// either as a list (frameworks: [CIS 1.1.3]) or as a mapping (frameworks: {CIS: 1.1.3})
+func resolveFrameworks(annotations *ast.Annotations) []string {
+	switch frameworks := annotations.Custom["frameworks"].(type) {
+	case map[string]interface{}:
+		result := make([]string, 0, len(frameworks))
+		for name, control := range frameworks {
+			result = append(result, fmt.Sprintf("%s %v", name, control))
+		}
+		sort.Strings(result)
+		return result
+	default:
+		return resolveStringArray(frameworks)
+	}
+}
+
+func printPolicies(a *args, writer io.Writer) error {
+	policies, err := loadedPolicies(a)
+	if err != nil {
+		return err
+	}
+
+	if a.OutputFormat == formatter.Json {
+		data, err := json.MarshalIndent(policies, "", formatter.DefaultOutputIndent)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(writer, "%s\n", data)
+		return err
+	}
+
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SCM\tNAMESPACE\tSEVERITY\tPOLICY\tTITLE\tFRAMEWORKS\tDATA")
+	for _, policy := range policies {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", policy.Scm, policy.Namespace, policy.Severity,
+			policy.PolicyName, policy.Title, strings.Join(policy.Frameworks, ", "), strings.Join(policy.DataGroups, ", "))
+	}
+	return table.Flush()
+}
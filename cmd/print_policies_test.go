@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/stretchr/testify/require"
+)
+
+func policyNames(policies []PolicyMetadata) map[string]bool {
+	names := make(map[string]bool, len(policies))
+	for _, policy := range policies {
+		names[policy.PolicyName] = true
+	}
+	return names
+}
+
+func TestLoadedPolicies(t *testing.T) {
+	a := &args{
+		ScmType:    scm_type.GitHub,
+		Namespaces: []namespace.Namespace{namespace.Repository},
+	}
+
+	policies, err := loadedPolicies(a)
+	require.Nil(t, err)
+	require.NotEmpty(t, policies)
+	for _, policy := range policies {
+		require.Equal(t, scm_type.GitHub, policy.Scm)
+		require.Equal(t, namespace.Repository, policy.Namespace, "expecting only the policies of the requested namespaces")
+	}
+	require.True(t, policyNames(policies)["repository_not_maintained"])
+
+	a.IgnoredPolicies = filepath.Join(t.TempDir(), "ignored")
+	require.Nil(t, os.WriteFile(a.IgnoredPolicies, []byte("repository_not_maintained\n  code_review_not_required  \n"), 0600))
+
+	remaining, err := loadedPolicies(a)
+	require.Nil(t, err)
+	require.Len(t, remaining, len(policies)-2, "expecting the ignored policies not to be listed")
+	require.False(t, policyNames(remaining)["repository_not_maintained"])
+	require.False(t, policyNames(remaining)["code_review_not_required"])
+}
+
+func TestPrintPolicies(t *testing.T) {
+	a := &args{
+		ScmType:    scm_type.GitLab,
+		Namespaces: []namespace.Namespace{namespace.Organization},
+	}
+
+	var output bytes.Buffer
+	require.Nil(t, printPolicies(a, &output))
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Greater(t, len(lines), 1)
+	require.Equal(t, []string{"SCM", "NAMESPACE", "SEVERITY", "POLICY", "TITLE", "FRAMEWORKS", "DATA"}, strings.Fields(lines[0]))
+
+	a.OutputFormat = formatter.Json
+	output.Reset()
+	require.Nil(t, printPolicies(a, &output))
+	var policies []PolicyMetadata
+	require.Nil(t, json.Unmarshal(output.Bytes(), &policies))
+	require.Len(t, policies, len(lines)-1, "expecting the json output to list the same policies as the table")
+}
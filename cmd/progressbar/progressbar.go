@@ -135,7 +135,7 @@ func (pb *progressBar) handleOptionalBarCreation(data OptionalBarCreation) {
 
 	displayName := data.BarName
 
-	if _, exists := pb.bars[displayName]; exists {
+	if bar, exists := pb.bars[displayName]; exists && !isBarFinished(bar) {
 		log.Panicf("trying to create a bar that already exists: %s (%v)", displayName, data)
 	}
 
@@ -150,6 +150,12 @@ func (pb *progressBar) handleOptionalBarCreation(data OptionalBarCreation) {
 	)
 }
 
+// isBarFinished reports whether a bar can be replaced by a new bar with the same name
+// (e.g. when collecting the same namespace of multiple providers one after the other).
+func isBarFinished(bar *mpb.Bar) bool {
+	return bar.Completed() || bar.Aborted()
+}
+
 func (pb *progressBar) handleSpinnerBarUpdate(data SpinnerBarUpdate) {
 	displayName := data.BarName
 
@@ -24,8 +24,9 @@ func newPbWaiter(w group_waiter.Waitable) *pbWaiter {
 	}
 }
 
+// SetMinCount adds to the minimum number of required bars, so that multiple collection managers can share the progress bar.
 func (w *pbWaiter) SetMinCount(min int) {
-	w.minCount = min
+	w.minCount += min
 	w.signal()
 }
 
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/clients/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/screen"
+)
+
+// rough number of API calls issued per collected entity (excluding scorecard).
+// the numbers are intentionally pessimistic since a warning is cheaper than a stalled scan.
+type callsEstimate struct {
+	rest    int
+	graphql int
+}
+
+var estimatedCallsPerEntity = map[namespace.Namespace]callsEstimate{
+	namespace.Repository:   {rest: 12, graphql: 2},
+	namespace.Organization: {rest: 6, graphql: 1},
+	namespace.Enterprise:   {rest: 2, graphql: 1},
+	namespace.Member:       {rest: 1, graphql: 0},
+	namespace.Actions:      {rest: 3, graphql: 0},
+	namespace.RunnerGroup:  {rest: 2, graphql: 0},
+}
+
+// the primary rate limit window of GitHub
+const rateLimitWindow = time.Hour
+
+// checkRateLimitBudget compares the remaining rate limit with the estimated number of API calls
+// the scan is going to issue, and warns when the scan will likely exhaust the budget.
+func checkRateLimitBudget(client *github.Client, initiatedCollectors []collectors.Collector) {
+	core, graphql, err := client.RateLimitBudgets()
+	if err != nil {
+		// e.g. GitHub Enterprise Server with rate limiting disabled
+		log.Printf("skipping rate limit check: %v", err)
+		return
+	}
+
+	required := estimateRequiredCalls(initiatedCollectors)
+	warnIfExceedsBudget("REST", required.rest, core)
+	warnIfExceedsBudget("GraphQL", required.graphql, graphql)
+}
+
+// estimateRequiredCalls sums the estimated API calls of the entities of the collectors.
+func estimateRequiredCalls(initiatedCollectors []collectors.Collector) callsEstimate {
+	var required callsEstimate
+	for _, c := range initiatedCollectors {
+		estimate, ok := estimatedCallsPerEntity[c.Namespace()]
+		if !ok {
+			continue
+		}
+		total := c.CollectTotalEntities()
+		required.rest += total * estimate.rest
+		required.graphql += total * estimate.graphql
+	}
+	return required
+}
+
+func warnIfExceedsBudget(resource string, required int, budget github.RateLimitBudget) {
+	if required <= budget.Remaining {
+		return
+	}
+
+	msg := fmt.Sprintf("Warning: the scan requires roughly %d %s API calls but only %d of %d remain (resets at %s).",
+		required, resource, budget.Remaining, budget.Limit, budget.Reset.Local().Format(time.Kitchen))
+	if eta, ok := estimateCompletion(required, budget); ok {
+		msg += fmt.Sprintf(" legitify will wait for the rate limit to reset, so the scan is expected to complete around %s.",
+			eta.Local().Format(time.RFC1123))
+	}
+	screen.Printf("%s", msg)
+}
+
+// estimateCompletion returns the time of the rate limit reset after which the remaining calls fit in the budget.
+func estimateCompletion(required int, budget github.RateLimitBudget) (time.Time, bool) {
+	if budget.Limit <= 0 {
+		return time.Time{}, false
+	}
+	deficit := required - budget.Remaining
+	windows := (deficit + budget.Limit - 1) / budget.Limit
+
+	return budget.Reset.Add(time.Duration(windows-1) * rateLimitWindow), true
+}
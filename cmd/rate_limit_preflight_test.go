@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/clients/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/screen"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCollector struct {
+	collectors.Collector
+	namespace namespace.Namespace
+	total     int
+}
+
+func (c countingCollector) Namespace() namespace.Namespace {
+	return c.namespace
+}
+
+func (c countingCollector) CollectTotalEntities() int {
+	return c.total
+}
+
+func TestEstimateRequiredCalls(t *testing.T) {
+	required := estimateRequiredCalls([]collectors.Collector{
+		countingCollector{namespace: namespace.Repository, total: 10},
+		countingCollector{namespace: namespace.Organization, total: 2},
+		countingCollector{namespace: "unknown", total: 1000},
+	})
+
+	require.Equal(t, callsEstimate{rest: 10*12 + 2*6, graphql: 10*2 + 2*1}, required,
+		"expecting the entities of unknown namespaces to be skipped")
+	require.Equal(t, callsEstimate{}, estimateRequiredCalls(nil))
+}
+
+func TestEstimateCompletion(t *testing.T) {
+	reset := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	budget := github.RateLimitBudget{Limit: 5000, Remaining: 1000, Reset: reset}
+
+	tests := []struct {
+		name     string
+		required int
+		expected time.Time
+	}{
+		{name: "fits after the first reset", required: 1001, expected: reset},
+		{name: "fits exactly in one window", required: 6000, expected: reset},
+		{name: "needs another window", required: 6001, expected: reset.Add(rateLimitWindow)},
+		{name: "needs several windows", required: 16000, expected: reset.Add(2 * rateLimitWindow)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eta, ok := estimateCompletion(test.required, budget)
+			require.True(t, ok)
+			require.Equal(t, test.expected, eta)
+		})
+	}
+
+	_, ok := estimateCompletion(100, github.RateLimitBudget{Reset: reset})
+	require.False(t, ok, "expecting no estimation without a limit")
+}
+
+func TestWarnIfExceedsBudget(t *testing.T) {
+	var output bytes.Buffer
+	screen.Init(&output, false)
+	defer screen.Init(os.Stderr, false)
+
+	budget := github.RateLimitBudget{Limit: 5000, Remaining: 1000, Reset: time.Now().Add(time.Minute)}
+
+	warnIfExceedsBudget("REST", 1000, budget)
+	require.Empty(t, output.String(), "expecting no warning when the calls fit in the budget")
+
+	warnIfExceedsBudget("REST", 7000, budget)
+	require.Contains(t, output.String(), "roughly 7000 REST API calls but only 1000 of 5000 remain")
+	require.Contains(t, output.String(), "expected to complete around")
+
+	output.Reset()
+	warnIfExceedsBudget("GraphQL", 10, github.RateLimitBudget{})
+	require.Contains(t, output.String(), "roughly 10 GraphQL API calls but only 0 of 0 remain")
+	require.NotContains(t, output.String(), "expected to complete around", "expecting no estimation without a limit")
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/clients/github/transport"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/coverage"
+	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/inventory"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/Legit-Labs/legitify/internal/protection"
+	"github.com/Legit-Labs/legitify/internal/screen"
+	"github.com/Legit-Labs/legitify/internal/version"
+)
+
+// apiCallCounter is a client that counts its API calls (see ghclient.Client.APICalls)
+type apiCallCounter interface {
+	APICalls() transport.Metrics
+}
+
+// scanState is what a scan records about itself while it runs
+type scanState struct {
+	// metadata describes the scan at the top of the outputs
+	metadata *scheme.Metadata
+	// counters are the clients that count their API calls, by the index of their provider in the metadata
+	counters map[int]apiCallCounter
+	// coverage records the coverage of the policies (nil without --coverage-file)
+	coverage *coverage.Report
+	// inventory records the collected entities (nil without --inventory-file)
+	inventory *inventory.Inventory
+	// protection records the effective protection of the collected repositories (nil without --protection-report-file)
+	protection *protection.Report
+	// collection records the collected entities and their missing permissions (see --min-coverage)
+	collection *errlog.CollectionLog
+}
+
+// newScanState starts the metadata of the scan.
+// Each provider of the scan adds its scope once it is set up (several providers are scanned with --aggregate).
+func newScanState(a *args) *scanState {
+	start := time.Now()
+	policies, err := opa.PoliciesVersion(a.PoliciesPath)
+	if err != nil {
+		log.Printf("failed to identify the policies version: %v", err)
+	}
+	return &scanState{
+		metadata: &scheme.Metadata{
+			Tool:      version.Name,
+			Version:   version.Version,
+			Commit:    version.Commit,
+			ScanStart: &start,
+			Policies:  policies,
+		},
+		counters:   make(map[int]apiCallCounter),
+		collection: errlog.NewCollectionLog(),
+	}
+}
+
+func recordScanMetadata(ctx context.Context, client Client, a *args) {
+	if a.scan == nil {
+		return
+	}
+	scanMetadata := a.scan.metadata
+
+	var tokenScopes []string
+	for scope, granted := range context_utils.GetTokenScopes(ctx) {
+		if granted {
+			tokenScopes = append(tokenScopes, scope)
+		}
+	}
+	sort.Strings(tokenScopes)
+
+	if counter, ok := client.(apiCallCounter); ok {
+		a.scan.counters[len(scanMetadata.Providers)] = counter
+	}
+	scanMetadata.Providers = append(scanMetadata.Providers, scheme.ProviderMetadata{
+		Scm:             a.ScmType,
+		Endpoint:        a.Endpoint,
+		Organizations:   a.Organizations,
+		Repositories:    a.Repositories,
+		Enterprises:     a.Enterprises,
+		Namespaces:      a.Namespaces,
+		TokenScopes:     tokenScopes,
+		MaxRepositories: a.MaxRepositories,
+	})
+}
+
+// newContext passes the reports the analysis of the scan adds to (e.g. the coverage of the policies) down to it
+func (s *scanState) newContext(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	ctx = context_utils.NewContextWithCoverage(ctx, s.coverage)
+	ctx = context_utils.NewContextWithInventory(ctx, s.inventory)
+	ctx = context_utils.NewContextWithProtectionReport(ctx, s.protection)
+	return context_utils.NewContextWithCollectionLog(ctx, s.collection)
+}
+
+// withAPICalls forwards the results of the scan, and adds the totals of the API calls of each provider to the metadata
+// once all of them were received (before the output is formatted)
+func (s *scanState) withAPICalls(results <-chan enricher.EnrichedData) <-chan enricher.EnrichedData {
+	if s == nil {
+		return results
+	}
+
+	forwarded := make(chan enricher.EnrichedData)
+	go func() {
+		defer close(forwarded)
+		for data := range results {
+			forwarded <- data
+		}
+		s.recordAPICalls()
+	}()
+
+	return forwarded
+}
+
+func (s *scanState) recordAPICalls() {
+	for i, counter := range s.counters {
+		s.metadata.Providers[i].APICalls = apiCallsOf(counter)
+	}
+}
+
+// reportAPICalls prints the totals of the API calls of each provider once the run completes.
+// Unlike the totals in the metadata, they include the calls of the sinks (e.g. the check run of --check-run).
+func (s *scanState) reportAPICalls() {
+	if s == nil {
+		return
+	}
+	for i, provider := range s.metadata.Providers {
+		if counter, ok := s.counters[i]; ok {
+			screen.Printf("%s API calls made: %s\n", provider.Scm, apiCallsOf(counter))
+		}
+	}
+}
+
+func apiCallsOf(counter apiCallCounter) *scheme.APICalls {
+	metrics := counter.APICalls()
+	return &scheme.APICalls{
+		Rest:            metrics.Rest,
+		GraphQL:         metrics.GraphQL,
+		Cached:          metrics.Cached,
+		Retries:         metrics.Retries,
+		RateLimitSleeps: metrics.RateLimitSleeps,
+	}
+}
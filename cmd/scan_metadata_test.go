@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/clients/github/transport"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordScanMetadata(t *testing.T) {
+	ctx := context_utils.NewContextWithTokenScopes(context.Background(), permissions.TokenScopes{"repo": true, "read:org": true, "admin:org": false})
+
+	a := &args{Organizations: []string{"org"}}
+	a.scan = &scanState{metadata: &scheme.Metadata{Tool: "legitify"}, counters: make(map[int]apiCallCounter)}
+
+	// the arguments of each provider are copies that share the metadata (see --aggregate)
+	githubArgs := *a
+	githubArgs.ScmType = scm_type.GitHub
+	gitlabArgs := *a
+	gitlabArgs.ScmType = scm_type.GitLab
+
+	recordScanMetadata(ctx, nil, &githubArgs)
+	recordScanMetadata(ctx, nil, &gitlabArgs)
+
+	require.Equal(t, []scheme.ProviderMetadata{
+		{Scm: scm_type.GitHub, Organizations: []string{"org"}, TokenScopes: []string{"read:org", "repo"}},
+		{Scm: scm_type.GitLab, Organizations: []string{"org"}, TokenScopes: []string{"read:org", "repo"}},
+	}, a.scan.metadata.Providers)
+	require.Equal(t, a.scan.metadata, githubArgs.formatOptions().Metadata, "expecting the metadata to be rendered in the output")
+
+	// e.g. explain sets up the context without scanning
+	unscanned := &args{}
+	recordScanMetadata(ctx, nil, unscanned)
+	require.Nil(t, unscanned.scan)
+	require.Nil(t, unscanned.formatOptions().Metadata)
+}
+
+type fakeCounter struct {
+	Client
+	calls transport.Metrics
+}
+
+func (c *fakeCounter) APICalls() transport.Metrics {
+	return c.calls
+}
+
+func TestScanAPICalls(t *testing.T) {
+	ctx := context_utils.NewContextWithTokenScopes(context.Background(), permissions.TokenScopes{})
+	newArgs := func() *args {
+		a := &args{ScmType: scm_type.GitHub}
+		a.scan = &scanState{metadata: &scheme.Metadata{}, counters: make(map[int]apiCallCounter)}
+		return a
+	}
+
+	first := newArgs()
+	counter := &fakeCounter{calls: transport.Metrics{Rest: 2, GraphQL: 1}}
+	recordScanMetadata(ctx, counter, first)
+
+	// e.g. gitlab does not count its calls
+	gitlabArgs := *first
+	gitlabArgs.ScmType = scm_type.GitLab
+	recordScanMetadata(ctx, nil, &gitlabArgs)
+
+	results := make(chan enricher.EnrichedData, 1)
+	results <- enricher.EnrichedData{}
+	forwarded := first.scan.withAPICalls(results)
+	counter.calls.Rest++ // calls made until the results are complete are counted
+	close(results)
+
+	received := 0
+	for range forwarded {
+		received++
+	}
+	require.Equal(t, 1, received)
+	require.Equal(t, &scheme.APICalls{Rest: 3, GraphQL: 1}, first.scan.metadata.Providers[0].APICalls,
+		"expecting the totals to be recorded before the channel is closed")
+	require.Nil(t, first.scan.metadata.Providers[1].APICalls)
+
+	// the counters belong to the scan, so another scan does not record the calls of the first one
+	second := newArgs()
+	recordScanMetadata(ctx, nil, second)
+	for range second.scan.withAPICalls(closedResults()) {
+	}
+	require.Nil(t, second.scan.metadata.Providers[0].APICalls)
+}
+
+func closedResults() <-chan enricher.EnrichedData {
+	results := make(chan enricher.EnrichedData)
+	close(results)
+	return results
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer/manifest"
 )
 
 func setErrorFile(path string) (*os.File, error) {
@@ -35,8 +37,11 @@ func openForWrite(path string) (*os.File, error) {
 	return file, nil
 }
 
+// stdoutPath explicitly selects stdout as the output destination (e.g. --output-file -)
+const stdoutPath = "-"
+
 func setOutputFile(path string) error {
-	if path == "" { // default to stdout
+	if path == "" || path == stdoutPath { // default to stdout
 		return nil
 	}
 
@@ -52,3 +57,27 @@ func setOutputFile(path string) error {
 	os.Stdout = file
 	return nil
 }
+
+// writeOutputManifest signs the content of the output file once it was fully written.
+func writeOutputManifest(outputFile string, manifestFile string, key string) error {
+	if err := os.Stdout.Sync(); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read output file: %v", err)
+	}
+
+	m, err := manifest.New(outputFile, content, []byte(key))
+	if err != nil {
+		return err
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(manifestFile, data, 0644)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Legit-Labs/legitify/internal/outputer/manifest"
+	"github.com/Legit-Labs/legitify/internal/screen"
+	"github.com/spf13/cobra"
+
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(newVerifyManifestCommand())
+}
+
+var verifyManifestArgs args
+
+func newVerifyManifestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "verify-manifest",
+		Short:        `Verify that an output file matches its signed manifest (see --manifest-file)`,
+		RunE:         executeVerifyManifestCommand,
+		SilenceUsage: true,
+	}
+
+	viper.AutomaticEnv()
+	flags := cmd.Flags()
+	flags.StringVar(&verifyManifestArgs.InputFile, argInputFile, "", "the output file to verify")
+	flags.StringVar(&verifyManifestArgs.ManifestFile, ArgManifestFile, "", "the manifest file of the output")
+	flags.StringVar(&verifyManifestArgs.ManifestKey, ArgManifestKey, "", "key used to sign the manifest (can be set via the environment variable MANIFEST_KEY)")
+
+	return cmd
+}
+
+func validateVerifyManifestArgs() error {
+	if verifyManifestArgs.InputFile == "" {
+		return fmt.Errorf("please provide an input file")
+	}
+
+	if verifyManifestArgs.ManifestFile == "" {
+		return fmt.Errorf("please provide a manifest file")
+	}
+
+	if verifyManifestArgs.ManifestKey == "" {
+		verifyManifestArgs.ManifestKey = viper.GetString(EnvManifestKey)
+		if verifyManifestArgs.ManifestKey == "" {
+			return fmt.Errorf("please provide the manifest key")
+		}
+	}
+
+	return nil
+}
+
+func executeVerifyManifestCommand(cmd *cobra.Command, _args []string) error {
+	if err := validateVerifyManifestArgs(); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(verifyManifestArgs.InputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+
+	data, err := os.ReadFile(verifyManifestArgs.ManifestFile)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %v", err)
+	}
+
+	m, err := manifest.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Verify(content, []byte(verifyManifestArgs.ManifestKey)); err != nil {
+		return err
+	}
+
+	screen.Printf("%s matches the manifest (sha256: %s, created at: %v)\n", verifyManifestArgs.InputFile, m.SHA256, m.CreatedAt)
+	return nil
+}
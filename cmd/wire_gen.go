@@ -33,19 +33,43 @@ func setupGitHub(analyzeArgs2 *args) (*analyzeExecutor, error) {
 		return nil, err
 	}
 	v := provideGitHubCollectors(context, client, analyzeArgs2)
-	collectorManager := collectors_manager.NewCollectorsManager(v)
+	collectorManager := collectors_manager.NewCollectorsManager(context, v)
 	enginer, err := provideOpa(analyzeArgs2)
 	if err != nil {
 		return nil, err
 	}
-	skipper := skippers.NewSkipper(context)
+	skipper := skippers.NewSkipper(context, enginer)
 	analyzer := analyzers.NewAnalyzer(context, enginer, skipper)
 	enricherManager := enricher.NewEnricherManager()
-	outputer := provideOutputer(context, analyzeArgs2)
-	cmdAnalyzeExecutor := initializeAnalyzeExecutor(collectorManager, analyzer, enricherManager, outputer, context)
+	cmdAnalyzePipeline := initializeAnalyzePipeline(collectorManager, analyzer, enricherManager, context, client)
+	outputer := provideOutputer(context, client, analyzeArgs2)
+	cmdAnalyzeExecutor := initializeAnalyzeExecutor(cmdAnalyzePipeline, outputer, analyzeArgs2)
 	return cmdAnalyzeExecutor, nil
 }
 
+// setupGitHubPipeline sets up the analysis without its output (see --aggregate)
+func setupGitHubPipeline(analyzeArgs2 *args) (*analyzePipeline, error) {
+	client, err := provideGitHubClient(analyzeArgs2)
+	if err != nil {
+		return nil, err
+	}
+	context, err := provideContext(client, analyzeArgs2)
+	if err != nil {
+		return nil, err
+	}
+	v := provideGitHubCollectors(context, client, analyzeArgs2)
+	collectorManager := collectors_manager.NewCollectorsManager(context, v)
+	enginer, err := provideOpa(analyzeArgs2)
+	if err != nil {
+		return nil, err
+	}
+	skipper := skippers.NewSkipper(context, enginer)
+	analyzer := analyzers.NewAnalyzer(context, enginer, skipper)
+	enricherManager := enricher.NewEnricherManager()
+	cmdAnalyzePipeline := initializeAnalyzePipeline(collectorManager, analyzer, enricherManager, context, client)
+	return cmdAnalyzePipeline, nil
+}
+
 func setupGitHubGPTExecutor(analyzeArgs2 *args) (*analyzeGPTExecutor, error) {
 	client, err := provideGitHubClient(analyzeArgs2)
 	if err != nil {
@@ -57,7 +81,7 @@ func setupGitHubGPTExecutor(analyzeArgs2 *args) (*analyzeGPTExecutor, error) {
 	}
 	analyzer := provideGPTAnalyzer(context, analyzeArgs2)
 	v := provideGitHubCollectors(context, client, analyzeArgs2)
-	collectorManager := collectors_manager.NewCollectorsManager(v)
+	collectorManager := collectors_manager.NewCollectorsManager(context, v)
 	cmdAnalyzeGPTExecutor := initializeAnalyzeGPTExecutor(analyzer, collectorManager, context)
 	return cmdAnalyzeGPTExecutor, nil
 }
@@ -74,19 +98,43 @@ func setupGitLab(analyzeArgs2 *args) (*analyzeExecutor, error) {
 		return nil, err
 	}
 	v := provideGitLabCollectors(context, client, analyzeArgs2)
-	collectorManager := collectors_manager.NewCollectorsManager(v)
+	collectorManager := collectors_manager.NewCollectorsManager(context, v)
 	enginer, err := provideOpa(analyzeArgs2)
 	if err != nil {
 		return nil, err
 	}
-	skipper := skippers.NewSkipper(context)
+	skipper := skippers.NewSkipper(context, enginer)
 	analyzer := analyzers.NewAnalyzer(context, enginer, skipper)
 	enricherManager := enricher.NewEnricherManager()
-	outputer := provideOutputer(context, analyzeArgs2)
-	cmdAnalyzeExecutor := initializeAnalyzeExecutor(collectorManager, analyzer, enricherManager, outputer, context)
+	cmdAnalyzePipeline := initializeAnalyzePipeline(collectorManager, analyzer, enricherManager, context, client)
+	outputer := provideOutputer(context, client, analyzeArgs2)
+	cmdAnalyzeExecutor := initializeAnalyzeExecutor(cmdAnalyzePipeline, outputer, analyzeArgs2)
 	return cmdAnalyzeExecutor, nil
 }
 
+// setupGitLabPipeline sets up the analysis without its output (see --aggregate)
+func setupGitLabPipeline(analyzeArgs2 *args) (*analyzePipeline, error) {
+	client, err := provideGitLabClient(analyzeArgs2)
+	if err != nil {
+		return nil, err
+	}
+	context, err := provideContext(client, analyzeArgs2)
+	if err != nil {
+		return nil, err
+	}
+	v := provideGitLabCollectors(context, client, analyzeArgs2)
+	collectorManager := collectors_manager.NewCollectorsManager(context, v)
+	enginer, err := provideOpa(analyzeArgs2)
+	if err != nil {
+		return nil, err
+	}
+	skipper := skippers.NewSkipper(context, enginer)
+	analyzer := analyzers.NewAnalyzer(context, enginer, skipper)
+	enricherManager := enricher.NewEnricherManager()
+	cmdAnalyzePipeline := initializeAnalyzePipeline(collectorManager, analyzer, enricherManager, context, client)
+	return cmdAnalyzePipeline, nil
+}
+
 func setupGitLabGPTExecutor(analyzeArgs2 *args) (*analyzeGPTExecutor, error) {
 	client, err := provideGitLabClient(analyzeArgs2)
 	if err != nil {
@@ -98,7 +146,7 @@ func setupGitLabGPTExecutor(analyzeArgs2 *args) (*analyzeGPTExecutor, error) {
 	}
 	analyzer := provideGPTAnalyzer(context, analyzeArgs2)
 	v := provideGitLabCollectors(context, client, analyzeArgs2)
-	collectorManager := collectors_manager.NewCollectorsManager(v)
+	collectorManager := collectors_manager.NewCollectorsManager(context, v)
 	cmdAnalyzeGPTExecutor := initializeAnalyzeGPTExecutor(analyzer, collectorManager, context)
 	return cmdAnalyzeGPTExecutor, nil
 }
@@ -114,6 +162,10 @@ func provideGitHubCollectors(ctx context.Context, client *github.Client, analyze
 		result = append(result, collectorsMapping[ns](ctx, client))
 	}
 
+	if analyzeArgs2.CheckRateLimit {
+		checkRateLimitBudget(client, result)
+	}
+
 	return result
 }
 
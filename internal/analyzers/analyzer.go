@@ -6,6 +6,10 @@ import (
 	"github.com/Legit-Labs/legitify/internal/analyzers/skippers"
 	githubcollected "github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/coverage"
+	"github.com/Legit-Labs/legitify/internal/inventory"
+	"github.com/Legit-Labs/legitify/internal/protection"
 	"log"
 
 	"github.com/Legit-Labs/legitify/internal/collectors"
@@ -35,6 +39,7 @@ type AnalyzedData struct {
 	RemediationSteps         []string
 	Threat                   []string
 	Severity                 severity.Severity
+	Category                 string
 	CanonicalLink            string
 	ExtraData                interface{}
 	Status                   PolicyStatus
@@ -46,16 +51,22 @@ type Analyzer interface {
 
 func NewAnalyzer(ctx context.Context, enginer opa_engine.Enginer, skipper skippers.Skipper) Analyzer {
 	return &analyzer{
-		context: ctx,
-		engine:  enginer,
-		skipper: skipper,
+		context:    ctx,
+		engine:     enginer,
+		skipper:    skipper,
+		coverage:   context_utils.GetCoverage(ctx),
+		inventory:  context_utils.GetInventory(ctx),
+		protection: context_utils.GetProtectionReport(ctx),
 	}
 }
 
 type analyzer struct {
-	context context.Context
-	engine  opa_engine.Enginer
-	skipper skippers.Skipper
+	context    context.Context
+	engine     opa_engine.Enginer
+	skipper    skippers.Skipper
+	coverage   *coverage.Report
+	inventory  *inventory.Inventory
+	protection *protection.Report
 }
 
 func newAnalyzedData(collectedData collectors.CollectedData, result opa_engine.QueryResult, status PolicyStatus) AnalyzedData {
@@ -71,6 +82,7 @@ func newAnalyzedData(collectedData collectors.CollectedData, result opa_engine.Q
 		RemediationSteps:         parsing_utils.ResolveAnnotation(result.Annotations.Custom["remediationSteps"]),
 		Threat:                   parsing_utils.ResolveAnnotation(result.Annotations.Custom["threat"]),
 		Severity:                 resolveSeverity(result),
+		Category:                 resolveCategory(result),
 		CanonicalLink:            collectedData.Entity.CanonicalLink(),
 		ExtraData:                result.ExtraData,
 		Status:                   status,
@@ -86,14 +98,20 @@ func (a *analyzer) Analyze(dataChannel <-chan collectors.CollectedData) <-chan A
 		for data := range dataChannel {
 			data := data
 			gw.Do(func() {
+				a.inventory.Add(data.Namespace, data.Entity)
+				a.protection.Add(data.Entity)
 				results, err := a.engine.Query(a.context, data.Namespace, data.Entity)
 				if err != nil {
 					log.Printf("Failed to query opa %s: %s", data.Namespace, err)
 					return
 				}
 
+				document := a.coverageDocument(data)
 				for _, result := range results {
 					status := a.resolvePolicyStatus(data, result)
+					if document != nil {
+						a.coverage.Add(result.FullyQualifiedPolicyName, data.Entity.Name(), status, document)
+					}
 					outputChannel <- newAnalyzedData(data, result, status)
 				}
 			})
@@ -104,6 +122,20 @@ func (a *analyzer) Analyze(dataChannel <-chan collectors.CollectedData) <-chan A
 	return outputChannel
 }
 
+// coverageDocument returns the input document of the entity when the coverage report is enabled
+func (a *analyzer) coverageDocument(data collectors.CollectedData) interface{} {
+	if !a.coverage.Enabled() {
+		return nil
+	}
+
+	document, err := coverage.InputDocument(data.Entity)
+	if err != nil {
+		log.Printf("failed to build the coverage input of %s: %v", data.Entity.Name(), err)
+		return nil
+	}
+	return document
+}
+
 func (a *analyzer) resolvePolicyStatus(data collectors.CollectedData, opaResult opa_engine.QueryResult) PolicyStatus {
 	if a.skipper.ShouldSkip(data, opaResult) {
 		return PolicySkipped
@@ -116,6 +148,12 @@ func (a *analyzer) resolvePolicyStatus(data collectors.CollectedData, opaResult
 	return PolicyFailed
 }
 
+// resolveCategory returns the optional category of the policy (e.g. "secrets"), which groups related policies of all namespaces
+func resolveCategory(qResult opa_engine.QueryResult) string {
+	category, _ := qResult.Annotations.Custom["category"].(string)
+	return category
+}
+
 func resolveSeverity(qResult opa_engine.QueryResult) severity.Severity {
 	s := severity.Unknown
 	raw := qResult.Annotations.Custom["severity"]
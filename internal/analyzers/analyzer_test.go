@@ -26,7 +26,7 @@ func TestAnalyzerSanity(t *testing.T) {
 
 	// Doesn't matter which scm type we use here
 	engine, _ := opa.Load([]string{}, scm_type.GitHub)
-	analyzer := NewAnalyzer(ctx, engine, skippers.NewSkipper(ctx))
+	analyzer := NewAnalyzer(ctx, engine, skippers.NewSkipper(ctx, engine))
 	require.NotNilf(t, analyzer, "failed to create analyzer")
 
 	type nullEntity struct {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"github.com/Legit-Labs/legitify/internal/analyzers/parsing_utils"
 	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/data_groups"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	"github.com/Legit-Labs/legitify/internal/context_utils"
 	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/opa"
 	"github.com/Legit-Labs/legitify/internal/opa/opa_engine"
 	"log"
 )
@@ -17,10 +19,19 @@ type Skipper interface {
 
 type IsPrerequisitesSatisfied func(data collectors.CollectedData) bool
 
-func NewSkipper(ctx context.Context) Skipper {
+func NewSkipper(ctx context.Context, engine opa_engine.Enginer) Skipper {
+	dataGroups := context_utils.GetDataGroups(ctx)
+	var policyFields map[string][]string
+	if len(dataGroups) > 0 {
+		// the input fields tell which data groups each policy depends on
+		policyFields = opa.PolicyInputFields(engine.Modules())
+	}
+
 	return &skipper{
 		ctx:             ctx,
 		ignoredPolicies: context_utils.GetIgnoredPolicies(ctx),
+		dataGroups:      dataGroups,
+		policyFields:    policyFields,
 		prerequisitesCheckers: map[string]IsPrerequisitesSatisfied{
 			"premium": func(data collectors.CollectedData) bool {
 				return data.Context.Premium()
@@ -28,6 +39,15 @@ func NewSkipper(ctx context.Context) Skipper {
 			"scorecard_enabled": func(data collectors.CollectedData) bool {
 				return context_utils.GetScorecardEnabled(ctx)
 			},
+			"actions_storage_enabled": func(data collectors.CollectedData) bool {
+				return context_utils.GetActionsStorageEnabled(ctx)
+			},
+			"workflow_contents_enabled": func(data collectors.CollectedData) bool {
+				return context_utils.GetWorkflowContentsEnabled(ctx)
+			},
+			"self_hosted_runners_enabled": func(data collectors.CollectedData) bool {
+				return !context_utils.GetSkipSelfHostedRunners(ctx)
+			},
 			"has_branch_protection_permission": func(data collectors.CollectedData) bool {
 				repositoryContext, ok := data.Context.(collectors.CollectedDataRepositoryContext)
 				if !ok {
@@ -55,6 +75,8 @@ type skipper struct {
 	ctx                   context.Context
 	prerequisitesCheckers map[string]IsPrerequisitesSatisfied
 	ignoredPolicies       []string
+	dataGroups            []data_groups.DataGroup
+	policyFields          map[string][]string
 }
 
 func (sm *skipper) ShouldSkip(data collectors.CollectedData, violation opa_engine.QueryResult) bool {
@@ -62,6 +84,17 @@ func (sm *skipper) ShouldSkip(data collectors.CollectedData, violation opa_engin
 		return true
 	}
 
+	if repositoryContext, ok := data.Context.(collectors.CollectedDataRepositoryContext); ok && repositoryContext.IsDisabled() {
+		errlog.AddSkipIssue(violation.PolicyName, data.Entity.Name(), errlog.NewDisabledSkipReason("repository is disabled"))
+		return true
+	}
+
+	// the policy would be evaluated against data that was not collected
+	if group, unselected := data_groups.Unselected(violation.FullyQualifiedPolicyName, sm.policyFields[violation.FullyQualifiedPolicyName], sm.dataGroups); unselected {
+		errlog.AddSkipIssue(violation.PolicyName, data.Entity.Name(), errlog.NewPrerequisiteSkipReason("collection of "+group+" data (--collect)"))
+		return true
+	}
+
 	prerequisites := parsing_utils.ResolveAnnotation(violation.Annotations.Custom["prerequisites"])
 
 	sufficient, missingPrerequisite := sm.arePrerequisitesSatisfied(prerequisites, data)
@@ -112,3 +145,22 @@ func sufficientScopes(roles []permissions.Role, availableScopes permissions.Toke
 
 	return true, ""
 }
+
+// NewInputSkipper returns the skipper of entities that were not collected by legitify (see the evaluate command).
+// The permissions and prerequisites of the collection do not apply to such entities, so only the ignored policies are skipped.
+func NewInputSkipper(ctx context.Context) Skipper {
+	return &inputSkipper{
+		skipper: skipper{
+			ctx:             ctx,
+			ignoredPolicies: context_utils.GetIgnoredPolicies(ctx),
+		},
+	}
+}
+
+type inputSkipper struct {
+	skipper
+}
+
+func (s *inputSkipper) ShouldSkip(_ collectors.CollectedData, violation opa_engine.QueryResult) bool {
+	return s.ignoredPolicy(violation)
+}
@@ -0,0 +1,101 @@
+package skippers
+
+import (
+	"context"
+	"testing"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/data_groups"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/Legit-Labs/legitify/internal/opa/opa_engine"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/stretchr/testify/require"
+)
+
+type testRepositoryContext struct {
+	disabled bool
+}
+
+func (c testRepositoryContext) Premium() bool {
+	return false
+}
+
+func (c testRepositoryContext) Roles() []permissions.Role {
+	return []permissions.Role{permissions.RepoRoleAdmin}
+}
+
+func (c testRepositoryContext) HasBranchProtectionPermission() bool {
+	return true
+}
+
+func (c testRepositoryContext) HasGithubAdvancedSecurity() bool {
+	return true
+}
+
+func (c testRepositoryContext) IsDisabled() bool {
+	return c.disabled
+}
+
+func TestSkipDisabledRepository(t *testing.T) {
+	defer errlog.Isolate()()
+
+	skipper := NewSkipper(context_utils.NewContextWithTokenScopes(context.Background(), permissions.TokenScopes{}), nil)
+	violation := opa_engine.QueryResult{
+		PolicyName:               "repository_not_maintained",
+		FullyQualifiedPolicyName: "data.repository.repository_not_maintained",
+		Annotations:              &ast.Annotations{Custom: map[string]interface{}{}},
+	}
+	collectedData := func(name string, disabled bool) collectors.CollectedData {
+		return collectors.CollectedData{
+			Context:   testRepositoryContext{disabled: disabled},
+			Entity:    githubcollected.Repository{Repository: &githubcollected.GitHubQLRepository{Name: name}},
+			Namespace: namespace.Repository,
+		}
+	}
+
+	require.True(t, skipper.ShouldSkip(collectedData("disabled", true), violation))
+	reason, ok := errlog.SkipReasonOf(violation.PolicyName, "disabled")
+	require.True(t, ok, "expecting the skip of the disabled repository to be reported")
+	require.Equal(t, "Disabled", reason.ReasonPrefix())
+
+	require.False(t, skipper.ShouldSkip(collectedData("enabled", false), violation))
+	_, ok = errlog.SkipReasonOf(violation.PolicyName, "enabled")
+	require.False(t, ok)
+}
+
+func TestSkipUnselectedDataGroup(t *testing.T) {
+	defer errlog.Isolate()()
+
+	engine, err := opa.Load([]string{}, scm_type.GitHub)
+	require.Nil(t, err, "failed initializing opa client")
+	ctx := context_utils.NewContextWithTokenScopes(context.Background(), permissions.TokenScopes{})
+	ctx = context_utils.NewContextWithDataGroups(ctx, []data_groups.DataGroup{data_groups.Hooks})
+	skipper := NewSkipper(ctx, engine)
+
+	data := collectors.CollectedData{
+		Context:   testRepositoryContext{},
+		Entity:    githubcollected.Repository{Repository: &githubcollected.GitHubQLRepository{Name: "repo"}},
+		Namespace: namespace.Repository,
+	}
+	violation := func(policyName string) opa_engine.QueryResult {
+		return opa_engine.QueryResult{
+			PolicyName:               policyName,
+			FullyQualifiedPolicyName: "data.repository." + policyName,
+			Annotations:              &ast.Annotations{Custom: map[string]interface{}{}},
+		}
+	}
+
+	require.False(t, skipper.ShouldSkip(data, violation("repository_webhook_no_secret")), "expecting the policies of the selected groups to be evaluated")
+	require.False(t, skipper.ShouldSkip(data, violation("repository_not_maintained")), "expecting the policies of no group to be evaluated")
+
+	require.True(t, skipper.ShouldSkip(data, violation("code_review_not_required")))
+	reason, ok := errlog.SkipReasonOf("code_review_not_required", "repo")
+	require.True(t, ok, "expecting the skip of the policy to be reported")
+	require.Contains(t, reason.String(), data_groups.BranchProtection)
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Legit-Labs/legitify/internal/clients/github/pagination"
 	"github.com/Legit-Labs/legitify/internal/clients/github/transport"
@@ -24,7 +25,6 @@ import (
 
 	gh "github.com/google/go-github/v53/github"
 	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
 )
 
 const scopeHttpHeader = "X-OAuth-Scopes"
@@ -39,6 +39,9 @@ type Client struct {
 	serverUrl        string
 	once             sync.Once
 	enterprises      []string
+	tokens           []string
+	orgCache         orgCache
+	callMetrics      *transport.CallMetrics
 }
 
 func NewClient(ctx context.Context, token string, githubEndpoint string, org []string, enterprises []string) (*Client, error) {
@@ -77,13 +80,21 @@ func (c *Client) IsGithubCloud() bool {
 }
 
 func (c *Client) initClients(ctx context.Context, token string) error {
-	if err := c.validateToken(token); err != nil {
-		return err
+	tokens := splitTokens(token)
+	if len(tokens) == 0 {
+		return fmt.Errorf("missing token")
+	}
+	for _, t := range tokens {
+		if err := c.validateToken(t); err != nil {
+			return err
+		}
 	}
+	c.tokens = tokens
 
 	var ghClient *gh.Client
 	var graphQLClient *githubv4.Client
-	rawClient, graphQLRawClient, err := newHttpClients(ctx, token)
+	c.callMetrics = transport.NewCallMetrics()
+	rawClient, graphQLRawClient, err := newHttpClients(ctx, tokens, c.callMetrics)
 	if err != nil {
 		return err
 	}
@@ -106,6 +117,18 @@ func (c *Client) initClients(ctx context.Context, token string) error {
 	return nil
 }
 
+// splitTokens allows passing multiple tokens as a comma separated list
+// in order to spread the requests across their rate limits.
+func splitTokens(token string) []string {
+	var tokens []string
+	for _, t := range strings.Split(token, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
 // Note: tokens before April 2021 did not have the ghp_ prefix.
 var githubTokenPattern = regexp.MustCompile("(ghp_)?[A-Za-z0-9_]{36}")
 
@@ -247,12 +270,45 @@ func (c *Client) getRole(orgName string) (permissions.OrganizationRole, error) {
 	return permissions.GetOrgRole(query.Organization.ViewerCanAdminister), nil
 }
 
+// collectTokenScopes validates each of the tokens against the server.
+// When multiple tokens are used, only the scopes granted to all of them are considered available
+// since any request may be sent with any of the tokens.
 func (c *Client) collectTokenScopes() (permissions.TokenScopes, error) {
+	var scopes permissions.TokenScopes
+	for i, token := range c.tokens {
+		tokenScopes, err := c.collectScopesForToken(token)
+		if err != nil {
+			return nil, fmt.Errorf("token #%d: %v", i+1, err)
+		}
+		if scopes == nil {
+			scopes = tokenScopes
+			continue
+		}
+		for scope, granted := range scopes {
+			scopes[scope] = granted && tokenScopes[scope]
+		}
+	}
+
+	return scopes, nil
+}
+
+func (c *Client) collectScopesForToken(token string) (permissions.TokenScopes, error) {
 	var buf bytes.Buffer
-	resp, err := c.graphQLRawClient.Post(c.getGitHubGraphURL(), "application/json", &buf)
+	req, err := http.NewRequestWithContext(c.context, http.MethodPost, c.getGitHubGraphURL(), &buf)
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.graphQLRawClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("bad credentials")
+	}
 
 	scopesList := resp.Header.Get(scopeHttpHeader)
 	parsed := strings.Split(scopesList, ", ")
@@ -299,7 +355,14 @@ func (c *Client) collectSpecificOrganizations() ([]githubcollected.ExtendedOrg,
 	return res, nil
 }
 
+// Organization returns the organization metadata, which is fetched once per scan
 func (c *Client) Organization(login string) (*githubcollected.ExtendedOrg, error) {
+	return c.orgCache.get(login, func() (*githubcollected.ExtendedOrg, error) {
+		return c.fetchOrganization(login)
+	})
+}
+
+func (c *Client) fetchOrganization(login string) (*githubcollected.ExtendedOrg, error) {
 	org, _, err := c.Client().Organizations.Get(c.context, login)
 
 	if err != nil {
@@ -340,6 +403,65 @@ func (c *Client) GetActionsTokenPermissions(url string) (*types.TokenPermissions
 	return &p, nil
 }
 
+func (c *Client) GetForkPullRequestApprovalForOrganization(organization string) (*types.ForkPullRequestApproval, *gh.Response, error) {
+	u := fmt.Sprintf("orgs/%s/actions/permissions/fork-pr-contributor-approval", organization)
+	return c.GetForkPullRequestApproval(u)
+}
+
+func (c *Client) GetForkPullRequestApprovalForRepository(organization string, repository string) (*types.ForkPullRequestApproval, *gh.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/actions/permissions/fork-pr-contributor-approval", organization, repository)
+	return c.GetForkPullRequestApproval(u)
+}
+
+func (c *Client) GetForkPullRequestApproval(url string) (*types.ForkPullRequestApproval, *gh.Response, error) {
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	approval := types.ForkPullRequestApproval{}
+	resp, err := c.client.Do(c.context, req, &approval)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &approval, resp, nil
+}
+
+// GetLatestRelease returns the latest published release of the repository (not found when the repository has no releases)
+func (c *Client) GetLatestRelease(organization string, repository string) (*types.Release, *gh.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/releases/latest", organization, repository)
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := types.Release{}
+	resp, err := c.client.Do(c.context, req, &release)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &release, resp, nil
+}
+
+// HasAttestations reports whether the repository has attestations for the subject digest (e.g. "sha256:...")
+func (c *Client) HasAttestations(organization string, repository string, digest string) (bool, *gh.Response, error) {
+	u := fmt.Sprintf("repos/%s/%s/attestations/%s?per_page=1", organization, repository, digest)
+	req, err := c.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return false, nil, err
+	}
+
+	attestations := types.Attestations{}
+	resp, err := c.client.Do(c.context, req, &attestations)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, resp, nil
+		}
+		return false, resp, err
+	}
+	return len(attestations.Attestations) > 0, resp, nil
+}
+
 func (c *Client) IsAnalyzable(repository commontypes.RepositoryWithOwner) (bool, error) {
 	var repo struct {
 		Repository struct {
@@ -493,26 +615,29 @@ func (se *samlError) Error() string {
 	return fmt.Sprintf("Token is not SAML authorized for organization: %s.\nPlease go to https://github.com/settings/tokens and authorize.", se.organization)
 }
 
-func newHttpClients(ctx context.Context, token string) (client *http.Client, graphQL *http.Client, err error) {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := &oauth2.Transport{
-		Base:   commontransport.NewCacheTransport(),
-		Source: ts,
+func newHttpClients(ctx context.Context, tokens []string, metrics *transport.CallMetrics) (client *http.Client, graphQL *http.Client, err error) {
+	tc, err := transport.NewTokenRotator(commontransport.NewCacheTransport(), tokens)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rateLimitWaiter, err := transport.NewRateLimitWaiter(ctx, tc)
+	rateLimitWaiter, err := transport.NewRateLimitWaiter(ctx, transport.NewAttemptCounter(tc, metrics), metrics)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create rate limiter: %v", err)
 	}
 
-	clientWithSecondaryRateLimit := commontransport.NewCacheTracker(rateLimitWaiter)
-	clientWithAcceptHeader := transport.NewGraphQL(rateLimitWaiter.Transport)
+	countedClient := &http.Client{Transport: transport.NewCallCounter(rateLimitWaiter.Transport, metrics)}
+	clientWithSecondaryRateLimit := commontransport.NewCacheTracker(countedClient)
+	clientWithAcceptHeader := transport.NewGraphQL(transport.NewSchemaFallback(countedClient.Transport))
 
 	return clientWithSecondaryRateLimit, clientWithAcceptHeader, nil
 }
 
+// APICalls returns the totals of the API calls the client made so far
+func (c *Client) APICalls() transport.Metrics {
+	return c.callMetrics.Snapshot()
+}
+
 var enterpriseQuery struct {
 	Enterprise struct {
 		OwnerInfo struct {
@@ -615,6 +740,7 @@ func (c *Client) GetRulesForBranch(organization, repository, branch string) ([]*
 		}
 
 		rule.Ruleset = specific
+		rule.Enforcement = specific.Enforcement
 	}
 
 	return rules, nil
@@ -658,9 +784,18 @@ func (c *Client) GetOrganizationSecrets(org string) (*gh.Secrets, error) {
 	return secrets, nil
 }
 
-func (c *Client) GetSecurityAndAnalysisForRepository(repo, owner string) (*gh.SecurityAndAnalysis, error) {
-	r, res, err := c.Client().Repositories.Get(c.context, owner, repo)
+// GetSecurityAndAnalysisForRepository fetches the repository directly (rather than through go-github)
+// to keep the secret scanning sub-features (validity checks, non-provider patterns, generic secrets)
+func (c *Client) GetSecurityAndAnalysisForRepository(repo, owner string) (*types.SecurityAndAnalysis, error) {
+	req, err := c.Client().NewRequest("GET", fmt.Sprintf("repos/%v/%v", owner, repo), nil)
+	if err != nil {
+		return nil, err
+	}
 
+	var r struct {
+		SecurityAndAnalysis *types.SecurityAndAnalysis `json:"security_and_analysis,omitempty"`
+	}
+	res, err := c.Client().Do(c.context, req, &r)
 	if err != nil {
 		return nil, err
 	}
@@ -670,3 +805,188 @@ func (c *Client) GetSecurityAndAnalysisForRepository(repo, owner string) (*gh.Se
 
 	return r.SecurityAndAnalysis, nil
 }
+
+// RateLimitBudget is the rate limit state of a single API resource, summed across all of the tokens.
+type RateLimitBudget struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimitBudgets returns the REST (core) and GraphQL rate limit budgets.
+// The reset time is the earliest reset among the tokens.
+func (c *Client) RateLimitBudgets() (core RateLimitBudget, graphql RateLimitBudget, err error) {
+	for i, token := range c.tokens {
+		limits, err := c.rateLimitsForToken(token)
+		if err != nil {
+			return RateLimitBudget{}, RateLimitBudget{}, fmt.Errorf("token #%d: %v", i+1, err)
+		}
+		core = addRateLimit(core, limits.Core)
+		graphql = addRateLimit(graphql, limits.GraphQL)
+	}
+
+	return core, graphql, nil
+}
+
+func addRateLimit(budget RateLimitBudget, rate *gh.Rate) RateLimitBudget {
+	if rate == nil {
+		return budget
+	}
+	budget.Limit += rate.Limit
+	budget.Remaining += rate.Remaining
+	if budget.Reset.IsZero() || rate.Reset.Time.Before(budget.Reset) {
+		budget.Reset = rate.Reset.Time
+	}
+	return budget
+}
+
+func (c *Client) rateLimitsForToken(token string) (*gh.RateLimits, error) {
+	req, err := c.client.NewRequest("GET", "rate_limit", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var response struct {
+		Resources *gh.RateLimits `json:"resources"`
+	}
+	if _, err = c.client.Do(c.context, req, &response); err != nil {
+		return nil, err
+	}
+	if response.Resources == nil {
+		return nil, fmt.Errorf("empty rate limit response")
+	}
+
+	return response.Resources, nil
+}
+
+func (c *Client) GetOrganizationInstallations(org string) ([]*gh.Installation, error) {
+	mapper := func(installations *gh.OrganizationInstallations) []*gh.Installation {
+		if installations == nil {
+			return []*gh.Installation{}
+		}
+		return installations.Installations
+	}
+
+	res, err := pagination.NewMapper(c.client.Organizations.ListInstallations, &gh.ListOptions{}, mapper).Sync(c.context, org)
+	if err != nil {
+		return nil, err
+	}
+	return res.Collected, nil
+}
+
+func (c *Client) GetInstallationRepositories(installationID int64) ([]*gh.Repository, error) {
+	mapper := func(repositories *gh.ListRepositories) []*gh.Repository {
+		if repositories == nil {
+			return []*gh.Repository{}
+		}
+		return repositories.Repositories
+	}
+
+	res, err := pagination.NewMapper(c.client.Apps.ListUserRepos, &gh.ListOptions{}, mapper).Sync(c.context, installationID)
+	if err != nil {
+		return nil, err
+	}
+	return res.Collected, nil
+}
+
+func (c *Client) GetOrganizationSSHCertificateAuthorities(org string) ([]*githubcollected.SSHCertificateAuthority, error) {
+	url := fmt.Sprintf("orgs/%v/ssh-certificate-authorities", org)
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	authorities := []*githubcollected.SSHCertificateAuthority{}
+	if _, err = c.client.Do(c.context, req, &authorities); err != nil {
+		return nil, err
+	}
+	return authorities, nil
+}
+
+// IsPrivateVulnerabilityReportingEnabled returns whether the repository accepts private vulnerability reports.
+// The response is returned to distinguish unsupported repositories from missing permissions.
+func (c *Client) IsPrivateVulnerabilityReportingEnabled(owner, repository string) (bool, *gh.Response, error) {
+	url := fmt.Sprintf("repos/%v/%v/private-vulnerability-reporting", owner, repository)
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var result struct {
+		Enabled bool `json:"enabled"`
+	}
+	resp, err := c.client.Do(c.context, req, &result)
+	if err != nil {
+		return false, resp, err
+	}
+	return result.Enabled, resp, nil
+}
+
+func (c *Client) GetAutomatedSecurityFixes(owner, repository string) (*types.AutomatedSecurityFixes, *gh.Response, error) {
+	url := fmt.Sprintf("repos/%v/%v/automated-security-fixes", owner, repository)
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fixes types.AutomatedSecurityFixes
+	resp, err := c.client.Do(c.context, req, &fixes)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &fixes, resp, nil
+}
+
+func (c *Client) GetArtifactAndLogRetentionForRepository(owner, repository string) (*types.ArtifactAndLogRetention, *gh.Response, error) {
+	url := fmt.Sprintf("repos/%v/%v/actions/permissions/artifact-and-log-retention", owner, repository)
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var retention types.ArtifactAndLogRetention
+	resp, err := c.client.Do(c.context, req, &retention)
+	if err != nil {
+		return nil, resp, err
+	}
+	return &retention, resp, nil
+}
+
+// ListOrganizationRulesets lists a page of the organization rulesets (the go-github listing does not paginate)
+func (c *Client) ListOrganizationRulesets(ctx context.Context, org string, opts *gh.ListOptions) ([]*gh.Ruleset, *gh.Response, error) {
+	url := fmt.Sprintf("orgs/%v/rulesets", org)
+	if opts != nil && opts.Page > 0 {
+		url = fmt.Sprintf("%s?page=%d", url, opts.Page)
+	}
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rulesets []*gh.Ruleset
+	resp, err := c.client.Do(ctx, req, &rulesets)
+	if err != nil {
+		return nil, resp, err
+	}
+	return rulesets, resp, nil
+}
+
+// ListCredentialAuthorizations lists the credentials authorized to access a SAML SSO organization (organization owners only)
+func (c *Client) ListCredentialAuthorizations(ctx context.Context, org string, opts *gh.ListOptions) ([]*types.CredentialAuthorization, *gh.Response, error) {
+	url := fmt.Sprintf("orgs/%v/credential-authorizations", org)
+	if opts != nil && opts.Page > 0 {
+		url = fmt.Sprintf("%s?page=%d", url, opts.Page)
+	}
+	req, err := c.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var authorizations []*types.CredentialAuthorization
+	resp, err := c.client.Do(ctx, req, &authorizations)
+	if err != nil {
+		return nil, resp, err
+	}
+	return authorizations, resp, nil
+}
@@ -0,0 +1,46 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/clients/github/transport"
+	gh "github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+)
+
+// The scorecard checks and the check run of --check-run send their calls through the clients of the collection,
+// so their calls are counted with the calls of the collectors.
+func TestHttpClientsCountCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	metrics := transport.NewCallMetrics()
+	rawClient, _, err := newHttpClients(context.Background(), []string{"token"}, metrics)
+	require.Nil(t, err)
+
+	// the scorecard runner is given the transport of the client (see scorecard.NewRunner)
+	scorecardClient := &http.Client{Transport: rawClient.Transport}
+	resp, err := scorecardClient.Get(server.URL + "/repos/owner/repo/contents")
+	require.Nil(t, err)
+	resp.Body.Close()
+	resp, err = scorecardClient.Post(server.URL+"/graphql", "application/json", nil)
+	require.Nil(t, err)
+	resp.Body.Close()
+
+	// the check run is created with the go-github client (see provideCheckRun)
+	checkRunClient := gh.NewClient(rawClient)
+	checkRunClient.BaseURL, _ = url.Parse(server.URL + "/")
+	_, _, err = checkRunClient.Checks.CreateCheckRun(context.Background(), "owner", "repo", gh.CreateCheckRunOptions{Name: "legitify", HeadSHA: "sha"})
+	require.Nil(t, err)
+
+	calls := metrics.Snapshot()
+	require.Equal(t, int64(2), calls.Rest)
+	require.Equal(t, int64(1), calls.GraphQL)
+}
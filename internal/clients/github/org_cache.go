@@ -0,0 +1,71 @@
+package github
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/google/go-github/v53/github"
+)
+
+// orgCache keeps the metadata of the organizations (plan - and thus the enterprise status - and the viewer role)
+// for the duration of a scan, so collecting many repositories of the same owner does not fetch it repeatedly.
+type orgCache struct {
+	lock    sync.Mutex
+	entries map[string]*orgCacheEntry
+}
+
+// orgCacheEntry holds the encoded organization, so every caller decodes its own copy
+// (the organization is made of pointers, which a shallow copy would share between the collectors)
+type orgCacheEntry struct {
+	lock    sync.Mutex
+	fetched bool
+	org     []byte
+	role    permissions.OrganizationRole
+}
+
+func (c *orgCache) entry(login string) *orgCacheEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*orgCacheEntry)
+	}
+
+	key := strings.ToLower(login) // logins are case insensitive
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &orgCacheEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// get returns a deep copy of the cached organization, fetching it on first use.
+// Concurrent requests for the same organization wait for a single fetch; failures are not cached.
+func (c *orgCache) get(login string, fetch func() (*githubcollected.ExtendedOrg, error)) (*githubcollected.ExtendedOrg, error) {
+	entry := c.entry(login)
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if !entry.fetched {
+		org, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(org.Organization)
+		if err != nil {
+			return nil, err
+		}
+		entry.org, entry.role, entry.fetched = encoded, org.Role, true
+	}
+
+	var org github.Organization
+	if err := json.Unmarshal(entry.org, &org); err != nil {
+		return nil, err
+	}
+	extended := githubcollected.NewExtendedOrg(&org, entry.role)
+	return &extended, nil
+}
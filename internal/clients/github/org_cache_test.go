@@ -0,0 +1,62 @@
+package github
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	gh "github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgCache(t *testing.T) {
+	var cache orgCache
+	var fetches int32
+	fetch := func() (*githubcollected.ExtendedOrg, error) {
+		atomic.AddInt32(&fetches, 1)
+		org := githubcollected.NewExtendedOrg(&gh.Organization{Login: gh.String("Org"), Plan: &gh.Plan{Name: gh.String("enterprise")}}, permissions.OrgRoleOwner)
+		return &org, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		login := "org"
+		if i%2 == 0 {
+			login = "ORG"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			org, err := cache.get(login, fetch)
+			require.Nil(t, err)
+			require.Equal(t, permissions.OrgRoleOwner, org.Role)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), fetches, "expecting a single fetch per organization")
+
+	// every caller gets its own copy
+	first, err := cache.get("org", fetch)
+	require.Nil(t, err)
+	*first.Login = "changed"
+	*first.Plan.Name = "free"
+	first.Role = permissions.OrgRoleMember
+	second, err := cache.get("org", fetch)
+	require.Nil(t, err)
+	require.Equal(t, "Org", second.GetLogin())
+	require.True(t, second.IsEnterprise())
+	require.Equal(t, permissions.OrgRoleOwner, second.Role)
+
+	// failures are not cached
+	failing := func() (*githubcollected.ExtendedOrg, error) {
+		return nil, fmt.Errorf("temporary failure")
+	}
+	_, err = cache.get("other", failing)
+	require.NotNil(t, err)
+	_, err = cache.get("other", fetch)
+	require.Nil(t, err)
+	require.Equal(t, int32(2), fetches)
+}
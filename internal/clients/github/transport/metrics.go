@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Metrics are the totals of the API calls a client made
+type Metrics struct {
+	Rest    int64
+	GraphQL int64
+	// Cached is the number of calls (of either type) that were served from the cache
+	Cached int64
+	// Retries is the number of calls that were sent again after a secondary rate limit
+	Retries int64
+	// RateLimitSleeps is the number of times the client slept until a secondary rate limit was lifted
+	RateLimitSleeps int64
+}
+
+// CallMetrics counts the API calls of a client, for tuning the concurrency and the scheduling of the scans.
+// The calls are counted above the rate limit waiter and the attempts below it, so the difference is the retries.
+type CallMetrics struct {
+	rest     atomic.Int64
+	graphQL  atomic.Int64
+	cached   atomic.Int64
+	attempts atomic.Int64
+	sleeps   atomic.Int64
+}
+
+func NewCallMetrics() *CallMetrics {
+	return &CallMetrics{}
+}
+
+func (m *CallMetrics) Snapshot() Metrics {
+	calls := m.rest.Load() + m.graphQL.Load()
+	retries := m.attempts.Load() - calls
+	if retries < 0 {
+		retries = 0 // attempts of calls that are still in flight
+	}
+	return Metrics{
+		Rest:            m.rest.Load(),
+		GraphQL:         m.graphQL.Load(),
+		Cached:          m.cached.Load(),
+		Retries:         retries,
+		RateLimitSleeps: m.sleeps.Load(),
+	}
+}
+
+func (m *CallMetrics) addSleep() {
+	m.sleeps.Add(1)
+}
+
+type callCounter struct {
+	base    http.RoundTripper
+	metrics *CallMetrics
+}
+
+// NewCallCounter counts the calls by their type (REST or GraphQL)
+func NewCallCounter(base http.RoundTripper, metrics *CallMetrics) http.RoundTripper {
+	return &callCounter{base: base, metrics: metrics}
+}
+
+func (c *callCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	if resourceOf(request) == graphQLResource {
+		c.metrics.graphQL.Add(1)
+	} else {
+		c.metrics.rest.Add(1)
+	}
+	return c.base.RoundTrip(request)
+}
+
+type attemptCounter struct {
+	base    http.RoundTripper
+	metrics *CallMetrics
+}
+
+// NewAttemptCounter counts every attempt to send a call (including the retries), and the ones served from the cache
+func NewAttemptCounter(base http.RoundTripper, metrics *CallMetrics) http.RoundTripper {
+	return &attemptCounter{base: base, metrics: metrics}
+}
+
+func (c *attemptCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	c.metrics.attempts.Add(1)
+	resp, err := c.base.RoundTrip(request)
+	if resp != nil {
+		if _, cached := resp.Header[fromCacheHeader]; cached {
+			c.metrics.cached.Add(1)
+		}
+	}
+	return resp, err
+}
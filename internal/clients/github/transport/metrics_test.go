@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestCallMetrics(t *testing.T) {
+	metrics := NewCallMetrics()
+	sent := 0
+	base := NewAttemptCounter(roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		sent++
+		header := http.Header{}
+		if request.URL.Path == "/repos/org/cached" {
+			header.Set(fromCacheHeader, "1")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+	}), metrics)
+	// retries the first graphql call once, like the rate limit waiter does after a secondary rate limit
+	retrying := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		if resourceOf(request) == graphQLResource && sent == 0 {
+			metrics.addSleep()
+			if _, err := base.RoundTrip(request); err != nil {
+				return nil, err
+			}
+		}
+		return base.RoundTrip(request)
+	})
+	client := &http.Client{Transport: NewCallCounter(retrying, metrics)}
+
+	for _, path := range []string{"/graphql", "/repos/org/name", "/repos/org/cached"} {
+		resp, err := client.Get("https://api.github.com" + path)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	require.Equal(t, Metrics{Rest: 2, GraphQL: 1, Cached: 1, Retries: 1, RateLimitSleeps: 1}, metrics.Snapshot())
+}
@@ -0,0 +1,342 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const undefinedFieldCode = "undefinedField"
+
+var undefinedFieldMessage = regexp.MustCompile(`^Field '(\w+)' doesn't exist on type '(\w+)'`)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLErrors struct {
+	Errors []struct {
+		Message string `json:"message"`
+		// Path is the path of the field in the query: the operation, then the fields (by alias) and the inline fragments
+		Path       []interface{} `json:"path"`
+		Extensions struct {
+			Code      string `json:"code"`
+			TypeName  string `json:"typeName"`
+			FieldName string `json:"fieldName"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// fieldKey is a field of a GraphQL type
+type fieldKey struct {
+	typeName string
+	field    string
+}
+
+// schemaGaps are the fields that are known to be missing from the schema, so they are removed from every query
+type schemaGaps struct {
+	fields map[fieldKey]bool
+	// selectionTypes are the types of the selection sets (by their path in the query) that had missing fields
+	selectionTypes map[string]string
+}
+
+// schemaFallback retries the GraphQL queries without the fields the server does not know.
+// Older GitHub Enterprise Server versions lack newer fields, and a single unknown field fails the whole query.
+type schemaFallback struct {
+	base http.RoundTripper
+	gaps schemaGaps
+	lock sync.RWMutex
+}
+
+// NewSchemaFallback removes the fields that are missing from the GraphQL schema of the server from the queries.
+// The fields are removed only from the selections of the type they are missing from, and are left unset in the results.
+func NewSchemaFallback(base http.RoundTripper) http.RoundTripper {
+	return &schemaFallback{
+		base: base,
+		gaps: schemaGaps{
+			fields:         make(map[fieldKey]bool),
+			selectionTypes: make(map[string]string),
+		},
+	}
+}
+
+func (t *schemaFallback) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body == nil {
+		return t.base.RoundTrip(request)
+	}
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var query graphQLRequest
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&query); err != nil || query.Query == "" {
+		return t.base.RoundTrip(withBody(request, body))
+	}
+
+	for {
+		t.lock.RLock()
+		query = removeFields(query, t.gaps)
+		t.lock.RUnlock()
+
+		body, err = json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(withBody(request, body))
+		if err != nil || resp.StatusCode != http.StatusOK {
+			return resp, err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+		if !t.learnUnavailableFields(respBody) {
+			return resp, nil
+		}
+	}
+}
+
+// learnUnavailableFields records the unknown fields the response complains about, and returns whether there were new ones
+func (t *schemaFallback) learnUnavailableFields(respBody []byte) bool {
+	var parsed graphQLErrors
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	learned := false
+	for _, queryError := range parsed.Errors {
+		field, typeName := queryError.Extensions.FieldName, queryError.Extensions.TypeName
+		if queryError.Extensions.Code != undefinedFieldCode {
+			match := undefinedFieldMessage.FindStringSubmatch(queryError.Message)
+			if match == nil {
+				continue
+			}
+			field, typeName = match[1], match[2]
+		}
+		// the path is the operation, the path of the selection set and the field itself
+		if field == "" || typeName == "" || len(queryError.Path) < 2 {
+			continue
+		}
+		selection := selectionPath(queryError.Path[1 : len(queryError.Path)-1])
+		key := fieldKey{typeName: typeName, field: field}
+		if t.gaps.fields[key] && t.gaps.selectionTypes[selection] == typeName {
+			continue
+		}
+		if !t.gaps.fields[key] {
+			log.Printf("graphql field %s of %s is not available on this server, collecting without it", field, typeName)
+		}
+		t.gaps.fields[key] = true
+		t.gaps.selectionTypes[selection] = typeName
+		learned = true
+	}
+
+	return learned
+}
+
+func selectionPath(elements []interface{}) string {
+	path := make([]string, len(elements))
+	for i, element := range elements {
+		path[i] = fmt.Sprint(element)
+	}
+	return strings.Join(path, "/")
+}
+
+func withBody(request *http.Request, body []byte) *http.Request {
+	clone := request.Clone(request.Context())
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+	clone.ContentLength = int64(len(body))
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return clone
+}
+
+var variableDeclaration = regexp.MustCompile(`\$(\w+)\s*:\s*[^$)]+`)
+
+// removeFields removes the missing fields from the query, along with the selections they leave empty and the variables they leave unused
+func removeFields(query graphQLRequest, gaps schemaGaps) graphQLRequest {
+	if len(gaps.fields) == 0 {
+		return query
+	}
+	start := strings.Index(query.Query, "{")
+	if start < 0 {
+		return query
+	}
+
+	p := &selectionParser{query: query.Query, pos: start, gaps: gaps}
+	selection, _ := p.selectionSet(nil, gaps.selectionTypes[""])
+	if p.failed {
+		return query
+	}
+	operation := query.Query[:start]
+
+	argumentsStart, argumentsEnd := strings.Index(operation, "("), strings.LastIndex(operation, ")")
+	if argumentsStart < 0 || argumentsEnd < argumentsStart {
+		return graphQLRequest{Query: operation + selection, Variables: query.Variables}
+	}
+	variables := make(map[string]interface{})
+	declarations := ""
+	for _, declaration := range variableDeclaration.FindAllStringSubmatch(operation[argumentsStart+1:argumentsEnd], -1) {
+		if !regexp.MustCompile(`\$` + declaration[1] + `\b`).MatchString(selection) {
+			continue
+		}
+		declarations += declaration[0]
+		if value, ok := query.Variables[declaration[1]]; ok {
+			variables[declaration[1]] = value
+		}
+	}
+	if declarations == "" {
+		return graphQLRequest{Query: operation[:argumentsStart] + selection}
+	}
+
+	return graphQLRequest{
+		Query:     operation[:argumentsStart] + "(" + declarations + ")" + selection,
+		Variables: variables,
+	}
+}
+
+// selectionParser parses the selection sets of a query, dropping the removed fields as it goes
+type selectionParser struct {
+	query  string
+	pos    int
+	gaps   schemaGaps
+	failed bool
+}
+
+// selectionSet returns the selection set at the current position without the removed fields, and whether it is left empty.
+// The path of the selection set and its type (when known) determine which fields are removed from it.
+func (p *selectionParser) selectionSet(path []string, typeName string) (string, bool) {
+	p.pos++ // {
+	var selections []string
+	for !p.failed {
+		p.skipSeparators()
+		if p.pos >= len(p.query) {
+			p.failed = true
+			break
+		}
+		if p.query[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		if selection, keep := p.selection(path, typeName); keep {
+			selections = append(selections, selection)
+		}
+	}
+
+	return "{" + strings.Join(selections, ",") + "}", len(selections) == 0
+}
+
+// selection returns a single field (or inline fragment) of a selection set and whether it should be kept
+func (p *selectionParser) selection(path []string, typeName string) (string, bool) {
+	start := p.pos
+	removed := false
+	var element, selectionType string
+	if strings.HasPrefix(p.query[p.pos:], "...") {
+		p.pos += len("...")
+		p.skipSpaces()
+		element = "..."
+		selectionType = typeName
+		if strings.HasPrefix(p.query[p.pos:], "on") {
+			p.pos += len("on")
+			p.skipSpaces()
+			selectionType = p.name()
+			element = "... on " + selectionType
+		}
+	} else {
+		name := p.name()
+		element = name
+		p.skipSpaces()
+		if p.pos < len(p.query) && p.query[p.pos] == ':' {
+			// an alias, which is the field in the path
+			p.pos++
+			p.skipSpaces()
+			name = p.name()
+		}
+		if name == "" {
+			p.failed = true
+			return "", false
+		}
+		removed = p.gaps.fields[fieldKey{typeName: typeName, field: name}]
+		p.skipSpaces()
+		if p.pos < len(p.query) && p.query[p.pos] == '(' {
+			p.arguments()
+		}
+	}
+	head := strings.TrimSpace(p.query[start:p.pos])
+
+	p.skipSpaces()
+	if p.pos < len(p.query) && p.query[p.pos] == '{' {
+		selectionPath := append(append([]string{}, path...), element)
+		if selectionType == "" {
+			selectionType = p.gaps.selectionTypes[strings.Join(selectionPath, "/")]
+		}
+		selection, empty := p.selectionSet(selectionPath, selectionType)
+		return head + selection, !removed && !empty
+	}
+	return head, !removed
+}
+
+func (p *selectionParser) name() string {
+	start := p.pos
+	for p.pos < len(p.query) && (p.query[p.pos] == '_' || isAlphanumeric(p.query[p.pos])) {
+		p.pos++
+	}
+	return p.query[start:p.pos]
+}
+
+// arguments skips the arguments of a field (which may contain nested objects and strings)
+func (p *selectionParser) arguments() {
+	depth := 0
+	inString := false
+	for ; p.pos < len(p.query); p.pos++ {
+		switch c := p.query[p.pos]; {
+		case inString && c == '\\':
+			p.pos++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return
+			}
+		}
+	}
+	p.failed = true
+}
+
+func (p *selectionParser) skipSpaces() {
+	for p.pos < len(p.query) && strings.ContainsRune(" \t\r\n", rune(p.query[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *selectionParser) skipSeparators() {
+	for p.pos < len(p.query) && strings.ContainsRune(" \t\r\n,", rune(p.query[p.pos])) {
+		p.pos++
+	}
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
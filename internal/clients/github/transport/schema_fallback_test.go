@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFallback(t *testing.T) {
+	var queries []string
+	// a server that does not know the webCommitSignoffRequired and allowUpdateBranch fields of repositories (like older GHES versions)
+	server := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		var query graphQLRequest
+		require.Nil(t, json.NewDecoder(request.Body).Decode(&query))
+		queries = append(queries, query.Query)
+
+		body := `{"data":{"repository":{"name":"repo","isPrivate":true,"defaultBranchRef":{"name":"main"}}}}`
+		if strings.Contains(query.Query, "webCommitSignoffRequired") {
+			body = `{"errors":[
+				{"message":"Field 'webCommitSignoffRequired' doesn't exist on type 'Repository'","path":["query","repository","webCommitSignoffRequired"],"extensions":{"code":"undefinedField","typeName":"Repository","fieldName":"webCommitSignoffRequired"}},
+				{"message":"Field 'allowUpdateBranch' doesn't exist on type 'Repository'","path":["query","repository","allowUpdateBranch"]}
+			]}`
+		} else {
+			require.NotContains(t, query.Query, "allowUpdateBranch")
+			// the fields of the other types are kept
+			require.Contains(t, query.Query, "branchProtectionRule")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+	})
+	client := githubv4.NewClient(&http.Client{Transport: NewSchemaFallback(server)})
+
+	var query struct {
+		Repository githubcollected.GitHubQLRepository `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	variables := map[string]interface{}{
+		"owner": githubv4.String("org"),
+		"name":  githubv4.String("repo"),
+	}
+
+	require.Nil(t, client.Query(context.Background(), &query, variables))
+	require.Equal(t, "repo", query.Repository.Name)
+	require.True(t, query.Repository.IsPrivate)
+	require.Equal(t, "main", *query.Repository.DefaultBranchRef.Name)
+	require.Nil(t, query.Repository.WebCommitSignoffRequired)
+	require.Nil(t, query.Repository.AllowUpdateBranch)
+	require.Len(t, queries, 2)
+
+	// the unavailable fields are removed from the following queries without another attempt
+	require.Nil(t, client.Query(context.Background(), &query, variables))
+	require.Len(t, queries, 3)
+}
+
+func TestSchemaFallbackWithoutPath(t *testing.T) {
+	attempts := 0
+	server := roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		attempts++
+		body := `{"errors":[{"message":"Field 'webCommitSignoffRequired' doesn't exist on type 'Repository'"}]}`
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+	})
+	client := githubv4.NewClient(&http.Client{Transport: NewSchemaFallback(server)})
+
+	var query struct {
+		Repository struct {
+			WebCommitSignoffRequired *bool
+		} `graphql:"repository(owner: \"org\", name: \"repo\")"`
+	}
+	// the field cannot be located in the query, so the error is returned instead of retrying
+	require.NotNil(t, client.Query(context.Background(), &query, nil))
+	require.Equal(t, 1, attempts)
+}
+
+func TestRemoveFields(t *testing.T) {
+	query := graphQLRequest{
+		Query:     `query($login:String!$cursor:String){organization(login: $login){name,samlIdentityProvider{externalIdentities(first: 100, after: $cursor){totalCount}},repos: repositories(first: 10){nodes{name,url}},target{... on Commit{oid,name}}}}`,
+		Variables: map[string]interface{}{"login": "org", "cursor": nil},
+	}
+
+	gaps := schemaGaps{
+		fields: map[fieldKey]bool{
+			{typeName: "SamlIdentityProvider", field: "externalIdentities"}: true,
+			{typeName: "Repository", field: "name"}:                         true,
+			{typeName: "Commit", field: "name"}:                             true,
+		},
+		selectionTypes: map[string]string{
+			"organization/samlIdentityProvider": "SamlIdentityProvider",
+			"organization/repos/nodes":          "Repository",
+		},
+	}
+	removed := removeFields(query, gaps)
+	require.Equal(t, `query($login:String!){organization(login: $login){name,repos: repositories(first: 10){nodes{url}},target{... on Commit{oid}}}}`, removed.Query)
+	require.Equal(t, map[string]interface{}{"login": "org"}, removed.Variables)
+
+	require.Equal(t, query, removeFields(query, schemaGaps{}))
+}
@@ -17,8 +17,9 @@ const (
 	singleSleepLimit = 90 * time.Second
 )
 
-func NewRateLimitWaiter(ctx context.Context, base http.RoundTripper) (*http.Client, error) {
+func NewRateLimitWaiter(ctx context.Context, base http.RoundTripper, metrics *CallMetrics) (*http.Client, error) {
 	sleepCB := github_ratelimit.WithLimitDetectedCallback(func(ctx *github_ratelimit.CallbackContext) {
+		metrics.addSleep()
 		log.Printf("facing secondary rate limit with request: %v. sleeping until: %v", ctx.Request.URL, *ctx.SleepUntil)
 		progressbar.Report(progressbar.NewTimedBar("secondary rate limit", *ctx.SleepUntil))
 	})
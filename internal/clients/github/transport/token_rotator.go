@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+	rateLimitResourceHeader  = "X-RateLimit-Resource"
+	fromCacheHeader          = "X-From-Cache"
+
+	coreResource    = "core"
+	graphQLResource = "graphql"
+)
+
+// tokenState tracks the last known rate limit state of a single token for a single resource.
+// a token with no known state is assumed to have remaining quota.
+type tokenState struct {
+	known     bool
+	remaining int
+	reset     time.Time
+}
+
+func (s tokenState) hasQuota(now time.Time) bool {
+	return !s.known || s.remaining > 0 || now.After(s.reset)
+}
+
+// tokenRotator is a round tripper that spreads requests across multiple tokens.
+// tokens are picked in a round-robin manner, skipping tokens that are known to be out of quota.
+// when all tokens are exhausted, the token with the earliest reset time is used.
+type tokenRotator struct {
+	base   http.RoundTripper
+	tokens []string
+	lock   sync.Mutex
+	next   int
+	states map[string][]tokenState
+}
+
+func NewTokenRotator(base http.RoundTripper, tokens []string) (http.RoundTripper, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("missing token")
+	}
+	return &tokenRotator{
+		base:   base,
+		tokens: tokens,
+		states: make(map[string][]tokenState),
+	}, nil
+}
+
+func resourceOf(request *http.Request) string {
+	if strings.HasSuffix(request.URL.Path, "/graphql") {
+		return graphQLResource
+	}
+	return coreResource
+}
+
+func (t *tokenRotator) resourceStates(resource string) []tokenState {
+	states, ok := t.states[resource]
+	if !ok {
+		states = make([]tokenState, len(t.tokens))
+		t.states[resource] = states
+	}
+	return states
+}
+
+func (t *tokenRotator) pick(resource string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	states := t.resourceStates(resource)
+	now := time.Now()
+	earliest := -1
+	for i := 0; i < len(t.tokens); i++ {
+		idx := (t.next + i) % len(t.tokens)
+		if states[idx].hasQuota(now) {
+			t.next = (idx + 1) % len(t.tokens)
+			return idx
+		}
+		if earliest == -1 || states[idx].reset.Before(states[earliest].reset) {
+			earliest = idx
+		}
+	}
+
+	return earliest
+}
+
+func (t *tokenRotator) update(idx int, resource string, resp *http.Response) {
+	if _, cached := resp.Header[fromCacheHeader]; cached {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get(rateLimitRemainingHeader))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get(rateLimitResetHeader), 10, 64)
+	if err != nil {
+		return
+	}
+	if r := resp.Header.Get(rateLimitResourceHeader); r != "" {
+		resource = r
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.resourceStates(resource)[idx] = tokenState{
+		known:     true,
+		remaining: remaining,
+		reset:     time.Unix(reset, 0),
+	}
+}
+
+func (t *tokenRotator) RoundTrip(request *http.Request) (*http.Response, error) {
+	// allow callers to pin a specific token (e.g. when validating each token)
+	if request.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(request)
+	}
+
+	resource := resourceOf(request)
+	idx := t.pick(resource)
+
+	req2 := CloneRequest(*request)
+	req2.Header.Set("Authorization", "Bearer "+t.tokens[idx])
+
+	resp, err := t.base.RoundTrip(&req2)
+	if resp != nil {
+		t.update(idx, resource, resp)
+	}
+	return resp, err
+}
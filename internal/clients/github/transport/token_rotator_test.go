@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rateLimitedServer answers as if each token had the given remaining quota and reset time
+type rateLimitedServer struct {
+	remaining map[string]int
+	reset     map[string]time.Time
+	used      []string
+}
+
+func (s *rateLimitedServer) RoundTrip(request *http.Request) (*http.Response, error) {
+	token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+	s.used = append(s.used, token)
+
+	status := http.StatusOK
+	if s.remaining[token] > 0 {
+		s.remaining[token]--
+	} else {
+		status = http.StatusForbidden
+	}
+
+	header := http.Header{}
+	header.Set(rateLimitRemainingHeader, strconv.Itoa(s.remaining[token]))
+	header.Set(rateLimitResetHeader, strconv.FormatInt(s.reset[token].Unix(), 10))
+	header.Set(rateLimitResourceHeader, resourceOf(request))
+	return &http.Response{StatusCode: status, Header: header}, nil
+}
+
+func sendRequests(t *testing.T, rotator http.RoundTripper, url string, count int) {
+	for i := 0; i < count; i++ {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		_, err = rotator.RoundTrip(request)
+		require.NoError(t, err)
+	}
+}
+
+func TestTokenRotatorRotatesOnRateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	server := &rateLimitedServer{
+		remaining: map[string]int{"first": 1, "second": 10, "third": 10},
+		reset:     map[string]time.Time{"first": reset, "second": reset, "third": reset},
+	}
+	rotator, err := NewTokenRotator(server, []string{"first", "second", "third"})
+	require.NoError(t, err)
+
+	sendRequests(t, rotator, "https://api.github.com/repos/org/repo", 6)
+	require.Equal(t, []string{"first", "second", "third", "second", "third", "second"}, server.used,
+		"expecting the exhausted token to be skipped once its quota is known")
+
+	server.used = nil
+	sendRequests(t, rotator, "https://api.github.com/graphql", 3)
+	require.ElementsMatch(t, []string{"first", "second", "third"}, server.used,
+		"expecting the quota of each resource to be tracked separately")
+}
+
+func TestTokenRotatorAllTokensExhausted(t *testing.T) {
+	now := time.Now()
+	server := &rateLimitedServer{
+		remaining: map[string]int{"first": 0, "second": 0},
+		reset:     map[string]time.Time{"first": now.Add(time.Hour), "second": now.Add(time.Minute)},
+	}
+	rotator, err := NewTokenRotator(server, []string{"first", "second"})
+	require.NoError(t, err)
+
+	sendRequests(t, rotator, "https://api.github.com/repos/org/repo", 4)
+	require.Equal(t, []string{"first", "second", "second", "second"}, server.used,
+		"expecting the token with the earliest reset to be used when all the tokens are exhausted")
+}
+
+func TestTokenRotatorPinnedToken(t *testing.T) {
+	server := &rateLimitedServer{remaining: map[string]int{"pinned": 1}, reset: map[string]time.Time{}}
+	rotator, err := NewTokenRotator(server, []string{"first"})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer pinned")
+	_, err = rotator.RoundTrip(request)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pinned"}, server.used, "expecting the token of the caller to be kept")
+
+	_, err = NewTokenRotator(server, nil)
+	require.Error(t, err, "expecting an error without tokens")
+}
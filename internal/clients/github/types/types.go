@@ -10,11 +10,49 @@ type TokenPermissions struct {
 	CanApprovePullRequestReviews *bool   `json:"can_approve_pull_request_reviews,omitempty"`
 }
 
+// ForkPullRequestApproval is the policy of the approval required to run the workflows of fork pull requests:
+// first_time_contributors_new_to_github, first_time_contributors or all_external_contributors
+type ForkPullRequestApproval struct {
+	ApprovalPolicy *string `json:"approval_policy,omitempty"`
+}
+
+// Release is a published release of a repository. The assets carry the digest that identifies them in the attestations API,
+// which is not part of the go-github model.
+type Release struct {
+	TagName     *string           `json:"tag_name,omitempty"`
+	HTMLURL     *string           `json:"html_url,omitempty"`
+	PublishedAt *github.Timestamp `json:"published_at,omitempty"`
+	Assets      []*ReleaseAsset   `json:"assets,omitempty"`
+}
+
+type ReleaseAsset struct {
+	Name   *string `json:"name,omitempty"`
+	Digest *string `json:"digest,omitempty"`
+}
+
+// Attestations are the artifact attestations (e.g. SLSA build provenance) of a subject digest
+type Attestations struct {
+	Attestations []json.RawMessage `json:"attestations"`
+}
+
+type ArtifactAndLogRetention struct {
+	Days               *int `json:"days,omitempty"`
+	MaximumAllowedDays *int `json:"maximum_allowed_days,omitempty"`
+}
+
+// AutomatedSecurityFixes is the status of the Dependabot security updates of a repository
+type AutomatedSecurityFixes struct {
+	Enabled bool `json:"enabled"`
+	Paused  bool `json:"paused"`
+}
+
 type RepositoryRule struct {
 	Type       string           `json:"type"`
 	Parameters *json.RawMessage `json:"parameters,omitempty"`
 	Id         int64            `json:"ruleset_id"`
 	Ruleset    *github.Ruleset  `json:"ruleset"`
+	// Enforcement of the ruleset the rule belongs to: disabled, active or evaluate (dry-run, does not block)
+	Enforcement string `json:"enforcement,omitempty"`
 }
 
 type AnalysisAndSecurityPolicies struct {
@@ -24,3 +62,27 @@ type AnalysisAndSecurityPolicies struct {
 	SecretScanningPushProtectionEnabledForNewRepos bool   `json:"secret_scanning_push_protection_enabled_for_new_repositories"`
 	SecretScanningPushProtectionCustomLink         string `json:"secret_scanning_push_protection_custom_link"`
 }
+
+// SecurityAndAnalysis extends the repository security and analysis settings with the secret scanning sub-features
+// that are not part of the go-github model
+type SecurityAndAnalysis struct {
+	github.SecurityAndAnalysis
+	SecretScanningValidityChecks      *SecurityAndAnalysisStatus `json:"secret_scanning_validity_checks,omitempty"`
+	SecretScanningNonProviderPatterns *SecurityAndAnalysisStatus `json:"secret_scanning_non_provider_patterns,omitempty"`
+	SecretScanningAIDetection         *SecurityAndAnalysisStatus `json:"secret_scanning_ai_detection,omitempty"`
+}
+
+type SecurityAndAnalysisStatus struct {
+	Status *string `json:"status,omitempty"`
+}
+
+// CredentialAuthorization is a credential (e.g. a classic personal access token) authorized to access a SAML SSO organization
+type CredentialAuthorization struct {
+	Login                         *string           `json:"login,omitempty"`
+	CredentialID                  *int64            `json:"credential_id,omitempty"`
+	CredentialType                *string           `json:"credential_type,omitempty"`
+	Scopes                        []string          `json:"scopes,omitempty"`
+	AuthorizedCredentialTitle     *string           `json:"authorized_credential_title,omitempty"`
+	AuthorizedCredentialExpiresAt *github.Timestamp `json:"authorized_credential_expires_at,omitempty"`
+	CredentialAccessedAt          *github.Timestamp `json:"credential_accessed_at,omitempty"`
+}
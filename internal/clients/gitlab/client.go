@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab/pagination"
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab/transport"
+	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	"github.com/Legit-Labs/legitify/internal/common/slice_utils"
 	"github.com/Legit-Labs/legitify/internal/common/types"
@@ -218,3 +220,208 @@ func (c *Client) IsGroupPremium(group string) bool {
 
 	return plan != "free"
 }
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// GraphQL executes a query against the GraphQL API of the instance, which exposes some fields the REST API lacks.
+// The response "data" field is unmarshalled into result.
+func (c *Client) GraphQL(query string, variables map[string]interface{}, result interface{}) error {
+	req, err := c.Client().NewRequest(http.MethodPost, "", graphQLRequest{Query: query, Variables: variables}, nil)
+	if err != nil {
+		return err
+	}
+	req.URL.Path = strings.TrimSuffix(req.URL.Path, "/v4/") + "/graphql"
+	req.URL.RawPath = ""
+
+	var resp struct {
+		Data   interface{}    `json:"data"`
+		Errors []graphQLError `json:"errors"`
+	}
+	resp.Data = result
+	if _, err := c.Client().Do(req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("graphql error: %s", resp.Errors[0].Message)
+	}
+
+	return nil
+}
+
+const projectComplianceFrameworksQuery = `query($fullPath: ID!) {
+  project(fullPath: $fullPath) {
+    complianceFrameworks {
+      nodes {
+        id
+        name
+        description
+        default
+        pipelineConfigurationFullPath
+      }
+    }
+  }
+}`
+
+// ProjectComplianceFrameworks returns the compliance frameworks assigned to the project.
+// A nil project in the response means the project is not visible to the token.
+func (c *Client) ProjectComplianceFrameworks(fullPath string) ([]gitlab_collected.ComplianceFramework, bool, error) {
+	var result struct {
+		Project *struct {
+			ComplianceFrameworks struct {
+				Nodes []struct {
+					ID                            string `json:"id"`
+					Name                          string `json:"name"`
+					Description                   string `json:"description"`
+					Default                       bool   `json:"default"`
+					PipelineConfigurationFullPath string `json:"pipelineConfigurationFullPath"`
+				} `json:"nodes"`
+			} `json:"complianceFrameworks"`
+		} `json:"project"`
+	}
+
+	variables := map[string]interface{}{
+		"fullPath": fullPath,
+	}
+	if err := c.GraphQL(projectComplianceFrameworksQuery, variables, &result); err != nil {
+		return nil, false, err
+	}
+	if result.Project == nil {
+		return nil, false, nil
+	}
+
+	frameworks := make([]gitlab_collected.ComplianceFramework, 0, len(result.Project.ComplianceFrameworks.Nodes))
+	for _, node := range result.Project.ComplianceFrameworks.Nodes {
+		frameworks = append(frameworks, gitlab_collected.ComplianceFramework{
+			ID:                            node.ID,
+			Name:                          node.Name,
+			Description:                   node.Description,
+			Default:                       node.Default,
+			PipelineConfigurationFullPath: node.PipelineConfigurationFullPath,
+		})
+	}
+
+	return frameworks, true, nil
+}
+
+// ProjectIntegrationProperties returns the settings of an integration (service) of the project, e.g. the URL of a Jira server.
+// Secret properties (e.g. tokens) are not returned by the API.
+func (c *Client) ProjectIntegrationProperties(pid int, slug string) (map[string]interface{}, *gitlab.Response, error) {
+	return c.integrationProperties(fmt.Sprintf("projects/%d/integrations/%s", pid, gitlab.PathEscape(slug)))
+}
+
+// GroupIntegrations returns the active integrations of the group, which are inherited by its projects
+func (c *Client) GroupIntegrations(gid int) ([]*gitlab.Service, *gitlab.Response, error) {
+	req, err := c.Client().NewRequest(http.MethodGet, fmt.Sprintf("groups/%d/integrations", gid), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var integrations []*gitlab.Service
+	resp, err := c.Client().Do(req, &integrations)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return integrations, resp, nil
+}
+
+// GroupIntegrationProperties returns the settings of an integration of the group (see ProjectIntegrationProperties)
+func (c *Client) GroupIntegrationProperties(gid int, slug string) (map[string]interface{}, *gitlab.Response, error) {
+	return c.integrationProperties(fmt.Sprintf("groups/%d/integrations/%s", gid, gitlab.PathEscape(slug)))
+}
+
+func (c *Client) integrationProperties(u string) (map[string]interface{}, *gitlab.Response, error) {
+	req, err := c.Client().NewRequest(http.MethodGet, u, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var integration struct {
+		Properties map[string]interface{} `json:"properties"`
+	}
+	resp, err := c.Client().Do(req, &integration)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return integration.Properties, resp, nil
+}
+
+// ProjectJobTokenScope returns whether the inbound and outbound job token scopes of the project are enabled
+func (c *Client) ProjectJobTokenScope(pid int) (*gitlab_collected.JobTokenScope, *gitlab.Response, error) {
+	u := fmt.Sprintf("projects/%d/job_token_scope", pid)
+	req, err := c.Client().NewRequest(http.MethodGet, u, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scope := new(gitlab_collected.JobTokenScope)
+	resp, err := c.Client().Do(req, scope)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return scope, resp, nil
+}
+
+const projectJobTokenAllowlistsQuery = `query($fullPath: ID!) {
+  project(fullPath: $fullPath) {
+    ciJobTokenScope {
+      inboundAllowlist {
+        nodes {
+          fullPath
+        }
+      }
+      outboundAllowlist {
+        nodes {
+          fullPath
+        }
+      }
+    }
+  }
+}`
+
+// ProjectJobTokenAllowlists returns the full paths of the projects in the inbound and outbound job token allowlists of the project.
+// The allowlists are not available in the REST API.
+func (c *Client) ProjectJobTokenAllowlists(fullPath string) (inbound []string, outbound []string, err error) {
+	type allowlist struct {
+		Nodes []struct {
+			FullPath string `json:"fullPath"`
+		} `json:"nodes"`
+	}
+	var result struct {
+		Project *struct {
+			CiJobTokenScope *struct {
+				InboundAllowlist  allowlist `json:"inboundAllowlist"`
+				OutboundAllowlist allowlist `json:"outboundAllowlist"`
+			} `json:"ciJobTokenScope"`
+		} `json:"project"`
+	}
+
+	variables := map[string]interface{}{
+		"fullPath": fullPath,
+	}
+	if err := c.GraphQL(projectJobTokenAllowlistsQuery, variables, &result); err != nil {
+		return nil, nil, err
+	}
+	if result.Project == nil || result.Project.CiJobTokenScope == nil {
+		return nil, nil, fmt.Errorf("the job token scope of %s is not visible", fullPath)
+	}
+
+	paths := func(list allowlist) []string {
+		result := make([]string, 0, len(list.Nodes))
+		for _, node := range list.Nodes {
+			result = append(result, node.FullPath)
+		}
+		return result
+	}
+
+	return paths(result.Project.CiJobTokenScope.InboundAllowlist), paths(result.Project.CiJobTokenScope.OutboundAllowlist), nil
+}
@@ -1,8 +1,32 @@
 package collected
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Entity interface {
 	ViolationEntityType() string
 	CanonicalLink() string
 	Name() string
 	ID() int64
+	// EntityID identifies the entity for joining results across policies and providers (see NewEntityID)
+	EntityID() string
+}
+
+// PublicEntity is implemented by entities that may be publicly visible (e.g. repositories)
+type PublicEntity interface {
+	IsPublic() bool
+}
+
+// OwnedEntity is implemented by entities that have owners responsible for their remediation (e.g. the teams
+// that administer a repository)
+type OwnedEntity interface {
+	Owners() []string
+}
+
+// NewEntityID returns a normalized identifier of an entity from its numeric ID (e.g. "github:repository:1296269"),
+// which is stable across policies and does not depend on the format of the canonical link.
+func NewEntityID(scmType string, entityType string, id string) string {
+	return fmt.Sprintf("%s:%s:%s", scmType, strings.ReplaceAll(entityType, " ", "_"), id)
 }
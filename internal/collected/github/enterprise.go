@@ -1,9 +1,13 @@
 package githubcollected
 
 import (
+	"strconv"
+
 	"github.com/Legit-Labs/legitify/internal/clients/github/types"
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 )
 
 type Enterprise struct {
@@ -62,3 +66,7 @@ func (o Enterprise) Name() string {
 func (o Enterprise) ID() int64 {
 	return o.Id
 }
+
+func (o Enterprise) EntityID() string {
+	return collected.NewEntityID(scm_type.GitHub, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
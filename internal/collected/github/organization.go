@@ -1,8 +1,12 @@
 package githubcollected
 
 import (
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 
 	"github.com/google/go-github/v53/github"
 )
@@ -46,6 +50,106 @@ type Organization struct {
 	Hooks        []*github.Hook `json:"hooks"`
 	UserRole     permissions.OrganizationRole
 	OrgSecrets   []*OrganizationSecret `json:"organization_secrets,omitempty"`
+
+	SSHCertificateAuthorities []*SSHCertificateAuthority `json:"ssh_certificate_authorities"`
+	MemberPrivileges          *MemberPrivileges          `json:"member_privileges,omitempty"`
+	RequiredWorkflows         []*RequiredWorkflow        `json:"required_workflows"`
+	ClassicTokens             []*ClassicToken            `json:"classic_tokens"`
+	Invitations               []*OrganizationInvitation  `json:"invitations"`
+	// Rulesets are the organization-level rulesets, which apply to the repositories matching their conditions
+	Rulesets []*github.Ruleset `json:"rulesets"`
+	// Packages are the packages owned by the organization (nil when they could not be listed)
+	Packages []*OrganizationPackage `json:"packages"`
+	// MembersWithoutSSO are the logins of the members that did not link their SSO identity
+	// (SAML SSO organizations only, nil when they could not be listed)
+	MembersWithoutSSO []string `json:"members_without_sso"`
+	// OutsideCollaboratorsCount is the number of users that are not members but collaborate on repositories of the organization.
+	// Whether members may invite them is not exposed for organizations (see the enterprise external_collaborators_invite_policy).
+	OutsideCollaboratorsCount *int `json:"outside_collaborators_count"`
+	// Runners are the self-hosted runners registered to the organization, and RunnerGroups the groups that control
+	// which repositories may use them (visible to organization owners, nil when they could not be listed)
+	Runners      []*OrganizationRunner      `json:"runners"`
+	RunnerGroups []*OrganizationRunnerGroup `json:"runner_groups"`
+}
+
+// OrganizationRunner is a self-hosted runner registered to the organization
+type OrganizationRunner struct {
+	Name   string   `json:"name"`
+	OS     string   `json:"os"`
+	Status string   `json:"status"`
+	Labels []string `json:"labels"`
+	// RunnerGroup is the name of the runner group the runner belongs to
+	RunnerGroup string `json:"runner_group,omitempty"`
+}
+
+// OrganizationRunnerGroup is a group of self-hosted runners of the organization
+type OrganizationRunnerGroup struct {
+	Name string `json:"name"`
+	// Visibility is all or selected (the repositories that may use the runners of the group)
+	Visibility               string `json:"visibility"`
+	Default                  bool   `json:"default"`
+	AllowsPublicRepositories bool   `json:"allows_public_repositories"`
+	RestrictedToWorkflows    bool   `json:"restricted_to_workflows"`
+	// Runners are the names of the runners in the group
+	Runners []string `json:"runners"`
+}
+
+// OrganizationPackage is a package published to GitHub Packages by the organization
+type OrganizationPackage struct {
+	Name        string `json:"name"`
+	PackageType string `json:"package_type"`
+	// Visibility is public, internal or private
+	Visibility string `json:"visibility"`
+	// Repository is the repository the package is linked to (if any), and RepositoryVisibility is its visibility
+	Repository           string `json:"repository,omitempty"`
+	RepositoryVisibility string `json:"repository_visibility,omitempty"`
+	Link                 string `json:"link"`
+}
+
+// OrganizationInvitation is a pending invitation to join the organization (visible to organization owners)
+type OrganizationInvitation struct {
+	// Invitee is the login of the invited user, or the email address the invitation was sent to
+	Invitee string `json:"invitee"`
+	Inviter string `json:"inviter"`
+	Role    string `json:"role"`
+	// CreatedAt is not set when GitHub does not report the creation time (so the invitation is never considered stale)
+	CreatedAt *int `json:"created_at,omitempty"`
+}
+
+// ClassicToken is a classic personal access token that is authorized to access the organization (SAML SSO organizations only).
+// GitHub does not expose the personal access token policy of the organization, so it is inferred from the authorized tokens.
+type ClassicToken struct {
+	Login     string   `json:"login"`
+	Title     string   `json:"title"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+// RequiredWorkflow is a workflow the organization requires to run on its repositories (enterprise only)
+type RequiredWorkflow struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Repository string `json:"repository"`
+	Ref        string `json:"ref,omitempty"`
+	State      string `json:"state"`
+	Scope      string `json:"scope"`
+	// SelectedRepositories lists the repositories the workflow applies to when the scope is 'selected'
+	SelectedRepositories []string `json:"selected_repositories,omitempty"`
+}
+
+// MemberPrivileges holds the abuse related settings of the organization, which are only visible to organization owners
+type MemberPrivileges struct {
+	BlockedUsersCount                  int   `json:"blocked_users_count"`
+	MembersCanCreatePublicRepositories *bool `json:"members_can_create_public_repositories,omitempty"`
+	MembersCanForkPrivateRepositories  *bool `json:"members_can_fork_private_repositories,omitempty"`
+	MembersCanCreatePublicPages        *bool `json:"members_can_create_public_pages,omitempty"`
+}
+
+type SSHCertificateAuthority struct {
+	ID          int64  `json:"id"`
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+	CreatedAt   string `json:"created_at"`
 }
 
 type OrganizationSecret struct {
@@ -68,3 +172,7 @@ func (o Organization) Name() string {
 func (o Organization) ID() int64 {
 	return *o.Organization.ID
 }
+
+func (o Organization) EntityID() string {
+	return collected.NewEntityID(scm_type.GitHub, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
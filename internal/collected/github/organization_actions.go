@@ -2,7 +2,11 @@ package githubcollected
 
 import (
 	"fmt"
+	"strconv"
+
 	"github.com/Legit-Labs/legitify/internal/clients/github/types"
+	"github.com/Legit-Labs/legitify/internal/collected"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 
 	"github.com/google/go-github/v53/github"
 )
@@ -11,6 +15,8 @@ type OrganizationActions struct {
 	Organization       ExtendedOrg                `json:"organization"`
 	ActionsPermissions *github.ActionsPermissions `json:"actions_permissions"`
 	TokenPermissions   *types.TokenPermissions    `json:"token_permissions"`
+	// ForkPullRequestApproval is nil when the setting is not available (e.g. older GitHub Enterprise Server versions)
+	ForkPullRequestApproval *types.ForkPullRequestApproval `json:"fork_pr_approval,omitempty"`
 }
 
 func (o OrganizationActions) ViolationEntityType() string {
@@ -29,3 +35,7 @@ func (o OrganizationActions) Name() string {
 func (o OrganizationActions) ID() int64 {
 	return *o.Organization.ID
 }
+
+func (o OrganizationActions) EntityID() string {
+	return collected.NewEntityID(scm_type.GitHub, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
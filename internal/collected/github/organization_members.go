@@ -2,6 +2,10 @@ package githubcollected
 
 import (
 	"fmt"
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 
 	"github.com/google/go-github/v53/github"
 )
@@ -43,3 +47,7 @@ func (o OrganizationMembers) ID() int64 {
 	// Deliberately using the Org; see membersList enricher
 	return *o.Organization.ID
 }
+
+func (o OrganizationMembers) EntityID() string {
+	return collected.NewEntityID(scm_type.GitHub, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
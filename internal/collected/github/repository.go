@@ -1,8 +1,15 @@
 package githubcollected
 
 import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
 	"github.com/Legit-Labs/legitify/internal/clients/github/types"
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/Legit-Labs/legitify/internal/scorecard"
 	"github.com/google/go-github/v53/github"
 	"github.com/shurcooL/githubv4"
@@ -15,8 +22,58 @@ type GitHubQLPageInfo struct {
 	StartCursor     *githubv4.String
 }
 
+type GitHubQLDependencyGraphManifest struct {
+	Filename          string `json:"filename"`
+	BlobPath          string `json:"blob_path"`
+	DependenciesCount int    `json:"dependencies_count"`
+	Parseable         bool   `json:"parseable"`
+}
+
 type GitHubQLDependencyGraphManifests struct {
-	TotalCount int `json:"total_count"`
+	TotalCount int                               `json:"total_count"`
+	Nodes      []GitHubQLDependencyGraphManifest `json:"nodes"`
+}
+
+// DependencyEcosystem summarizes the dependency manifests of a single ecosystem (e.g. npm, go)
+type DependencyEcosystem struct {
+	Ecosystem         string   `json:"ecosystem"`
+	Manifests         []string `json:"manifests"`
+	Lockfiles         []string `json:"lockfiles"`
+	LockfileSupported bool     `json:"lockfile_supported"`
+	HasLockfile       bool     `json:"has_lockfile"`
+}
+
+// DependencyManagementFiles reports the presence of the dependency management files in the default branch
+type DependencyManagementFiles struct {
+	HasDependabotConfig bool `json:"has_dependabot_config"`
+	HasRenovateConfig   bool `json:"has_renovate_config"`
+	HasFundingFile      bool `json:"has_funding_file"`
+}
+
+// Codeowners is the CODEOWNERS file of the default branch
+type Codeowners struct {
+	Exists bool `json:"exists"`
+	// Path is the path of the file that GitHub uses (the first of .github/, the root and docs/)
+	Path    string `json:"path,omitempty"`
+	Content string `json:"content"`
+}
+
+// LatestRelease summarizes the supply-chain integrity of the latest published release
+type LatestRelease struct {
+	TagName     string `json:"tag_name"`
+	Link        string `json:"link"`
+	PublishedAt string `json:"published_at,omitempty"`
+	AssetsCount int    `json:"assets_count"`
+	// SignatureAssets are the assets that sign other assets (e.g. .sig, .asc, sigstore bundles)
+	SignatureAssets []string `json:"signature_assets"`
+	// ProvenanceAssets are the in-toto provenance assets (e.g. generated by the SLSA generators)
+	ProvenanceAssets []string `json:"provenance_assets"`
+	// HasAttestations reports whether the assets have artifact attestations (e.g. actions/attest-build-provenance)
+	HasAttestations bool `json:"has_attestations"`
+}
+
+type GitHubQLLanguage struct {
+	Name string `json:"name"`
 }
 
 type GitHubQLRepositoryCollaboratorsEdge struct {
@@ -27,33 +84,49 @@ type GitHubQLRepositoryCollaborators struct {
 	Edges []GitHubQLRepositoryCollaboratorsEdge `json:"edges" graphql:"edges"`
 }
 
+// GitHubQLRepository is the repository as queried from the GraphQL API.
+// The pointer fields that are missing from older GitHub Enterprise Server versions are nil there.
 type GitHubQLRepository struct {
-	Name               string `json:"name"`
-	RebaseMergeAllowed bool
-	Url                string
-	DatabaseId         int64
-	IsPrivate          bool               `json:"is_private"`
-	ForkingAllowed     bool               `json:"allow_forking"`
-	IsArchived         bool               `json:"is_archived"`
-	DefaultBranchRef   *GitHubQLBranch    `json:"default_branch"`
-	PushedAt           *githubv4.DateTime `json:"pushed_at"`
-	ViewerPermission   string             `json:"viewerPermission"`
+	Name                     string `json:"name"`
+	RebaseMergeAllowed       bool
+	Url                      string
+	DatabaseId               int64
+	IsPrivate                bool               `json:"is_private"`
+	ForkingAllowed           bool               `json:"allow_forking"`
+	IsArchived               bool               `json:"is_archived"`
+	IsLocked                 bool               `json:"is_locked"`
+	IsDisabled               bool               `json:"is_disabled"`
+	LockReason               *string            `json:"lock_reason"`
+	IsTemplate               bool               `json:"is_template"`
+	IsInOrganization         bool               `json:"is_in_organization"`
+	WebCommitSignoffRequired *bool              `json:"web_commit_signoff_required"`
+	SquashMergeCommitTitle   *string            `json:"squash_merge_commit_title"`
+	SquashMergeCommitMessage *string            `json:"squash_merge_commit_message"`
+	MergeCommitTitle         *string            `json:"merge_commit_title"`
+	MergeCommitMessage       *string            `json:"merge_commit_message"`
+	AllowUpdateBranch        *bool              `json:"allow_update_branch"`
+	DefaultBranchRef         *GitHubQLBranch    `json:"default_branch"`
+	PushedAt                 *githubv4.DateTime `json:"pushed_at"`
+	ViewerPermission         string             `json:"viewerPermission"`
+	PrimaryLanguage          *GitHubQLLanguage  `json:"primary_language"`
 }
 
 type GitHubQLBranchProtectionRule struct {
-	AllowsDeletions                *bool `json:"allows_deletions,omitempty"`
-	AllowsForcePushes              *bool `json:"allows_force_pushes,omitempty"`
-	DismissesStaleReviews          *bool `json:"dismisses_stale_reviews,omitempty"`
-	IsAdminEnforced                *bool `json:"is_admin_enforced,omitempty"`
-	RequiredApprovingReviewCount   *int  `json:"required_approving_review_count,omitempty"`
-	RequiresStatusChecks           *bool `json:"requires_status_checks,omitempty"`
-	RequiresStrictStatusChecks     *bool `json:"requires_strict_status_checks,omitempty"`
-	RestrictsPushes                *bool `json:"restricts_pushes,omitempty"`
-	RequiresCodeOwnerReviews       *bool `json:"requires_code_owner_reviews,omitempty"`
-	RequiresLinearHistory          *bool `json:"requires_linear_history,omitempty"`
-	RequiresConversationResolution *bool `json:"requires_conversation_resolution,omitempty"`
-	RequiresCommitSignatures       *bool `json:"requires_commit_signatures,omitempty"`
-	RestrictsReviewDismissals      *bool `json:"restricts_review_dismissals,omitempty"`
+	AllowsDeletions                *bool    `json:"allows_deletions,omitempty"`
+	AllowsForcePushes              *bool    `json:"allows_force_pushes,omitempty"`
+	DismissesStaleReviews          *bool    `json:"dismisses_stale_reviews,omitempty"`
+	IsAdminEnforced                *bool    `json:"is_admin_enforced,omitempty"`
+	RequiredApprovingReviewCount   *int     `json:"required_approving_review_count,omitempty"`
+	RequiresStatusChecks           *bool    `json:"requires_status_checks,omitempty"`
+	RequiresStrictStatusChecks     *bool    `json:"requires_strict_status_checks,omitempty"`
+	RestrictsPushes                *bool    `json:"restricts_pushes,omitempty"`
+	RequiresCodeOwnerReviews       *bool    `json:"requires_code_owner_reviews,omitempty"`
+	RequiresLinearHistory          *bool    `json:"requires_linear_history,omitempty"`
+	RequiresConversationResolution *bool    `json:"requires_conversation_resolution,omitempty"`
+	RequiresCommitSignatures       *bool    `json:"requires_commit_signatures,omitempty"`
+	RestrictsReviewDismissals      *bool    `json:"restricts_review_dismissals,omitempty"`
+	RequiresDeployments            *bool    `json:"requires_deployments,omitempty"`
+	RequiredDeploymentEnvironments []string `json:"required_deployment_environments,omitempty"`
 }
 
 type GitHubQLBranch struct {
@@ -61,18 +134,222 @@ type GitHubQLBranch struct {
 	BranchProtectionRule *GitHubQLBranchProtectionRule `json:"branch_protection_rule"`
 }
 
+type GitHubQLBranchTarget struct {
+	Commit GitHubQLCommit `graphql:"... on Commit"`
+}
+
+type GitHubQLCommit struct {
+	StatusCheckRollup *GitHubQLStatusCheckRollup
+}
+
+type GitHubQLStatusCheckRollup struct {
+	Contexts struct {
+		Nodes []GitHubQLStatusCheckContext
+	} `graphql:"contexts(first: 100)"`
+}
+
+type GitHubQLStatusCheckContext struct {
+	CheckRun struct {
+		Name       string
+		Status     string
+		Conclusion *string
+	} `graphql:"... on CheckRun"`
+	StatusContext struct {
+		Context string
+		State   string
+	} `graphql:"... on StatusContext"`
+}
+
+// StatusCheck is the latest result of a check run or a commit status reported for the default branch HEAD
+type StatusCheck struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Conclusion string `json:"conclusion"`
+}
+
 type Repository struct {
-	Repository                   *GitHubQLRepository               `json:"repository"`
-	VulnerabilityAlertsEnabled   *bool                             `json:"vulnerability_alerts_enabled"`
-	NoBranchProtectionPermission bool                              `json:"no_branch_protection_permission"`
-	Scorecard                    *scorecard.Result                 `json:"scorecard,omitempty"`
-	Hooks                        []*github.Hook                    `json:"hooks"`
-	Collaborators                []*github.User                    `json:"collaborators,omitempty"`
-	ActionsTokenPermissions      *types.TokenPermissions           `json:"actions_token_permissions"`
-	DependencyGraphManifests     *GitHubQLDependencyGraphManifests `json:"dependency_graph_manifests"`
-	RulesSet                     []*types.RepositoryRule           `json:"rules_set,omitempty"`
-	RepoSecrets                  []*RepositorySecret               `json:"repository_secrets,omitempty"`
-	SecurityAndAnalysis          *github.SecurityAndAnalysis       `json:"security_and_analysis,omitempty"`
+	Repository                    *GitHubQLRepository               `json:"repository"`
+	VulnerabilityAlertsEnabled    *bool                             `json:"vulnerability_alerts_enabled"`
+	AutomatedSecurityFixes        *types.AutomatedSecurityFixes     `json:"automated_security_fixes,omitempty"`
+	NoBranchProtectionPermission  bool                              `json:"no_branch_protection_permission"`
+	Scorecard                     *scorecard.Result                 `json:"scorecard,omitempty"`
+	Hooks                         []*github.Hook                    `json:"hooks"`
+	Collaborators                 []*github.User                    `json:"collaborators,omitempty"`
+	CollaboratorsCount            *int                              `json:"collaborators_count,omitempty"`
+	Teams                         []*github.Team                    `json:"teams,omitempty"`
+	EffectivePermissions          []EffectivePermission             `json:"effective_permissions,omitempty"`
+	ActionsTokenPermissions       *types.TokenPermissions           `json:"actions_token_permissions"`
+	ActionsForkPRApproval         *types.ForkPullRequestApproval    `json:"actions_fork_pr_approval,omitempty"`
+	DependencyGraphManifests      *GitHubQLDependencyGraphManifests `json:"dependency_graph_manifests"`
+	DependencyEcosystems          []DependencyEcosystem             `json:"dependency_ecosystems,omitempty"`
+	DependencyManagementFiles     *DependencyManagementFiles        `json:"dependency_management_files,omitempty"`
+	LatestRelease                 *LatestRelease                    `json:"latest_release,omitempty"`
+	RulesSet                      []*types.RepositoryRule           `json:"rules_set,omitempty"`
+	RepoSecrets                   []*RepositorySecret               `json:"repository_secrets,omitempty"`
+	SecurityAndAnalysis           *github.SecurityAndAnalysis       `json:"security_and_analysis,omitempty"`
+	SecretScanningFeatures        *SecretScanningFeatures           `json:"secret_scanning_features,omitempty"`
+	Integrations                  []RepositoryIntegration           `json:"integrations,omitempty"`
+	PrivateVulnerabilityReporting *bool                             `json:"private_vulnerability_reporting_enabled,omitempty"`
+	ActionsArtifactRetention      *types.ArtifactAndLogRetention    `json:"actions_artifact_retention,omitempty"`
+	ActionsCacheUsage             *github.ActionsCacheUsage         `json:"actions_cache_usage,omitempty"`
+	DefaultBranchChecks           []StatusCheck                     `json:"default_branch_checks"`
+	Environments                  []*github.Environment             `json:"environments"`
+	// ProtectionWithoutReviews is set when the default branch is protected (by a branch protection rule or a pull
+	// request ruleset) but none of the protections requires an approving review; nil when it is not protected
+	ProtectionWithoutReviews *bool `json:"protection_present_but_no_reviews,omitempty"`
+	// MergeQueueRequired is set when the rulesets are collected: whether an active ruleset of the default branch
+	// requires a merge queue
+	MergeQueueRequired *bool `json:"merge_queue_required,omitempty"`
+	// RequiredSignatures is whether the default branch requires signed commits, by each of its protection sources
+	RequiredSignatures *RequiredSignatures `json:"required_signatures,omitempty"`
+	// Workflows are the workflow files of the default branch and the token permissions they request
+	Workflows []Workflow `json:"workflows,omitempty"`
+	// PrivateForking is whether the repository can be forked, for private (and internal) repositories only
+	PrivateForking *PrivateForking `json:"private_forking,omitempty"`
+	// EffectiveProtection is the protection that is enforced on the default branch, out of all its protection sources
+	EffectiveProtection *EffectiveProtection `json:"effective_protection,omitempty"`
+	// DeployKeys are the SSH keys that grant access to the repository alone (nil when they could not be read)
+	DeployKeys []DeployKey `json:"deploy_keys"`
+	// SelfHostedRunners are the self-hosted runners registered to the repository (nil when they were not collected)
+	SelfHostedRunners []SelfHostedRunner `json:"self_hosted_runners"`
+	// Pages is the GitHub Pages site of the repository (nil when Pages is disabled or could not be read)
+	Pages *Pages `json:"pages,omitempty"`
+	// Codeowners is nil when the file could not be read (or the repository is empty)
+	Codeowners *Codeowners `json:"codeowners,omitempty"`
+}
+
+// Pages is the configuration of a GitHub Pages site
+type Pages struct {
+	// BuildType is legacy (built from a branch) or workflow (built by an actions workflow)
+	BuildType    string `json:"build_type"`
+	SourceBranch string `json:"source_branch,omitempty"`
+	SourcePath   string `json:"source_path,omitempty"`
+	// HTTPSEnforced is nil when GitHub does not report it (e.g. for a custom domain without a certificate yet)
+	HTTPSEnforced *bool  `json:"https_enforced,omitempty"`
+	CNAME         string `json:"cname,omitempty"`
+	// SourceBranchProtected is whether the source branch is protected (nil when unknown or not built from a branch)
+	SourceBranchProtected *bool `json:"source_branch_protected,omitempty"`
+}
+
+// SelfHostedRunner is a self-hosted actions runner
+type SelfHostedRunner struct {
+	Name string `json:"name"`
+	OS   string `json:"os"`
+	// Status is online or offline
+	Status string   `json:"status"`
+	Busy   bool     `json:"busy"`
+	Labels []string `json:"labels"`
+}
+
+// DeployKey is a repository deploy key (without the public key)
+type DeployKey struct {
+	Title    string `json:"title"`
+	ReadOnly bool   `json:"read_only"`
+	// CreatedAt is in nanoseconds since the epoch (0 when unknown)
+	CreatedAt int `json:"created_at"`
+}
+
+// EffectiveProtection consolidates the protection of the default branch by the legacy branch protection rule and the
+// active rulesets (of the repository, the organization or the enterprise) into the state that is actually enforced.
+// A protection is enforced when any of its sources enforces it. Partial is set when some of the sources could not be
+// collected (e.g. missing permissions), in which case the protection may be stronger than reported.
+type EffectiveProtection struct {
+	RequiredReviews ProtectionState `json:"required_reviews"`
+	// RequiredApprovingReviews is the highest number of approving reviews any of the sources requires
+	RequiredApprovingReviews int             `json:"required_approving_reviews"`
+	RequiredSignatures       ProtectionState `json:"required_signatures"`
+	ForcePushBlocked         ProtectionState `json:"force_push_blocked"`
+	StatusChecks             ProtectionState `json:"status_checks"`
+	// AdminEnforced is whether the protection applies to the administrators as well: a branch protection rule that
+	// includes administrators, or a ruleset that no one can bypass
+	AdminEnforced ProtectionState `json:"admin_enforced"`
+	Partial       bool            `json:"partial"`
+}
+
+// ProtectionState is whether a protection is enforced and by which sources.
+// Unknown lists the sources that may enforce it, but whose configuration could not be read.
+type ProtectionState struct {
+	Enforced bool               `json:"enforced"`
+	Sources  []ProtectionSource `json:"sources,omitempty"`
+	Unknown  []ProtectionSource `json:"unknown,omitempty"`
+}
+
+func (s *ProtectionState) Enforce(source ProtectionSource) {
+	s.Enforced = true
+	s.Sources = append(s.Sources, source)
+}
+
+func (s *ProtectionState) MayEnforce(source ProtectionSource) {
+	s.Unknown = append(s.Unknown, source)
+}
+
+const ProtectionSourceBranchProtection = "branch_protection"
+
+// ProtectionSource is the branch protection rule or a ruleset (by its name).
+// The type of a ruleset is by where it is defined, e.g. "organization_ruleset".
+type ProtectionSource struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+func NewRulesetProtectionSource(sourceType string, name string) ProtectionSource {
+	if sourceType == "" {
+		sourceType = "repository"
+	}
+	return ProtectionSource{Type: strings.ToLower(sourceType) + "_ruleset", Name: name}
+}
+
+// PrivateForking reconciles the forking setting of a private repository with the one of its organization.
+// The repository setting only applies when the organization allows forking private repositories.
+type PrivateForking struct {
+	// Organization is nil when the organization setting is not visible (to non-owners) or there is no organization
+	Organization *bool `json:"organization"`
+	Repository   bool  `json:"repository"`
+	Effective    bool  `json:"effective"`
+}
+
+// Workflow is a workflow file and the GITHUB_TOKEN permissions it requests.
+// The permissions map a scope to its access; "read-all" and "write-all" are kept under the "*" scope.
+type Workflow struct {
+	Path string `json:"path"`
+	// Permissions are the permissions of the workflow level "permissions" key, which apply to all of its jobs;
+	// nil when the key is not set (the default token permissions of the repository apply)
+	Permissions map[string]string `json:"permissions"`
+	// Jobs is the number of jobs of the workflow
+	Jobs int `json:"jobs"`
+	// JobPermissions are the permissions of the jobs that set their own "permissions" key, by the job ID
+	JobPermissions map[string]map[string]string `json:"job_permissions,omitempty"`
+	// RequestsIDToken is whether the workflow or any of its jobs may request an OIDC token (id-token: write).
+	// The default token permissions never include the id-token scope, so only the workflow files can request it.
+	RequestsIDToken bool `json:"requests_id_token"`
+	// Content is the decoded YAML document of the workflow file (only collected with --collect-workflow-contents)
+	Content interface{} `json:"content,omitempty"`
+}
+
+// RequiredSignatures separates the signed commits requirement of the legacy branch protection rule (managed by the
+// repository admins) from the one of the rulesets (possibly managed by the organization).
+// A source is nil when it could not be collected; Effective is whether any of the sources requires signed commits.
+type RequiredSignatures struct {
+	BranchProtection *bool `json:"branch_protection"`
+	Rulesets         *bool `json:"rulesets"`
+	Effective        bool  `json:"effective"`
+}
+
+// SecretScanningFeatures holds the status ("enabled"/"disabled") of the secret scanning sub-features.
+// Features that are not reported (e.g. not included in the plan) are left nil.
+type SecretScanningFeatures struct {
+	ValidityChecks      *string `json:"validity_checks,omitempty"`
+	NonProviderPatterns *string `json:"non_provider_patterns,omitempty"`
+	GenericSecrets      *string `json:"generic_secrets,omitempty"`
+}
+
+// RepositoryIntegration is a GitHub App installation that has access to the repository
+type RepositoryIntegration struct {
+	AppSlug             string                          `json:"app_slug"`
+	AppID               int64                           `json:"app_id"`
+	InstallationID      int64                           `json:"installation_id"`
+	RepositorySelection string                          `json:"repository_selection"`
+	Permissions         *github.InstallationPermissions `json:"permissions,omitempty"`
 }
 
 type RepositorySecret struct {
@@ -92,7 +369,45 @@ func (r Repository) Name() string {
 	return r.Repository.Name
 }
 
+func (r Repository) IsPublic() bool {
+	return r.Repository != nil && !r.Repository.IsPrivate
+}
+
+// Owners returns the teams that administer the repository (with the admin or maintain role), or the owner account
+// of the repository when no team does (e.g. when the teams are not collected)
+func (r Repository) Owners() []string {
+	if r.Repository == nil {
+		return nil
+	}
+
+	account := repositoryOwner(r.Repository.Url)
+	var owners []string
+	for _, team := range r.Teams {
+		if permission := team.GetPermission(); permission == "admin" || permission == "maintain" {
+			owners = append(owners, path.Join(account, team.GetSlug()))
+		}
+	}
+	if len(owners) == 0 && account != "" {
+		owners = append(owners, account)
+	}
+	return owners
+}
+
+// repositoryOwner returns the owner account of the repository link (e.g. "org" of https://github.com/org/repo)
+func repositoryOwner(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	owner, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	return owner
+}
+
 func (r Repository) ID() int64 {
 	// Deliberately using the Org; see membersList enricher
 	return r.Repository.DatabaseId
 }
+
+func (r Repository) EntityID() string {
+	return collected.NewEntityID(scm_type.GitHub, r.ViolationEntityType(), strconv.FormatInt(r.ID(), 10))
+}
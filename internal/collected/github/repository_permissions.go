@@ -0,0 +1,72 @@
+package githubcollected
+
+import "strings"
+
+const (
+	PermissionSourceDirect       = "direct"
+	PermissionSourceOrganization = "organization"
+	PermissionSourceTeamPrefix   = "team:"
+)
+
+// permissionRanks orders the repository roles; both the REST (pull/push) and the GraphQL (read/write) names are supported
+var permissionRanks = map[string]int{
+	"read":     1,
+	"pull":     1,
+	"triage":   2,
+	"write":    3,
+	"push":     3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+var normalizedPermissions = map[string]string{
+	"pull": "read",
+	"push": "write",
+}
+
+// PermissionGrant is a single grant of a repository role to a user
+type PermissionGrant struct {
+	Permission string `json:"permission"`
+	// Source is "direct", "organization" (base permission or ownership) or "team:<slug>"
+	Source string `json:"source"`
+}
+
+// EffectivePermission is the highest role a user has on the repository, out of all the grants
+type EffectivePermission struct {
+	Login      string            `json:"login"`
+	Permission string            `json:"permission"`
+	Source     string            `json:"source"`
+	Grants     []PermissionGrant `json:"grants"`
+}
+
+func NormalizePermission(permission string) string {
+	permission = strings.ToLower(permission)
+	if normalized, ok := normalizedPermissions[permission]; ok {
+		return normalized
+	}
+	return permission
+}
+
+func PermissionRank(permission string) int {
+	return permissionRanks[strings.ToLower(permission)]
+}
+
+// ReconcilePermission returns the effective permission of the user, which is the highest of its grants.
+// On equal grants, the first one is considered the source.
+func ReconcilePermission(login string, grants []PermissionGrant) EffectivePermission {
+	effective := EffectivePermission{
+		Login:  login,
+		Grants: make([]PermissionGrant, 0, len(grants)),
+	}
+
+	for _, grant := range grants {
+		grant.Permission = NormalizePermission(grant.Permission)
+		effective.Grants = append(effective.Grants, grant)
+		if effective.Permission == "" || PermissionRank(grant.Permission) > PermissionRank(effective.Permission) {
+			effective.Permission = grant.Permission
+			effective.Source = grant.Source
+		}
+	}
+
+	return effective
+}
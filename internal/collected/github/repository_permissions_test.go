@@ -0,0 +1,61 @@
+package githubcollected
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcilePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		grants     []PermissionGrant
+		permission string
+		source     string
+	}{
+		{
+			name:       "no grants",
+			grants:     nil,
+			permission: "",
+			source:     "",
+		},
+		{
+			name:       "single grant is normalized",
+			grants:     []PermissionGrant{{Permission: "PUSH", Source: PermissionSourceDirect}},
+			permission: "write",
+			source:     PermissionSourceDirect,
+		},
+		{
+			name: "highest grant wins",
+			grants: []PermissionGrant{
+				{Permission: "read", Source: PermissionSourceDirect},
+				{Permission: "maintain", Source: PermissionSourceTeamPrefix + "team"},
+				{Permission: "write", Source: PermissionSourceOrganization},
+			},
+			permission: "maintain",
+			source:     PermissionSourceTeamPrefix + "team",
+		},
+		{
+			name: "first of equal grants is the source",
+			grants: []PermissionGrant{
+				{Permission: "push", Source: PermissionSourceDirect},
+				{Permission: "write", Source: PermissionSourceTeamPrefix + "team"},
+			},
+			permission: "write",
+			source:     PermissionSourceDirect,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effective := ReconcilePermission("user", test.grants)
+			require.Equal(t, "user", effective.Login)
+			require.Equal(t, test.permission, effective.Permission)
+			require.Equal(t, test.source, effective.Source)
+			require.Len(t, effective.Grants, len(test.grants), "expecting every grant to be kept")
+			for _, grant := range effective.Grants {
+				require.Equal(t, NormalizePermission(grant.Permission), grant.Permission)
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@ package githubcollected
 
 import (
 	"fmt"
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/google/go-github/v53/github"
 )
 
@@ -26,3 +30,7 @@ func (o RunnerGroup) Name() string {
 func (o RunnerGroup) ID() int64 {
 	return *o.RunnerGroup.ID
 }
+
+func (o RunnerGroup) EntityID() string {
+	return collected.NewEntityID(scm_type.GitHub, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
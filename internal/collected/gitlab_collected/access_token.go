@@ -0,0 +1,58 @@
+package gitlab_collected
+
+import (
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// AccessToken is a group or project access token (without the token value).
+// Access tokens act as a bot member of the group/project with the token access level.
+type AccessToken struct {
+	Name        string     `json:"name"`
+	Scopes      []string   `json:"scopes"`
+	AccessLevel int        `json:"access_level"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	Active      bool       `json:"active"`
+	Revoked     bool       `json:"revoked"`
+}
+
+func newAccessToken(name string, scopes []string, accessLevel gitlab.AccessLevelValue, expiresAt *gitlab.ISOTime,
+	lastUsedAt *time.Time, active bool, revoked bool) AccessToken {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	var expires *time.Time
+	if expiresAt != nil {
+		t := time.Time(*expiresAt)
+		expires = &t
+	}
+	return AccessToken{
+		Name:        name,
+		Scopes:      scopes,
+		AccessLevel: int(accessLevel),
+		ExpiresAt:   expires,
+		LastUsedAt:  lastUsedAt,
+		Active:      active,
+		Revoked:     revoked,
+	}
+}
+
+func NewGroupAccessTokens(tokens []*gitlab.GroupAccessToken) []AccessToken {
+	result := make([]AccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, newAccessToken(token.Name, token.Scopes, token.AccessLevel, token.ExpiresAt,
+			token.LastUsedAt, token.Active, token.Revoked))
+	}
+	return result
+}
+
+func NewProjectAccessTokens(tokens []*gitlab.ProjectAccessToken) []AccessToken {
+	result := make([]AccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, newAccessToken(token.Name, token.Scopes, token.AccessLevel, token.ExpiresAt,
+			token.LastUsedAt, token.Active, token.Revoked))
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package gitlab_collected
+
+import (
+	"github.com/xanzy/go-gitlab"
+)
+
+// CIVariable is a CI/CD variable of a project (without the variable value)
+type CIVariable struct {
+	Key              string `json:"key"`
+	VariableType     string `json:"variable_type"`
+	Protected        bool   `json:"protected"`
+	Masked           bool   `json:"masked"`
+	EnvironmentScope string `json:"environment_scope"`
+}
+
+func NewProjectCIVariables(variables []*gitlab.ProjectVariable) []CIVariable {
+	result := make([]CIVariable, 0, len(variables))
+	for _, variable := range variables {
+		result = append(result, CIVariable{
+			Key:              variable.Key,
+			VariableType:     string(variable.VariableType),
+			Protected:        variable.Protected,
+			Masked:           variable.Masked,
+			EnvironmentScope: variable.EnvironmentScope,
+		})
+	}
+	return result
+}
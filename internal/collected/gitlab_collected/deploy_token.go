@@ -0,0 +1,36 @@
+package gitlab_collected
+
+import (
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// DeployToken is a project or group deploy token (without the token value)
+type DeployToken struct {
+	Name      string     `json:"name"`
+	Username  string     `json:"username"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Revoked   bool       `json:"revoked"`
+	Expired   bool       `json:"expired"`
+}
+
+func NewDeployTokens(tokens []*gitlab.DeployToken) []DeployToken {
+	result := make([]DeployToken, 0, len(tokens))
+	for _, token := range tokens {
+		scopes := token.Scopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+		result = append(result, DeployToken{
+			Name:      token.Name,
+			Username:  token.Username,
+			Scopes:    scopes,
+			ExpiresAt: token.ExpiresAt,
+			Revoked:   token.Revoked,
+			Expired:   token.Expired,
+		})
+	}
+	return result
+}
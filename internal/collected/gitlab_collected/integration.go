@@ -0,0 +1,38 @@
+package gitlab_collected
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Integration is an active integration (service) of the project or group, e.g. Slack notifications, Jira or an external CI
+type Integration struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	// Hosts are the hosts of the URLs the integration is configured with (e.g. the Jira server or the Slack webhook)
+	Hosts []string `json:"hosts"`
+}
+
+// IntegrationHosts extracts the hosts of the http(s) URLs among the integration properties
+func IntegrationHosts(properties map[string]interface{}) []string {
+	unique := make(map[string]bool)
+	for _, value := range properties {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		parsed, err := url.Parse(strings.TrimSpace(str))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+			continue
+		}
+		unique[strings.ToLower(parsed.Hostname())] = true
+	}
+
+	hosts := make([]string, 0, len(unique))
+	for host := range unique {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
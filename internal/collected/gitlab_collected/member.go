@@ -1,7 +1,11 @@
 package gitlab_collected
 
 import (
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -24,3 +28,7 @@ func (o Member) Name() string {
 func (o Member) ID() int64 {
 	return int64(o.User.ID)
 }
+
+func (o Member) EntityID() string {
+	return collected.NewEntityID(scm_type.GitLab, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
@@ -1,13 +1,20 @@
 package gitlab_collected
 
 import (
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/xanzy/go-gitlab"
 )
 
 type Organization struct {
 	*gitlab.Group
-	Hooks []*gitlab.GroupHook `json:"hooks"`
+	Hooks        []*gitlab.GroupHook `json:"hooks"`
+	DeployTokens []DeployToken       `json:"deploy_tokens"`
+	AccessTokens []AccessToken       `json:"access_tokens"`
+	Integrations []Integration       `json:"integrations"`
 }
 
 func (o Organization) ViolationEntityType() string {
@@ -25,3 +32,7 @@ func (o Organization) Name() string {
 func (o Organization) ID() int64 {
 	return int64(o.Group.ID)
 }
+
+func (o Organization) EntityID() string {
+	return collected.NewEntityID(scm_type.GitLab, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
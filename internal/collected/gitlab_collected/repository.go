@@ -1,7 +1,11 @@
 package gitlab_collected
 
 import (
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	gitlab2 "github.com/xanzy/go-gitlab"
 )
 
@@ -9,11 +13,78 @@ type Repository struct {
 	*gitlab2.Project
 	Members                  []*gitlab2.ProjectMember       `json:"members,omitempty"`
 	ProtectedBranches        []*gitlab2.ProtectedBranch     `json:"protected_branches"`
+	ProtectedTags            []*gitlab2.ProtectedTag        `json:"protected_tags"`
 	Webhooks                 []*gitlab2.ProjectHook         `json:"webhooks"`
 	PushRules                *gitlab2.ProjectPushRules      `json:"push_rules"`
 	ApprovalConfiguration    *gitlab2.ProjectApprovals      `json:"approval_configuration"`
 	ApprovalRules            []*gitlab2.ProjectApprovalRule `json:"approval_rules"`
 	MinimumRequiredApprovals int                            `json:"minimum_required_approvals"`
+	ComplianceFrameworksInfo []ComplianceFramework          `json:"compliance_frameworks_info"`
+	MergeSettings            *MergeSettings                 `json:"merge_settings"`
+	DefaultBranchProtection  *DefaultBranchProtection       `json:"default_branch_protection"`
+	RegistrySettings         *RegistrySettings              `json:"registry_settings"`
+	DeployTokens             []DeployToken                  `json:"deploy_tokens"`
+	AccessTokens             []AccessToken                  `json:"access_tokens"`
+	CIVariables              []CIVariable                   `json:"ci_variables"`
+	Integrations             []Integration                  `json:"integrations"`
+	JobTokenScope            *JobTokenScope                 `json:"job_token_scope"`
+	DescriptionTemplates     *DescriptionTemplates          `json:"description_templates"`
+}
+
+// DescriptionTemplates are the templates offered for the descriptions of new issues and merge requests:
+// the named templates (of the project .gitlab directory or the group templates) and the default templates.
+// Templates that are absent are reported as false.
+type DescriptionTemplates struct {
+	IssueTemplates                 []string `json:"issue_templates"`
+	MergeRequestTemplates          []string `json:"merge_request_templates"`
+	HasDefaultIssueTemplate        bool     `json:"has_default_issue_template"`
+	HasDefaultMergeRequestTemplate bool     `json:"has_default_merge_request_template"`
+}
+
+// JobTokenScope are the settings that limit the access of the CI/CD job tokens across projects
+type JobTokenScope struct {
+	// InboundEnabled limits the access to the project to the job tokens of the projects in the inbound allowlist
+	// (nil on older instances, which do not have the inbound scope)
+	InboundEnabled *bool `json:"inbound_enabled"`
+	// OutboundEnabled limits the access of the project job tokens to the projects in the outbound allowlist (deprecated by GitLab)
+	OutboundEnabled bool `json:"outbound_enabled"`
+	// the full paths of the allowlisted projects (nil when not available)
+	InboundAllowlist  []string `json:"inbound_allowlist"`
+	OutboundAllowlist []string `json:"outbound_allowlist"`
+}
+
+// RegistrySettings are the project settings of the container and package registries.
+// The visibilities are the effective ones: disabled, private (project members only) or the project visibility.
+type RegistrySettings struct {
+	ContainerRegistryEnabled    bool   `json:"container_registry_enabled"`
+	ContainerRegistryVisibility string `json:"container_registry_visibility"`
+	CleanupPolicyEnabled        bool   `json:"cleanup_policy_enabled"`
+	PackagesEnabled             bool   `json:"packages_enabled"`
+	PackagesVisibility          string `json:"packages_visibility"`
+}
+
+// DefaultBranchProtection reflects whether the default branch is covered by a protected branch
+// (by name or by a wildcard). It is nil when the project has no branches or the protected branches are not available.
+type DefaultBranchProtection struct {
+	Name      string `json:"name"`
+	Protected bool   `json:"protected"`
+	// MatchedBy is the name of the protected branch that covers the default branch (possibly a wildcard)
+	MatchedBy string `json:"matched_by,omitempty"`
+}
+
+// MergeSettings are the project settings that control how merge requests are merged
+type MergeSettings struct {
+	MergeMethod                  string `json:"merge_method"`
+	SquashOption                 string `json:"squash_option"`
+	RemoveSourceBranchAfterMerge bool   `json:"remove_source_branch_after_merge"`
+}
+
+type ComplianceFramework struct {
+	ID                            string `json:"id"`
+	Name                          string `json:"name"`
+	Description                   string `json:"description"`
+	Default                       bool   `json:"default"`
+	PipelineConfigurationFullPath string `json:"pipeline_configuration_full_path"`
 }
 
 func (r Repository) ViolationEntityType() string {
@@ -28,6 +99,22 @@ func (r Repository) Name() string {
 	return r.Project.Name
 }
 
+func (r Repository) IsPublic() bool {
+	return r.Project != nil && r.Project.Visibility == gitlab2.PublicVisibility
+}
+
+// Owners returns the namespace (group or user) the project belongs to
+func (r Repository) Owners() []string {
+	if r.Project == nil || r.Project.Namespace == nil {
+		return nil
+	}
+	return []string{r.Project.Namespace.FullPath}
+}
+
 func (r Repository) ID() int64 {
 	return int64(r.Project.ID)
 }
+
+func (r Repository) EntityID() string {
+	return collected.NewEntityID(scm_type.GitLab, r.ViolationEntityType(), strconv.FormatInt(r.ID(), 10))
+}
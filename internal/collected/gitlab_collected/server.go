@@ -1,7 +1,11 @@
 package gitlab_collected
 
 import (
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -32,3 +36,7 @@ func (o Server) Name() string {
 func (o Server) ID() int64 {
 	return int64(o.Settings.ID)
 }
+
+func (o Server) EntityID() string {
+	return collected.NewEntityID(scm_type.GitLab, o.ViolationEntityType(), strconv.FormatInt(o.ID(), 10))
+}
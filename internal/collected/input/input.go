@@ -0,0 +1,104 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	gitlabcollected "github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+)
+
+type decoder func(data []byte) (collected.Entity, error)
+
+func decode[T collected.Entity](data []byte) (collected.Entity, error) {
+	var entity T
+	if err := json.Unmarshal(data, &entity); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+var decoders = map[scm_type.ScmType]map[namespace.Namespace]decoder{
+	scm_type.GitHub: {
+		namespace.Organization: decode[githubcollected.Organization],
+		namespace.Enterprise:   decode[githubcollected.Enterprise],
+		namespace.Repository:   decode[githubcollected.Repository],
+		namespace.Member:       decode[githubcollected.OrganizationMembers],
+		namespace.Actions:      decode[githubcollected.OrganizationActions],
+		namespace.RunnerGroup:  decode[githubcollected.RunnerGroup],
+	},
+	scm_type.GitLab: {
+		namespace.Organization: decode[gitlabcollected.Organization],
+		namespace.Enterprise:   decode[gitlabcollected.Server],
+		namespace.Repository:   decode[gitlabcollected.Repository],
+		namespace.Member:       decode[gitlabcollected.Member],
+	},
+}
+
+// Decode parses entities of the namespace that were collected outside of legitify.
+// The data is either a single entity or an array of entities, in the same json schema the policies see as input.
+func Decode(scmType scm_type.ScmType, ns namespace.Namespace, data []byte) ([]collected.Entity, error) {
+	decoder, ok := decoders[scmType][ns]
+	if !ok {
+		return nil, fmt.Errorf("the %s namespace is not supported for %s", ns, scmType)
+	}
+
+	var documents []json.RawMessage
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &documents); err != nil {
+			return nil, fmt.Errorf("failed to parse the input entities: %v", err)
+		}
+	} else {
+		documents = []json.RawMessage{trimmed}
+	}
+
+	entities := make([]collected.Entity, 0, len(documents))
+	for i, document := range documents {
+		entity, err := decodeEntity(decoder, scmType, ns, document)
+		if err != nil {
+			return nil, fmt.Errorf("input entity #%d: %v", i, err)
+		}
+		entities = append(entities, entity)
+	}
+
+	return entities, nil
+}
+
+// DecodeEntity parses a single entity of the namespace (e.g. a line of an NDJSON stream)
+func DecodeEntity(scmType scm_type.ScmType, ns namespace.Namespace, data []byte) (collected.Entity, error) {
+	decoder, ok := decoders[scmType][ns]
+	if !ok {
+		return nil, fmt.Errorf("the %s namespace is not supported for %s", ns, scmType)
+	}
+	return decodeEntity(decoder, scmType, ns, data)
+}
+
+func decodeEntity(decoder decoder, scmType scm_type.ScmType, ns namespace.Namespace, data []byte) (collected.Entity, error) {
+	entity, err := decoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse as a %s %s: %v", scmType, ns, err)
+	}
+	if err = validate(entity); err != nil {
+		return nil, fmt.Errorf("invalid entity: %v", err)
+	}
+	return entity, nil
+}
+
+// validate makes sure the fields that identify the entity are present,
+// since the entities assume they were populated by the collectors.
+func validate(entity collected.Entity) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("missing the fields that identify the %s entity", entity.ViolationEntityType())
+		}
+	}()
+
+	entity.Name()
+	entity.CanonicalLink()
+	entity.EntityID()
+	return nil
+}
@@ -0,0 +1,65 @@
+package input
+
+import (
+	"testing"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	gitlabcollected "github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSingleEntity(t *testing.T) {
+	data := []byte(`{"repository": {"name": "repo", "Url": "https://github.com/owner/repo", "DatabaseId": 1, "is_private": true}}`)
+
+	entities, err := Decode(scm_type.GitHub, namespace.Repository, data)
+	require.Nil(t, err)
+	require.Len(t, entities, 1)
+
+	repo, ok := entities[0].(githubcollected.Repository)
+	require.True(t, ok, "expecting a github repository, got %T", entities[0])
+	require.Equal(t, "repo", repo.Name())
+	require.True(t, repo.Repository.IsPrivate)
+	require.Equal(t, "github:repository:1", repo.EntityID())
+}
+
+func TestDecodeArray(t *testing.T) {
+	data := []byte(` [{"id": 1, "full_name": "group", "web_url": "https://gitlab.com/group"}, {"id": 2, "full_name": "other"}]`)
+
+	entities, err := Decode(scm_type.GitLab, namespace.Organization, data)
+	require.Nil(t, err)
+	require.Len(t, entities, 2)
+	require.IsType(t, gitlabcollected.Organization{}, entities[0])
+	require.Equal(t, "other", entities[1].Name())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		scm   scm_type.ScmType
+		ns    namespace.Namespace
+		input string
+	}{
+		{name: "unsupported namespace", scm: scm_type.GitLab, ns: namespace.Actions, input: `{}`},
+		{name: "invalid json", scm: scm_type.GitHub, ns: namespace.Repository, input: `{"repository":`},
+		{name: "mismatching schema", scm: scm_type.GitHub, ns: namespace.Repository, input: `{"repository": "repo"}`},
+		{name: "missing identifying fields", scm: scm_type.GitHub, ns: namespace.Repository, input: `[{"hooks": []}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Decode(test.scm, test.ns, []byte(test.input))
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func TestDecodeEntity(t *testing.T) {
+	entity, err := DecodeEntity(scm_type.GitLab, namespace.Repository, []byte(`{"id": 3, "name": "project", "web_url": "https://gitlab.com/group/project"}`))
+	require.Nil(t, err)
+	require.Equal(t, "project", entity.Name())
+
+	_, err = DecodeEntity(scm_type.GitHub, namespace.Repository, []byte(`[{"repository": {"name": "repo"}}]`))
+	require.NotNil(t, err, "an array is not a single entity")
+}
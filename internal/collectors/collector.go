@@ -16,6 +16,7 @@ type CollectedDataRepositoryContext interface {
 	CollectedDataContext
 	HasBranchProtectionPermission() bool
 	HasGithubAdvancedSecurity()     bool
+	IsDisabled() bool
 }
 
 type CollectedData struct {
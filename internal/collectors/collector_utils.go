@@ -6,7 +6,9 @@ import (
 	"github.com/Legit-Labs/legitify/cmd/progressbar"
 	"github.com/Legit-Labs/legitify/internal/collected"
 	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/Legit-Labs/legitify/internal/errlog"
 )
 
 func FullRepoName(org string, repo string) string {
@@ -77,6 +79,22 @@ func (b *BaseCollector) IssueMissingPermissions(missingPermissions ...MissingPer
 	}
 }
 
+// FailCollection records that the namespace could not be collected (e.g. no access to the organizations).
+// The other namespaces are collected regardless.
+func (b *BaseCollector) FailCollection(err error) {
+	errlog.AddNamespaceFailure(b.namespace, err.Error())
+}
+
+func (b *BaseCollector) failUnexpectedly(recovered interface{}) {
+	b.FailCollection(fmt.Errorf("collection failed unexpectedly: %v", recovered))
+}
+
+// NewGroupWaiter returns a GroupWaiter for the goroutines of the collection:
+// a panic in one of them fails the namespace instead of aborting the whole analysis.
+func (b *BaseCollector) NewGroupWaiter() *group_waiter.GroupWaiter {
+	return group_waiter.NewRecovering(b.failUnexpectedly)
+}
+
 func (b *BaseCollector) makeChannels() {
 	const depthToUnblockTotalEntitiesCollection = 128
 	b.collectedChan = make(chan CollectedData, depthToUnblockTotalEntitiesCollection)
@@ -103,6 +121,12 @@ func (b *BaseCollector) WrappedCollection(collection func()) SubCollectorChannel
 	b.makeChannels()
 	go func() {
 		defer b.closeChannels()
+		defer func() {
+			// an unexpected failure of a single namespace should not abort the whole analysis
+			if r := recover(); r != nil {
+				b.failUnexpectedly(r)
+			}
+		}()
 		collection()
 	}()
 	return b.getChannels()
@@ -0,0 +1,131 @@
+package collectors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/cmd/progressbar"
+	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/stretchr/testify/require"
+)
+
+// run with -race to detect unsynchronized access in the aggregation paths
+func TestBaseCollectorConcurrentAggregation(t *testing.T) {
+	const goroutines = 64
+	const iterations = 50
+
+	collector := NewBaseCollector(namespace.Repository)
+	channels := collector.WrappedCollection(func() {
+		gw := group_waiter.New()
+		for i := 0; i < goroutines; i++ {
+			i := i
+			gw.Do(func() {
+				inner := group_waiter.New()
+				for j := 0; j < iterations; j++ {
+					j := j
+					inner.Do(func() {
+						entity := fmt.Sprintf("org/repo-%d-%d", i, j)
+						collector.IssueMissingPermissions(NewMissingPermission(permissions.RepoAdmin, entity, "effect", namespace.Repository))
+						collector.CollectionChangeByOne()
+					})
+				}
+				inner.Wait()
+			})
+		}
+		gw.Wait()
+	})
+
+	var wg sync.WaitGroup
+	var progress, missingPermissions int
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for x := range channels.Progress {
+			if update, ok := x.(progressbar.BarUpdate); ok {
+				progress += update.Change
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		forwarded := make(chan MissingPermission)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			CollectMissingPermissions(forwarded, errlog.NewCollectionLog())
+		}()
+		for x := range channels.MissingPermission {
+			missingPermissions++
+			forwarded <- x
+		}
+		close(forwarded)
+		<-done
+	}()
+	go func() {
+		defer wg.Done()
+		for range channels.Collected {
+		}
+	}()
+	wg.Wait()
+
+	require.Equal(t, goroutines*iterations, progress, "unexpected progress count")
+	require.Equal(t, goroutines*iterations, missingPermissions, "unexpected missing permissions count")
+}
+
+func drainChannels(channels SubCollectorChannels) {
+	for range channels.Progress {
+	}
+	for range channels.Collected {
+	}
+	for range channels.MissingPermission {
+	}
+}
+
+func TestWrappedCollectionIsolatesFailures(t *testing.T) {
+	defer errlog.Isolate()()
+
+	collector := NewBaseCollector(namespace.RunnerGroup)
+	channels := collector.WrappedCollection(func() {
+		collector.CollectionChangeByOne()
+		panic("unexpected response")
+	})
+
+	// the channels are closed even though the collection failed
+	drainChannels(channels)
+
+	reason, ok := errlog.FailedNamespaces()[namespace.RunnerGroup]
+	require.True(t, ok, "expecting the namespace to be reported as skipped")
+	require.Contains(t, reason, "unexpected response")
+
+	// only the first reason of a namespace is kept
+	collector.FailCollection(fmt.Errorf("another failure"))
+	require.Equal(t, reason, errlog.FailedNamespaces()[namespace.RunnerGroup])
+}
+
+func TestGroupWaiterIsolatesFailures(t *testing.T) {
+	defer errlog.Isolate()()
+
+	collector := NewBaseCollector(namespace.RunnerGroup)
+	collected := 0
+	channels := collector.WrappedCollection(func() {
+		gw := collector.NewGroupWaiter()
+		gw.Do(func() {
+			panic("unexpected response")
+		})
+		gw.Do(func() {
+			collected++
+		})
+		gw.Wait()
+	})
+	drainChannels(channels)
+
+	require.Equal(t, 1, collected, "expecting the other goroutines to complete")
+	reason, ok := errlog.FailedNamespaces()[namespace.RunnerGroup]
+	require.True(t, ok, "expecting the namespace to be reported as skipped")
+	require.Contains(t, reason, "unexpected response")
+}
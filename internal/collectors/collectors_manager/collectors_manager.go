@@ -1,9 +1,14 @@
 package collectors_manager
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/Legit-Labs/legitify/cmd/progressbar"
 	"github.com/Legit-Labs/legitify/internal/collectors"
 	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/errlog"
 )
 
 type CollectorManager interface {
@@ -12,11 +17,13 @@ type CollectorManager interface {
 
 type manager struct {
 	collectors []collectors.Collector
+	collection *errlog.CollectionLog
 }
 
-func NewCollectorsManager(initiatedCollectors []collectors.Collector) CollectorManager {
+func NewCollectorsManager(ctx context.Context, initiatedCollectors []collectors.Collector) CollectorManager {
 	return &manager{
 		collectors: initiatedCollectors,
+		collection: context_utils.GetCollectionLog(ctx),
 	}
 }
 
@@ -36,7 +43,7 @@ func (m *manager) Collect() <-chan collectors.CollectedData {
 		missingPermissionsChannel := make(chan collectors.MissingPermission)
 		permWait := group_waiter.New()
 		permWait.Do(func() {
-			collectors.CollectMissingPermissions(missingPermissionsChannel)
+			collectors.CollectMissingPermissions(missingPermissionsChannel, m.collection)
 		})
 
 		gw := group_waiter.New()
@@ -45,7 +52,7 @@ func (m *manager) Collect() <-chan collectors.CollectedData {
 			collectionChannels := c.Collect()
 
 			gw.Do(func() {
-				totalEntities := c.CollectTotalEntities()
+				totalEntities := collectTotalEntities(c)
 				progressbar.Report(progressbar.NewRequiredBar(c.Namespace(), totalEntities))
 				progressbar.Report(progressbar.NewUpdate(metadataBarName, 1))
 
@@ -69,6 +76,7 @@ func (m *manager) Collect() <-chan collectors.CollectedData {
 						if !ok {
 							collected = nil
 						} else {
+							m.collection.Add(x.Namespace)
 							collectedChan <- x
 						}
 					case x, ok := <-perm:
@@ -92,3 +100,16 @@ func (m *manager) Collect() <-chan collectors.CollectedData {
 
 	return collectedChan
 }
+
+// collectTotalEntities isolates the namespace: a collector that fails unexpectedly is skipped
+// and the other namespaces are still collected
+func collectTotalEntities(c collectors.Collector) (total int) {
+	defer func() {
+		if r := recover(); r != nil {
+			errlog.AddNamespaceFailure(c.Namespace(), fmt.Sprintf("collection failed unexpectedly: %v", r))
+			total = 0
+		}
+	}()
+
+	return c.CollectTotalEntities()
+}
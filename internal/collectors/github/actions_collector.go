@@ -3,19 +3,19 @@ package github
 import (
 	"context"
 	"fmt"
-	"log"
+	"net/http"
 
 	"github.com/Legit-Labs/legitify/internal/collectors"
 
 	ghclient "github.com/Legit-Labs/legitify/internal/clients/github"
 	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 )
 
 const (
-	orgActionPermEffect = "Cannot read organization actions settings"
+	orgActionPermEffect         = "Cannot read organization actions settings"
+	orgForkPRApprovalPermEffect = "Cannot read organization fork pull request workflows approval settings"
 )
 
 type actionCollector struct {
@@ -36,7 +36,7 @@ func NewActionCollector(ctx context.Context, client *ghclient.Client) collectors
 func (c *actionCollector) CollectTotalEntities() int {
 	orgs, err := c.client.CollectOrganizations()
 	if err != nil {
-		log.Printf("failed to collect organizations %s", err)
+		c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 		return 0
 	}
 
@@ -48,11 +48,11 @@ func (c *actionCollector) Collect() collectors.SubCollectorChannels {
 		orgs, err := c.client.CollectOrganizations()
 
 		if err != nil {
-			log.Printf("failed to collect organizations %s", err)
+			c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 			return
 		}
 
-		gw := group_waiter.New()
+		gw := c.NewGroupWaiter()
 		for _, org := range orgs {
 			org := org
 			gw.Do(func() {
@@ -68,11 +68,20 @@ func (c *actionCollector) Collect() collectors.SubCollectorChannels {
 					return
 				}
 
+				// not found: the setting is not available in this GitHub version
+				approval, resp, err3 := c.client.GetForkPullRequestApprovalForOrganization(org.Name())
+				if err3 != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+					entityName := fmt.Sprintf("%s/%s", namespace.Organization, org.Name())
+					perm := collectors.NewMissingPermission(permissions.OrgAdmin, entityName, orgForkPRApprovalPermEffect, namespace.Organization)
+					c.IssueMissingPermissions(perm)
+				}
+
 				c.CollectData(org,
 					ghcollected.OrganizationActions{
-						Organization:       org,
-						ActionsPermissions: actionsData,
-						TokenPermissions:   actionsPermissions,
+						Organization:            org,
+						ActionsPermissions:      actionsData,
+						TokenPermissions:        actionsPermissions,
+						ForkPullRequestApproval: approval,
 					},
 					org.CanonicalLink(),
 					[]permissions.Role{org.Role})
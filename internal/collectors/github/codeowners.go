@@ -0,0 +1,49 @@
+package github
+
+import (
+	"net/http"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/google/go-github/v53/github"
+)
+
+// codeownersPaths are the locations of the CODEOWNERS file, in the order GitHub looks them up
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// withCodeowners collects the CODEOWNERS file of the default branch.
+// A missing file is reported as absent rather than as an error.
+func (rc *repositoryCollector) withCodeowners(repo ghcollected.Repository, org string) (ghcollected.Repository, error) {
+	if repo.Repository.DefaultBranchRef == nil || repo.Repository.DefaultBranchRef.Name == nil {
+		// an empty repository
+		return repo, nil
+	}
+
+	opts := &github.RepositoryContentGetOptions{Ref: *repo.Repository.DefaultBranchRef.Name}
+	for _, path := range codeownersPaths {
+		file, _, resp, err := rc.Client.Client().Repositories.GetContents(rc.Context, org, repo.Repository.Name, path, opts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return repo, err
+		}
+		if file == nil {
+			// a directory by that name
+			continue
+		}
+
+		content, err := file.GetContent()
+		if err != nil {
+			return repo, err
+		}
+		repo.Codeowners = &ghcollected.Codeowners{
+			Exists:  true,
+			Path:    path,
+			Content: content,
+		}
+		return repo, nil
+	}
+
+	repo.Codeowners = &ghcollected.Codeowners{Exists: false}
+	return repo, nil
+}
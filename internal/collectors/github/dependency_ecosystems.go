@@ -0,0 +1,124 @@
+package github
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+)
+
+type manifestKind struct {
+	ecosystem  string
+	isLockfile bool
+}
+
+// known manifest and lockfile names, as reported by the dependency graph
+var manifestKinds = map[string]manifestKind{
+	// go.sum only holds checksums: go.mod already pins the versions of all the dependencies (minimal version selection)
+	"go.mod": {"go", false},
+	"go.sum": {"go", false},
+
+	"package.json":        {"npm", false},
+	"package-lock.json":   {"npm", true},
+	"npm-shrinkwrap.json": {"npm", true},
+	"yarn.lock":           {"npm", true},
+	"pnpm-lock.yaml":      {"npm", true},
+
+	"requirements.txt": {"pip", false},
+	"setup.py":         {"pip", false},
+	"pyproject.toml":   {"pip", false},
+	"pipfile":          {"pip", false},
+	"pipfile.lock":     {"pip", true},
+	"poetry.lock":      {"pip", true},
+
+	"pom.xml": {"maven", false},
+
+	"build.gradle":     {"gradle", false},
+	"build.gradle.kts": {"gradle", false},
+	"gradle.lockfile":  {"gradle", true},
+
+	"gemfile":      {"rubygems", false},
+	"gemfile.lock": {"rubygems", true},
+
+	"composer.json": {"composer", false},
+	"composer.lock": {"composer", true},
+
+	"cargo.toml": {"cargo", false},
+	"cargo.lock": {"cargo", true},
+
+	"packages.config":    {"nuget", false},
+	"packages.lock.json": {"nuget", true},
+
+	"package.swift":    {"swift", false},
+	"package.resolved": {"swift", true},
+
+	"pubspec.yaml": {"pub", false},
+	"pubspec.lock": {"pub", true},
+}
+
+// ecosystems for which no lockfile exists or is needed (e.g. maven, actions, go)
+var noLockfileEcosystems = map[string]bool{
+	"maven":   true,
+	"actions": true,
+	"go":      true,
+}
+
+func classifyManifest(manifest ghcollected.GitHubQLDependencyGraphManifest) (manifestKind, bool) {
+	name := strings.ToLower(path.Base(manifest.Filename))
+	if kind, ok := manifestKinds[name]; ok {
+		return kind, true
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".gemspec"):
+		return manifestKind{"rubygems", false}, true
+	case strings.HasSuffix(name, ".csproj"):
+		return manifestKind{"nuget", false}, true
+	case (path.Ext(name) == ".yml" || path.Ext(name) == ".yaml") && strings.Contains(manifest.Filename, ".github/workflows/"):
+		return manifestKind{"actions", false}, true
+	}
+
+	return manifestKind{}, false
+}
+
+// dependencyEcosystems groups the dependency graph manifests by their ecosystem
+// so that policies can require e.g. a lockfile per ecosystem.
+func dependencyEcosystems(manifests []ghcollected.GitHubQLDependencyGraphManifest) []ghcollected.DependencyEcosystem {
+	byEcosystem := make(map[string]*ghcollected.DependencyEcosystem)
+
+	for _, manifest := range manifests {
+		kind, ok := classifyManifest(manifest)
+		if !ok {
+			continue
+		}
+
+		ecosystem, ok := byEcosystem[kind.ecosystem]
+		if !ok {
+			ecosystem = &ghcollected.DependencyEcosystem{
+				Ecosystem:         kind.ecosystem,
+				Manifests:         []string{},
+				Lockfiles:         []string{},
+				LockfileSupported: !noLockfileEcosystems[kind.ecosystem],
+			}
+			byEcosystem[kind.ecosystem] = ecosystem
+		}
+
+		if kind.isLockfile {
+			ecosystem.Lockfiles = append(ecosystem.Lockfiles, manifest.Filename)
+			ecosystem.HasLockfile = true
+		} else {
+			ecosystem.Manifests = append(ecosystem.Manifests, manifest.Filename)
+		}
+	}
+
+	result := make([]ghcollected.DependencyEcosystem, 0, len(byEcosystem))
+	for _, ecosystem := range byEcosystem {
+		result = append(result, *ecosystem)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Ecosystem < result[j].Ecosystem
+	})
+
+	return result
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// the configuration files of the dependency update tools and the funding file, by the directory they are looked up in
+var (
+	dependabotConfigFiles = map[string]bool{".github/dependabot.yml": true, ".github/dependabot.yaml": true}
+	renovateConfigFiles   = map[string]bool{
+		"renovate.json": true, "renovate.json5": true, ".renovaterc": true, ".renovaterc.json": true,
+		".github/renovate.json": true, ".github/renovate.json5": true,
+	}
+	fundingFiles = map[string]bool{".github/FUNDING.yml": true}
+)
+
+type treeEntries struct {
+	Tree struct {
+		Entries []struct {
+			Name string
+		}
+	} `graphql:"... on Tree"`
+}
+
+// withDependencyManagementFiles checks which dependency management files exist in the default branch.
+// Only the root and .github directories are listed (a single query), and missing files (or an empty repository) are reported as absent.
+func (rc *repositoryCollector) withDependencyManagementFiles(repo ghcollected.Repository, org string) (ghcollected.Repository, error) {
+	var filesQuery struct {
+		RepositoryOwner struct {
+			Repository struct {
+				Root      *treeEntries `graphql:"root: object(expression: $root)"`
+				DotGitHub *treeEntries `graphql:"dotGitHub: object(expression: $dotGitHub)"`
+			} `graphql:"repository(name: $name)"`
+		} `graphql:"repositoryOwner(login: $login)"`
+	}
+
+	variables := map[string]interface{}{
+		"login":     githubv4.String(org),
+		"name":      githubv4.String(repo.Name()),
+		"root":      githubv4.String("HEAD:"),
+		"dotGitHub": githubv4.String("HEAD:.github"),
+	}
+
+	err := rc.Client.GraphQLClient().Query(rc.Context, &filesQuery, variables)
+	if err != nil {
+		return repo, err
+	}
+
+	var paths []string
+	if root := filesQuery.RepositoryOwner.Repository.Root; root != nil {
+		for _, entry := range root.Tree.Entries {
+			paths = append(paths, entry.Name)
+		}
+	}
+	if dotGitHub := filesQuery.RepositoryOwner.Repository.DotGitHub; dotGitHub != nil {
+		for _, entry := range dotGitHub.Tree.Entries {
+			paths = append(paths, ".github/"+entry.Name)
+		}
+	}
+
+	repo.DependencyManagementFiles = dependencyManagementFiles(paths)
+	return repo, nil
+}
+
+func dependencyManagementFiles(paths []string) *ghcollected.DependencyManagementFiles {
+	files := &ghcollected.DependencyManagementFiles{}
+	for _, path := range paths {
+		files.HasDependabotConfig = files.HasDependabotConfig || dependabotConfigFiles[path]
+		files.HasRenovateConfig = files.HasRenovateConfig || renovateConfigFiles[path]
+		files.HasFundingFile = files.HasFundingFile || fundingFiles[path]
+	}
+	return files
+}
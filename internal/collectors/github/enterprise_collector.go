@@ -2,13 +2,12 @@ package github
 
 import (
 	"context"
+	"fmt"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
-	"log"
 
 	"github.com/Legit-Labs/legitify/internal/collectors"
 
 	ghclient "github.com/Legit-Labs/legitify/internal/clients/github"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 )
 
@@ -30,7 +29,7 @@ func NewEnterpriseCollector(ctx context.Context, client *ghclient.Client) collec
 func (c *enterpriseCollector) CollectTotalEntities() int {
 	collectedEnterprises, err := c.Client.CollectEnterprises()
 	if err != nil {
-		log.Printf("failed to collect enterprise %s", err)
+		c.FailCollection(fmt.Errorf("failed to collect enterprise: %v", err))
 		return 0
 	}
 
@@ -42,11 +41,11 @@ func (c *enterpriseCollector) Collect() collectors.SubCollectorChannels {
 		enterprises, err := c.Client.CollectEnterprises()
 
 		if err != nil {
-			log.Printf("failed to collect enterprise %s", err)
+			c.FailCollection(fmt.Errorf("failed to collect enterprise: %v", err))
 			return
 		}
 
-		gw := group_waiter.New()
+		gw := c.NewGroupWaiter()
 		for _, enterprise := range enterprises {
 			localEnterprise := enterprise
 			gw.Do(func() {
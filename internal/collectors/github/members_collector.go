@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/Legit-Labs/legitify/internal/collectors"
 
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 
 	ghclient "github.com/Legit-Labs/legitify/internal/clients/github"
@@ -43,10 +43,10 @@ type totalCountMembersQuery struct {
 }
 
 func (c *memberCollector) CollectTotalEntities() int {
-	gw := group_waiter.New()
+	gw := c.NewGroupWaiter()
 	orgs, err := c.Client.CollectOrganizations()
 	if err != nil {
-		log.Printf("failed to collect organization %s", err)
+		c.FailCollection(fmt.Errorf("failed to collect organization: %v", err))
 		return 0
 	}
 
@@ -70,7 +70,7 @@ func (c *memberCollector) CollectTotalEntities() int {
 				return
 			}
 
-			totalCount += int32(totalCountQuery.Organization.MembersWithRole.TotalCount)
+			atomic.AddInt32(&totalCount, int32(totalCountQuery.Organization.MembersWithRole.TotalCount))
 		})
 	}
 	gw.Wait()
@@ -83,7 +83,7 @@ func (c *memberCollector) Collect() collectors.SubCollectorChannels {
 		orgs, err := c.Client.CollectOrganizations()
 
 		if err != nil {
-			log.Printf("failed to collect organizations %s", err)
+			c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 			return
 		}
 
@@ -125,7 +125,7 @@ func (c *memberCollector) Collect() collectors.SubCollectorChannels {
 }
 
 func (c *memberCollector) enrichMembers(org *ghcollected.ExtendedOrg, members []*github.User, memberType string) []ghcollected.OrganizationMember {
-	gw := group_waiter.New()
+	gw := c.NewGroupWaiter()
 	resChannel := make(chan ghcollected.OrganizationMember, len(members))
 
 	for _, member := range members {
@@ -2,8 +2,10 @@ package github
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/Legit-Labs/legitify/internal/collectors"
 
@@ -11,8 +13,8 @@ import (
 
 	ghclient "github.com/Legit-Labs/legitify/internal/clients/github"
 	"github.com/Legit-Labs/legitify/internal/clients/github/pagination"
+	"github.com/Legit-Labs/legitify/internal/clients/github/types"
 	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	"github.com/shurcooL/githubv4"
@@ -46,7 +48,7 @@ func NewOrganizationCollector(ctx context.Context, client *ghclient.Client) coll
 func (c *organizationCollector) CollectTotalEntities() int {
 	orgs, err := c.Client.CollectOrganizations()
 	if err != nil {
-		log.Printf("failed to collect organizations %s", err)
+		c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 		return 0
 	}
 
@@ -58,11 +60,11 @@ func (c *organizationCollector) Collect() collectors.SubCollectorChannels {
 		orgs, err := c.Client.CollectOrganizations()
 
 		if err != nil {
-			log.Printf("failed to collect organizations %s", err)
+			c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 			return
 		}
 
-		gw := group_waiter.New()
+		gw := c.NewGroupWaiter()
 		for _, org := range orgs {
 			org := org
 			gw.Do(func() {
@@ -97,12 +99,451 @@ func (c *organizationCollector) collectExtraData(org *ghcollected.ExtendedOrg) g
 		log.Printf("failed to collect secrets for %s, %s", org.Name(), err)
 	}
 
+	sshCAs, err := c.collectOrgSSHCertificateAuthorities(org)
+	if err != nil {
+		sshCAs = nil
+		log.Printf("failed to collect ssh certificate authorities for %s, %s", org.Name(), err)
+	}
+
+	memberPrivileges, err := c.collectOrgMemberPrivileges(org)
+	if err != nil {
+		memberPrivileges = nil
+		log.Printf("failed to collect member privileges for %s, %s", org.Name(), err)
+	}
+
+	requiredWorkflows, err := c.collectOrgRequiredWorkflows(org)
+	if err != nil {
+		requiredWorkflows = nil
+		log.Printf("failed to collect required workflows for %s, %s", org.Name(), err)
+	}
+
+	classicTokens, err := c.collectOrgClassicTokens(org, samlEnabled)
+	if err != nil {
+		classicTokens = nil
+		log.Printf("failed to collect classic personal access tokens for %s, %s", org.Name(), err)
+	}
+
+	membersWithoutSSO, err := c.collectOrgMembersWithoutSSO(org, samlEnabled)
+	if err != nil {
+		membersWithoutSSO = nil
+		log.Printf("failed to collect the SSO identities of the members of %s, %s", org.Name(), err)
+	}
+
+	outsideCollaborators, err := c.collectOrgOutsideCollaboratorsCount(org)
+	if err != nil {
+		outsideCollaborators = nil
+		log.Printf("failed to collect outside collaborators for %s, %s", org.Name(), err)
+	}
+
+	invitations, err := c.collectOrgInvitations(org)
+	if err != nil {
+		invitations = nil
+		log.Printf("failed to collect pending invitations for %s, %s", org.Name(), err)
+	}
+
+	rulesets, err := c.collectOrgRulesets(org)
+	if err != nil {
+		rulesets = nil
+		log.Printf("failed to collect rulesets for %s, %s", org.Name(), err)
+	}
+
+	packages, err := c.collectOrgPackages(org)
+	if err != nil {
+		packages = nil
+		log.Printf("failed to collect packages for %s, %s", org.Name(), err)
+	}
+
+	runners, runnerGroups, err := c.collectOrgRunners(org)
+	if err != nil {
+		runners, runnerGroups = nil, nil
+		log.Printf("failed to collect self-hosted runners for %s, %s", org.Name(), err)
+	}
+
 	return ghcollected.Organization{
-		Organization: org,
-		SamlEnabled:  samlEnabled,
-		Hooks:        hooks,
-		OrgSecrets:   secrets,
+		Organization:              org,
+		SamlEnabled:               samlEnabled,
+		Hooks:                     hooks,
+		OrgSecrets:                secrets,
+		SSHCertificateAuthorities: sshCAs,
+		MemberPrivileges:          memberPrivileges,
+		RequiredWorkflows:         requiredWorkflows,
+		ClassicTokens:             classicTokens,
+		MembersWithoutSSO:         membersWithoutSSO,
+		OutsideCollaboratorsCount: outsideCollaborators,
+		Invitations:               invitations,
+		Rulesets:                  rulesets,
+		Packages:                  packages,
+		Runners:                   runners,
+		RunnerGroups:              runnerGroups,
+	}
+}
+
+// packageTypes are the package types of GitHub Packages (the packages are listed by type)
+var packageTypes = []string{"npm", "maven", "rubygems", "docker", "nuget", "container"}
+
+// the packages require the read:packages scope, and are not available in every GitHub Enterprise Server
+// (which may also support only some of the package types)
+func (c *organizationCollector) collectOrgPackages(org *ghcollected.ExtendedOrg) ([]*ghcollected.OrganizationPackage, error) {
+	packages := []*ghcollected.OrganizationPackage{}
+	unsupported := 0
+	for _, packageType := range packageTypes {
+		opts := &github.PackageListOptions{PackageType: github.String(packageType)}
+		res, err := pagination.New[*github.Package](c.Client.Client().Organizations.ListPackages, opts).Sync(c.Context, org.Name())
+		if err != nil {
+			if res.Resp != nil {
+				switch res.Resp.Response.StatusCode {
+				case http.StatusNotFound:
+					// the package type is not supported by the server
+					unsupported++
+					continue
+				case http.StatusUnauthorized, http.StatusForbidden:
+					perm := collectors.NewMissingPermission(permissions.PackagesRead, org.Name(),
+						"Cannot read the packages of the organization", namespace.Organization)
+					c.IssueMissingPermissions(perm)
+					return nil, nil
+				}
+			}
+			return nil, err
+		}
+
+		for _, pkg := range res.Collected {
+			collected := &ghcollected.OrganizationPackage{
+				Name:        pkg.GetName(),
+				PackageType: pkg.GetPackageType(),
+				Visibility:  pkg.GetVisibility(),
+				Link:        pkg.GetHTMLURL(),
+			}
+			if pkg.Repository != nil {
+				collected.Repository = pkg.Repository.GetFullName()
+				collected.RepositoryVisibility = repositoryVisibility(pkg.Repository)
+			}
+			packages = append(packages, collected)
+		}
+	}
+
+	if unsupported == len(packageTypes) {
+		// packages are not supported by the server
+		return nil, nil
+	}
+	return packages, nil
+}
+
+func repositoryVisibility(repository *github.Repository) string {
+	if repository.Visibility != nil {
+		return repository.GetVisibility()
+	}
+	if repository.GetPrivate() {
+		return "private"
+	}
+	return "public"
+}
+
+// organization rulesets are visible to organization owners.
+// the listing omits the rules, conditions and bypass actors, so each ruleset is fetched once per organization.
+func (c *organizationCollector) collectOrgRulesets(org *ghcollected.ExtendedOrg) ([]*github.Ruleset, error) {
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read organization rulesets", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	res, err := pagination.New[*github.Ruleset](c.Client.ListOrganizationRulesets, nil).Sync(c.Context, org.Name())
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusNotFound || res.Resp.StatusCode == http.StatusForbidden) {
+			// not included in the organization plan or not supported by the server
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rulesets := make([]*github.Ruleset, 0, len(res.Collected))
+	for _, ruleset := range res.Collected {
+		full, _, err := c.Client.Client().Organizations.GetOrganizationRuleset(c.Context, org.Name(), ruleset.ID)
+		if err != nil {
+			return nil, err
+		}
+		rulesets = append(rulesets, full)
+	}
+
+	return rulesets, nil
+}
+
+const classicTokenCredentialType = "personal access token"
+
+// the authorized credentials are only available for SAML SSO organizations and visible to organization owners
+func (c *organizationCollector) collectOrgClassicTokens(org *ghcollected.ExtendedOrg, samlEnabled *bool) ([]*ghcollected.ClassicToken, error) {
+	if samlEnabled == nil || !*samlEnabled {
+		return nil, nil
+	}
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read the personal access tokens authorized for the organization", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	res, err := pagination.New[*types.CredentialAuthorization](c.Client.ListCredentialAuthorizations, nil).Sync(c.Context, org.Name())
+	if err != nil {
+		if res.Resp != nil && res.Resp.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	tokens := []*ghcollected.ClassicToken{}
+	for _, credential := range res.Collected {
+		if credential.CredentialType == nil || *credential.CredentialType != classicTokenCredentialType {
+			continue
+		}
+		token := &ghcollected.ClassicToken{
+			Scopes: credential.Scopes,
+		}
+		if token.Scopes == nil {
+			token.Scopes = []string{}
+		}
+		if credential.Login != nil {
+			token.Login = *credential.Login
+		}
+		if credential.AuthorizedCredentialTitle != nil {
+			token.Title = *credential.AuthorizedCredentialTitle
+		}
+		if credential.AuthorizedCredentialExpiresAt != nil {
+			token.ExpiresAt = credential.AuthorizedCredentialExpiresAt.Format(time.RFC3339)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+func (c *organizationCollector) collectOrgOutsideCollaboratorsCount(org *ghcollected.ExtendedOrg) (*int, error) {
+	res, err := pagination.New[*github.User](c.Client.Client().Organizations.ListOutsideCollaborators, &github.ListOutsideCollaboratorsOptions{}).Sync(c.Context, org.Name())
+	if err != nil {
+		if res.Resp != nil && (res.Resp.Response.StatusCode == http.StatusNotFound || res.Resp.Response.StatusCode == http.StatusForbidden) {
+			perm := collectors.NewMissingPermission(permissions.OrgRead, org.Name(),
+				"Cannot read the outside collaborators of the organization", namespace.Organization)
+			c.IssueMissingPermissions(perm)
+			return nil, nil
+		}
+		return nil, err
 	}
+
+	count := len(res.Collected)
+	return &count, nil
+}
+
+// pending invitations are only visible to organization owners
+func (c *organizationCollector) collectOrgInvitations(org *ghcollected.ExtendedOrg) ([]*ghcollected.OrganizationInvitation, error) {
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read the pending invitations of the organization", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	res, err := pagination.New[*github.Invitation](c.Client.Client().Organizations.ListPendingOrgInvitations, nil).Sync(c.Context, org.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := []*ghcollected.OrganizationInvitation{}
+	for _, pending := range res.Collected {
+		invitation := &ghcollected.OrganizationInvitation{
+			Invitee: pending.GetLogin(),
+			Inviter: pending.GetInviter().GetLogin(),
+			Role:    pending.GetRole(),
+		}
+		if invitation.Invitee == "" {
+			invitation.Invitee = pending.GetEmail()
+		}
+		if pending.CreatedAt != nil {
+			createdAt := int(pending.CreatedAt.UnixNano())
+			invitation.CreatedAt = &createdAt
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+// self-hosted runners and runner groups are only visible to organization owners.
+// the runners listing does not include their group, so the runners of each group are listed as well.
+func (c *organizationCollector) collectOrgRunners(org *ghcollected.ExtendedOrg) ([]*ghcollected.OrganizationRunner, []*ghcollected.OrganizationRunnerGroup, error) {
+	missingPermission := func() {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read the self-hosted runners of the organization", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+	}
+	if org.Role != permissions.OrgRoleOwner {
+		missingPermission()
+		return nil, nil, nil
+	}
+
+	runnersMapper := func(runners *github.Runners) []*github.Runner {
+		if runners == nil {
+			return []*github.Runner{}
+		}
+		return runners.Runners
+	}
+	res, err := pagination.NewMapper(c.Client.Client().Actions.ListOrganizationRunners, nil, runnersMapper).Sync(c.Context, org.Name())
+	if err != nil {
+		if res.Resp != nil && (res.Resp.Response.StatusCode == http.StatusNotFound || res.Resp.Response.StatusCode == http.StatusForbidden) {
+			missingPermission()
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	groupsMapper := func(groups *github.RunnerGroups) []*github.RunnerGroup {
+		if groups == nil {
+			return []*github.RunnerGroup{}
+		}
+		return groups.RunnerGroups
+	}
+	groupsRes, err := pagination.NewMapper(c.Client.Client().Actions.ListOrganizationRunnerGroups, nil, groupsMapper).Sync(c.Context, org.Name())
+	if err != nil {
+		if groupsRes.Resp != nil && (groupsRes.Resp.Response.StatusCode == http.StatusNotFound || groupsRes.Resp.Response.StatusCode == http.StatusForbidden) {
+			missingPermission()
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	groupOfRunner := make(map[int64]string)
+	groups := make([]*ghcollected.OrganizationRunnerGroup, 0, len(groupsRes.Collected))
+	for _, group := range groupsRes.Collected {
+		groupRunners, err := pagination.NewMapper(c.Client.Client().Actions.ListRunnerGroupRunners, nil, runnersMapper).Sync(c.Context, org.Name(), group.GetID())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		collected := &ghcollected.OrganizationRunnerGroup{
+			Name:                     group.GetName(),
+			Visibility:               group.GetVisibility(),
+			Default:                  group.GetDefault(),
+			AllowsPublicRepositories: group.GetAllowsPublicRepositories(),
+			RestrictedToWorkflows:    group.GetRestrictedToWorkflows(),
+			Runners:                  make([]string, 0, len(groupRunners.Collected)),
+		}
+		for _, runner := range groupRunners.Collected {
+			groupOfRunner[runner.GetID()] = group.GetName()
+			collected.Runners = append(collected.Runners, runner.GetName())
+		}
+		groups = append(groups, collected)
+	}
+
+	runners := make([]*ghcollected.OrganizationRunner, 0, len(res.Collected))
+	for _, runner := range res.Collected {
+		collected := &ghcollected.OrganizationRunner{
+			Name:        runner.GetName(),
+			OS:          runner.GetOS(),
+			Status:      runner.GetStatus(),
+			Labels:      make([]string, 0, len(runner.Labels)),
+			RunnerGroup: groupOfRunner[runner.GetID()],
+		}
+		for _, label := range runner.Labels {
+			collected.Labels = append(collected.Labels, label.GetName())
+		}
+		runners = append(runners, collected)
+	}
+
+	return runners, groups, nil
+}
+
+// required workflows are only available for enterprise organizations and visible to organization owners
+func (c *organizationCollector) collectOrgRequiredWorkflows(org *ghcollected.ExtendedOrg) ([]*ghcollected.RequiredWorkflow, error) {
+	if !org.IsEnterprise() {
+		return nil, nil
+	}
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read organization required workflows", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	mapper := func(rw *github.OrgRequiredWorkflows) []*github.OrgRequiredWorkflow {
+		if rw == nil {
+			return []*github.OrgRequiredWorkflow{}
+		}
+		return rw.RequiredWorkflows
+	}
+	res, err := pagination.NewMapper(c.Client.Client().Actions.ListOrgRequiredWorkflows, nil, mapper).Sync(c.Context, org.Name())
+	if err != nil {
+		if res.Resp != nil && res.Resp.Response.StatusCode == http.StatusNotFound {
+			// not supported by the server (e.g. older GitHub Enterprise Server versions)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	workflows := make([]*ghcollected.RequiredWorkflow, 0, len(res.Collected))
+	for _, w := range res.Collected {
+		workflow := &ghcollected.RequiredWorkflow{
+			Name:       w.GetName(),
+			Path:       w.GetPath(),
+			Repository: w.GetRepository().GetFullName(),
+			Ref:        w.GetRef(),
+			State:      w.GetState(),
+			Scope:      w.GetScope(),
+		}
+
+		if workflow.Scope == "selected" {
+			repoMapper := func(r *github.RequiredWorkflowSelectedRepos) []*github.Repository {
+				if r == nil {
+					return []*github.Repository{}
+				}
+				return r.Repositories
+			}
+			repos, err := pagination.NewMapper(c.Client.Client().Actions.ListRequiredWorkflowSelectedRepos, nil, repoMapper).
+				Sync(c.Context, org.Name(), w.GetID())
+			if err != nil {
+				return nil, err
+			}
+			for _, r := range repos.Collected {
+				workflow.SelectedRepositories = append(workflow.SelectedRepositories, r.GetName())
+			}
+		}
+
+		workflows = append(workflows, workflow)
+	}
+
+	return workflows, nil
+}
+
+// blocked users and member privileges are only visible to organization owners
+func (c *organizationCollector) collectOrgMemberPrivileges(org *ghcollected.ExtendedOrg) (*ghcollected.MemberPrivileges, error) {
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read organization blocked users and member privileges", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	blocked, err := pagination.New[*github.User](c.Client.Client().Organizations.ListBlockedUsers, nil).Sync(c.Context, org.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ghcollected.MemberPrivileges{
+		BlockedUsersCount:                  len(blocked.Collected),
+		MembersCanCreatePublicRepositories: org.MembersCanCreatePublicRepos,
+		MembersCanForkPrivateRepositories:  org.MembersCanForkPrivateRepos,
+		MembersCanCreatePublicPages:        org.MembersCanCreatePublicPages,
+	}, nil
+}
+
+// SSH certificate authorities are only visible to organization owners
+func (c *organizationCollector) collectOrgSSHCertificateAuthorities(org *ghcollected.ExtendedOrg) ([]*ghcollected.SSHCertificateAuthority, error) {
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read organization SSH certificate authorities", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	return c.Client.GetOrganizationSSHCertificateAuthorities(org.Name())
 }
 
 func (c *organizationCollector) collectOrgWebhooks(org string) ([]*github.Hook, error) {
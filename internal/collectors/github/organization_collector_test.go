@@ -0,0 +1,130 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectOrgRulesets(t *testing.T) {
+	client := newTestClient(t, map[string]http.HandlerFunc{
+		"/orgs/org/rulesets": func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				writeJSON(w, []*github.Ruleset{{ID: 2, Name: "second"}})
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v3/orgs/org/rulesets?page=2>; rel="next"`, r.Host))
+			writeJSON(w, []*github.Ruleset{{ID: 1, Name: "first"}})
+		},
+		"/orgs/org/rulesets/": func(w http.ResponseWriter, r *http.Request) {
+			id, _ := strconv.ParseInt(path.Base(r.URL.Path), 10, 64)
+			writeJSON(w, &github.Ruleset{ID: id, Name: "full", Enforcement: "active"})
+		},
+	})
+	c := NewOrganizationCollector(context.Background(), client).(*organizationCollector)
+
+	owner := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleOwner)
+	var rulesets []*github.Ruleset
+	var err error
+	missing := runCollection(&c.BaseCollector, func() {
+		rulesets, err = c.collectOrgRulesets(&owner)
+	})
+	require.Nil(t, err)
+	require.Empty(t, missing)
+	require.Len(t, rulesets, 2, "expecting the rulesets of every page")
+	for i, ruleset := range rulesets {
+		require.Equal(t, int64(i+1), ruleset.ID)
+		require.Equal(t, "active", ruleset.Enforcement, "expecting the full ruleset")
+	}
+
+	member := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleMember)
+	missing = runCollection(&c.BaseCollector, func() {
+		rulesets, err = c.collectOrgRulesets(&member)
+	})
+	require.Nil(t, err)
+	require.Nil(t, rulesets)
+	require.Len(t, missing, 1)
+}
+
+func TestCollectOrgRulesetsNotAvailable(t *testing.T) {
+	client := newTestClient(t, map[string]http.HandlerFunc{
+		"/orgs/org/rulesets": respondStatus(http.StatusNotFound),
+	})
+	c := NewOrganizationCollector(context.Background(), client).(*organizationCollector)
+
+	owner := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleOwner)
+	var rulesets []*github.Ruleset
+	var err error
+	runCollection(&c.BaseCollector, func() {
+		rulesets, err = c.collectOrgRulesets(&owner)
+	})
+	require.Nil(t, err)
+	require.Nil(t, rulesets)
+}
+
+func TestCollectOrgInvitations(t *testing.T) {
+	createdAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newTestClient(t, map[string]http.HandlerFunc{
+		"/orgs/org/invitations": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, []*github.Invitation{
+				{Login: github.String("octocat"), Role: github.String("direct_member"), CreatedAt: &github.Timestamp{Time: createdAt}},
+				{Email: github.String("octocat@example.com"), Role: github.String("admin"), Inviter: &github.User{Login: github.String("admin")}},
+			})
+		},
+	})
+	c := NewOrganizationCollector(context.Background(), client).(*organizationCollector)
+
+	owner := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleOwner)
+	var invitations []*ghcollected.OrganizationInvitation
+	var err error
+	runCollection(&c.BaseCollector, func() {
+		invitations, err = c.collectOrgInvitations(&owner)
+	})
+	require.Nil(t, err)
+	require.Equal(t, []*ghcollected.OrganizationInvitation{
+		{Invitee: "octocat", Role: "direct_member", CreatedAt: github.Int(int(createdAt.UnixNano()))},
+		{Invitee: "octocat@example.com", Inviter: "admin", Role: "admin"},
+	}, invitations, "expecting no creation time when it is not reported")
+}
+
+func TestCollectOrgPackagesUnsupportedTypes(t *testing.T) {
+	supported := map[string]bool{"npm": true}
+	client := newTestClient(t, map[string]http.HandlerFunc{
+		"/orgs/org/packages": func(w http.ResponseWriter, r *http.Request) {
+			packageType := r.URL.Query().Get("package_type")
+			if !supported[packageType] {
+				respondStatus(http.StatusNotFound)(w, r)
+				return
+			}
+			writeJSON(w, []*github.Package{{Name: github.String("lib"), PackageType: github.String(packageType)}})
+		},
+	})
+	c := NewOrganizationCollector(context.Background(), client).(*organizationCollector)
+
+	org := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleOwner)
+	var packages []*ghcollected.OrganizationPackage
+	var err error
+	runCollection(&c.BaseCollector, func() {
+		packages, err = c.collectOrgPackages(&org)
+	})
+	require.Nil(t, err)
+	require.Len(t, packages, 1, "expecting the packages of the supported types")
+	require.Equal(t, "npm", packages[0].PackageType)
+
+	// none of the types is supported
+	supported = map[string]bool{}
+	runCollection(&c.BaseCollector, func() {
+		packages, err = c.collectOrgPackages(&org)
+	})
+	require.Nil(t, err)
+	require.Nil(t, packages)
+}
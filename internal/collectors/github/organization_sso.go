@@ -0,0 +1,113 @@
+package github
+
+import (
+	"sort"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/shurcooL/githubv4"
+)
+
+type orgExternalIdentitiesQuery struct {
+	Organization struct {
+		SamlIdentityProvider struct {
+			ExternalIdentities struct {
+				PageInfo ghcollected.GitHubQLPageInfo
+				Nodes    []struct {
+					User *struct {
+						Login string
+					}
+				}
+			} `graphql:"externalIdentities(first: 100, after: $cursor)"`
+		}
+	} `graphql:"organization(login: $login)"`
+}
+
+type orgMembersQuery struct {
+	Organization struct {
+		MembersWithRole struct {
+			PageInfo ghcollected.GitHubQLPageInfo
+			Nodes    []struct {
+				Login string
+			}
+		} `graphql:"membersWithRole(first: 100, after: $cursor)"`
+	} `graphql:"organization(login: $login)"`
+}
+
+// the linked SSO identities are only available for SAML SSO organizations and visible to organization owners
+func (c *organizationCollector) collectOrgMembersWithoutSSO(org *ghcollected.ExtendedOrg, samlEnabled *bool) ([]string, error) {
+	if samlEnabled == nil || !*samlEnabled {
+		return nil, nil
+	}
+	if org.Role != permissions.OrgRoleOwner {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"Cannot read the SSO identities linked by the organization members", namespace.Organization)
+		c.IssueMissingPermissions(perm)
+		return nil, nil
+	}
+
+	linked, err := c.collectOrgLinkedLogins(org.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	variables := map[string]interface{}{
+		"login":  githubv4.String(org.Name()),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	unlinked := []string{}
+	for {
+		query := orgMembersQuery{}
+		if err := c.Client.GraphQLClient().Query(c.Context, &query, variables); err != nil {
+			return nil, err
+		}
+
+		for _, member := range query.Organization.MembersWithRole.Nodes {
+			if !linked[member.Login] {
+				unlinked = append(unlinked, member.Login)
+			}
+		}
+
+		if !query.Organization.MembersWithRole.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = query.Organization.MembersWithRole.PageInfo.EndCursor
+	}
+
+	sort.Strings(unlinked)
+	return unlinked, nil
+}
+
+// collectOrgLinkedLogins returns the members that linked their SSO identity (identities that were provisioned
+// but not linked yet have no user)
+func (c *organizationCollector) collectOrgLinkedLogins(org string) (map[string]bool, error) {
+	variables := map[string]interface{}{
+		"login":  githubv4.String(org),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	linked := make(map[string]bool)
+	for {
+		query := orgExternalIdentitiesQuery{}
+		if err := c.Client.GraphQLClient().Query(c.Context, &query, variables); err != nil {
+			return nil, err
+		}
+
+		identities := query.Organization.SamlIdentityProvider.ExternalIdentities
+		for _, identity := range identities.Nodes {
+			if identity.User != nil {
+				linked[identity.User.Login] = true
+			}
+		}
+
+		if !identities.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = identities.PageInfo.EndCursor
+	}
+
+	return linked, nil
+}
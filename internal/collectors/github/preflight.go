@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"net/http"
+
+	ghclient "github.com/Legit-Labs/legitify/internal/clients/github"
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/google/go-github/v53/github"
+)
+
+// probe is a representative API call of a collector: when it is denied, the collector misses the permission
+type probe struct {
+	permission string
+	namespace  namespace.Namespace
+	effect     string
+	// deniedStatuses are the response statuses that mean the permission is missing
+	// (GitHub responds with not found rather than forbidden to some of the calls)
+	deniedStatuses []int
+	call           func(ctx context.Context, client *github.Client, org string, repo *github.Repository) (*github.Response, error)
+}
+
+var (
+	forbidden         = []int{http.StatusUnauthorized, http.StatusForbidden}
+	forbiddenNotFound = []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound}
+	firstItem         = github.ListOptions{PerPage: 1}
+)
+
+var organizationProbes = []probe{
+	{
+		permission: permissions.OrgHookAdmin, namespace: namespace.Organization, effect: "Cannot collect webhook for organization", deniedStatuses: forbiddenNotFound,
+		call: func(ctx context.Context, client *github.Client, org string, _ *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Organizations.ListHooks(ctx, org, &firstItem)
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.OrgAdmin, namespace: namespace.Organization, effect: "Cannot read organization secrets", deniedStatuses: forbiddenNotFound,
+		call: func(ctx context.Context, client *github.Client, org string, _ *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Actions.ListOrgSecrets(ctx, org, &firstItem)
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.PackagesRead, namespace: namespace.Organization, effect: "Cannot read the packages of the organization", deniedStatuses: forbidden,
+		call: func(ctx context.Context, client *github.Client, org string, _ *github.Repository) (*github.Response, error) {
+			opts := &github.PackageListOptions{PackageType: github.String("container"), ListOptions: firstItem}
+			_, resp, err := client.Organizations.ListPackages(ctx, org, opts)
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.OrgAdmin, namespace: namespace.Actions, effect: orgActionPermEffect, deniedStatuses: forbiddenNotFound,
+		call: func(ctx context.Context, client *github.Client, org string, _ *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Organizations.GetActionsPermissions(ctx, org)
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.OrgAdmin, namespace: namespace.Member, effect: "Cannot read the two-factor authentication status of the organization members", deniedStatuses: forbidden,
+		call: func(ctx context.Context, client *github.Client, org string, _ *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Organizations.ListMembers(ctx, org, &github.ListMembersOptions{Filter: "2fa_disabled", ListOptions: firstItem})
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.OrgAdmin, namespace: namespace.RunnerGroup, effect: "Cannot read organization runner groups", deniedStatuses: forbiddenNotFound,
+		call: func(ctx context.Context, client *github.Client, org string, _ *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Actions.ListOrganizationRunnerGroups(ctx, org, &github.ListOrgRunnerGroupOptions{ListOptions: firstItem})
+			return resp, err
+		},
+	},
+}
+
+var repositoryProbes = []probe{
+	{
+		// disabled vulnerability alerts are not found as well, so only a forbidden response is a missing permission
+		permission: permissions.RepoAdmin, namespace: namespace.Repository, effect: "Cannot read repository vulnerability alerts", deniedStatuses: forbidden,
+		call: func(ctx context.Context, client *github.Client, org string, repo *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Repositories.GetVulnerabilityAlerts(ctx, org, repo.GetName())
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.RepoHookRead, namespace: namespace.Repository, effect: "Cannot read repository webhooks", deniedStatuses: forbiddenNotFound,
+		call: func(ctx context.Context, client *github.Client, org string, repo *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Repositories.ListHooks(ctx, org, repo.GetName(), &firstItem)
+			return resp, err
+		},
+	},
+	{
+		permission: permissions.RepoAdmin, namespace: namespace.Repository, effect: "Cannot read repository collaborators", deniedStatuses: forbidden,
+		call: func(ctx context.Context, client *github.Client, org string, repo *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Repositories.ListCollaborators(ctx, org, repo.GetName(), &github.ListCollaboratorsOptions{ListOptions: firstItem})
+			return resp, err
+		},
+	},
+	{
+		// an unprotected branch is not found as well, so only a forbidden response is a missing permission
+		permission: permissions.RepoAdmin, namespace: namespace.Repository, effect: "Cannot read repository branch protection", deniedStatuses: forbidden,
+		call: func(ctx context.Context, client *github.Client, org string, repo *github.Repository) (*github.Response, error) {
+			_, resp, err := client.Repositories.GetBranchProtection(ctx, org, repo.GetName(), repo.GetDefaultBranch())
+			return resp, err
+		},
+	},
+}
+
+// Preflight probes a representative set of the API calls the collectors issue for the organization (and one of its repositories),
+// and returns the missing permissions the collectors would run into, without collecting anything.
+func Preflight(ctx context.Context, client *ghclient.Client, org ghcollected.ExtendedOrg, namespaces []namespace.Namespace) []collectors.MissingPermission {
+	requested := make(map[namespace.Namespace]bool, len(namespaces))
+	for _, ns := range namespaces {
+		requested[ns] = true
+	}
+
+	var missing []collectors.MissingPermission
+	if org.Role != permissions.OrgRoleOwner {
+		missing = append(missing, collectors.NewMissingPermission(permissions.OrgAdmin, org.Name(),
+			"The organization settings cannot be collected since the token user is not an owner of the organization", namespace.Organization))
+	}
+
+	for _, p := range organizationProbes {
+		if requested[p.namespace] && p.denied(ctx, client.Client(), org.Name(), nil) {
+			missing = append(missing, collectors.NewMissingPermission(p.permission, org.Name(), p.effect, p.namespace))
+		}
+	}
+
+	if !requested[namespace.Repository] {
+		return missing
+	}
+	repos, _, err := client.Client().Repositories.ListByOrg(ctx, org.Name(), &github.RepositoryListByOrgOptions{ListOptions: firstItem})
+	if err != nil || len(repos) == 0 {
+		return missing
+	}
+	repo := repos[0]
+	for _, p := range repositoryProbes {
+		if p.denied(ctx, client.Client(), org.Name(), repo) {
+			missing = append(missing, collectors.NewMissingPermission(p.permission, collectors.FullRepoName(org.Name(), repo.GetName()), p.effect, p.namespace))
+		}
+	}
+
+	return missing
+}
+
+func (p probe) denied(ctx context.Context, client *github.Client, org string, repo *github.Repository) bool {
+	resp, err := p.call(ctx, client, org, repo)
+	if err == nil || resp == nil {
+		return false
+	}
+	for _, status := range p.deniedStatuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []interface{}{})
+	}
+	client := newTestClient(t, map[string]http.HandlerFunc{
+		"/orgs/org/hooks":                 respondStatus(http.StatusNotFound),
+		"/orgs/org/actions/secrets":       func(w http.ResponseWriter, r *http.Request) { writeJSON(w, map[string]interface{}{"total_count": 0}) },
+		"/orgs/org/packages":              respondStatus(http.StatusNotFound),
+		"/orgs/org/actions/permissions":   respondStatus(http.StatusForbidden),
+		"/orgs/org/members":               ok,
+		"/orgs/org/actions/runner-groups": func(w http.ResponseWriter, r *http.Request) { writeJSON(w, map[string]interface{}{"total_count": 0}) },
+		"/orgs/org/repos": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, []map[string]interface{}{{"name": "repo", "default_branch": "main"}})
+		},
+		"/repos/org/repo/vulnerability-alerts":     respondStatus(http.StatusNotFound),
+		"/repos/org/repo/hooks":                    respondStatus(http.StatusNotFound),
+		"/repos/org/repo/collaborators":            respondStatus(http.StatusNotFound),
+		"/repos/org/repo/branches/main/protection": respondStatus(http.StatusForbidden),
+	})
+	org := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleMember)
+
+	missing := Preflight(context.Background(), client, org, namespace.All)
+	require.Equal(t, []collectors.MissingPermission{
+		collectors.NewMissingPermission(permissions.OrgAdmin, "org",
+			"The organization settings cannot be collected since the token user is not an owner of the organization", namespace.Organization),
+		collectors.NewMissingPermission(permissions.OrgHookAdmin, "org", "Cannot collect webhook for organization", namespace.Organization),
+		collectors.NewMissingPermission(permissions.OrgAdmin, "org", orgActionPermEffect, namespace.Actions),
+		collectors.NewMissingPermission(permissions.RepoHookRead, "org/repo", "Cannot read repository webhooks", namespace.Repository),
+		collectors.NewMissingPermission(permissions.RepoAdmin, "org/repo", "Cannot read repository branch protection", namespace.Repository),
+	}, missing, "expecting a not found response to be a missing permission only for the calls that hide forbidden resources")
+
+	missing = Preflight(context.Background(), client, org, []namespace.Namespace{namespace.Actions})
+	require.Equal(t, []collectors.MissingPermission{
+		collectors.NewMissingPermission(permissions.OrgAdmin, "org",
+			"The organization settings cannot be collected since the token user is not an owner of the organization", namespace.Organization),
+		collectors.NewMissingPermission(permissions.OrgAdmin, "org", orgActionPermEffect, namespace.Actions),
+	}, missing, "expecting only the requested namespaces to be probed")
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/clients/github/types"
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+)
+
+// the suffixes of the release assets that sign other assets, and of the in-toto provenance assets
+var (
+	signatureAssetSuffixes  = []string{".sig", ".asc", ".pem", ".crt", ".sigstore", ".sigstore.json", ".bundle", ".minisig"}
+	provenanceAssetSuffixes = []string{".intoto.jsonl", ".intoto.json", ".provenance", ".provenance.json"}
+)
+
+func latestRelease(release *types.Release) *ghcollected.LatestRelease {
+	latest := &ghcollected.LatestRelease{
+		AssetsCount:      len(release.Assets),
+		SignatureAssets:  []string{},
+		ProvenanceAssets: []string{},
+	}
+	if release.TagName != nil {
+		latest.TagName = *release.TagName
+	}
+	if release.HTMLURL != nil {
+		latest.Link = *release.HTMLURL
+	}
+	if release.PublishedAt != nil {
+		latest.PublishedAt = release.PublishedAt.Format(time.RFC3339)
+	}
+
+	for _, asset := range release.Assets {
+		if asset.Name == nil {
+			continue
+		}
+		name := strings.ToLower(*asset.Name)
+		if hasAnySuffix(name, provenanceAssetSuffixes) {
+			latest.ProvenanceAssets = append(latest.ProvenanceAssets, *asset.Name)
+		} else if hasAnySuffix(name, signatureAssetSuffixes) {
+			latest.SignatureAssets = append(latest.SignatureAssets, *asset.Name)
+		}
+	}
+
+	return latest
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/Legit-Labs/legitify/internal/collectors"
 	"github.com/Legit-Labs/legitify/internal/common/types"
@@ -9,12 +10,14 @@ import (
 	"github.com/Legit-Labs/legitify/internal/scorecard"
 	"log"
 	"net/http"
+	"sync/atomic"
 
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
+	"github.com/Legit-Labs/legitify/internal/common/data_groups"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 
 	ghclient "github.com/Legit-Labs/legitify/internal/clients/github"
 	"github.com/Legit-Labs/legitify/internal/clients/github/pagination"
+	ghtypes "github.com/Legit-Labs/legitify/internal/clients/github/types"
 	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/utils"
@@ -27,6 +30,14 @@ type repositoryCollector struct {
 	Client           *ghclient.Client
 	Context          context.Context
 	scorecardEnabled bool
+	scorecard        *scorecard.Runner
+	actionsStorage   bool
+	runnersEnabled   bool
+	workflowContents bool
+	dataGroups       []data_groups.DataGroup
+	maxRepositories  int
+	integrations     *integrationsCache
+	securityChecks   bool
 }
 
 func NewRepositoryCollector(ctx context.Context, client *ghclient.Client) collectors.Collector {
@@ -35,6 +46,14 @@ func NewRepositoryCollector(ctx context.Context, client *ghclient.Client) collec
 		Client:           client,
 		Context:          ctx,
 		scorecardEnabled: context_utils.GetScorecardEnabled(ctx),
+		scorecard:        scorecard.NewRunner(client.Client().Client().Transport, context_utils.GetScorecardConcurrency(ctx)),
+		actionsStorage:   context_utils.GetActionsStorageEnabled(ctx),
+		runnersEnabled:   !context_utils.GetSkipSelfHostedRunners(ctx),
+		workflowContents: context_utils.GetWorkflowContentsEnabled(ctx),
+		dataGroups:       context_utils.GetDataGroups(ctx),
+		maxRepositories:  context_utils.GetMaxRepositories(ctx),
+		integrations:     newIntegrationsCache(),
+		securityChecks:   len(context_utils.GetConfigList(ctx, "security_checks")) > 0,
 	}
 	return c
 }
@@ -53,11 +72,11 @@ func (rc *repositoryCollector) CollectTotalEntities() int {
 		return len(repositories)
 	}
 
-	gw := group_waiter.New()
+	gw := rc.NewGroupWaiter()
 	orgs, err := rc.Client.CollectOrganizations()
 
 	if err != nil {
-		log.Printf("failed to collect organization %s", err)
+		rc.FailCollection(fmt.Errorf("failed to collect organization: %v", err))
 		return 0
 	}
 
@@ -77,7 +96,11 @@ func (rc *repositoryCollector) CollectTotalEntities() int {
 				return
 			}
 
-			totalCount += int32(totalCountQuery.Organization.Repositories.TotalCount)
+			count := int32(totalCountQuery.Organization.Repositories.TotalCount)
+			if rc.maxRepositories > 0 && count > int32(rc.maxRepositories) {
+				count = int32(rc.maxRepositories)
+			}
+			atomic.AddInt32(&totalCount, count)
 		})
 	}
 	gw.Wait()
@@ -95,20 +118,20 @@ func (rc *repositoryCollector) Collect() collectors.SubCollectorChannels {
 	return rc.collectAll()
 }
 
+// the GraphQL type name of a repository owner that is an organization (rather than a user)
+const repositoryOwnerOrganization = "Organization"
+
 func (rc *repositoryCollector) collectSpecific(repositories []types.RepositoryWithOwner) collectors.SubCollectorChannels {
 	type specificRepoQuery struct {
 		RepositoryOwner struct {
-			Organization struct {
-				ViewerCanAdminister *bool
-			} `graphql:"... on Organization"`
-
+			Typename   githubv4.String `graphql:"__typename"`
 			Login      githubv4.String
 			Repository ghcollected.GitHubQLRepository `graphql:"repository(name: $name)"`
 		} `graphql:"repositoryOwner(login: $login)"`
 	}
 
 	return rc.WrappedCollection(func() {
-		gw := group_waiter.New()
+		gw := rc.NewGroupWaiter()
 		for _, r := range repositories {
 			repo := r
 			gw.Do(func() {
@@ -125,15 +148,16 @@ func (rc *repositoryCollector) collectSpecific(repositories []types.RepositoryWi
 				}
 
 				var collectionContext *repositoryContext
+				var orgAllowsPrivateForks *bool
 
-				if query.RepositoryOwner.Organization.ViewerCanAdminister != nil {
-
+				if query.RepositoryOwner.Typename == repositoryOwnerOrganization {
 					org, err := rc.Client.Organization(repo.Owner)
 					if err != nil {
 						log.Println(err.Error())
 						return
 					}
 
+					orgAllowsPrivateForks = org.MembersCanForkPrivateRepos
 					hasBp := hasBranchProtection(org, query.RepositoryOwner.Repository.IsPrivate)
 					collectionContext = newRepositoryContext([]permissions.Role{org.Role, query.RepositoryOwner.Repository.ViewerPermission},
 						hasBp, org.IsEnterprise(), false, false)
@@ -143,7 +167,7 @@ func (rc *repositoryCollector) collectSpecific(repositories []types.RepositoryWi
 						hasBp, false, false, false)
 				}
 
-				rc.collectRepository(&query.RepositoryOwner.Repository, repo.Owner, collectionContext)
+				rc.collectRepository(&query.RepositoryOwner.Repository, repo.Owner, orgAllowsPrivateForks, collectionContext)
 			})
 		}
 
@@ -156,11 +180,11 @@ func (rc *repositoryCollector) collectAll() collectors.SubCollectorChannels {
 		orgs, err := rc.Client.CollectOrganizations()
 
 		if err != nil {
-			log.Printf("failed to collect organizations %s", err)
+			rc.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 			return
 		}
 
-		gw := group_waiter.New()
+		gw := rc.NewGroupWaiter()
 		for _, org := range orgs {
 			localOrg := org
 			gw.Do(func() {
@@ -189,7 +213,8 @@ func (rc *repositoryCollector) collectRepositories(org *ghcollected.ExtendedOrg)
 		"repositoryCursor": (*githubv4.String)(nil),
 	}
 
-	gw := group_waiter.New()
+	collected := 0
+	gw := rc.NewGroupWaiter()
 	defer gw.Wait()
 	for {
 		query := repoQuery{}
@@ -199,15 +224,20 @@ func (rc *repositoryCollector) collectRepositories(org *ghcollected.ExtendedOrg)
 			return err
 		}
 
+		nodes := query.Organization.Repositories.Nodes
+		if rc.maxRepositories > 0 && collected+len(nodes) > rc.maxRepositories {
+			nodes = nodes[:rc.maxRepositories-collected]
+		}
+		collected += len(nodes)
+
 		gw.Do(func() {
-			nodes := query.Organization.Repositories.Nodes
-			extraGw := group_waiter.New()
+			extraGw := rc.NewGroupWaiter()
 			for i := range nodes {
 				node := &(nodes[i])
 				extraGw.Do(func() {
 					collectionContext := newRepositoryContext([]permissions.Role{org.Role, node.ViewerPermission},
 						hasBranchProtection(org, node.IsPrivate), org.IsEnterprise(), false, false)
-					rc.collectRepository(node, org.Name(), collectionContext)
+					rc.collectRepository(node, org.Name(), org.MembersCanForkPrivateRepos, collectionContext)
 				})
 			}
 			extraGw.Wait()
@@ -216,6 +246,10 @@ func (rc *repositoryCollector) collectRepositories(org *ghcollected.ExtendedOrg)
 		if !query.Organization.Repositories.PageInfo.HasNextPage {
 			break
 		}
+		if rc.maxRepositories > 0 && collected >= rc.maxRepositories {
+			// a sample of the organization was requested (--max-repos)
+			break
+		}
 
 		variables["repositoryCursor"] = query.Organization.Repositories.PageInfo.EndCursor
 	}
@@ -223,8 +257,19 @@ func (rc *repositoryCollector) collectRepositories(org *ghcollected.ExtendedOrg)
 	return nil
 }
 
-func (rc *repositoryCollector) collectRepository(repository *ghcollected.GitHubQLRepository, login string, collectionContext *repositoryContext) {
+func (rc *repositoryCollector) collectRepository(repository *ghcollected.GitHubQLRepository, login string,
+	orgAllowsPrivateForks *bool, collectionContext *repositoryContext) {
+	if repository.IsDisabled {
+		// disabled repositories (e.g. suspended due to TOS) fail most of the REST endpoints;
+		// report them as-is and let the analyzer skip their policies.
+		collectionContext.SetIsDisabled(true)
+		rc.CollectDataWithContext(ghcollected.Repository{Repository: repository}, repository.Url, collectionContext)
+		rc.CollectionChangeByOne()
+		return
+	}
+
 	repo := rc.collectExtraData(login, repository, collectionContext.isBranchProtectionSupported)
+	repo = withPrivateForking(repo, orgAllowsPrivateForks)
 	entityName := collectors.FullRepoName(login, repo.Repository.Name)
 	missingPermissions := rc.checkMissingPermissions(repo, entityName, collectionContext)
 	rc.IssueMissingPermissions(missingPermissions...)
@@ -242,42 +287,117 @@ func (rc *repositoryCollector) collectExtraData(login string,
 		Repository: repository,
 	}
 
-	repo = rc.withVulnerabilityAlerts(repo, login)
-	repo = rc.withRepositoryHooks(repo, login)
-	repo = rc.withRepoCollaborators(repo, login)
-	repo = rc.withActionsSettings(repo, login)
-	repo, err = rc.withSecrets(repo, login)
-	if err != nil {
-		log.Printf("failed to collect repository secrets for %s: %s", repo.Repository.Name, err)
+	if rc.collects(data_groups.VulnerabilityAlerts) {
+		repo = rc.withVulnerabilityAlerts(repo, login)
+		repo = rc.withAutomatedSecurityFixes(repo, login)
 	}
-
-	repo, err = rc.withDependencyGraphManifestsCount(repo, login)
-	if err != nil {
-		log.Printf("error getting repository dependency manifests for %s: %s", collectors.FullRepoName(login, repo.Repository.Name), err)
+	if rc.collects(data_groups.Hooks) {
+		repo = rc.withRepositoryHooks(repo, login)
+	}
+	if rc.collects(data_groups.DeployKeys) {
+		repo = rc.withDeployKeys(repo, login)
+	}
+	if rc.collects(data_groups.Pages) {
+		repo = rc.withPagesConfig(repo, login)
+	}
+	if rc.collects(data_groups.Environments) {
+		repo = rc.withEnvironments(repo, login)
+	}
+	if rc.collects(data_groups.Collaborators) {
+		repo = rc.withRepoCollaborators(repo, login)
+		repo = rc.withRepoTeams(repo, login)
+		repo = rc.withEffectivePermissions(repo, login)
+	}
+	if rc.collects(data_groups.Actions) {
+		repo = rc.withActionsSettings(repo, login)
+		if rc.runnersEnabled {
+			repo = rc.withSelfHostedRunners(repo, login)
+		}
+		repo, err = rc.withWorkflows(repo, login)
+		if err != nil {
+			log.Printf("failed to collect the workflows of %s: %s", collectors.FullRepoName(login, repo.Repository.Name), err)
+		}
+	}
+	if rc.collects(data_groups.Integrations) {
+		repo = rc.withIntegrations(repo, login)
+	}
+	if rc.collects(data_groups.VulnerabilityReporting) {
+		repo = rc.withPrivateVulnerabilityReporting(repo, login)
+	}
+	if rc.securityChecks {
+		repo, err = rc.withDefaultBranchChecks(repo, login)
+		if err != nil {
+			log.Printf("failed to collect the default branch checks of %s: %s", collectors.FullRepoName(login, repo.Repository.Name), err)
+		}
+	}
+	if rc.actionsStorage {
+		repo = rc.withActionsStorage(repo, login)
+	}
+	if rc.collects(data_groups.Secrets) {
+		repo, err = rc.withSecrets(repo, login)
+		if err != nil {
+			log.Printf("failed to collect repository secrets for %s: %s", repo.Repository.Name, err)
+		}
 	}
 
-	repo, err = rc.withSecurityAndAnalysis(repo, login)
-	if err != nil {
-		log.Printf("failed to collect repository Security and Analysis settings for %s: %s", repo.Repository.Name, err)
+	if rc.collects(data_groups.Dependencies) {
+		repo, err = rc.withDependencyGraphManifests(repo, login)
+		if err != nil {
+			log.Printf("error getting repository dependency manifests for %s: %s", collectors.FullRepoName(login, repo.Repository.Name), err)
+		}
+		repo, err = rc.withDependencyManagementFiles(repo, login)
+		if err != nil {
+			log.Printf("error getting repository dependency management files for %s: %s", collectors.FullRepoName(login, repo.Repository.Name), err)
+		}
 	}
 
-	if isBranchProtectionSupported {
-		repo, err = rc.fixBranchProtectionInfo(repo, login)
+	if rc.collects(data_groups.Codeowners) {
+		repo, err = rc.withCodeowners(repo, login)
 		if err != nil {
-			// If we can't get branch protection info, rego will ignore it (as nil)
-			log.Printf("error getting branch protection info for %s: %s", repository.Name, err)
+			log.Printf("error getting repository CODEOWNERS file for %s: %s", collectors.FullRepoName(login, repo.Repository.Name), err)
 		}
-		repo, err = rc.withRulesSet(repo, login)
+	}
+
+	if rc.collects(data_groups.SecurityAndAnalysis) {
+		repo, err = rc.withSecurityAndAnalysis(repo, login)
 		if err != nil {
-			log.Printf("error getting rules set for %s: %s", repository.Name, err)
+			log.Printf("failed to collect repository Security and Analysis settings for %s: %s", repo.Repository.Name, err)
+		}
+	}
+
+	if rc.collects(data_groups.Releases) {
+		repo = rc.withLatestRelease(repo, login)
+	}
+
+	if isBranchProtectionSupported {
+		branchProtectionCollected, rulesetsCollected := false, false
+		if rc.collects(data_groups.BranchProtection) {
+			repo, err = rc.fixBranchProtectionInfo(repo, login)
+			if err != nil {
+				// If we can't get branch protection info, rego will ignore it (as nil)
+				log.Printf("error getting branch protection info for %s: %s", repository.Name, err)
+			} else {
+				branchProtectionCollected = !repo.NoBranchProtectionPermission
+			}
+		}
+		if rc.collects(data_groups.Rulesets) {
+			repo, err = rc.withRulesSet(repo, login)
+			if err != nil {
+				log.Printf("error getting rules set for %s: %s", repository.Name, err)
+			} else {
+				rulesetsCollected = true
+			}
 		}
+		repo = withProtectionWithoutReviews(repo)
+		repo = withRequiredSignatures(repo, branchProtectionCollected, rulesetsCollected)
+		repo = withEffectiveProtection(repo, branchProtectionCollected, rulesetsCollected)
 	} else {
 		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(login, repo.Repository.Name), orgIsFreeEffect, namespace.Repository)
 		rc.IssueMissingPermissions(perm)
 	}
 
 	if rc.scorecardEnabled {
-		scResult, err := scorecard.Calculate(rc.Context, repository.Url, repo.Repository.IsPrivate)
+		scResult, err := rc.scorecard.Calculate(rc.Context, repository.Url, repo.Repository.IsPrivate)
 		if err != nil {
 			scResult = nil
 			log.Printf("error getting scorecard result for %s: %s", repository.Name, err)
@@ -288,6 +408,11 @@ func (rc *repositoryCollector) collectExtraData(login string,
 	return repo
 }
 
+// collects reports whether the data group was selected for collection (see --collect)
+func (rc *repositoryCollector) collects(group data_groups.DataGroup) bool {
+	return data_groups.IsSelected(rc.dataGroups, group)
+}
+
 func hasBranchProtection(org *ghcollected.ExtendedOrg, isPrivateRepository bool) bool {
 	return org.IsEnterprise() || !isPrivateRepository
 }
@@ -305,11 +430,11 @@ func (rc *repositoryCollector) hasBranchProtectionForUser(userLogin string, isPr
 	return user.Plan != nil && *user.Plan.Name != "free"
 }
 
-func (rc *repositoryCollector) withDependencyGraphManifestsCount(repo ghcollected.Repository, org string) (ghcollected.Repository, error) {
+func (rc *repositoryCollector) withDependencyGraphManifests(repo ghcollected.Repository, org string) (ghcollected.Repository, error) {
 	var dependencyGraphQuery struct {
 		RepositoryOwner struct {
 			Repository struct {
-				DependencyGraphManifests *ghcollected.GitHubQLDependencyGraphManifests `json:"dependency_graph_manifests" graphql:"dependencyGraphManifests(first: 1)"`
+				DependencyGraphManifests *ghcollected.GitHubQLDependencyGraphManifests `json:"dependency_graph_manifests" graphql:"dependencyGraphManifests(first: 100)"`
 			} `graphql:"repository(name: $name)"`
 		} `graphql:"repositoryOwner(login: $login)"`
 	}
@@ -325,6 +450,9 @@ func (rc *repositoryCollector) withDependencyGraphManifestsCount(repo ghcollecte
 	}
 
 	repo.DependencyGraphManifests = dependencyGraphQuery.RepositoryOwner.Repository.DependencyGraphManifests
+	if repo.DependencyGraphManifests != nil {
+		repo.DependencyEcosystems = dependencyEcosystems(repo.DependencyGraphManifests.Nodes)
+	}
 	return repo, nil
 }
 
@@ -337,6 +465,51 @@ func (rc *repositoryCollector) withActionsSettings(repo ghcollected.Repository,
 		return repo
 	}
 	repo.ActionsTokenPermissions = settings
+
+	approval, resp, err := rc.Client.GetForkPullRequestApprovalForRepository(org, repo.Name())
+	if err != nil {
+		// not found: the setting is not available in this GitHub version
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+				"Cannot read repository fork pull request workflows approval settings", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+		}
+		return repo
+	}
+	repo.ActionsForkPRApproval = approval
+	return repo
+}
+
+// withSelfHostedRunners collects the self-hosted runners registered to the repository (skippable: an extra API call per repository)
+func (rc *repositoryCollector) withSelfHostedRunners(repo ghcollected.Repository, org string) ghcollected.Repository {
+	mapper := func(runners *github.Runners) []*github.Runner {
+		if runners == nil {
+			return []*github.Runner{}
+		}
+		return runners.Runners
+	}
+	res, err := pagination.NewMapper(rc.Client.Client().Actions.ListRunners, nil, mapper).Sync(rc.Context, org, repo.Repository.Name)
+	if err != nil {
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read repository self-hosted runners", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.SelfHostedRunners = make([]ghcollected.SelfHostedRunner, 0, len(res.Collected))
+	for _, runner := range res.Collected {
+		collected := ghcollected.SelfHostedRunner{
+			Name:   runner.GetName(),
+			OS:     runner.GetOS(),
+			Status: runner.GetStatus(),
+			Busy:   runner.GetBusy(),
+			Labels: make([]string, 0, len(runner.Labels)),
+		}
+		for _, label := range runner.Labels {
+			collected.Labels = append(collected.Labels, label.GetName())
+		}
+		repo.SelfHostedRunners = append(repo.SelfHostedRunners, collected)
+	}
 	return repo
 }
 
@@ -355,6 +528,90 @@ func (rc *repositoryCollector) withRepositoryHooks(repo ghcollected.Repository,
 	return repo
 }
 
+func (rc *repositoryCollector) withDeployKeys(repo ghcollected.Repository, org string) ghcollected.Repository {
+	res, err := pagination.New[*github.Key](rc.Client.Client().Repositories.ListKeys, nil).Sync(rc.Context, org, repo.Repository.Name)
+	if err != nil {
+		if res.Resp != nil && (res.Resp.Response.StatusCode == http.StatusNotFound || res.Resp.Response.StatusCode == http.StatusForbidden) {
+			perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+				"Cannot read repository deploy keys", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+		}
+		return repo
+	}
+
+	repo.DeployKeys = make([]ghcollected.DeployKey, 0, len(res.Collected))
+	for _, key := range res.Collected {
+		deployKey := ghcollected.DeployKey{
+			Title:    key.GetTitle(),
+			ReadOnly: key.GetReadOnly(),
+		}
+		if key.CreatedAt != nil {
+			deployKey.CreatedAt = int(key.CreatedAt.UnixNano())
+		}
+		repo.DeployKeys = append(repo.DeployKeys, deployKey)
+	}
+	return repo
+}
+
+// pagesBuildTypeWorkflow is the build type of Pages sites that are built by an actions workflow rather than from a branch
+const pagesBuildTypeWorkflow = "workflow"
+
+func (rc *repositoryCollector) withPagesConfig(repo ghcollected.Repository, org string) ghcollected.Repository {
+	pages, resp, err := rc.Client.Client().Repositories.GetPagesInfo(rc.Context, org, repo.Repository.Name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// pages is disabled for the repository
+			return repo
+		}
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read repository pages configuration", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.Pages = &ghcollected.Pages{
+		BuildType:     pages.GetBuildType(),
+		HTTPSEnforced: pages.HTTPSEnforced,
+		CNAME:         pages.GetCNAME(),
+	}
+	if pages.Source != nil {
+		repo.Pages.SourceBranch = pages.Source.GetBranch()
+		repo.Pages.SourcePath = pages.Source.GetPath()
+	}
+	if repo.Pages.BuildType != pagesBuildTypeWorkflow && repo.Pages.SourceBranch != "" {
+		branch, _, err := rc.Client.Client().Repositories.GetBranch(rc.Context, org, repo.Repository.Name, repo.Pages.SourceBranch, true)
+		if err != nil {
+			log.Printf("failed to collect the pages source branch of %s: %s", collectors.FullRepoName(org, repo.Repository.Name), err)
+			return repo
+		}
+		repo.Pages.SourceBranchProtected = github.Bool(branch.GetProtected())
+	}
+	return repo
+}
+
+func (rc *repositoryCollector) withEnvironments(repo ghcollected.Repository, org string) ghcollected.Repository {
+	mapper := func(env *github.EnvResponse) []*github.Environment {
+		if env == nil {
+			return []*github.Environment{}
+		}
+		return env.Environments
+	}
+	res, err := pagination.NewMapper(rc.Client.Client().Repositories.ListEnvironments, nil, mapper).Sync(rc.Context, org, repo.Repository.Name)
+	if err != nil {
+		if res.Resp != nil && (res.Resp.Response.StatusCode == http.StatusNotFound || res.Resp.Response.StatusCode == http.StatusForbidden) {
+			perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+				"Cannot read repository deployment environments", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+		} else {
+			log.Printf("failed to collect the deployment environments of %s: %s", collectors.FullRepoName(org, repo.Repository.Name), err)
+		}
+		return repo
+	}
+
+	repo.Environments = res.Collected
+	return repo
+}
+
 func (rc *repositoryCollector) withVulnerabilityAlerts(repo ghcollected.Repository, org string) ghcollected.Repository {
 	enabled, _, err := rc.Client.Client().Repositories.GetVulnerabilityAlerts(rc.Context, org, repo.Repository.Name)
 	if err != nil {
@@ -368,6 +625,103 @@ func (rc *repositoryCollector) withVulnerabilityAlerts(repo ghcollected.Reposito
 	return repo
 }
 
+// withAutomatedSecurityFixes collects whether Dependabot security updates (automatic fix pull requests) are enabled
+func (rc *repositoryCollector) withAutomatedSecurityFixes(repo ghcollected.Repository, org string) ghcollected.Repository {
+	fixes, resp, err := rc.Client.GetAutomatedSecurityFixes(org, repo.Repository.Name)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			// not supported for the repository (e.g. GitHub Enterprise Server without Dependabot)
+			return repo
+		}
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read repository Dependabot security updates settings", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.AutomatedSecurityFixes = fixes
+	return repo
+}
+
+func (rc *repositoryCollector) withPrivateVulnerabilityReporting(repo ghcollected.Repository, org string) ghcollected.Repository {
+	enabled, resp, err := rc.Client.IsPrivateVulnerabilityReportingEnabled(org, repo.Repository.Name)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity) {
+			// not supported for the repository (e.g. private repositories or GitHub Enterprise Server)
+			return repo
+		}
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read repository private vulnerability reporting settings", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.PrivateVulnerabilityReporting = &enabled
+	return repo
+}
+
+func (rc *repositoryCollector) withLatestRelease(repo ghcollected.Repository, org string) ghcollected.Repository {
+	release, resp, err := rc.Client.GetLatestRelease(org, repo.Repository.Name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// the repository has no releases
+			return repo
+		}
+		log.Printf("error getting the latest release of %s: %s", collectors.FullRepoName(org, repo.Repository.Name), err)
+		return repo
+	}
+
+	repo.LatestRelease = latestRelease(release)
+
+	// the assets of a release are usually built by the same workflow, so a single asset is checked for attestations
+	for _, asset := range release.Assets {
+		if asset.Digest == nil || *asset.Digest == "" {
+			continue
+		}
+		hasAttestations, _, err := rc.Client.HasAttestations(org, repo.Repository.Name, *asset.Digest)
+		if err != nil {
+			log.Printf("error getting the release attestations of %s: %s", collectors.FullRepoName(org, repo.Repository.Name), err)
+		}
+		repo.LatestRelease.HasAttestations = hasAttestations
+		break
+	}
+
+	return repo
+}
+
+// withActionsStorage collects the artifact and log retention and the cache usage of the repository actions (opt-in: extra API calls per repository).
+// A repository whose actions are unavailable (not found) has no actions storage data.
+func (rc *repositoryCollector) withActionsStorage(repo ghcollected.Repository, org string) ghcollected.Repository {
+	retention, resp, err := rc.Client.GetArtifactAndLogRetentionForRepository(org, repo.Repository.Name)
+	if err != nil {
+		rc.actionsStorageError(repo, org, "artifact and log retention", resp, err)
+	} else {
+		repo.ActionsArtifactRetention = retention
+	}
+
+	usage, resp, err := rc.Client.Client().Actions.GetCacheUsageForRepo(rc.Context, org, repo.Repository.Name)
+	if err != nil {
+		rc.actionsStorageError(repo, org, "cache usage", resp, err)
+	} else {
+		repo.ActionsCacheUsage = usage
+	}
+
+	return repo
+}
+
+func (rc *repositoryCollector) actionsStorageError(repo ghcollected.Repository, org string, setting string, resp *github.Response, err error) {
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		return
+	case resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized):
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read repository actions "+setting, namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+	default:
+		log.Printf("failed to collect the actions %s of %s: %s", setting, repo.Repository.Name, err)
+	}
+}
+
 func (rc *repositoryCollector) withRepoCollaborators(repo ghcollected.Repository, org string) ghcollected.Repository {
 	users, err := pagination.New[*github.User](rc.Client.Client().Repositories.ListCollaborators, &github.ListCollaboratorsOptions{}).Sync(rc.Context, org, repo.Repository.Name)
 	if err != nil {
@@ -377,7 +731,120 @@ func (rc *repositoryCollector) withRepoCollaborators(repo ghcollected.Repository
 		return repo
 	}
 
+	collaboratorsCount := len(users.Collected)
 	repo.Collaborators = users.Collected
+	repo.CollaboratorsCount = &collaboratorsCount
+	return repo
+}
+
+func (rc *repositoryCollector) withRepoTeams(repo ghcollected.Repository, org string) ghcollected.Repository {
+	teams, err := pagination.New[*github.Team](rc.Client.Client().Repositories.ListTeams, nil).Sync(rc.Context, org, repo.Repository.Name)
+	if err != nil {
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read repository teams", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.Teams = teams.Collected
+	return repo
+}
+
+func (rc *repositoryCollector) withEffectivePermissions(repo ghcollected.Repository, org string) ghcollected.Repository {
+	effectivePermissions, err := rc.repositoryEffectivePermissions(org, repo.Repository.Name)
+	if err != nil {
+		if !isAccessError(err) {
+			log.Printf("failed to collect the effective permissions of %s: %s", collectors.FullRepoName(org, repo.Repository.Name), err)
+			return repo
+		}
+		perm := collectors.NewMissingPermission(permissions.RepoAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot reconcile the effective permissions of the repository collaborators", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.EffectivePermissions = effectivePermissions
+	return repo
+}
+
+func (rc *repositoryCollector) withIntegrations(repo ghcollected.Repository, org string) ghcollected.Repository {
+	integrations, err := rc.repositoryIntegrations(org, repo.Name())
+	if err != nil {
+		perm := collectors.NewMissingPermission(permissions.OrgAdmin, collectors.FullRepoName(org, repo.Repository.Name),
+			"Cannot read the integrations installed on the repository", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return repo
+	}
+
+	repo.Integrations = integrations
+	return repo
+}
+
+// withDefaultBranchChecks collects the status check rollup of the default branch HEAD.
+// It is queried separately from the repositories page, since the contexts are only needed when security_checks are configured.
+func (rc *repositoryCollector) withDefaultBranchChecks(repo ghcollected.Repository, org string) (ghcollected.Repository, error) {
+	if repo.Repository.DefaultBranchRef == nil {
+		return repo, nil // no branches
+	}
+
+	var defaultBranchChecksQuery struct {
+		RepositoryOwner struct {
+			Repository struct {
+				DefaultBranchRef *struct {
+					Target *ghcollected.GitHubQLBranchTarget
+				}
+			} `graphql:"repository(name: $name)"`
+		} `graphql:"repositoryOwner(login: $login)"`
+	}
+
+	variables := map[string]interface{}{
+		"login": githubv4.String(org),
+		"name":  githubv4.String(repo.Name()),
+	}
+
+	err := rc.Client.GraphQLClient().Query(rc.Context, &defaultBranchChecksQuery, variables)
+	if err != nil {
+		return repo, err
+	}
+
+	branch := defaultBranchChecksQuery.RepositoryOwner.Repository.DefaultBranchRef
+	if branch == nil || branch.Target == nil {
+		return repo, nil // no branches
+	}
+
+	return withStatusCheckRollup(repo, branch.Target.Commit.StatusCheckRollup), nil
+}
+
+// withStatusCheckRollup flattens the check runs and the commit statuses of the rollup
+func withStatusCheckRollup(repo ghcollected.Repository, rollup *ghcollected.GitHubQLStatusCheckRollup) ghcollected.Repository {
+	checks := []ghcollected.StatusCheck{}
+	if rollup == nil {
+		repo.DefaultBranchChecks = checks // no checks reported for the HEAD commit
+		return repo
+	}
+
+	for _, node := range rollup.Contexts.Nodes {
+		switch {
+		case node.CheckRun.Name != "":
+			conclusion := node.CheckRun.Status // still running
+			if node.CheckRun.Conclusion != nil {
+				conclusion = *node.CheckRun.Conclusion
+			}
+			checks = append(checks, ghcollected.StatusCheck{
+				Name:       node.CheckRun.Name,
+				Type:       "check_run",
+				Conclusion: conclusion,
+			})
+		case node.StatusContext.Context != "":
+			checks = append(checks, ghcollected.StatusCheck{
+				Name:       node.StatusContext.Context,
+				Type:       "status",
+				Conclusion: node.StatusContext.State,
+			})
+		}
+	}
+
+	repo.DefaultBranchChecks = checks
 	return repo
 }
 
@@ -394,9 +861,203 @@ func (rc *repositoryCollector) withRulesSet(repository ghcollected.Repository, o
 	}
 
 	repository.RulesSet = rules
+	repository.MergeQueueRequired = requiresRule(rules, "merge_queue")
 	return repository, nil
 }
 
+// isActiveRule reports whether the ruleset of the rule is enforced: rulesets in "evaluate" (dry-run) mode do not block
+// anything, and the enforcement is unknown (empty) when the ruleset could not be read
+func isActiveRule(rule *ghtypes.RepositoryRule) bool {
+	return rule.Enforcement == "active"
+}
+
+// requiresRule reports whether an active ruleset has a rule of the type.
+// It is nil when this is unknown: none of the rules of the type is active, but some of their rulesets could not be read.
+func requiresRule(rules []*ghtypes.RepositoryRule, ruleType string) *bool {
+	required, unknown := false, false
+	for _, rule := range rules {
+		if rule.Type != ruleType {
+			continue
+		}
+		if isActiveRule(rule) {
+			required = true
+			break
+		}
+		unknown = unknown || rule.Enforcement == ""
+	}
+	if !required && unknown {
+		return nil
+	}
+	return &required
+}
+
+// withProtectionWithoutReviews reconciles the protection of the default branch with its required reviews:
+// a branch protection rule or an active pull request ruleset that requires no approving review does not count as protection.
+func withProtectionWithoutReviews(repository ghcollected.Repository) ghcollected.Repository {
+	if repository.Repository.DefaultBranchRef == nil {
+		return repository // no branches
+	}
+
+	protected, requiresReviews := false, false
+	if rule := repository.Repository.DefaultBranchRef.BranchProtectionRule; rule != nil {
+		protected = true
+		requiresReviews = rule.RequiredApprovingReviewCount != nil && *rule.RequiredApprovingReviewCount >= 1
+	}
+	for _, rule := range repository.RulesSet {
+		if rule.Type != "pull_request" || !isActiveRule(rule) {
+			continue
+		}
+		protected = true
+		count, err := requiredApprovingReviewCount(rule)
+		if err != nil {
+			log.Printf("failed to parse the pull request rule of %s: %v", repository.Name(), err)
+			continue
+		}
+		requiresReviews = requiresReviews || count >= 1
+	}
+
+	if protected {
+		withoutReviews := !requiresReviews
+		repository.ProtectionWithoutReviews = &withoutReviews
+	}
+	return repository
+}
+
+// withRequiredSignatures records the signed commits requirement of each protection source of the default branch,
+// so a policy can tell a ruleset requirement from a legacy branch protection one
+func withRequiredSignatures(repository ghcollected.Repository, branchProtectionCollected bool, rulesetsCollected bool) ghcollected.Repository {
+	if repository.Repository.DefaultBranchRef == nil {
+		return repository // no branches
+	}
+	if !branchProtectionCollected && !rulesetsCollected {
+		return repository
+	}
+
+	signatures := &ghcollected.RequiredSignatures{}
+	if branchProtectionCollected {
+		rule := repository.Repository.DefaultBranchRef.BranchProtectionRule
+		required := rule != nil && rule.RequiresCommitSignatures != nil && *rule.RequiresCommitSignatures
+		signatures.BranchProtection = &required
+	}
+	if rulesetsCollected {
+		signatures.Rulesets = requiresRule(repository.RulesSet, "required_signatures")
+	}
+	signatures.Effective = (signatures.BranchProtection != nil && *signatures.BranchProtection) ||
+		(signatures.Rulesets != nil && *signatures.Rulesets)
+
+	repository.RequiredSignatures = signatures
+	return repository
+}
+
+// withEffectiveProtection consolidates the protections of the default branch by the branch protection rule and the
+// active rulesets (rulesets in "evaluate" mode do not block anything), along with the sources that enforce each of them
+func withEffectiveProtection(repository ghcollected.Repository, branchProtectionCollected bool, rulesetsCollected bool) ghcollected.Repository {
+	if repository.Repository.DefaultBranchRef == nil {
+		return repository // no branches
+	}
+	if !branchProtectionCollected && !rulesetsCollected {
+		return repository
+	}
+
+	protection := &ghcollected.EffectiveProtection{Partial: !branchProtectionCollected || !rulesetsCollected}
+	if rule := repository.Repository.DefaultBranchRef.BranchProtectionRule; branchProtectionCollected && rule != nil {
+		source := ghcollected.ProtectionSource{Type: ghcollected.ProtectionSourceBranchProtection}
+		if rule.RequiredApprovingReviewCount != nil && *rule.RequiredApprovingReviewCount >= 1 {
+			protection.RequiredReviews.Enforce(source)
+			protection.RequiredApprovingReviews = *rule.RequiredApprovingReviewCount
+		}
+		if rule.RequiresCommitSignatures != nil && *rule.RequiresCommitSignatures {
+			protection.RequiredSignatures.Enforce(source)
+		}
+		if rule.AllowsForcePushes != nil && !*rule.AllowsForcePushes {
+			protection.ForcePushBlocked.Enforce(source)
+		}
+		if rule.RequiresStatusChecks != nil && *rule.RequiresStatusChecks {
+			protection.StatusChecks.Enforce(source)
+		}
+		if rule.IsAdminEnforced != nil && *rule.IsAdminEnforced {
+			protection.AdminEnforced.Enforce(source)
+		}
+	}
+
+	// a ruleset protects the branch by several rules, but is a single source of each protection
+	adminEnforcingRulesets := make(map[int64]bool)
+	for _, rule := range repository.RulesSet {
+		if !rulesetsCollected {
+			break
+		}
+		if rule.Ruleset == nil {
+			// the ruleset could not be read, so neither its enforcement nor its bypass actors are known
+			protection.Partial = true
+			continue
+		}
+		if !isActiveRule(rule) {
+			continue
+		}
+		source := ghcollected.NewRulesetProtectionSource(rule.Ruleset.GetSourceType(), rule.Ruleset.Name)
+		if !adminEnforcingRulesets[rule.Id] {
+			adminEnforcingRulesets[rule.Id] = true
+			switch {
+			case rule.Ruleset.BypassActors == nil:
+				// the bypass actors are only visible to the administrators of the ruleset
+				protection.AdminEnforced.MayEnforce(source)
+				protection.Partial = true
+			case len(rule.Ruleset.BypassActors) == 0:
+				protection.AdminEnforced.Enforce(source)
+			}
+		}
+
+		switch rule.Type {
+		case "pull_request":
+			// parse errors are logged by withProtectionWithoutReviews
+			if count, _ := requiredApprovingReviewCount(rule); count >= 1 {
+				protection.RequiredReviews.Enforce(source)
+				if count > protection.RequiredApprovingReviews {
+					protection.RequiredApprovingReviews = count
+				}
+			}
+		case "required_signatures":
+			protection.RequiredSignatures.Enforce(source)
+		case "non_fast_forward":
+			protection.ForcePushBlocked.Enforce(source)
+		case "required_status_checks":
+			protection.StatusChecks.Enforce(source)
+		}
+	}
+
+	repository.EffectiveProtection = protection
+	return repository
+}
+
+// requiredApprovingReviewCount returns the number of approving reviews a pull request rule requires
+func requiredApprovingReviewCount(rule *ghtypes.RepositoryRule) (int, error) {
+	if rule.Parameters == nil {
+		return 0, nil
+	}
+	var parameters struct {
+		RequiredApprovingReviewCount int `json:"required_approving_review_count"`
+	}
+	if err := json.Unmarshal(*rule.Parameters, &parameters); err != nil {
+		return 0, err
+	}
+	return parameters.RequiredApprovingReviewCount, nil
+}
+
+// withPrivateForking records whether a private repository can be forked: the repository allows forking
+// and its organization allows forking private repositories (or its setting is not visible)
+func withPrivateForking(repository ghcollected.Repository, orgAllowsPrivateForks *bool) ghcollected.Repository {
+	if !repository.Repository.IsPrivate {
+		return repository
+	}
+
+	repository.PrivateForking = &ghcollected.PrivateForking{
+		Organization: orgAllowsPrivateForks,
+		Repository:   repository.Repository.ForkingAllowed,
+		Effective:    repository.Repository.ForkingAllowed && (orgAllowsPrivateForks == nil || *orgAllowsPrivateForks),
+	}
+	return repository
+}
+
 func (rc *repositoryCollector) withSecrets(repository ghcollected.Repository, login string) (ghcollected.Repository, error) {
 	secrets, err := rc.Client.GetRepositorySecrets(repository.Name(), login)
 	if err != nil {
@@ -419,11 +1080,35 @@ func (rc *repositoryCollector) withSecurityAndAnalysis(repo ghcollected.Reposito
 	if err != nil {
 		return repo, err
 	}
+	if securityAndAnalysis == nil {
+		return repo, nil
+	}
 
-	repo.SecurityAndAnalysis = securityAndAnalysis
+	repo.SecurityAndAnalysis = &securityAndAnalysis.SecurityAndAnalysis
+	repo.SecretScanningFeatures = secretScanningFeatures(securityAndAnalysis)
 	return repo, nil
 }
 
+// secretScanningFeatures returns nil when none of the sub-features is reported (e.g. repositories without GHAS)
+func secretScanningFeatures(securityAndAnalysis *ghtypes.SecurityAndAnalysis) *ghcollected.SecretScanningFeatures {
+	status := func(feature *ghtypes.SecurityAndAnalysisStatus) *string {
+		if feature == nil {
+			return nil
+		}
+		return feature.Status
+	}
+
+	features := ghcollected.SecretScanningFeatures{
+		ValidityChecks:      status(securityAndAnalysis.SecretScanningValidityChecks),
+		NonProviderPatterns: status(securityAndAnalysis.SecretScanningNonProviderPatterns),
+		GenericSecrets:      status(securityAndAnalysis.SecretScanningAIDetection),
+	}
+	if features.ValidityChecks == nil && features.NonProviderPatterns == nil && features.GenericSecrets == nil {
+		return nil
+	}
+	return &features
+}
+
 // fixBranchProtectionInfo fixes the branch protection info for the repository,
 // to reflect whether there is no branch protection, or just no permission to fetch the info.
 func (rc *repositoryCollector) fixBranchProtectionInfo(repository ghcollected.Repository, org string) (ghcollected.Repository, error) {
@@ -468,6 +1153,9 @@ func (rc *repositoryCollector) checkMissingPermissions(repo ghcollected.Reposito
 		perm := collectors.NewMissingPermission(permissions.RepoAdmin, entityName, effect, namespace.Repository)
 		missingPermissions = append(missingPermissions, perm)
 	}
+	if !rc.collects(data_groups.SecurityAndAnalysis) {
+		return missingPermissions
+	}
 	if repo.SecurityAndAnalysis == nil {
 		var effect string
 		if !checkRepoAdminPermission(repoContext.roles) {
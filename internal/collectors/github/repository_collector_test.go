@@ -0,0 +1,563 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	ghtypes "github.com/Legit-Labs/legitify/internal/clients/github/types"
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepository(rule *ghcollected.GitHubQLBranchProtectionRule, rules ...*ghtypes.RepositoryRule) ghcollected.Repository {
+	return ghcollected.Repository{
+		Repository: &ghcollected.GitHubQLRepository{
+			Name: "repo",
+			DefaultBranchRef: &ghcollected.GitHubQLBranch{
+				Name:                 github.String("main"),
+				BranchProtectionRule: rule,
+			},
+		},
+		RulesSet: rules,
+	}
+}
+
+func newTestRule(ruleType string, enforcement string) *ghtypes.RepositoryRule {
+	return &ghtypes.RepositoryRule{Type: ruleType, Enforcement: enforcement}
+}
+
+func newTestPullRequestRule(enforcement string, requiredReviews int) *ghtypes.RepositoryRule {
+	parameters := json.RawMessage(fmt.Sprintf(`{"required_approving_review_count":%d}`, requiredReviews))
+	rule := newTestRule("pull_request", enforcement)
+	rule.Parameters = &parameters
+	return rule
+}
+
+func TestRequiresRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []*ghtypes.RepositoryRule
+		expected *bool
+	}{
+		{name: "no rules", expected: github.Bool(false)},
+		{name: "active rule", rules: []*ghtypes.RepositoryRule{newTestRule("merge_queue", "active")}, expected: github.Bool(true)},
+		{name: "other rule type", rules: []*ghtypes.RepositoryRule{newTestRule("deletion", "active")}, expected: github.Bool(false)},
+		{name: "evaluate mode", rules: []*ghtypes.RepositoryRule{newTestRule("merge_queue", "evaluate")}, expected: github.Bool(false)},
+		{name: "unknown enforcement", rules: []*ghtypes.RepositoryRule{newTestRule("merge_queue", "")}, expected: nil},
+		{
+			name:     "unknown enforcement with an active rule",
+			rules:    []*ghtypes.RepositoryRule{newTestRule("merge_queue", ""), newTestRule("merge_queue", "active")},
+			expected: github.Bool(true),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, requiresRule(test.rules, "merge_queue"))
+		})
+	}
+}
+
+func TestWithRequiredSignatures(t *testing.T) {
+	signed := &ghcollected.GitHubQLBranchProtectionRule{RequiresCommitSignatures: github.Bool(true)}
+	unsigned := &ghcollected.GitHubQLBranchProtectionRule{RequiresCommitSignatures: github.Bool(false)}
+
+	tests := []struct {
+		name                      string
+		repository                ghcollected.Repository
+		branchProtectionCollected bool
+		rulesetsCollected         bool
+		expected                  *ghcollected.RequiredSignatures
+	}{
+		{
+			name:                      "nothing collected",
+			repository:                newTestRepository(signed),
+			branchProtectionCollected: false,
+			rulesetsCollected:         false,
+			expected:                  nil,
+		},
+		{
+			name:                      "branch protection requires signatures",
+			repository:                newTestRepository(signed),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.RequiredSignatures{BranchProtection: github.Bool(true), Rulesets: github.Bool(false), Effective: true},
+		},
+		{
+			name:                      "active ruleset requires signatures",
+			repository:                newTestRepository(unsigned, newTestRule("required_signatures", "active")),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.RequiredSignatures{BranchProtection: github.Bool(false), Rulesets: github.Bool(true), Effective: true},
+		},
+		{
+			name:                      "ruleset in evaluate mode",
+			repository:                newTestRepository(nil, newTestRule("required_signatures", "evaluate")),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.RequiredSignatures{BranchProtection: github.Bool(false), Rulesets: github.Bool(false), Effective: false},
+		},
+		{
+			name:                      "ruleset that could not be read",
+			repository:                newTestRepository(nil, newTestRule("required_signatures", "")),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.RequiredSignatures{BranchProtection: github.Bool(false), Rulesets: nil, Effective: false},
+		},
+		{
+			name:                      "rulesets not collected",
+			repository:                newTestRepository(signed, newTestRule("required_signatures", "active")),
+			branchProtectionCollected: true,
+			rulesetsCollected:         false,
+			expected:                  &ghcollected.RequiredSignatures{BranchProtection: github.Bool(true), Rulesets: nil, Effective: true},
+		},
+		{
+			name:                      "branch protection not collected",
+			repository:                newTestRepository(signed),
+			branchProtectionCollected: false,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.RequiredSignatures{BranchProtection: nil, Rulesets: github.Bool(false), Effective: false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := withRequiredSignatures(test.repository, test.branchProtectionCollected, test.rulesetsCollected)
+			require.Equal(t, test.expected, repository.RequiredSignatures)
+		})
+	}
+
+	withoutBranches := ghcollected.Repository{Repository: &ghcollected.GitHubQLRepository{}}
+	require.Nil(t, withRequiredSignatures(withoutBranches, true, true).RequiredSignatures)
+}
+
+func TestWithProtectionWithoutReviews(t *testing.T) {
+	reviewed := &ghcollected.GitHubQLBranchProtectionRule{RequiredApprovingReviewCount: github.Int(1)}
+	unreviewed := &ghcollected.GitHubQLBranchProtectionRule{RequiredApprovingReviewCount: github.Int(0)}
+
+	tests := []struct {
+		name       string
+		repository ghcollected.Repository
+		expected   *bool
+	}{
+		{name: "not protected", repository: newTestRepository(nil), expected: nil},
+		{name: "branch protection requires reviews", repository: newTestRepository(reviewed), expected: github.Bool(false)},
+		{name: "branch protection without reviews", repository: newTestRepository(unreviewed), expected: github.Bool(true)},
+		{name: "active ruleset requires reviews", repository: newTestRepository(nil, newTestPullRequestRule("active", 2)), expected: github.Bool(false)},
+		{name: "active ruleset without reviews", repository: newTestRepository(nil, newTestPullRequestRule("active", 0)), expected: github.Bool(true)},
+		{name: "ruleset in evaluate mode", repository: newTestRepository(nil, newTestPullRequestRule("evaluate", 2)), expected: nil},
+		{name: "ruleset that could not be read", repository: newTestRepository(nil, newTestPullRequestRule("", 2)), expected: nil},
+		{
+			name:       "ruleset in evaluate mode does not add reviews",
+			repository: newTestRepository(unreviewed, newTestPullRequestRule("evaluate", 2)),
+			expected:   github.Bool(true),
+		},
+		{
+			name:       "branch protection without reviews and an active ruleset",
+			repository: newTestRepository(unreviewed, newTestPullRequestRule("active", 1)),
+			expected:   github.Bool(false),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, withProtectionWithoutReviews(test.repository).ProtectionWithoutReviews)
+		})
+	}
+}
+
+func newTestRulesetRule(ruleType string, enforcement string, bypassActors []*github.BypassActor) *ghtypes.RepositoryRule {
+	rule := newTestRule(ruleType, enforcement)
+	rule.Id = 1
+	rule.Ruleset = &github.Ruleset{
+		ID:           1,
+		Name:         "main",
+		SourceType:   github.String("Organization"),
+		Enforcement:  enforcement,
+		BypassActors: bypassActors,
+	}
+	return rule
+}
+
+func TestWithEffectiveProtection(t *testing.T) {
+	branchProtection := ghcollected.ProtectionSource{Type: ghcollected.ProtectionSourceBranchProtection}
+	ruleset := ghcollected.ProtectionSource{Type: "organization_ruleset", Name: "main"}
+	noBypass := []*github.BypassActor{}
+	withBypass := []*github.BypassActor{{ActorID: github.Int64(1), ActorType: github.String("Team")}}
+
+	tests := []struct {
+		name                      string
+		repository                ghcollected.Repository
+		branchProtectionCollected bool
+		rulesetsCollected         bool
+		expected                  *ghcollected.EffectiveProtection
+	}{
+		{
+			name:                      "nothing collected",
+			repository:                newTestRepository(&ghcollected.GitHubQLBranchProtectionRule{IsAdminEnforced: github.Bool(true)}),
+			branchProtectionCollected: false,
+			rulesetsCollected:         false,
+			expected:                  nil,
+		},
+		{
+			name: "branch protection",
+			repository: newTestRepository(&ghcollected.GitHubQLBranchProtectionRule{
+				RequiredApprovingReviewCount: github.Int(2),
+				RequiresCommitSignatures:     github.Bool(true),
+				AllowsForcePushes:            github.Bool(false),
+				RequiresStatusChecks:         github.Bool(false),
+				IsAdminEnforced:              github.Bool(true),
+			}),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected: &ghcollected.EffectiveProtection{
+				RequiredReviews:          ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{branchProtection}},
+				RequiredApprovingReviews: 2,
+				RequiredSignatures:       ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{branchProtection}},
+				ForcePushBlocked:         ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{branchProtection}},
+				AdminEnforced:            ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{branchProtection}},
+			},
+		},
+		{
+			name: "active ruleset that no one can bypass",
+			repository: newTestRepository(nil,
+				newTestRulesetRule("non_fast_forward", "active", noBypass),
+				newTestRulesetRule("required_status_checks", "active", noBypass)),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected: &ghcollected.EffectiveProtection{
+				ForcePushBlocked: ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{ruleset}},
+				StatusChecks:     ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{ruleset}},
+				AdminEnforced:    ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{ruleset}},
+			},
+		},
+		{
+			name:                      "active ruleset with bypass actors",
+			repository:                newTestRepository(nil, newTestRulesetRule("required_signatures", "active", withBypass)),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected: &ghcollected.EffectiveProtection{
+				RequiredSignatures: ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{ruleset}},
+			},
+		},
+		{
+			name:                      "active ruleset with unknown bypass actors",
+			repository:                newTestRepository(nil, newTestRulesetRule("required_signatures", "active", nil)),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected: &ghcollected.EffectiveProtection{
+				RequiredSignatures: ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{ruleset}},
+				AdminEnforced:      ghcollected.ProtectionState{Unknown: []ghcollected.ProtectionSource{ruleset}},
+				Partial:            true,
+			},
+		},
+		{
+			name:                      "ruleset in evaluate mode",
+			repository:                newTestRepository(nil, newTestRulesetRule("required_signatures", "evaluate", noBypass)),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.EffectiveProtection{},
+		},
+		{
+			name:                      "ruleset that could not be read",
+			repository:                newTestRepository(nil, newTestRule("required_signatures", "")),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected:                  &ghcollected.EffectiveProtection{Partial: true},
+		},
+		{
+			name:                      "rulesets not collected",
+			repository:                newTestRepository(nil, newTestRulesetRule("required_signatures", "active", noBypass)),
+			branchProtectionCollected: true,
+			rulesetsCollected:         false,
+			expected:                  &ghcollected.EffectiveProtection{Partial: true},
+		},
+		{
+			name: "highest number of approving reviews",
+			repository: newTestRepository(&ghcollected.GitHubQLBranchProtectionRule{RequiredApprovingReviewCount: github.Int(1)},
+				func() *ghtypes.RepositoryRule {
+					rule := newTestPullRequestRule("active", 3)
+					rule.Ruleset = newTestRulesetRule("pull_request", "active", withBypass).Ruleset
+					return rule
+				}()),
+			branchProtectionCollected: true,
+			rulesetsCollected:         true,
+			expected: &ghcollected.EffectiveProtection{
+				RequiredReviews:          ghcollected.ProtectionState{Enforced: true, Sources: []ghcollected.ProtectionSource{branchProtection, ruleset}},
+				RequiredApprovingReviews: 3,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := withEffectiveProtection(test.repository, test.branchProtectionCollected, test.rulesetsCollected)
+			require.Equal(t, test.expected, repository.EffectiveProtection)
+		})
+	}
+}
+
+func TestWithActionsStorage(t *testing.T) {
+	retentionPath := "/repos/org/repo/actions/permissions/artifact-and-log-retention"
+	cacheUsagePath := "/repos/org/repo/actions/cache/usage"
+	collected := map[string]http.HandlerFunc{
+		retentionPath: func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]interface{}{"days": 90, "maximum_allowed_days": 400})
+		},
+		cacheUsagePath: func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, map[string]interface{}{"full_name": "org/repo", "active_caches_size_in_bytes": 1024, "active_caches_count": 2})
+		},
+	}
+
+	tests := []struct {
+		name      string
+		status    int
+		collected bool
+		missing   int
+	}{
+		{name: "collected", status: http.StatusOK, collected: true},
+		{name: "actions are unavailable", status: http.StatusNotFound},
+		{name: "forbidden", status: http.StatusForbidden, missing: 2},
+		{name: "server error", status: http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handlers := collected
+			if test.status != http.StatusOK {
+				handlers = map[string]http.HandlerFunc{
+					retentionPath:  respondStatus(test.status),
+					cacheUsagePath: respondStatus(test.status),
+				}
+			}
+			rc := &repositoryCollector{
+				BaseCollector: collectors.NewBaseCollector(namespace.Repository),
+				Client:        newTestClient(t, handlers),
+				Context:       context.Background(),
+			}
+
+			repo := newTestRepository(nil)
+			missing := runCollection(&rc.BaseCollector, func() {
+				repo = rc.withActionsStorage(repo, "org")
+			})
+			require.Len(t, missing, test.missing)
+			if !test.collected {
+				require.Nil(t, repo.ActionsArtifactRetention)
+				require.Nil(t, repo.ActionsCacheUsage)
+				return
+			}
+			require.Equal(t, 90, *repo.ActionsArtifactRetention.Days)
+			require.Equal(t, int64(1024), repo.ActionsCacheUsage.ActiveCachesSizeInBytes)
+			require.Equal(t, 2, repo.ActionsCacheUsage.ActiveCachesCount)
+		})
+	}
+}
+
+func TestWithDefaultBranchChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected []ghcollected.StatusCheck
+	}{
+		{
+			name: "check runs and statuses",
+			response: `{"data":{"repositoryOwner":{"repository":{"defaultBranchRef":{"target":{"statusCheckRollup":{"contexts":{"nodes":[
+				{"name":"CodeQL","status":"COMPLETED","conclusion":"FAILURE"},
+				{"name":"semgrep","status":"IN_PROGRESS","conclusion":null},
+				{"context":"ci/jenkins","state":"SUCCESS"}
+			]}}}}}}}}`,
+			expected: []ghcollected.StatusCheck{
+				{Name: "CodeQL", Type: "check_run", Conclusion: "FAILURE"},
+				{Name: "semgrep", Type: "check_run", Conclusion: "IN_PROGRESS"},
+				{Name: "ci/jenkins", Type: "status", Conclusion: "SUCCESS"},
+			},
+		},
+		{
+			name:     "no checks reported",
+			response: `{"data":{"repositoryOwner":{"repository":{"defaultBranchRef":{"target":{"statusCheckRollup":null}}}}}}`,
+			expected: []ghcollected.StatusCheck{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rc := &repositoryCollector{
+				BaseCollector: collectors.NewBaseCollector(namespace.Repository),
+				Client: newTestGraphQLClient(t, func(w http.ResponseWriter, r *http.Request) {
+					_, _ = w.Write([]byte(test.response))
+				}, nil),
+				Context: context.Background(),
+			}
+
+			repo, err := rc.withDefaultBranchChecks(newTestRepository(nil), "org")
+			require.NoError(t, err)
+			require.Equal(t, test.expected, repo.DefaultBranchChecks)
+		})
+	}
+
+	t.Run("no branches", func(t *testing.T) {
+		rc := &repositoryCollector{
+			BaseCollector: collectors.NewBaseCollector(namespace.Repository),
+			Client: newTestGraphQLClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if strings.Contains(string(body), "statusCheckRollup") {
+					t.Error("expecting no query for a repository without branches")
+				}
+				_, _ = w.Write([]byte(`{"data":{}}`))
+			}, nil),
+			Context: context.Background(),
+		}
+
+		repo := newTestRepository(nil)
+		repo.Repository.DefaultBranchRef = nil
+		repo, err := rc.withDefaultBranchChecks(repo, "org")
+		require.NoError(t, err)
+		require.Nil(t, repo.DefaultBranchChecks)
+	})
+}
+
+func TestWithEnvironments(t *testing.T) {
+	environmentsPath := "/repos/org/repo/environments"
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		expected int
+		missing  int
+	}{
+		{
+			name: "collected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, map[string]interface{}{
+					"total_count":  1,
+					"environments": []map[string]interface{}{{"name": "production", "can_admins_bypass": true}},
+				})
+			},
+			expected: 1,
+		},
+		{name: "forbidden", handler: respondStatus(http.StatusForbidden), missing: 1},
+		{name: "server error", handler: respondStatus(http.StatusInternalServerError)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rc := &repositoryCollector{
+				BaseCollector: collectors.NewBaseCollector(namespace.Repository),
+				Client:        newTestClient(t, map[string]http.HandlerFunc{environmentsPath: test.handler}),
+				Context:       context.Background(),
+			}
+
+			repo := newTestRepository(nil)
+			missing := runCollection(&rc.BaseCollector, func() {
+				repo = rc.withEnvironments(repo, "org")
+			})
+			require.Len(t, missing, test.missing, "expecting only the denied requests to be reported as missing permissions")
+			require.Len(t, repo.Environments, test.expected)
+		})
+	}
+}
+
+func TestWithPrivateForking(t *testing.T) {
+	tests := []struct {
+		name     string
+		private  bool
+		repo     bool
+		org      *bool
+		expected *ghcollected.PrivateForking
+	}{
+		{name: "public repository", private: false, repo: true, org: github.Bool(false), expected: nil},
+		{name: "both allow", private: true, repo: true, org: github.Bool(true), expected: &ghcollected.PrivateForking{Organization: github.Bool(true), Repository: true, Effective: true}},
+		{name: "organization setting not visible", private: true, repo: true, org: nil, expected: &ghcollected.PrivateForking{Repository: true, Effective: true}},
+		{name: "organization disallows", private: true, repo: true, org: github.Bool(false), expected: &ghcollected.PrivateForking{Organization: github.Bool(false), Repository: true, Effective: false}},
+		{name: "repository disallows", private: true, repo: false, org: github.Bool(true), expected: &ghcollected.PrivateForking{Organization: github.Bool(true), Repository: false, Effective: false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := newTestRepository(nil)
+			repo.Repository.IsPrivate = test.private
+			repo.Repository.ForkingAllowed = test.repo
+
+			repo = withPrivateForking(repo, test.org)
+			require.Equal(t, test.expected, repo.PrivateForking)
+		})
+	}
+}
+
+func TestCollectRepositoriesSample(t *testing.T) {
+	// disabled repositories are reported without any additional API call
+	page := func(hasNextPage bool, names ...string) string {
+		var nodes []string
+		for _, name := range names {
+			nodes = append(nodes, fmt.Sprintf(`{"name":%q,"url":"https://github.com/org/%s","isDisabled":true}`, name, name))
+		}
+		return fmt.Sprintf(`{"data":{"organization":{"repositories":{"pageInfo":{"hasNextPage":%t,"endCursor":"cursor"},"nodes":[%s]}}}}`,
+			hasNextPage, strings.Join(nodes, ","))
+	}
+
+	tests := []struct {
+		name            string
+		maxRepositories int
+		expected        int
+		pages           int
+	}{
+		{name: "all repositories", maxRepositories: 0, expected: 5, pages: 2},
+		{name: "sample within the first page", maxRepositories: 2, expected: 2, pages: 1},
+		{name: "sample of a whole page", maxRepositories: 3, expected: 3, pages: 1},
+		{name: "sample across pages", maxRepositories: 4, expected: 4, pages: 2},
+		{name: "sample larger than the organization", maxRepositories: 10, expected: 5, pages: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pages atomic.Int32
+			rc := &repositoryCollector{
+				BaseCollector: collectors.NewBaseCollector(namespace.Repository),
+				Client: newTestGraphQLClient(t, func(w http.ResponseWriter, r *http.Request) {
+					body, _ := io.ReadAll(r.Body)
+					switch {
+					case !strings.Contains(string(body), "repositories("):
+						_, _ = w.Write([]byte(`{"data":{}}`))
+					case strings.Contains(string(body), `"repositoryCursor":null`):
+						pages.Add(1)
+						_, _ = w.Write([]byte(page(true, "first", "second", "third")))
+					default:
+						pages.Add(1)
+						_, _ = w.Write([]byte(page(false, "fourth", "fifth")))
+					}
+				}, nil),
+				Context:         context.Background(),
+				maxRepositories: test.maxRepositories,
+			}
+
+			org := ghcollected.NewExtendedOrg(&github.Organization{Login: github.String("org")}, permissions.OrgRoleOwner)
+			channels := rc.WrappedCollection(func() {
+				require.NoError(t, rc.collectRepositories(&org))
+			})
+			go func() {
+				for range channels.Progress {
+				}
+			}()
+			go func() {
+				for range channels.MissingPermission {
+				}
+			}()
+
+			collected := 0
+			for range channels.Collected {
+				collected++
+			}
+			require.Equal(t, test.expected, collected)
+			require.Equal(t, int32(test.pages), pages.Load(), "expecting no query of the pages after the sample")
+		})
+	}
+}
@@ -10,6 +10,7 @@ type repositoryContext struct {
 	isBranchProtectionSupported   bool
 	hasBranchProtectionPermission bool
 	hasGithubAdvancedSecurity     bool
+	isDisabled                    bool
 }
 
 func (rc *repositoryContext) Premium() bool {
@@ -40,6 +41,14 @@ func (rc *repositoryContext) HasGithubAdvancedSecurity() bool {
 	return rc.hasGithubAdvancedSecurity
 }
 
+func (rc *repositoryContext) SetIsDisabled(value bool) {
+	rc.isDisabled = value
+}
+
+func (rc *repositoryContext) IsDisabled() bool {
+	return rc.isDisabled
+}
+
 func newRepositoryContext(roles []permissions.RepositoryRole, isBranchProtectionSupported bool, isEnterprise bool, hasBranchProtectionPermission bool, hasGithubAdvancedSecurity bool) *repositoryContext {
 	return &repositoryContext{
 		roles:                         roles,
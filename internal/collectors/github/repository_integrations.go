@@ -0,0 +1,99 @@
+package github
+
+import (
+	"sort"
+	"sync"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/google/go-github/v53/github"
+)
+
+const allRepositoriesSelection = "all"
+
+// orgIntegrations holds the app installations of an organization, mapped to the repositories they can access.
+// installations are collected once per organization and shared between its repositories.
+type orgIntegrations struct {
+	once   sync.Once
+	all    []ghcollected.RepositoryIntegration
+	byRepo map[string][]ghcollected.RepositoryIntegration
+	err    error
+}
+
+type integrationsCache struct {
+	lock  sync.Mutex
+	byOrg map[string]*orgIntegrations
+}
+
+func newIntegrationsCache() *integrationsCache {
+	return &integrationsCache{
+		byOrg: make(map[string]*orgIntegrations),
+	}
+}
+
+func (c *integrationsCache) forOrg(org string) *orgIntegrations {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.byOrg[org]
+	if !ok {
+		entry = &orgIntegrations{}
+		c.byOrg[org] = entry
+	}
+	return entry
+}
+
+func newRepositoryIntegration(installation *github.Installation) ghcollected.RepositoryIntegration {
+	return ghcollected.RepositoryIntegration{
+		AppSlug:             installation.GetAppSlug(),
+		AppID:               installation.GetAppID(),
+		InstallationID:      installation.GetID(),
+		RepositorySelection: installation.GetRepositorySelection(),
+		Permissions:         installation.GetPermissions(),
+	}
+}
+
+func (rc *repositoryCollector) collectOrgIntegrations(org string, entry *orgIntegrations) {
+	installations, err := rc.Client.GetOrganizationInstallations(org)
+	if err != nil {
+		entry.err = err
+		return
+	}
+
+	entry.byRepo = make(map[string][]ghcollected.RepositoryIntegration)
+	for _, installation := range installations {
+		integration := newRepositoryIntegration(installation)
+		if integration.RepositorySelection == allRepositoriesSelection {
+			entry.all = append(entry.all, integration)
+			continue
+		}
+
+		repositories, err := rc.Client.GetInstallationRepositories(installation.GetID())
+		if err != nil {
+			entry.err = err
+			return
+		}
+		for _, repository := range repositories {
+			entry.byRepo[repository.GetName()] = append(entry.byRepo[repository.GetName()], integration)
+		}
+	}
+}
+
+// repositoryIntegrations returns the app installations that can access the repository.
+func (rc *repositoryCollector) repositoryIntegrations(org, repository string) ([]ghcollected.RepositoryIntegration, error) {
+	entry := rc.integrations.forOrg(org)
+	entry.once.Do(func() {
+		rc.collectOrgIntegrations(org, entry)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	result := make([]ghcollected.RepositoryIntegration, 0, len(entry.all)+len(entry.byRepo[repository]))
+	result = append(result, entry.all...)
+	result = append(result, entry.byRepo[repository]...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AppSlug < result[j].AppSlug
+	})
+
+	return result, nil
+}
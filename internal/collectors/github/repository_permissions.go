@@ -0,0 +1,104 @@
+package github
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/shurcooL/githubv4"
+)
+
+var (
+	// the GraphQL client reports failed requests by their status, and query errors by their message only
+	graphQLStatusMessage = regexp.MustCompile(`^non-200 OK status code: (\d{3})`)
+	graphQLAccessMessage = regexp.MustCompile(`(?i)access|could not resolve`)
+)
+
+type collaboratorPermissionSource struct {
+	Permission githubv4.String
+	RoleName   *string
+	Source     struct {
+		Typename string `graphql:"__typename"`
+		Team     struct {
+			Slug string
+		} `graphql:"... on Team"`
+	}
+}
+
+type collaboratorPermissionsQuery struct {
+	Repository struct {
+		Collaborators struct {
+			PageInfo ghcollected.GitHubQLPageInfo
+			Edges    []struct {
+				Node struct {
+					Login string
+				}
+				PermissionSources []collaboratorPermissionSource
+			}
+		} `graphql:"collaborators(first: 100, after: $cursor)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// permissionGrants orders the grants from the most specific (direct) to the least specific (organization),
+// so a direct grant is considered the source when a team grants the same role.
+func permissionGrants(sources []collaboratorPermissionSource) []ghcollected.PermissionGrant {
+	var direct, teams, organization []ghcollected.PermissionGrant
+	for _, source := range sources {
+		permission := string(source.Permission)
+		if source.RoleName != nil && ghcollected.PermissionRank(*source.RoleName) > 0 {
+			// the role name distinguishes triage/maintain, which are reported as read/write permissions
+			permission = *source.RoleName
+		}
+
+		switch source.Source.Typename {
+		case "Repository":
+			direct = append(direct, ghcollected.PermissionGrant{Permission: permission, Source: ghcollected.PermissionSourceDirect})
+		case "Team":
+			teams = append(teams, ghcollected.PermissionGrant{Permission: permission, Source: ghcollected.PermissionSourceTeamPrefix + source.Source.Team.Slug})
+		case "Organization":
+			organization = append(organization, ghcollected.PermissionGrant{Permission: permission, Source: ghcollected.PermissionSourceOrganization})
+		}
+	}
+
+	grants := append(direct, teams...)
+	return append(grants, organization...)
+}
+
+// isAccessError reports whether the query failed because the collaborators are not accessible to the token,
+// as opposed to a server or a network failure.
+func isAccessError(err error) bool {
+	if match := graphQLStatusMessage.FindStringSubmatch(err.Error()); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusNotFound
+	}
+	return graphQLAccessMessage.MatchString(err.Error())
+}
+
+// repositoryEffectivePermissions reconciles the direct, team and organization grants of each collaborator
+func (rc *repositoryCollector) repositoryEffectivePermissions(org, repository string) ([]ghcollected.EffectivePermission, error) {
+	variables := map[string]interface{}{
+		"owner":  githubv4.String(org),
+		"name":   githubv4.String(repository),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	result := []ghcollected.EffectivePermission{}
+	for {
+		query := collaboratorPermissionsQuery{}
+		if err := rc.Client.GraphQLClient().Query(rc.Context, &query, variables); err != nil {
+			return nil, err
+		}
+
+		for _, edge := range query.Repository.Collaborators.Edges {
+			result = append(result, ghcollected.ReconcilePermission(edge.Node.Login, permissionGrants(edge.PermissionSources)))
+		}
+
+		if !query.Repository.Collaborators.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = query.Repository.Collaborators.PageInfo.EndCursor
+	}
+
+	return result, nil
+}
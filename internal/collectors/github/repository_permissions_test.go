@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collectors"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/google/go-github/v53/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPermissionSource(typename string, permission string, roleName *string) collaboratorPermissionSource {
+	source := collaboratorPermissionSource{Permission: githubv4.String(permission), RoleName: roleName}
+	source.Source.Typename = typename
+	return source
+}
+
+func TestPermissionGrants(t *testing.T) {
+	team := newTestPermissionSource("Team", "WRITE", github.String("maintain"))
+	team.Source.Team.Slug = "developers"
+	sources := []collaboratorPermissionSource{
+		newTestPermissionSource("Organization", "READ", nil),
+		team,
+		newTestPermissionSource("Repository", "WRITE", github.String("custom-role")),
+		newTestPermissionSource("Enterprise", "ADMIN", nil),
+	}
+
+	require.Equal(t, []ghcollected.PermissionGrant{
+		{Permission: "WRITE", Source: ghcollected.PermissionSourceDirect},
+		{Permission: "maintain", Source: ghcollected.PermissionSourceTeamPrefix + "developers"},
+		{Permission: "READ", Source: ghcollected.PermissionSourceOrganization},
+	}, permissionGrants(sources), "expecting the grants from the most specific, with the role names of the known roles")
+}
+
+func TestWithEffectivePermissions(t *testing.T) {
+	collaborators := `{"data":{"repository":{"collaborators":{"pageInfo":{"hasNextPage":false},"edges":[
+		{"node":{"login":"user"},"permissionSources":[
+			{"permission":"READ","source":{"__typename":"Organization"}},
+			{"permission":"WRITE","roleName":"write","source":{"__typename":"Repository"}}]}]}}}}`
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		permissions []ghcollected.EffectivePermission
+		missing     int
+	}{
+		{
+			name:   "collected",
+			status: http.StatusOK,
+			body:   collaborators,
+			permissions: []ghcollected.EffectivePermission{{
+				Login:      "user",
+				Permission: "write",
+				Source:     ghcollected.PermissionSourceDirect,
+				Grants: []ghcollected.PermissionGrant{
+					{Permission: "write", Source: ghcollected.PermissionSourceDirect},
+					{Permission: "read", Source: ghcollected.PermissionSourceOrganization},
+				},
+			}},
+		},
+		{
+			name:    "no push access",
+			status:  http.StatusOK,
+			body:    `{"data":{"repository":null},"errors":[{"type":"FORBIDDEN","message":"Must have push access to view repository collaborators."}]}`,
+			missing: 1,
+		},
+		{
+			name:    "forbidden",
+			status:  http.StatusForbidden,
+			body:    `{"message":"error"}`,
+			missing: 1,
+		},
+		{
+			name:    "server error",
+			status:  http.StatusInternalServerError,
+			body:    `{"message":"error"}`,
+			missing: 0,
+		},
+		{
+			name:    "query error",
+			status:  http.StatusOK,
+			body:    `{"data":null,"errors":[{"message":"Something went wrong while executing your query."}]}`,
+			missing: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestGraphQLClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if query, _ := io.ReadAll(r.Body); len(query) == 0 {
+					// the token scopes request
+					return
+				}
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}, nil)
+			rc := &repositoryCollector{
+				BaseCollector: collectors.NewBaseCollector(namespace.Repository),
+				Client:        client,
+				Context:       context.Background(),
+			}
+
+			repo := newTestRepository(nil)
+			missing := runCollection(&rc.BaseCollector, func() {
+				repo = rc.withEffectivePermissions(repo, "org")
+			})
+			require.Len(t, missing, test.missing)
+			require.Equal(t, test.permissions, repo.EffectivePermissions)
+		})
+	}
+}
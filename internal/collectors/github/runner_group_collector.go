@@ -2,7 +2,7 @@ package github
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
@@ -10,7 +10,6 @@ import (
 	"github.com/Legit-Labs/legitify/internal/clients/github/pagination"
 	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
 	"github.com/Legit-Labs/legitify/internal/collectors"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	"github.com/google/go-github/v53/github"
@@ -61,10 +60,10 @@ func (c *runnersCollector) collectForOrg(orgName string) []*github.RunnerGroup {
 }
 
 func (c *runnersCollector) CollectTotalEntities() int {
-	gw := group_waiter.New()
+	gw := c.NewGroupWaiter()
 	orgs, err := c.client.CollectOrganizations()
 	if err != nil {
-		log.Printf("failed to collect organizations %s", err)
+		c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 		return 0
 	}
 
@@ -86,7 +85,7 @@ func (c *runnersCollector) Collect() collectors.SubCollectorChannels {
 		orgs, err := c.client.CollectOrganizations()
 
 		if err != nil {
-			log.Printf("failed to collect organizations %s", err)
+			c.FailCollection(fmt.Errorf("failed to collect organizations: %v", err))
 			return
 		}
 
@@ -0,0 +1,174 @@
+package github
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/shurcooL/githubv4"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	workflowsDirectory = ".github/workflows"
+	idTokenScope       = "id-token"
+	// allScopes is the scope of the "read-all" and "write-all" permissions
+	allScopes = "*"
+)
+
+type workflowEntries struct {
+	Tree struct {
+		Entries []struct {
+			Name   string
+			Object *struct {
+				Blob struct {
+					Text *string
+				} `graphql:"... on Blob"`
+			}
+		}
+	} `graphql:"... on Tree"`
+}
+
+// withWorkflows collects the token permissions the workflow files of the default branch request (a single query).
+// A repository without workflows (or an empty repository) has no workflows.
+func (rc *repositoryCollector) withWorkflows(repo ghcollected.Repository, org string) (ghcollected.Repository, error) {
+	var workflowsQuery struct {
+		RepositoryOwner struct {
+			Repository struct {
+				Workflows *workflowEntries `graphql:"workflows: object(expression: $workflows)"`
+			} `graphql:"repository(name: $name)"`
+		} `graphql:"repositoryOwner(login: $login)"`
+	}
+
+	variables := map[string]interface{}{
+		"login":     githubv4.String(org),
+		"name":      githubv4.String(repo.Name()),
+		"workflows": githubv4.String("HEAD:" + workflowsDirectory),
+	}
+
+	err := rc.Client.GraphQLClient().Query(rc.Context, &workflowsQuery, variables)
+	if err != nil {
+		return repo, err
+	}
+
+	entries := workflowsQuery.RepositoryOwner.Repository.Workflows
+	if entries == nil {
+		return repo, nil
+	}
+	for _, entry := range entries.Tree.Entries {
+		if ext := path.Ext(entry.Name); (ext != ".yml" && ext != ".yaml") || entry.Object == nil || entry.Object.Blob.Text == nil {
+			continue
+		}
+		workflowPath := workflowsDirectory + "/" + entry.Name
+		workflow, err := parseWorkflow(workflowPath, []byte(*entry.Object.Blob.Text))
+		if err != nil {
+			log.Printf("failed to parse the workflow %s of %s: %v", workflowPath, repo.Name(), err)
+			continue
+		}
+		if rc.workflowContents {
+			workflow.Content, err = DecodeWorkflow([]byte(*entry.Object.Blob.Text))
+			if err != nil {
+				log.Printf("failed to decode the workflow %s of %s: %v", workflowPath, repo.Name(), err)
+			}
+		}
+		repo.Workflows = append(repo.Workflows, workflow)
+	}
+	return repo, nil
+}
+
+// parseWorkflow returns the token permissions the workflow and its jobs request
+func parseWorkflow(workflowPath string, content []byte) (ghcollected.Workflow, error) {
+	var parsed struct {
+		Permissions yaml.Node `yaml:"permissions"`
+		Jobs        map[string]struct {
+			Permissions yaml.Node `yaml:"permissions"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return ghcollected.Workflow{}, err
+	}
+
+	workflow := ghcollected.Workflow{Path: workflowPath, Jobs: len(parsed.Jobs)}
+	workflow.Permissions = parsePermissions(&parsed.Permissions)
+	workflow.RequestsIDToken = grantsIDToken(workflow.Permissions)
+
+	jobs := make([]string, 0, len(parsed.Jobs))
+	for job := range parsed.Jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+	for _, job := range jobs {
+		node := parsed.Jobs[job].Permissions
+		permissions := parsePermissions(&node)
+		if permissions == nil {
+			continue
+		}
+		if workflow.JobPermissions == nil {
+			workflow.JobPermissions = make(map[string]map[string]string)
+		}
+		workflow.JobPermissions[job] = permissions
+		workflow.RequestsIDToken = workflow.RequestsIDToken || grantsIDToken(permissions)
+	}
+
+	return workflow, nil
+}
+
+// DecodeWorkflow decodes the workflow file to the document the policies inspect (the "content" of input.workflows).
+// The file is decoded here rather than in the policies, since YAML 1.1 decoders (like the one of OPA) decode the "on" key as a boolean.
+func DecodeWorkflow(content []byte) (interface{}, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	return stringKeys(document), nil
+}
+
+// stringKeys converts the mappings with non-string keys (e.g. numbers) to mappings with string keys, so the document can be encoded as json
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = stringKeys(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = stringKeys(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parsePermissions returns the permissions of a "permissions" key (nil when it is not set):
+// either a map of scopes, or a single "read-all"/"write-all" value
+func parsePermissions(node *yaml.Node) map[string]string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil
+		}
+		return map[string]string{allScopes: node.Value}
+	case yaml.MappingNode:
+		permissions := make(map[string]string, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			permissions[node.Content[i].Value] = node.Content[i+1].Value
+		}
+		return permissions
+	default:
+		return nil
+	}
+}
+
+func grantsIDToken(permissions map[string]string) bool {
+	return permissions[idTokenScope] == "write" || permissions[allScopes] == "write-all"
+}
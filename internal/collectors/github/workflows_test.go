@@ -0,0 +1,189 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+
+	ghcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseWorkflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected ghcollected.Workflow
+	}{
+		{
+			name: "workflow level id-token permission",
+			content: `
+on: push
+permissions:
+  contents: read
+  id-token: write
+jobs:
+  build:
+    runs-on: ubuntu-latest
+  deploy:
+    runs-on: ubuntu-latest
+`,
+			expected: ghcollected.Workflow{
+				Path:            "workflow.yml",
+				Jobs:            2,
+				Permissions:     map[string]string{"contents": "read", "id-token": "write"},
+				RequestsIDToken: true,
+			},
+		},
+		{
+			name: "job level id-token permission",
+			content: `
+on: push
+permissions: read-all
+jobs:
+  build:
+    runs-on: ubuntu-latest
+  deploy:
+    runs-on: ubuntu-latest
+    permissions:
+      id-token: write
+`,
+			expected: ghcollected.Workflow{
+				Path:            "workflow.yml",
+				Jobs:            2,
+				Permissions:     map[string]string{allScopes: "read-all"},
+				JobPermissions:  map[string]map[string]string{"deploy": {"id-token": "write"}},
+				RequestsIDToken: true,
+			},
+		},
+		{
+			name: "write-all job permissions",
+			content: `
+on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    permissions: write-all
+`,
+			expected: ghcollected.Workflow{
+				Path:            "workflow.yml",
+				Jobs:            1,
+				JobPermissions:  map[string]map[string]string{"deploy": {allScopes: "write-all"}},
+				RequestsIDToken: true,
+			},
+		},
+		{
+			name: "no permissions",
+			content: `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    permissions:
+`,
+			expected: ghcollected.Workflow{
+				Path: "workflow.yml",
+				Jobs: 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workflow, err := parseWorkflow("workflow.yml", []byte(test.content))
+			require.NoError(t, err)
+			require.Equal(t, test.expected, workflow)
+		})
+	}
+
+	_, err := parseWorkflow("workflow.yml", []byte("jobs: ["))
+	require.Error(t, err, "expecting an error for a malformed workflow")
+}
+
+func TestParsePermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+	}{
+		{name: "null", content: "permissions:", expected: nil},
+		{name: "scalar", content: "permissions: read-all", expected: map[string]string{allScopes: "read-all"}},
+		{name: "empty mapping", content: "permissions: {}", expected: map[string]string{}},
+		{
+			name:     "mapping",
+			content:  "permissions:\n  contents: read\n  id-token: write",
+			expected: map[string]string{"contents": "read", "id-token": "write"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var parsed struct {
+				Permissions yaml.Node `yaml:"permissions"`
+			}
+			require.NoError(t, yaml.Unmarshal([]byte(test.content), &parsed))
+			require.Equal(t, test.expected, parsePermissions(&parsed.Permissions))
+		})
+	}
+
+	var unset yaml.Node
+	require.Nil(t, parsePermissions(&unset), "expecting no permissions when the key is not set")
+}
+
+func TestDecodeWorkflow(t *testing.T) {
+	document, err := DecodeWorkflow([]byte(`
+on:
+  pull_request_target:
+    types: [opened]
+jobs:
+  build:
+    strategy:
+      matrix:
+        node: [18, 20]
+        include:
+          - 18: legacy
+    steps:
+      - uses: actions/checkout@v4
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"on": map[string]interface{}{
+			"pull_request_target": map[string]interface{}{"types": []interface{}{"opened"}},
+		},
+		"jobs": map[string]interface{}{
+			"build": map[string]interface{}{
+				"strategy": map[string]interface{}{
+					"matrix": map[string]interface{}{
+						"node":    []interface{}{18, 20},
+						"include": []interface{}{map[string]interface{}{"18": "legacy"}},
+					},
+				},
+				"steps": []interface{}{map[string]interface{}{"uses": "actions/checkout@v4"}},
+			},
+		},
+	}, document, "expecting the 'on' key to remain a string and the numeric keys to be converted to strings")
+
+	_, err = json.Marshal(document)
+	require.NoError(t, err, "expecting the document to be encodable as json")
+
+	_, err = DecodeWorkflow([]byte("jobs: ["))
+	require.Error(t, err, "expecting an error for a malformed workflow")
+}
+
+func TestStringKeys(t *testing.T) {
+	value := []interface{}{
+		map[interface{}]interface{}{
+			1:    "one",
+			true: []interface{}{map[interface{}]interface{}{2.5: "nested"}},
+		},
+		"scalar",
+	}
+
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"1":    "one",
+			"true": []interface{}{map[string]interface{}{"2.5": "nested"}},
+		},
+		"scalar",
+	}, stringKeys(value))
+}
@@ -2,12 +2,14 @@ package gitlab
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
 
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab"
+	"github.com/Legit-Labs/legitify/internal/clients/gitlab/pagination"
 	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
 	"github.com/Legit-Labs/legitify/internal/collectors"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	gitlab2 "github.com/xanzy/go-gitlab"
 
@@ -16,8 +18,9 @@ import (
 
 type groupCollector struct {
 	collectors.BaseCollector
-	Client  *gitlab.Client
-	Context context.Context
+	Client       *gitlab.Client
+	Context      context.Context
+	integrations integrationsCollection
 }
 
 func NewGroupCollector(ctx context.Context, client *gitlab.Client) collectors.Collector {
@@ -25,6 +28,7 @@ func NewGroupCollector(ctx context.Context, client *gitlab.Client) collectors.Co
 		BaseCollector: collectors.NewBaseCollector(namespace.Organization),
 		Client:        client,
 		Context:       ctx,
+		integrations:  newIntegrationsCollection(ctx),
 	}
 	return c
 }
@@ -32,7 +36,7 @@ func NewGroupCollector(ctx context.Context, client *gitlab.Client) collectors.Co
 func (c *groupCollector) CollectTotalEntities() int {
 	groups, err := c.Client.Groups()
 	if err != nil {
-		log.Printf("failed to collect groups %s", err)
+		c.FailCollection(fmt.Errorf("failed to collect groups: %v", err))
 		return 0
 	}
 
@@ -43,11 +47,11 @@ func (c *groupCollector) Collect() collectors.SubCollectorChannels {
 	return c.WrappedCollection(func() {
 		groups, err := c.Client.Groups()
 		if err != nil {
-			log.Printf("failed to collect groups %s", err)
+			c.FailCollection(fmt.Errorf("failed to collect groups: %v", err))
 			return
 		}
 
-		gw := group_waiter.New()
+		gw := c.NewGroupWaiter()
 
 		for _, g := range groups {
 			g := g
@@ -67,8 +71,11 @@ func (c *groupCollector) Collect() collectors.SubCollectorChannels {
 				}
 
 				entity := gitlab_collected.Organization{
-					Group: fullGroup,
-					Hooks: hooks,
+					Group:        fullGroup,
+					Hooks:        hooks,
+					DeployTokens: c.collectDeployTokens(fullGroup),
+					AccessTokens: c.collectAccessTokens(fullGroup),
+					Integrations: c.collectIntegrations(fullGroup),
 				}
 
 				c.CollectDataWithContext(entity, g.WebURL,
@@ -80,3 +87,58 @@ func (c *groupCollector) Collect() collectors.SubCollectorChannels {
 		gw.Wait()
 	})
 }
+
+func (c *groupCollector) collectDeployTokens(group *gitlab2.Group) []gitlab_collected.DeployToken {
+	res, err := pagination.New[*gitlab2.DeployToken](c.Client.Client().DeployTokens.ListGroupDeployTokens, nil).Sync(group.ID)
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.GroupRoleOwner, group.FullPath,
+				"Cannot read group deploy tokens", namespace.Organization)
+			c.IssueMissingPermissions(perm)
+			return nil
+		}
+		log.Printf("failed to list group deploy tokens: %d - %s: %v", group.ID, group.Name, err)
+		return nil
+	}
+
+	return gitlab_collected.NewDeployTokens(res.Collected)
+}
+
+func (c *groupCollector) collectAccessTokens(group *gitlab2.Group) []gitlab_collected.AccessToken {
+	res, err := pagination.New[*gitlab2.GroupAccessToken](c.Client.Client().GroupAccessTokens.ListGroupAccessTokens, nil).Sync(group.ID)
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.GroupRoleOwner, group.FullPath,
+				"Cannot read group access tokens", namespace.Organization)
+			c.IssueMissingPermissions(perm)
+			return nil
+		}
+		log.Printf("failed to list group access tokens: %d - %s: %v", group.ID, group.Name, err)
+		return nil
+	}
+
+	return gitlab_collected.NewGroupAccessTokens(res.Collected)
+}
+
+func (c *groupCollector) collectIntegrations(group *gitlab2.Group) []gitlab_collected.Integration {
+	if !c.integrations.list {
+		return nil
+	}
+
+	services, resp, err := c.Client.GroupIntegrations(group.ID)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.GroupRoleOwner, group.FullPath,
+				"Cannot read group integrations", namespace.Organization)
+			c.IssueMissingPermissions(perm)
+			return nil
+		}
+		log.Printf("failed to list group integrations: %d - %s: %v", group.ID, group.Name, err)
+		return nil
+	}
+
+	return c.integrations.integrations(services, group.FullPath, func(slug string) (map[string]interface{}, error) {
+		properties, _, err := c.Client.GroupIntegrationProperties(group.ID, slug)
+		return properties, err
+	})
+}
@@ -0,0 +1,52 @@
+package gitlab
+
+import (
+	"context"
+	"log"
+
+	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	gitlab2 "github.com/xanzy/go-gitlab"
+)
+
+// integrationsCollection decides which integration data is collected, based on the lists of the policies config:
+// the integrations are only listed when a policy has approved integrations or trusted domains to compare them with,
+// and their properties (one request per integration) are only read to check their hosts against the trusted domains.
+type integrationsCollection struct {
+	list  bool
+	hosts bool
+}
+
+func newIntegrationsCollection(ctx context.Context) integrationsCollection {
+	hosts := len(context_utils.GetConfigList(ctx, "trusted_webhook_domains")) > 0
+	return integrationsCollection{
+		list:  hosts || len(context_utils.GetConfigList(ctx, "approved_integrations")) > 0,
+		hosts: hosts,
+	}
+}
+
+// integrations converts the active services of the entity, reading the properties of each one when the hosts are needed
+func (ic integrationsCollection) integrations(services []*gitlab2.Service, entity string,
+	properties func(slug string) (map[string]interface{}, error)) []gitlab_collected.Integration {
+	integrations := make([]gitlab_collected.Integration, 0, len(services))
+	for _, service := range services {
+		if !service.Active {
+			continue
+		}
+		integration := gitlab_collected.Integration{
+			Slug:  service.Slug,
+			Title: service.Title,
+		}
+		if ic.hosts {
+			props, err := properties(service.Slug)
+			if err != nil {
+				// the integration is still reported, without the hosts it is configured with
+				log.Printf("failed to get integration %s of %s: %v", service.Slug, entity, err)
+			}
+			integration.Hosts = gitlab_collected.IntegrationHosts(props)
+		}
+		integrations = append(integrations, integration)
+	}
+
+	return integrations
+}
@@ -0,0 +1,76 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/stretchr/testify/require"
+	gitlab2 "github.com/xanzy/go-gitlab"
+)
+
+func TestIntegrationsCollection(t *testing.T) {
+	services := []*gitlab2.Service{
+		{Slug: "slack", Title: "Slack notifications", Active: true},
+		{Slug: "jira", Title: "Jira", Active: false},
+		{Slug: "jenkins", Title: "Jenkins", Active: true},
+	}
+	properties := map[string]map[string]interface{}{
+		"slack": {"webhook": "https://hooks.slack.com/services/T000", "channel": "#ci"},
+	}
+
+	tests := []struct {
+		name     string
+		lists    map[string][]string
+		expected integrationsCollection
+		fetched  []string
+		result   []gitlab_collected.Integration
+	}{
+		{
+			name:     "nothing configured",
+			expected: integrationsCollection{},
+		},
+		{
+			name:     "approved integrations",
+			lists:    map[string][]string{"approved_integrations": {"slack"}},
+			expected: integrationsCollection{list: true},
+			result: []gitlab_collected.Integration{
+				{Slug: "slack", Title: "Slack notifications"},
+				{Slug: "jenkins", Title: "Jenkins"},
+			},
+		},
+		{
+			name:     "trusted domains",
+			lists:    map[string][]string{"trusted_webhook_domains": {"slack.com"}},
+			expected: integrationsCollection{list: true, hosts: true},
+			fetched:  []string{"slack", "jenkins"},
+			result: []gitlab_collected.Integration{
+				{Slug: "slack", Title: "Slack notifications", Hosts: []string{"hooks.slack.com"}},
+				{Slug: "jenkins", Title: "Jenkins", Hosts: []string{}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ic := newIntegrationsCollection(context_utils.NewContextWithPoliciesConfig(context.Background(), test.lists))
+			require.Equal(t, test.expected, ic)
+			if !ic.list {
+				return
+			}
+
+			var fetched []string
+			result := ic.integrations(services, "group/project", func(slug string) (map[string]interface{}, error) {
+				fetched = append(fetched, slug)
+				if props, ok := properties[slug]; ok {
+					return props, nil
+				}
+				return nil, fmt.Errorf("not found")
+			})
+			require.Equal(t, test.fetched, fetched, "expecting the properties of the active integrations to be read only for their hosts")
+			require.Equal(t, test.result, result)
+		})
+	}
+}
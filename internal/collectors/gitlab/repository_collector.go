@@ -2,14 +2,17 @@ package gitlab
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"regexp"
+	"strings"
 	"sync/atomic"
 
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab"
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab/pagination"
 	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
 	"github.com/Legit-Labs/legitify/internal/collectors"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	"github.com/Legit-Labs/legitify/internal/common/types"
 	"github.com/Legit-Labs/legitify/internal/context_utils"
@@ -20,8 +23,9 @@ import (
 
 type repositoryCollector struct {
 	collectors.BaseCollector
-	Client  *gitlab.Client
-	Context context.Context
+	Client       *gitlab.Client
+	Context      context.Context
+	integrations integrationsCollection
 }
 
 func NewRepositoryCollector(ctx context.Context, client *gitlab.Client) collectors.Collector {
@@ -29,6 +33,7 @@ func NewRepositoryCollector(ctx context.Context, client *gitlab.Client) collecto
 		BaseCollector: collectors.NewBaseCollector(namespace.Repository),
 		Client:        client,
 		Context:       ctx,
+		integrations:  newIntegrationsCollection(ctx),
 	}
 	return c
 }
@@ -46,7 +51,7 @@ func (rc *repositoryCollector) CollectTotalEntities() int {
 	}
 
 	var total atomic.Int64
-	gw := group_waiter.New()
+	gw := rc.NewGroupWaiter()
 	for _, group := range groups {
 		group := group
 		gw.Do(func() {
@@ -75,7 +80,7 @@ func (rc *repositoryCollector) Collect() collectors.SubCollectorChannels {
 
 func (rc *repositoryCollector) collectSpecific(repositories []types.RepositoryWithOwner) collectors.SubCollectorChannels {
 	return rc.WrappedCollection(func() {
-		gw := group_waiter.New()
+		gw := rc.NewGroupWaiter()
 		for _, r := range repositories {
 			r := r
 			gw.Do(func() {
@@ -109,6 +114,129 @@ func (rc *repositoryCollector) extendProjectWithProtectedBranches(project gitlab
 	return extendedProject, nil
 }
 
+// extendProjectWithProtectedTags collects the protected tags and who is allowed to create them.
+// A project without protected tags has an empty list, to distinguish it from missing info.
+func (rc *repositoryCollector) extendProjectWithProtectedTags(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	res, err := pagination.New[*gitlab2.ProtectedTag](rc.Client.Client().ProtectedTags.ListProtectedTags, nil).Sync(int(project.ID()))
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+				"Cannot read project protected tags", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+			return project, nil
+		}
+		log.Printf("failed to list project protected tags %s", err)
+		return project, err
+	}
+
+	extendedProject := project
+	extendedProject.ProtectedTags = res.Collected
+	if extendedProject.ProtectedTags == nil {
+		extendedProject.ProtectedTags = []*gitlab2.ProtectedTag{}
+	}
+	return extendedProject, nil
+}
+
+// extendProjectWithJobTokenScope collects the settings (REST) and the allowlists (GraphQL) of the project job token scope
+func (rc *repositoryCollector) extendProjectWithJobTokenScope(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	scope, resp, err := rc.Client.ProjectJobTokenScope(int(project.ID()))
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+				"Cannot read project job token scope", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+			return project, nil
+		}
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			// older instances only have the outbound scope, which is a project setting
+			scope = &gitlab_collected.JobTokenScope{OutboundEnabled: project.CIJobTokenScopeEnabled}
+		} else {
+			log.Printf("failed to get project job token scope %s", err)
+			return project, err
+		}
+	}
+
+	scope.InboundAllowlist, scope.OutboundAllowlist, err = rc.Client.ProjectJobTokenAllowlists(project.PathWithNamespace)
+	if err != nil {
+		// the scope settings are still reported, without the allowlists
+		log.Printf("failed to get the job token allowlists of %s: %v", project.PathWithNamespace, err)
+	}
+
+	extendedProject := project
+	extendedProject.JobTokenScope = scope
+	return extendedProject, nil
+}
+
+func (rc *repositoryCollector) extendProjectWithDescriptionTemplates(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	templates := &gitlab_collected.DescriptionTemplates{
+		IssueTemplates:                 []string{},
+		MergeRequestTemplates:          []string{},
+		HasDefaultIssueTemplate:        project.IssuesTemplate != "",
+		HasDefaultMergeRequestTemplate: project.MergeRequestsTemplate != "",
+	}
+
+	for templateType, names := range map[string]*[]string{
+		"issues":         &templates.IssueTemplates,
+		"merge_requests": &templates.MergeRequestTemplates,
+	} {
+		res, err := pagination.New[*gitlab2.ProjectTemplate](rc.Client.Client().ProjectTemplates.ListTemplates, nil).Sync(int(project.ID()), templateType)
+		if err != nil {
+			if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+				perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+					"Cannot read project description templates", namespace.Repository)
+				rc.IssueMissingPermissions(perm)
+				return project, nil
+			}
+			log.Printf("failed to list project %s templates %s", templateType, err)
+			return project, err
+		}
+		for _, template := range res.Collected {
+			*names = append(*names, template.Name)
+		}
+	}
+
+	extendedProject := project
+	extendedProject.DescriptionTemplates = templates
+	return extendedProject, nil
+}
+
+// extendProjectWithDefaultBranchProtection reconciles the default branch with the protected branches,
+// to distinguish between an unprotected default branch, a project without branches and missing info.
+func (rc *repositoryCollector) extendProjectWithDefaultBranchProtection(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	if project.EmptyRepo || project.DefaultBranch == "" {
+		return project, nil // no branches
+	}
+	if project.ProtectedBranches == nil {
+		return project, nil // failed to collect the protected branches
+	}
+
+	protection := &gitlab_collected.DefaultBranchProtection{
+		Name: project.DefaultBranch,
+	}
+	for _, branch := range project.ProtectedBranches {
+		if protectedBranchMatches(branch.Name, project.DefaultBranch) {
+			protection.Protected = true
+			protection.MatchedBy = branch.Name
+			break
+		}
+	}
+
+	extendedProject := project
+	extendedProject.DefaultBranchProtection = protection
+	return extendedProject, nil
+}
+
+// protectedBranchMatches checks whether the protected branch name (which may contain '*' wildcards) matches the branch
+func protectedBranchMatches(protectedName string, branch string) bool {
+	if !strings.Contains(protectedName, "*") {
+		return protectedName == branch
+	}
+
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(protectedName), `\*`, ".*") + "$"
+	matched, err := regexp.MatchString(pattern, branch)
+	return err == nil && matched
+}
+
 func (rc *repositoryCollector) extendProjectWithMembers(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
 	res, err := pagination.New[*gitlab2.ProjectMember](rc.Client.Client().ProjectMembers.ListAllProjectMembers, nil).Sync(int(project.ID()))
 	if err != nil {
@@ -179,14 +307,190 @@ func (rc *repositoryCollector) extendProjectWithMinimumRequiredApprovals(project
 	return project, nil
 }
 
+func (rc *repositoryCollector) extendProjectWithComplianceFrameworks(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	frameworks, found, err := rc.Client.ProjectComplianceFrameworks(project.PathWithNamespace)
+	if err != nil {
+		log.Printf("failed to get project compliance frameworks %s", err)
+		return project, err
+	}
+	if !found {
+		perm := collectors.NewMissingPermission(permissions.GroupRoleOwner, project.PathWithNamespace,
+			"Cannot read project compliance frameworks", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return project, nil
+	}
+
+	extendedProject := project
+	extendedProject.ComplianceFrameworksInfo = frameworks
+	return extendedProject, nil
+}
+
+func (rc *repositoryCollector) extendProjectWithMergeSettings(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	source := project.Project
+	// the merge settings are omitted from the project listing when the user is not allowed to manage the project
+	if source.MergeMethod == "" {
+		full, _, err := rc.Client.Client().Projects.GetProject(int(project.ID()), &gitlab2.GetProjectOptions{})
+		if err != nil {
+			log.Printf("failed to get project merge settings %s", err)
+			return project, err
+		}
+		source = full
+	}
+
+	if source.MergeMethod == "" {
+		perm := collectors.NewMissingPermission(permissions.GroupRoleOwner, project.PathWithNamespace,
+			"Cannot read project merge settings", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return project, nil
+	}
+
+	extendedProject := project
+	extendedProject.MergeSettings = &gitlab_collected.MergeSettings{
+		MergeMethod:                  string(source.MergeMethod),
+		SquashOption:                 string(source.SquashOption),
+		RemoveSourceBranchAfterMerge: source.RemoveSourceBranchAfterMerge,
+	}
+	return extendedProject, nil
+}
+
+const registryDisabled = "disabled"
+
+func (rc *repositoryCollector) extendProjectWithRegistrySettings(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	source := project.Project
+	accessLevel := source.ContainerRegistryAccessLevel
+	if accessLevel == "" { // older GitLab versions only report whether the registry is enabled
+		accessLevel = gitlab2.DisabledAccessControl
+		if source.ContainerRegistryEnabled {
+			accessLevel = gitlab2.EnabledAccessControl
+		}
+	}
+	registryEnabled := accessLevel != gitlab2.DisabledAccessControl
+	// the cleanup policy is omitted from the project listing when the user is not allowed to manage the project
+	if registryEnabled && source.ContainerExpirationPolicy == nil {
+		full, _, err := rc.Client.Client().Projects.GetProject(int(project.ID()), &gitlab2.GetProjectOptions{})
+		if err != nil {
+			log.Printf("failed to get project registry settings %s", err)
+			return project, err
+		}
+		source = full
+	}
+
+	if registryEnabled && source.ContainerExpirationPolicy == nil {
+		perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+			"Cannot read project container registry settings", namespace.Repository)
+		rc.IssueMissingPermissions(perm)
+		return project, nil
+	}
+
+	settings := &gitlab_collected.RegistrySettings{
+		ContainerRegistryEnabled:    registryEnabled,
+		ContainerRegistryVisibility: registryDisabled,
+		CleanupPolicyEnabled:        registryEnabled && source.ContainerExpirationPolicy.Enabled,
+		PackagesEnabled:             source.PackagesEnabled,
+		PackagesVisibility:          registryDisabled,
+	}
+	switch accessLevel {
+	case gitlab2.PrivateAccessControl:
+		settings.ContainerRegistryVisibility = string(gitlab2.PrivateVisibility)
+	case gitlab2.EnabledAccessControl:
+		settings.ContainerRegistryVisibility = string(source.Visibility)
+	}
+	if source.PackagesEnabled {
+		settings.PackagesVisibility = string(source.Visibility)
+	}
+
+	extendedProject := project
+	extendedProject.RegistrySettings = settings
+	return extendedProject, nil
+}
+
+func (rc *repositoryCollector) extendProjectWithDeployTokens(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	res, err := pagination.New[*gitlab2.DeployToken](rc.Client.Client().DeployTokens.ListProjectDeployTokens, nil).Sync(int(project.ID()))
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+				"Cannot read project deploy tokens", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+			return project, nil
+		}
+		log.Printf("failed to list project deploy tokens %s", err)
+		return project, err
+	}
+
+	extendedProject := project
+	extendedProject.DeployTokens = gitlab_collected.NewDeployTokens(res.Collected)
+	return extendedProject, nil
+}
+
+func (rc *repositoryCollector) extendProjectWithAccessTokens(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	res, err := pagination.New[*gitlab2.ProjectAccessToken](rc.Client.Client().ProjectAccessTokens.ListProjectAccessTokens, nil).Sync(int(project.ID()))
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+				"Cannot read project access tokens", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+			return project, nil
+		}
+		log.Printf("failed to list project access tokens %s", err)
+		return project, err
+	}
+
+	extendedProject := project
+	extendedProject.AccessTokens = gitlab_collected.NewProjectAccessTokens(res.Collected)
+	return extendedProject, nil
+}
+
+func (rc *repositoryCollector) extendProjectWithCIVariables(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	res, err := pagination.New[*gitlab2.ProjectVariable](rc.Client.Client().ProjectVariables.ListVariables, nil).Sync(int(project.ID()))
+	if err != nil {
+		if res.Resp != nil && (res.Resp.StatusCode == http.StatusForbidden || res.Resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+				"Cannot read project CI/CD variables", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+			return project, nil
+		}
+		log.Printf("failed to list project CI/CD variables %s", err)
+		return project, err
+	}
+
+	extendedProject := project
+	extendedProject.CIVariables = gitlab_collected.NewProjectCIVariables(res.Collected)
+	return extendedProject, nil
+}
+
+func (rc *repositoryCollector) extendProjectWithIntegrations(project gitlab_collected.Repository) (gitlab_collected.Repository, error) {
+	if !rc.integrations.list {
+		return project, nil
+	}
+
+	services, resp, err := rc.Client.Client().Services.ListServices(int(project.ID()))
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized) {
+			perm := collectors.NewMissingPermission(permissions.RepoRoleMaintainer, project.PathWithNamespace,
+				"Cannot read project integrations", namespace.Repository)
+			rc.IssueMissingPermissions(perm)
+			return project, nil
+		}
+		log.Printf("failed to list project integrations %s", err)
+		return project, err
+	}
+
+	extendedProject := project
+	extendedProject.Integrations = rc.integrations.integrations(services, project.PathWithNamespace, func(slug string) (map[string]interface{}, error) {
+		properties, _, err := rc.Client.ProjectIntegrationProperties(int(project.ID()), slug)
+		return properties, err
+	})
+	return extendedProject, nil
+}
+
 func (rc *repositoryCollector) collectAll() collectors.SubCollectorChannels {
 	return rc.WrappedCollection(func() {
 		groups, err := rc.Client.Groups()
 		if err != nil {
-			log.Printf("failed to collect list of groups to get repositories  %s", err)
+			rc.FailCollection(fmt.Errorf("failed to collect list of groups to get repositories: %v", err))
 			return
 		}
-		gw := group_waiter.New()
+		gw := rc.NewGroupWaiter()
 		for _, group := range groups {
 			g := group
 			gw.Do(func() {
@@ -216,11 +520,22 @@ func (rc *repositoryCollector) extendedCollection(completeProjectsList *gitlab2.
 	extensionFunctions := []func(gitlab_collected.Repository) (gitlab_collected.Repository, error){
 		rc.extendProjectWithMembers,
 		rc.extendProjectWithProtectedBranches,
+		rc.extendProjectWithDefaultBranchProtection,
+		rc.extendProjectWithProtectedTags,
 		rc.extendProjectWithWebhooks,
 		rc.extendProjectWithPushRules,
 		rc.extendProjectWithMergeRequestApprovalRules,
 		rc.extendProjectWithApprovalConfiguration,
 		rc.extendProjectWithMinimumRequiredApprovals,
+		rc.extendProjectWithComplianceFrameworks,
+		rc.extendProjectWithMergeSettings,
+		rc.extendProjectWithRegistrySettings,
+		rc.extendProjectWithDeployTokens,
+		rc.extendProjectWithAccessTokens,
+		rc.extendProjectWithCIVariables,
+		rc.extendProjectWithIntegrations,
+		rc.extendProjectWithJobTokenScope,
+		rc.extendProjectWithDescriptionTemplates,
 	}
 	var err error
 	for _, f := range extensionFunctions {
@@ -2,7 +2,9 @@ package gitlab
 
 import (
 	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	"log"
+	"net/http"
 
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab"
 	"github.com/Legit-Labs/legitify/internal/collectors"
@@ -28,7 +30,7 @@ func NewServerCollector(ctx context.Context, client *gitlab.Client) collectors.C
 }
 
 func (c *serverCollector) CollectTotalEntities() int {
-	if c.isServer {
+	if c.isServer && c.Client.IsAdmin() {
 		return 1
 	}
 
@@ -41,9 +43,18 @@ func (c *serverCollector) Collect() collectors.SubCollectorChannels {
 			return
 		}
 
-		settings, _, err := c.Client.Client().Settings.GetSettings()
+		// the application settings are available to the administrators of the instance only
+		if !c.Client.IsAdmin() {
+			c.issueMissingAdmin()
+			return
+		}
 
+		settings, resp, err := c.Client.Client().Settings.GetSettings()
 		if err != nil {
+			if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+				c.issueMissingAdmin()
+				return
+			}
 			log.Printf("failed to collect server settings %s", err)
 			return
 		}
@@ -54,3 +65,9 @@ func (c *serverCollector) Collect() collectors.SubCollectorChannels {
 		c.CollectionChangeByOne()
 	})
 }
+
+func (c *serverCollector) issueMissingAdmin() {
+	perm := collectors.NewMissingPermission(permissions.InstanceRoleAdmin, c.Client.ServerUrl(),
+		"Cannot collect the instance settings since the token user is not an administrator of the instance", namespace.Enterprise)
+	c.IssueMissingPermissions(perm)
+}
@@ -2,12 +2,13 @@ package gitlab
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync/atomic"
 
 	"github.com/Legit-Labs/legitify/internal/clients/gitlab"
 	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
 	"github.com/Legit-Labs/legitify/internal/collectors"
-	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 	gitlab2 "github.com/xanzy/go-gitlab"
 
@@ -32,12 +33,12 @@ func NewUserCollector(ctx context.Context, client *gitlab.Client) collectors.Col
 func (c *userCollector) CollectTotalEntities() int {
 	groups, err := c.Client.Groups()
 	if err != nil {
-		log.Printf("failed to collect members: %v", err)
+		c.FailCollection(fmt.Errorf("failed to collect members: %v", err))
 		return 0
 	}
 
-	totalGroupMembers := 0
-	gw := group_waiter.New()
+	var totalGroupMembers int64 = 0
+	gw := c.NewGroupWaiter()
 	for _, g := range groups {
 		group := g
 		gw.Do(func() {
@@ -46,13 +47,13 @@ func (c *userCollector) CollectTotalEntities() int {
 				log.Printf("Failed to get members for group %s", g.Name)
 				return
 			}
-			totalGroupMembers += resp.TotalItems
+			atomic.AddInt64(&totalGroupMembers, int64(resp.TotalItems))
 		})
 
 	}
 	gw.Wait()
 
-	return totalGroupMembers
+	return int(totalGroupMembers)
 }
 
 func (c *userCollector) Collect() collectors.SubCollectorChannels {
@@ -62,7 +63,7 @@ func (c *userCollector) Collect() collectors.SubCollectorChannels {
 			return
 		}
 
-		gw := group_waiter.New()
+		gw := c.NewGroupWaiter()
 
 		for _, group := range allGroups {
 			g := group
@@ -76,7 +77,7 @@ func (c *userCollector) Collect() collectors.SubCollectorChannels {
 }
 
 func (c *userCollector) collectGroupUsers(group *gitlab2.Group) {
-	gw := group_waiter.New()
+	gw := c.NewGroupWaiter()
 
 	members, err := c.Client.GroupMembers(group)
 	if err != nil {
@@ -21,8 +21,11 @@ func NewMissingPermission(permission, entity, effect string, namespace namespace
 	}
 }
 
-func CollectMissingPermissions(missingPermissionChan chan MissingPermission) {
+// CollectMissingPermissions records the missing permissions in the error log and in the collection log of the scan
+func CollectMissingPermissions(missingPermissionChan chan MissingPermission, collection *errlog.CollectionLog) {
 	for permission := range missingPermissionChan {
-		errlog.AddPermIssue(errlog.NewPermIssue(permission.Permission, permission.Entity, permission.Namespace, permission.Effect))
+		issue := errlog.NewPermIssue(permission.Permission, permission.Entity, permission.Namespace, permission.Effect)
+		errlog.AddPermIssue(issue)
+		collection.AddPermIssue(issue)
 	}
 }
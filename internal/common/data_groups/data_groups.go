@@ -0,0 +1,134 @@
+package data_groups
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DataGroup is a part of the repository data that is collected by dedicated API calls,
+// and can be left out of the collection (see --collect) to reduce the API cost of a scan.
+type DataGroup = string
+
+const (
+	BranchProtection       DataGroup = "branch-protection"
+	Rulesets               DataGroup = "rulesets"
+	VulnerabilityAlerts    DataGroup = "vulnerability-alerts"
+	Hooks                  DataGroup = "hooks"
+	DeployKeys             DataGroup = "deploy-keys"
+	Pages                  DataGroup = "pages"
+	Codeowners             DataGroup = "codeowners"
+	Environments           DataGroup = "environments"
+	Collaborators          DataGroup = "collaborators"
+	Actions                DataGroup = "actions"
+	Integrations           DataGroup = "integrations"
+	VulnerabilityReporting DataGroup = "vulnerability-reporting"
+	Secrets                DataGroup = "secrets"
+	Dependencies           DataGroup = "dependencies"
+	SecurityAndAnalysis    DataGroup = "security-and-analysis"
+	Releases               DataGroup = "releases"
+)
+
+var All = []DataGroup{
+	BranchProtection,
+	Rulesets,
+	VulnerabilityAlerts,
+	Hooks,
+	DeployKeys,
+	Pages,
+	Codeowners,
+	Environments,
+	Collaborators,
+	Actions,
+	Integrations,
+	VulnerabilityReporting,
+	Secrets,
+	Dependencies,
+	SecurityAndAnalysis,
+	Releases,
+}
+
+// repositoryFields are the input fields (of the repository policies) that each group populates
+var repositoryFields = map[DataGroup][]string{
+	BranchProtection:       {"repository.default_branch.branch_protection_rule", "no_branch_protection_permission", "protection_present_but_no_reviews", "required_signatures.branch_protection", "required_signatures.effective", "effective_protection"},
+	Rulesets:               {"rules_set", "merge_queue_required", "required_signatures.rulesets", "required_signatures.effective", "effective_protection"},
+	VulnerabilityAlerts:    {"vulnerability_alerts_enabled", "automated_security_fixes"},
+	Hooks:                  {"hooks"},
+	DeployKeys:             {"deploy_keys"},
+	Pages:                  {"pages"},
+	Codeowners:             {"codeowners"},
+	Environments:           {"environments"},
+	Collaborators:          {"collaborators", "collaborators_count", "teams", "effective_permissions"},
+	Actions:                {"actions_token_permissions", "actions_fork_pr_approval", "workflows", "self_hosted_runners"},
+	Integrations:           {"integrations"},
+	VulnerabilityReporting: {"private_vulnerability_reporting_enabled"},
+	Secrets:                {"repository_secrets"},
+	Dependencies:           {"dependency_graph_manifests", "dependency_ecosystems", "dependency_management_files"},
+	SecurityAndAnalysis:    {"security_and_analysis", "secret_scanning_features"},
+	Releases:               {"latest_release"},
+}
+
+const repositoryPoliciesPrefix = "data.repository."
+
+func Validate(group DataGroup) error {
+	for _, e := range All {
+		if e == group {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid data group %s (valid groups: %s)", group, strings.Join(All, ", "))
+}
+
+// IsSelected reports whether the group is collected. An empty selection selects all the groups.
+func IsSelected(selected []DataGroup, group DataGroup) bool {
+	if len(selected) == 0 {
+		return true
+	}
+	for _, e := range selected {
+		if e == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Required returns the groups the input fields of a policy depend on.
+// Only the repository policies depend on groups.
+func Required(fullyQualifiedPolicyName string, fields []string) []DataGroup {
+	if !strings.HasPrefix(fullyQualifiedPolicyName, repositoryPoliciesPrefix) {
+		return nil
+	}
+
+	required := make(map[DataGroup]bool)
+	for _, field := range fields {
+		for group, prefixes := range repositoryFields {
+			for _, prefix := range prefixes {
+				if field == prefix || strings.HasPrefix(field, prefix+".") {
+					required[group] = true
+				}
+			}
+		}
+	}
+
+	result := make([]DataGroup, 0, len(required))
+	for group := range required {
+		result = append(result, group)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Unselected returns the first group the input fields of a policy depend on that is not selected, if any
+func Unselected(fullyQualifiedPolicyName string, fields []string, selected []DataGroup) (DataGroup, bool) {
+	if len(selected) == 0 {
+		return "", false
+	}
+
+	for _, group := range Required(fullyQualifiedPolicyName, fields) {
+		if !IsSelected(selected, group) {
+			return group, true
+		}
+	}
+	return "", false
+}
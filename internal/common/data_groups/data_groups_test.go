@@ -0,0 +1,87 @@
+package data_groups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	for _, group := range All {
+		require.Nil(t, Validate(group))
+	}
+	require.NotNil(t, Validate("branch"))
+
+	for group := range repositoryFields {
+		require.Nil(t, Validate(group), "expecting the fields of %s to belong to a valid group", group)
+	}
+}
+
+func TestIsSelected(t *testing.T) {
+	require.True(t, IsSelected(nil, Hooks), "expecting an empty selection to select all the groups")
+	require.True(t, IsSelected([]DataGroup{Pages, Hooks}, Hooks))
+	require.False(t, IsSelected([]DataGroup{Pages}, Hooks))
+}
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		fields   []string
+		expected []DataGroup
+	}{
+		{
+			name:     "exact field",
+			policy:   "data.repository.repository_webhook_no_secret",
+			fields:   []string{"hooks", "repository.name"},
+			expected: []DataGroup{Hooks},
+		},
+		{
+			name:     "nested field",
+			policy:   "data.repository.code_review_not_required",
+			fields:   []string{"repository.default_branch.branch_protection_rule.requires_approving_reviews"},
+			expected: []DataGroup{BranchProtection},
+		},
+		{
+			name:     "field shared by several groups",
+			policy:   "data.repository.no_signed_commits",
+			fields:   []string{"required_signatures.effective"},
+			expected: []DataGroup{BranchProtection, Rulesets},
+		},
+		{
+			name:     "field with a group field as its prefix",
+			policy:   "data.repository.policy",
+			fields:   []string{"hooks_count", "pages_url"},
+			expected: []DataGroup{},
+		},
+		{
+			name:   "not a repository policy",
+			policy: "data.organization.organization_webhook_no_secret",
+			fields: []string{"hooks"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, Required(test.policy, test.fields))
+		})
+	}
+}
+
+func TestUnselected(t *testing.T) {
+	const policy = "data.repository.policy"
+	fields := []string{"hooks", "pages.https_enforced"}
+
+	_, unselected := Unselected(policy, fields, nil)
+	require.False(t, unselected, "expecting an empty selection to select all the groups")
+
+	_, unselected = Unselected(policy, fields, []DataGroup{Hooks, Pages})
+	require.False(t, unselected)
+
+	group, unselected := Unselected(policy, fields, []DataGroup{Pages})
+	require.True(t, unselected)
+	require.Equal(t, Hooks, group)
+
+	_, unselected = Unselected(policy, []string{"repository.name"}, []DataGroup{Pages})
+	require.False(t, unselected, "expecting a policy of no group to be selected")
+}
@@ -10,6 +10,7 @@ type Waitable interface {
 
 type GroupWaiter struct {
 	waitGroup *sync.WaitGroup
+	onPanic   func(recovered interface{})
 }
 
 func New() *GroupWaiter {
@@ -18,10 +19,25 @@ func New() *GroupWaiter {
 	}
 }
 
+// NewRecovering returns a GroupWaiter whose goroutines recover from panics and report them to onPanic,
+// so a failure of a single goroutine does not abort the whole program.
+func NewRecovering(onPanic func(recovered interface{})) *GroupWaiter {
+	gw := New()
+	gw.onPanic = onPanic
+	return gw
+}
+
 func (gw *GroupWaiter) Do(f func()) {
 	gw.waitGroup.Add(1)
 	go func() {
 		defer gw.waitGroup.Done()
+		if gw.onPanic != nil {
+			defer func() {
+				if r := recover(); r != nil {
+					gw.onPanic(r)
+				}
+			}()
+		}
 		f()
 	}()
 }
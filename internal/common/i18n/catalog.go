@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/util"
+)
+
+// English is the language of the policies metadata, which needs no catalog
+const English = "en"
+
+var catalogExtensions = []string{".yaml", ".yml", ".json"}
+
+// Messages are the localized texts of a policy. Empty fields fall back to the (English) policy metadata.
+type Messages struct {
+	Title            string   `json:"title,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	Threat           []string `json:"threat,omitempty"`
+	RemediationSteps []string `json:"remediationSteps,omitempty"`
+}
+
+// Catalog maps a policy name to its localized texts.
+// A key may be qualified by the namespace (e.g. repository.repository_not_maintained)
+// to localize a policy name that exists in several namespaces differently.
+type Catalog map[string]Messages
+
+func (c Catalog) Lookup(ns, policyName string) (Messages, bool) {
+	if messages, ok := c[ns+"."+policyName]; ok {
+		return messages, true
+	}
+	messages, ok := c[policyName]
+	return messages, ok
+}
+
+func IsEnglish(lang string) bool {
+	return lang == "" || strings.EqualFold(lang, English)
+}
+
+// Load reads the catalog of the language (<lang>.yaml, <lang>.yml or <lang>.json) from the messages directory.
+// English needs no catalog, so a nil catalog is returned for it.
+func Load(lang string, messagesPath string) (Catalog, error) {
+	if IsEnglish(lang) {
+		return nil, nil
+	}
+	if strings.ContainsAny(lang, `/\.`) {
+		return nil, fmt.Errorf("invalid language: %s", lang)
+	}
+
+	for _, ext := range catalogExtensions {
+		path := filepath.Join(messagesPath, lang+ext)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read message catalog: %v", err)
+		}
+
+		var catalog Catalog
+		if err = util.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("failed to parse message catalog %s: %v", path, err)
+		}
+		return catalog, nil
+	}
+
+	return nil, fmt.Errorf("no message catalog for language %s in %s", lang, messagesPath)
+}
@@ -65,7 +65,13 @@ func UnorderMap(m *orderedmap.OrderedMap) map[string]interface{} {
 func UnorderMapTypedValues[T any](m *orderedmap.OrderedMap) map[string]T {
 	newM := make(map[string]T, len(m.Keys()))
 	for _, k := range m.Keys() {
-		newM[k] = UnsafeGetUntyped(m, k).(T)
+		// null values (e.g. decoded from a json null) are kept as the zero value
+		if v := UnsafeGetUntyped(m, k); v != nil {
+			newM[k] = v.(T)
+		} else {
+			var zero T
+			newM[k] = zero
+		}
 	}
 	return newM
 }
@@ -16,6 +16,9 @@ const (
 	GroupRoleMember = OrgRoleMember
 )
 
+// InstanceRoleAdmin is the role of the administrators of a GitLab self-managed instance
+const InstanceRoleAdmin Role = "INSTANCE-ADMIN"
+
 type RepositoryRole = string
 
 const (
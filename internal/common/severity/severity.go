@@ -30,3 +30,20 @@ func IsValid(severity Severity) bool {
 func Less(first, second Severity) bool {
 	return all[first] < all[second]
 }
+
+var byLevel = []Severity{Critical, High, Medium, Low}
+
+// Raise returns the severity raised by the given number of levels (up to Critical).
+// Unknown severities are not raised.
+func Raise(severity Severity, levels int) Severity {
+	level, ok := all[severity]
+	if !ok || severity == Unknown {
+		return severity
+	}
+
+	level -= levels
+	if level < 0 {
+		level = 0
+	}
+	return byLevel[level]
+}
@@ -3,7 +3,13 @@ package context_utils
 import (
 	"context"
 
+	"github.com/Legit-Labs/legitify/internal/common/data_groups"
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
 	"github.com/Legit-Labs/legitify/internal/common/types"
+	"github.com/Legit-Labs/legitify/internal/coverage"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/inventory"
+	"github.com/Legit-Labs/legitify/internal/protection"
 
 	"github.com/Legit-Labs/legitify/internal/common/permissions"
 )
@@ -19,6 +25,23 @@ const (
 	isCloudKey                    contextKey = "isCloud"
 	simulateSecondaryRateLimitKey contextKey = "simulateSecondaryRateLimit"
 	ignoredPoliciesKey            contextKey = "ignoredPolicies"
+	publicSeverityBumpKey         contextKey = "publicSeverityBump"
+	memoryBudgetKey               contextKey = "memoryBudget"
+	actionsStorageKey             contextKey = "actionsStorage"
+	skipSelfHostedRunnersKey      contextKey = "skipSelfHostedRunners"
+	workflowContentsKey           contextKey = "workflowContents"
+	onlyFailuresKey               contextKey = "onlyFailures"
+	outputProfileKey              contextKey = "outputProfile"
+	scorecardConcurrencyKey       contextKey = "scorecardConcurrency"
+	messageCatalogKey             contextKey = "messageCatalog"
+	dataGroupsKey                 contextKey = "dataGroups"
+	maxRepositoriesKey            contextKey = "maxRepositories"
+	baselineKey                   contextKey = "baseline"
+	policiesConfigKey             contextKey = "policiesConfig"
+	coverageKey                   contextKey = "coverage"
+	collectionLogKey              contextKey = "collectionLog"
+	inventoryKey                  contextKey = "inventory"
+	protectionReportKey           contextKey = "protectionReport"
 )
 
 func NewContextWithRepos(repos []types.RepositoryWithOwner) context.Context {
@@ -51,6 +74,81 @@ func NewContextWithIgnoredPolicies(ctx context.Context, ignoredPolicies []string
 	return context.WithValue(ctx, ignoredPoliciesKey, ignoredPolicies)
 }
 
+func NewContextWithPublicSeverityBump(ctx context.Context, levels int) context.Context {
+	return context.WithValue(ctx, publicSeverityBumpKey, levels)
+}
+
+func NewContextWithMemoryBudget(ctx context.Context, budgetBytes int64) context.Context {
+	return context.WithValue(ctx, memoryBudgetKey, budgetBytes)
+}
+
+func NewContextWithActionsStorage(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, actionsStorageKey, enabled)
+}
+
+func NewContextWithWorkflowContents(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, workflowContentsKey, enabled)
+}
+
+func NewContextWithSkipSelfHostedRunners(ctx context.Context, skip bool) context.Context {
+	return context.WithValue(ctx, skipSelfHostedRunnersKey, skip)
+}
+
+func NewContextWithDataGroups(ctx context.Context, groups []data_groups.DataGroup) context.Context {
+	return context.WithValue(ctx, dataGroupsKey, groups)
+}
+
+func NewContextWithMaxRepositories(ctx context.Context, max int) context.Context {
+	return context.WithValue(ctx, maxRepositoriesKey, max)
+}
+
+// NewContextWithPoliciesConfig sets the lists of the policies config (see --policies-config),
+// so the collectors can skip the data that the policies have nothing to compare with
+func NewContextWithPoliciesConfig(ctx context.Context, lists map[string][]string) context.Context {
+	return context.WithValue(ctx, policiesConfigKey, lists)
+}
+
+// NewContextWithBaseline sets the fingerprints of the accepted violations (see --baseline)
+func NewContextWithBaseline(ctx context.Context, fingerprints map[string]bool) context.Context {
+	return context.WithValue(ctx, baselineKey, fingerprints)
+}
+
+// NewContextWithCoverage sets the report of the coverage of the policies of the scan (see --coverage-file)
+func NewContextWithCoverage(ctx context.Context, report *coverage.Report) context.Context {
+	return context.WithValue(ctx, coverageKey, report)
+}
+
+// NewContextWithCollectionLog sets the log the collection of the scan is recorded in (e.g. for --min-coverage)
+func NewContextWithCollectionLog(ctx context.Context, collection *errlog.CollectionLog) context.Context {
+	return context.WithValue(ctx, collectionLogKey, collection)
+}
+
+// NewContextWithInventory sets the inventory the collected entities of the scan are recorded in (see --inventory-file)
+func NewContextWithInventory(ctx context.Context, inv *inventory.Inventory) context.Context {
+	return context.WithValue(ctx, inventoryKey, inv)
+}
+
+// NewContextWithProtectionReport sets the report the protection of the collected repositories is recorded in (see --protection-report-file)
+func NewContextWithProtectionReport(ctx context.Context, report *protection.Report) context.Context {
+	return context.WithValue(ctx, protectionReportKey, report)
+}
+
+func NewContextWithOnlyFailures(ctx context.Context, onlyFailures bool) context.Context {
+	return context.WithValue(ctx, onlyFailuresKey, onlyFailures)
+}
+
+func NewContextWithOutputProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, outputProfileKey, profile)
+}
+
+func NewContextWithScorecardConcurrency(ctx context.Context, concurrency int) context.Context {
+	return context.WithValue(ctx, scorecardConcurrencyKey, concurrency)
+}
+
+func NewContextWithMessageCatalog(ctx context.Context, catalog i18n.Catalog) context.Context {
+	return context.WithValue(ctx, messageCatalogKey, catalog)
+}
+
 func GetTokenScopes(ctx context.Context) permissions.TokenScopes {
 	return ctx.Value(tokenScopesKey).(permissions.TokenScopes)
 }
@@ -89,3 +187,112 @@ func GetIgnoredPolicies(ctx context.Context) []string {
 
 	return val
 }
+
+func GetPublicSeverityBump(ctx context.Context) int {
+	val, ok := ctx.Value(publicSeverityBumpKey).(int)
+	if !ok {
+		return 0
+	}
+	return val
+}
+
+func GetMemoryBudget(ctx context.Context) int64 {
+	val, ok := ctx.Value(memoryBudgetKey).(int64)
+	if !ok {
+		return 0
+	}
+	return val
+}
+
+func GetActionsStorageEnabled(ctx context.Context) bool {
+	val, ok := ctx.Value(actionsStorageKey).(bool)
+	return ok && val
+}
+
+func GetWorkflowContentsEnabled(ctx context.Context) bool {
+	val, ok := ctx.Value(workflowContentsKey).(bool)
+	return ok && val
+}
+
+// GetSkipSelfHostedRunners returns whether the self-hosted runners of the repositories should not be collected (see --skip-self-hosted-runners)
+func GetSkipSelfHostedRunners(ctx context.Context) bool {
+	val, ok := ctx.Value(skipSelfHostedRunnersKey).(bool)
+	return ok && val
+}
+
+// GetMaxRepositories returns the maximum number of repositories to collect per organization (0 means unlimited)
+func GetMaxRepositories(ctx context.Context) int {
+	val, _ := ctx.Value(maxRepositoriesKey).(int)
+	return val
+}
+
+// GetDataGroups returns the selected data groups to collect. An empty selection selects all the groups.
+func GetDataGroups(ctx context.Context) []data_groups.DataGroup {
+	val, _ := ctx.Value(dataGroupsKey).([]data_groups.DataGroup)
+	return val
+}
+
+// GetConfigList returns a list of the policies config (empty when it is not configured)
+func GetConfigList(ctx context.Context, key string) []string {
+	val, _ := ctx.Value(policiesConfigKey).(map[string][]string)
+	return val[key]
+}
+
+// GetBaseline returns the fingerprints of the accepted violations (nil when there is no baseline)
+func GetBaseline(ctx context.Context) map[string]bool {
+	val, _ := ctx.Value(baselineKey).(map[string]bool)
+	return val
+}
+
+// GetCoverage returns the report of the coverage of the policies (nil when the coverage is not reported)
+func GetCoverage(ctx context.Context) *coverage.Report {
+	val, _ := ctx.Value(coverageKey).(*coverage.Report)
+	return val
+}
+
+// GetCollectionLog returns the log the collection of the scan is recorded in (nil when the collection is not a scan)
+func GetCollectionLog(ctx context.Context) *errlog.CollectionLog {
+	val, _ := ctx.Value(collectionLogKey).(*errlog.CollectionLog)
+	return val
+}
+
+// GetInventory returns the inventory of the collected entities (nil when the inventory is not written)
+func GetInventory(ctx context.Context) *inventory.Inventory {
+	val, _ := ctx.Value(inventoryKey).(*inventory.Inventory)
+	return val
+}
+
+// GetProtectionReport returns the report of the protection of the collected repositories (nil when the report is not written)
+func GetProtectionReport(ctx context.Context) *protection.Report {
+	val, _ := ctx.Value(protectionReportKey).(*protection.Report)
+	return val
+}
+
+func GetOnlyFailures(ctx context.Context) bool {
+	val, ok := ctx.Value(onlyFailuresKey).(bool)
+	return ok && val
+}
+
+func GetOutputProfile(ctx context.Context) string {
+	val, ok := ctx.Value(outputProfileKey).(string)
+	if !ok {
+		return ""
+	}
+	return val
+}
+
+func GetScorecardConcurrency(ctx context.Context) int {
+	val, ok := ctx.Value(scorecardConcurrencyKey).(int)
+	if !ok {
+		return 0
+	}
+	return val
+}
+
+func GetMessageCatalog(ctx context.Context) i18n.Catalog {
+	val, ok := ctx.Value(messageCatalogKey).(i18n.Catalog)
+	if !ok {
+		return nil
+	}
+	return val
+}
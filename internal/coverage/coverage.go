@@ -0,0 +1,159 @@
+package coverage
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EntityCoverage lists the fields referenced by a policy that were not populated for an entity
+// (e.g. nil due to a missing permission), along with the resulting status of the policy.
+type EntityCoverage struct {
+	Status        string   `json:"status"`
+	MissingFields []string `json:"missing_fields"`
+}
+
+type PolicyCoverage struct {
+	InputFields []string                  `json:"input_fields"`
+	Entities    map[string]EntityCoverage `json:"entities"`
+}
+
+// Report records the coverage of the policies of a scan (see --coverage-file).
+// A nil report records nothing, so it can be passed down whether or not the coverage is reported.
+type Report struct {
+	lock         sync.Mutex
+	writer       io.Writer
+	policyFields map[string][]string
+	policies     map[string]*PolicyCoverage
+}
+
+// NewReport starts recording the coverage of the policies; the report is written to the writer by Flush
+func NewReport(writer io.Writer) *Report {
+	return &Report{
+		writer:       writer,
+		policyFields: make(map[string][]string),
+		policies:     make(map[string]*PolicyCoverage),
+	}
+}
+
+// AddPolicyFields registers the input fields each policy references (see opa.PolicyInputFields).
+// The fields of policies that share a name (e.g. when analyzing both GitHub and GitLab) are merged.
+func (r *Report) AddPolicyFields(policyFields map[string][]string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for policyName, fields := range policyFields {
+		merged := make(map[string]bool)
+		for _, field := range r.policyFields[policyName] {
+			merged[field] = true
+		}
+		for _, field := range fields {
+			merged[field] = true
+		}
+
+		r.policyFields[policyName] = make([]string, 0, len(merged))
+		for field := range merged {
+			r.policyFields[policyName] = append(r.policyFields[policyName], field)
+		}
+		sort.Strings(r.policyFields[policyName])
+	}
+}
+
+func (r *Report) Enabled() bool {
+	return r != nil
+}
+
+// InputDocument converts an entity to the document the policies see as input
+func InputDocument(entity interface{}) (interface{}, error) {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err = json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// Add records the fields of the policy that were not populated in the input document of the entity
+func (r *Report) Add(policyName string, entityName string, status string, document interface{}) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	fields := r.policyFields[policyName]
+	policy, ok := r.policies[policyName]
+	if !ok {
+		policy = &PolicyCoverage{
+			InputFields: fields,
+			Entities:    make(map[string]EntityCoverage),
+		}
+		if policy.InputFields == nil {
+			policy.InputFields = []string{}
+		}
+		r.policies[policyName] = policy
+	}
+
+	missing := []string{}
+	for _, field := range fields {
+		if !populated(document, strings.Split(field, ".")) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		policy.Entities[entityName] = EntityCoverage{
+			Status:        status,
+			MissingFields: missing,
+		}
+	}
+}
+
+// populated reports whether the path leads to a non-null value.
+// Paths that go through arrays are considered populated once the array is reached.
+func populated(document interface{}, path []string) bool {
+	current := document
+	for _, key := range path {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			next, ok := value[key]
+			if !ok || next == nil {
+				return false
+			}
+			current = next
+		case []interface{}:
+			return true
+		default:
+			return false
+		}
+	}
+
+	return current != nil
+}
+
+// Flush writes the report (keyed by the fully qualified policy name)
+func (r *Report) Flush() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data, err := json.MarshalIndent(r.policies, "", "  ")
+	if err != nil {
+		log.Printf("failed to marshal the policies coverage report: %v", err)
+		return
+	}
+	if _, err = r.writer.Write(data); err != nil {
+		log.Printf("failed to write the policies coverage report: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package coverage
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageReport(t *testing.T) {
+	var buf bytes.Buffer
+	report := NewReport(&buf)
+
+	report.AddPolicyFields(map[string][]string{
+		"data.repository.policy": {"repository.is_private", "scorecard.score", "teams.permission"},
+	})
+
+	type entity struct {
+		Repository map[string]bool   `json:"repository"`
+		Scorecard  *struct{}         `json:"scorecard"`
+		Teams      []map[string]bool `json:"teams"`
+	}
+	populated, err := InputDocument(entity{Repository: map[string]bool{"is_private": false}, Teams: []map[string]bool{}})
+	require.Nil(t, err)
+	report.Add("data.repository.policy", "repo1", "PASSED", populated)
+
+	missing, err := InputDocument(entity{})
+	require.Nil(t, err)
+	report.Add("data.repository.policy", "repo2", "SKIPPED", missing)
+
+	report.Flush()
+
+	var written map[string]PolicyCoverage
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &written))
+	policy := written["data.repository.policy"]
+	require.Len(t, policy.InputFields, 3)
+	require.Equal(t, []string{"scorecard.score"}, policy.Entities["repo1"].MissingFields)
+	require.Equal(t, EntityCoverage{
+		Status:        "SKIPPED",
+		MissingFields: []string{"repository.is_private", "scorecard.score", "teams.permission"},
+	}, policy.Entities["repo2"])
+}
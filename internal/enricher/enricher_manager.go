@@ -2,6 +2,7 @@ package enricher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/Legit-Labs/legitify/internal/analyzers"
 	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"github.com/Legit-Labs/legitify/internal/common/severity"
 	"github.com/Legit-Labs/legitify/internal/enricher/enrichers"
 	"github.com/open-policy-agent/opa/ast"
@@ -39,18 +41,26 @@ type EnrichedData struct {
 	Threat                   []string
 	RemediationSteps         []string
 	Severity                 severity.Severity
+	Category                 string
 	CanonicalLink            string
 	Status                   analyzers.PolicyStatus
+	// ScmType is only set when aggregating results of multiple providers
+	ScmType scm_type.ScmType
 }
 
 var mapping = map[string]enrichers.Enricher{
-	enrichers.EntityId:       enrichers.NewEntityIdEnricher(),
-	enrichers.EntityName:     enrichers.NewEntityNameEnricher(),
-	enrichers.OrganizationId: enrichers.NewOrganizationIdEnricher(),
-	enrichers.Scorecard:      enrichers.NewScorecardEnricher(),
-	enrichers.MembersList:    enrichers.NewMembersListEnricher(),
-	enrichers.HooksList:      enrichers.NewHooksListEnricher(),
-	enrichers.SecretsList:    enrichers.NewSecretsListEnricher(),
+	enrichers.EntityId:         enrichers.NewEntityIdEnricher(),
+	enrichers.EntityName:       enrichers.NewEntityNameEnricher(),
+	enrichers.OrganizationId:   enrichers.NewOrganizationIdEnricher(),
+	enrichers.Scorecard:        enrichers.NewScorecardEnricher(),
+	enrichers.MembersList:      enrichers.NewMembersListEnricher(),
+	enrichers.HooksList:        enrichers.NewHooksListEnricher(),
+	enrichers.SecretsList:      enrichers.NewSecretsListEnricher(),
+	enrichers.IntegrationsList: enrichers.NewIntegrationsListEnricher(),
+	enrichers.AdminsList:       enrichers.NewAdminsListEnricher(),
+	enrichers.RepositoriesList: enrichers.NewRepositoriesListEnricher(),
+	enrichers.UsersList:        enrichers.NewUsersListEnricher(),
+	enrichers.WorkflowsList:    enrichers.NewWorkflowsListEnricher(),
 }
 
 func NewEnricherManager() EnricherManager {
@@ -106,11 +116,14 @@ func (e *enricherManager) Parse(name string, data interface{}) (enrichers.Enrich
 	return enricher.Parse(data)
 }
 
+// ErrUnknownEnricher is returned for enrichers that are not known to this version of legitify
+var ErrUnknownEnricher = errors.New("unknown enricher")
+
 func (e *enricherManager) getEnricher(name string) (enrichers.Enricher, error) {
 	if e, ok := mapping[name]; ok {
 		return e, nil
 	} else {
-		return nil, fmt.Errorf("failed to find enricher %s", name)
+		return nil, fmt.Errorf("failed to find enricher %s: %w", name, ErrUnknownEnricher)
 	}
 }
 
@@ -126,6 +139,7 @@ func newEnrichedData(analyzed analyzers.AnalyzedData, enrichments map[string]enr
 		Enrichers:                enrichments,
 		Threat:                   analyzed.Threat,
 		Severity:                 analyzed.Severity,
+		Category:                 analyzed.Category,
 		RemediationSteps:         analyzed.RemediationSteps,
 		CanonicalLink:            analyzed.CanonicalLink,
 		Status:                   analyzed.Status,
@@ -7,7 +7,9 @@ import (
 	"github.com/Legit-Labs/legitify/internal/collected"
 
 	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/common/map_utils"
 	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/enricher/enrichers"
 	"github.com/google/go-github/v53/github"
 
 	"github.com/Legit-Labs/legitify/internal/analyzers"
@@ -108,3 +110,27 @@ func TestEnricher_PolicyWithEnricher_EnrichData(t *testing.T) {
 		require.Equalf(t, len(outgoingMessage.Enrichers), 2, "A policy with no enrichers should enrich data twice (default enrichers)")
 	}
 }
+
+func TestEnricher_WorkflowsList(t *testing.T) {
+	enricherData := arrangeEnricher(t)
+	data := make(chan analyzers.AnalyzedData, 1)
+	data <- analyzers.AnalyzedData{
+		Entity:                   arbitraryEntity(),
+		PolicyName:               "A Policy",
+		FullyQualifiedPolicyName: "A Full Policy",
+		RequiredEnrichers:        []string{enrichers.WorkflowsList},
+		ExtraData: map[string]interface{}{
+			`{"job":"build","path":".github/workflows/b.yml"}`: true,
+			`{"path":".github/workflows/a.yml"}`:               true,
+		},
+	}
+	close(data)
+
+	for outgoingMessage := range enricherData.e.Enrich(enricherData.ctx, data) {
+		list, ok := outgoingMessage.Enrichers[enrichers.WorkflowsList].(enrichers.GenericListEnrichment)
+		require.True(t, ok, "expecting the workflows to be listed")
+		require.Len(t, list, 2)
+		require.Equal(t, ".github/workflows/a.yml", map_utils.UnsafeGet[string](&list[0], "path"), "expecting the workflows to be sorted by path")
+		require.Equal(t, "build", map_utils.UnsafeGet[string](&list[1], "job"))
+	}
+}
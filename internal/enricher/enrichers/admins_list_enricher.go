@@ -0,0 +1,92 @@
+package enrichers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/Legit-Labs/legitify/internal/common/slice_utils"
+)
+
+const AdminsList = "adminsList"
+
+const (
+	githubAdminPermission  = "admin"
+	gitlabOwnerAccessLevel = 50
+)
+
+func NewAdminsListEnricher() adminsListEnricher {
+	return adminsListEnricher{}
+}
+
+// adminsListEnricher lists the users and teams that have admin access to the violating entity.
+// users are prefixed with '@' and teams with 'team:'.
+type adminsListEnricher struct {
+}
+
+func (e adminsListEnricher) Enrich(_ context.Context, data analyzers.AnalyzedData) (Enrichment, bool) {
+	var admins []string
+
+	switch entity := data.Entity.(type) {
+	case githubcollected.Repository:
+		admins = githubRepositoryAdmins(entity)
+	case gitlab_collected.Repository:
+		admins = gitlabRepositoryAdmins(entity)
+	default:
+		return nil, false
+	}
+
+	sort.Strings(admins)
+	return AdminsListEnrichment(admins), true
+}
+
+func (e adminsListEnricher) Parse(data interface{}) (Enrichment, error) {
+	if val, ok := data.([]interface{}); !ok {
+		return nil, fmt.Errorf("expecting []string, found %T", data)
+	} else {
+		casted := slice_utils.CastInterfaces[string](val)
+		return AdminsListEnrichment(casted), nil
+	}
+}
+
+func githubRepositoryAdmins(repository githubcollected.Repository) []string {
+	admins := []string{}
+	for _, collaborator := range repository.Collaborators {
+		if collaborator.GetPermissions()[githubAdminPermission] {
+			admins = append(admins, "@"+collaborator.GetLogin())
+		}
+	}
+	for _, team := range repository.Teams {
+		if team.GetPermission() == githubAdminPermission {
+			admins = append(admins, "team:"+team.GetSlug())
+		}
+	}
+	return admins
+}
+
+func gitlabRepositoryAdmins(repository gitlab_collected.Repository) []string {
+	admins := []string{}
+	for _, member := range repository.Members {
+		if member.AccessLevel >= gitlabOwnerAccessLevel {
+			admins = append(admins, "@"+member.Username)
+		}
+	}
+	if repository.Project != nil {
+		for _, group := range repository.Project.SharedWithGroups {
+			if group.GroupAccessLevel >= gitlabOwnerAccessLevel {
+				admins = append(admins, "team:"+group.GroupFullPath)
+			}
+		}
+	}
+	return admins
+}
+
+type AdminsListEnrichment []string
+
+func (se AdminsListEnrichment) HumanReadable(_ string, _ string) string {
+	return fmt.Sprintf("%d admins: %s", len(se), strings.Join(se, ", "))
+}
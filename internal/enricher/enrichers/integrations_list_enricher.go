@@ -0,0 +1,7 @@
+package enrichers
+
+const IntegrationsList = "integrationsList"
+
+func NewIntegrationsListEnricher() listEnricher {
+	return newListEnricher("integrations list", sortByField("name"))
+}
@@ -0,0 +1,74 @@
+package enrichers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/map_utils"
+	"github.com/iancoleman/orderedmap"
+	"golang.org/x/net/context"
+)
+
+// listEnricher lists the json-encoded objects the policy reported as the keys of its extra data,
+// ordered by the sort key to maintain a deterministic order
+type listEnricher struct {
+	name    string
+	sortKey func(entry *orderedmap.OrderedMap) string
+}
+
+// newListEnricher creates an enricher of a GenericListEnrichment ordered by the sort key
+func newListEnricher(name string, sortKey func(entry *orderedmap.OrderedMap) string) listEnricher {
+	return listEnricher{
+		name:    name,
+		sortKey: sortKey,
+	}
+}
+
+// sortByField orders the list by the (string) field of its entries
+func sortByField(field string) func(entry *orderedmap.OrderedMap) string {
+	return func(entry *orderedmap.OrderedMap) string {
+		return map_utils.UnsafeGet[string](entry, field)
+	}
+}
+
+func (e listEnricher) Enrich(_ context.Context, data analyzers.AnalyzedData) (Enrichment, bool) {
+	result, err := e.createEnrichment(data.ExtraData)
+	if err != nil {
+		log.Printf("failed to enrich %s: %v", e.name, err)
+		return nil, false
+	}
+	return result, true
+}
+
+func (e listEnricher) Parse(data interface{}) (Enrichment, error) {
+	return NewGenericListEnrichmentFromInterface(data)
+}
+
+func (e listEnricher) createEnrichment(extraData interface{}) (GenericListEnrichment, error) {
+	asMap, ok := extraData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid %s extra data", e.name)
+	}
+
+	result := []orderedmap.OrderedMap{}
+	for k := range asMap {
+		var enrichment map[string]string
+
+		err := json.Unmarshal([]byte(k), &enrichment)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *map_utils.ToKeySortedMap(enrichment))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Compare(e.sortKey(&result[i]), e.sortKey(&result[j])) < 0
+	})
+
+	return result, nil
+}
@@ -0,0 +1,7 @@
+package enrichers
+
+const RepositoriesList = "repositoriesList"
+
+func NewRepositoriesListEnricher() listEnricher {
+	return newListEnricher("repositories list", sortByField("name"))
+}
@@ -0,0 +1,8 @@
+package enrichers
+
+// UsersList lists the users a policy was violated by, when the entity has no member objects (e.g. only their logins)
+const UsersList = "usersList"
+
+func NewUsersListEnricher() listEnricher {
+	return newListEnricher("users list", sortByField("login"))
+}
@@ -0,0 +1,19 @@
+package enrichers
+
+import (
+	"github.com/Legit-Labs/legitify/internal/common/map_utils"
+	"github.com/iancoleman/orderedmap"
+)
+
+// WorkflowsList lists the workflow files (by their "path") that violate the policy,
+// so the violations can be located in the repository (e.g. annotated by a check run)
+const WorkflowsList = "workflowsList"
+
+func NewWorkflowsListEnricher() listEnricher {
+	// order by path (and job, which is optional)
+	return newListEnricher("workflows list", func(entry *orderedmap.OrderedMap) string {
+		job, _ := entry.Get("job")
+		jobName, _ := job.(string)
+		return map_utils.UnsafeGet[string](entry, "path") + "\x00" + jobName
+	})
+}
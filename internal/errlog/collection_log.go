@@ -0,0 +1,76 @@
+package errlog
+
+import (
+	"sync"
+)
+
+// CollectionLog records the collection of a scan: the entities collected per namespace and the permissions they were missing,
+// to tell how many of them were collected without missing permissions.
+// A nil log records nothing (e.g. when the collection is not a scan).
+type CollectionLog struct {
+	collected map[string]int
+	permLog   *PermLog
+	lock      sync.Mutex
+}
+
+func NewCollectionLog() *CollectionLog {
+	return &CollectionLog{
+		collected: make(map[string]int),
+		permLog:   NewPermLog(),
+	}
+}
+
+func (c *CollectionLog) Add(namespace string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.collected[namespace]++
+}
+
+func (c *CollectionLog) AddPermIssue(issue PermIssue) {
+	if c == nil {
+		return
+	}
+	c.permLog.Add(issue)
+}
+
+// MissingPermissions lists the permissions that were missing during the collection (the entities are qualified by their namespace)
+func (c *CollectionLog) MissingPermissions() []PermIssue {
+	if c == nil {
+		return nil
+	}
+	return c.permLog.Issues()
+}
+
+// Coverage returns the fraction (0-1) of the collected entities that were not partially blocked by missing permissions,
+// along with the number of collected entities and the number of them that were blocked. The coverage is 0 when no entities were collected.
+// The blocked entities of a namespace are capped by the collected ones, since missing permissions may be reported
+// for entities that were not collected at all.
+func (c *CollectionLog) Coverage() (coverage float64, collected int, blocked int) {
+	if c == nil {
+		return 0, 0, 0
+	}
+	blockedEntities := c.permLog.blockedEntities()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for namespace, count := range c.collected {
+		collected += count
+		blocked += min(blockedEntities[namespace], count)
+	}
+	if collected == 0 {
+		return 0, 0, 0
+	}
+	return float64(collected-blocked) / float64(collected), collected, blocked
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
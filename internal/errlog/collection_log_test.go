@@ -0,0 +1,52 @@
+package errlog
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionLogCoverage(t *testing.T) {
+	log := NewCollectionLog()
+	log.AddPermIssue(NewPermIssue("admin", "org/first", namespace.Repository, "cannot read the branch protection"))
+	coverage, collected, blocked := log.Coverage()
+	require.Zero(t, coverage, "expecting no coverage when nothing was collected")
+	require.Zero(t, collected)
+	require.Zero(t, blocked, "expecting the blocked entities of namespaces that were not collected to be ignored")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Add(namespace.Repository)
+		}()
+	}
+	wg.Wait()
+	// the same entity is counted once, whatever the number of its missing permissions
+	log.AddPermIssue(NewPermIssue("maintain", "org/first", namespace.Repository, "cannot read the webhooks"))
+	log.AddPermIssue(NewPermIssue("owner", "org", namespace.Organization, "cannot read the organization settings"))
+
+	coverage, collected, blocked = log.Coverage()
+	require.Equal(t, 4, collected)
+	require.Equal(t, 1, blocked)
+	require.Equal(t, 0.75, coverage)
+
+	log.Add(namespace.Organization)
+	for i := 0; i < 4; i++ {
+		log.AddPermIssue(NewPermIssue("admin", fmt.Sprintf("org/repo%d", i), namespace.Repository, "cannot read the branch protection"))
+	}
+	_, collected, blocked = log.Coverage()
+	require.Equal(t, 5, collected)
+	require.Equal(t, 5, blocked, "expecting the blocked entities to be capped by the collected ones")
+
+	var unrecorded *CollectionLog
+	unrecorded.Add(namespace.Repository)
+	coverage, collected, blocked = unrecorded.Coverage()
+	require.Zero(t, coverage)
+	require.Zero(t, collected)
+	require.Zero(t, blocked)
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync/atomic"
 )
 
 type forwarder struct{}
@@ -16,22 +17,41 @@ func (f *forwarder) Write(p []byte) (n int, err error) {
 
 type errlog struct {
 	log         *log.Logger
-	everWritten bool
-	permIssues  bool
+	everWritten atomic.Bool
+	permIssues  atomic.Bool
 	skiplog     *SkipLog
 	permLog     *PermLog
+	nsLog       *NamespaceLog
 	permWriter  io.Writer
 }
 
-var singletone errlog
+var singletone *errlog
+
+func newErrlog(writer io.Writer) *errlog {
+	return &errlog{
+		log:     log.New(writer, "", log.LstdFlags),
+		skiplog: NewSkipLog(),
+		permLog: NewPermLog(),
+		nsLog:   NewNamespaceLog(),
+	}
+}
 
 func init() {
-	singletone.log = log.New(os.Stderr, "", log.LstdFlags)
-	singletone.skiplog = NewSkipLog()
-	singletone.permLog = NewPermLog()
+	singletone = newErrlog(os.Stderr)
 	log.SetOutput(&forwarder{})
 }
 
+// Isolate replaces the recorded issues with an empty record (which is not written anywhere) until restore is called,
+// so tests can inspect the issues they cause. It must not be called while issues are being recorded.
+func Isolate() (restore func()) {
+	previous := singletone
+	singletone = newErrlog(io.Discard)
+	singletone.permWriter = io.Discard
+	return func() {
+		singletone = previous
+	}
+}
+
 func SetOutput(writer io.Writer) {
 	singletone.log.SetOutput(writer)
 }
@@ -41,7 +61,7 @@ func SetPermissionsOutput(writer io.Writer) {
 }
 
 func Printf(format string, args ...interface{}) {
-	singletone.everWritten = true
+	singletone.everWritten.Store(true)
 	singletone.log.Printf(format, args...)
 }
 
@@ -53,21 +73,41 @@ func AddSkipIssue(policyName string, entityName string, skipReason SkipReason) {
 	singletone.skiplog.Add(policyName, entityName, skipReason)
 }
 
+// SkipReasonOf returns the reason the policy was skipped for the entity
+func SkipReasonOf(policyName string, entityName string) (SkipReason, bool) {
+	return singletone.skiplog.Reason(policyName, entityName)
+}
+
+// AddNamespaceFailure records a namespace that could not be collected at all
+func AddNamespaceFailure(namespace string, reason string) {
+	Printf("skipping the %s namespace: %s", namespace, reason)
+	singletone.nsLog.Add(namespace, reason)
+}
+
+// FailedNamespaces returns the namespaces that could not be collected, mapped to the reason
+func FailedNamespaces() map[string]string {
+	return singletone.nsLog.Reasons()
+}
+
 type PermissionsOutput struct {
-	Permissions     interface{} `json:"missing_permissions"`
-	SkippedPolicies interface{} `json:"skipped_policies"`
+	Permissions       interface{} `json:"missing_permissions"`
+	SkippedPolicies   interface{} `json:"skipped_policies"`
+	SkippedNamespaces interface{} `json:"skipped_namespaces,omitempty"`
 }
 
 func FlushAll() {
-	if singletone.permLog.Empty() && singletone.skiplog.Empty() {
+	if singletone.permLog.Empty() && singletone.skiplog.Empty() && singletone.nsLog.Empty() {
 		return
 	}
-	singletone.permIssues = true
+	singletone.permIssues.Store(true)
 
 	issuesOutput := PermissionsOutput{
 		Permissions:     singletone.permLog,
 		SkippedPolicies: singletone.skiplog,
 	}
+	if !singletone.nsLog.Empty() {
+		issuesOutput.SkippedNamespaces = singletone.nsLog
+	}
 
 	permIssues, err := json.MarshalIndent(issuesOutput, "", "  ")
 	if err != nil {
@@ -80,8 +120,8 @@ func FlushAll() {
 }
 
 func HadErrors() bool {
-	return singletone.everWritten
+	return singletone.everWritten.Load()
 }
 func HadPermIssues() bool {
-	return singletone.permIssues
+	return singletone.permIssues.Load()
 }
@@ -0,0 +1,55 @@
+package errlog
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// NamespaceLog records the namespaces whose collection failed altogether (e.g. no access to the organizations),
+// while the other namespaces were still collected
+type NamespaceLog struct {
+	namespaces map[string]string
+	lock       sync.Mutex
+}
+
+func NewNamespaceLog() *NamespaceLog {
+	return &NamespaceLog{
+		namespaces: make(map[string]string),
+	}
+}
+
+// Add records the failure of the namespace (only the first reason is kept)
+func (n *NamespaceLog) Add(namespace string, reason string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.namespaces[namespace]; !ok {
+		n.namespaces[namespace] = reason
+	}
+}
+
+func (n *NamespaceLog) MarshalJSON() ([]byte, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return json.Marshal(n.namespaces)
+}
+
+func (n *NamespaceLog) Empty() bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return len(n.namespaces) == 0
+}
+
+// Reasons returns a copy of the failed namespaces, mapped to the reason
+func (n *NamespaceLog) Reasons() map[string]string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	reasons := make(map[string]string, len(n.namespaces))
+	for namespace, reason := range n.namespaces {
+		reasons[namespace] = reason
+	}
+	return reasons
+}
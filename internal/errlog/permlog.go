@@ -2,6 +2,8 @@ package errlog
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/Legit-Labs/legitify/internal/common/map_utils"
@@ -20,6 +22,9 @@ func NewPermLog() *PermLog {
 }
 
 func (p *PermLog) MarshalJSON() ([]byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	p.sortEntities()
 	return p.permissions.MarshalJSON()
 }
@@ -45,10 +50,54 @@ func (p *PermLog) Add(issue PermIssue) {
 	p.permissions.Set(issue.Permission, typedEntities)
 }
 
+// Issues lists the missing permissions sorted by permission, entity and effect
+func (p *PermLog) Issues() []PermIssue {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.sortEntities()
+	permissions := p.permissions.Keys()
+	sort.Strings(permissions)
+	issues := []PermIssue{}
+	for _, permission := range permissions {
+		perEntity := map_utils.UnsafeGet[*orderedmap.OrderedMap](p.permissions, permission)
+		for _, entity := range perEntity.Keys() {
+			for _, effect := range map_utils.UnsafeGet[effectSet](perEntity, entity).filtered() {
+				issues = append(issues, PermIssue{Permission: permission, Entity: entity, Effect: effect})
+			}
+		}
+	}
+	return issues
+}
+
 func (p *PermLog) Empty() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	return len(p.permissions.Keys()) == 0
 }
 
+// blockedEntities counts the distinct entities with missing permissions per namespace
+func (p *PermLog) blockedEntities() map[string]int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	entities := make(map[string]bool)
+	for _, permission := range p.permissions.Keys() {
+		perEntity := map_utils.UnsafeGet[*orderedmap.OrderedMap](p.permissions, permission)
+		for _, entity := range perEntity.Keys() {
+			entities[entity] = true
+		}
+	}
+
+	blocked := make(map[string]int)
+	for entity := range entities {
+		namespace, _, _ := strings.Cut(entity, ":")
+		blocked[namespace]++
+	}
+	return blocked
+}
+
 func (p *PermLog) sortEntities() {
 	for _, permission := range p.permissions.Keys() {
 		entity := map_utils.UnsafeGet[*orderedmap.OrderedMap](p.permissions, permission)
@@ -10,6 +10,7 @@ type reasonType int
 const (
 	reasonTypePrerequisite reasonType = iota
 	reasonTypePermission   reasonType = iota
+	reasonTypeDisabled     reasonType = iota
 )
 
 type SkipReason struct {
@@ -31,8 +32,19 @@ func NewPermissionSkipReason(reason string) SkipReason {
 	}
 }
 
+func NewDisabledSkipReason(reason string) SkipReason {
+	return SkipReason{
+		reason:     reason,
+		reasonType: reasonTypeDisabled,
+	}
+}
+
 func (s SkipReason) MarshalJSON() ([]byte, error) {
-	return json.Marshal(fmt.Sprintf("%s: %s", s.ReasonPrefix(), s.reason))
+	return json.Marshal(s.String())
+}
+
+func (s SkipReason) String() string {
+	return fmt.Sprintf("%s: %s", s.ReasonPrefix(), s.reason)
 }
 
 func (s SkipReason) ReasonPrefix() string {
@@ -41,6 +53,8 @@ func (s SkipReason) ReasonPrefix() string {
 		return "Unmet prerequisite"
 	case reasonTypePermission:
 		return "Missing permission"
+	case reasonTypeDisabled:
+		return "Disabled"
 	default:
 		return fmt.Sprintf("Unknown reason type: %d", s.reasonType)
 	}
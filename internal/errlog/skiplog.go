@@ -27,10 +27,25 @@ func (s *SkipLog) Add(policyName string, entityName string, skipReason SkipReaso
 	s.policies[policyName][entityName] = skipReason
 }
 
+// Reason returns the reason the policy was skipped for the entity
+func (s *SkipLog) Reason(policyName string, entityName string) (SkipReason, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reason, ok := s.policies[policyName][entityName]
+	return reason, ok
+}
+
 func (p *SkipLog) MarshalJSON() ([]byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	return json.Marshal(p.policies)
 }
 
 func (p *SkipLog) Empty() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	return len(p.policies) == 0
 }
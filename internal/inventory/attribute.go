@@ -0,0 +1,190 @@
+package inventory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+)
+
+// Attribute is a value of the input document of an entity (the document the policies see as input).
+// The path is a dot separated list of keys, and may end with a filter on a list, e.g. "collaborators[permissions.admin]"
+// (the elements whose field is true) or "members[access_level=50]" (the elements whose field equals the value).
+type Attribute struct {
+	Label string
+	Path  string
+}
+
+// ParseAttribute parses an attribute of the form "[label=]path". The label defaults to the path.
+func ParseAttribute(spec string) (Attribute, error) {
+	label, path, hasLabel := strings.Cut(spec, "=")
+	if !hasLabel || strings.Contains(label, "[") {
+		label, path = spec, spec
+	}
+	label, path = strings.TrimSpace(label), strings.TrimSpace(path)
+
+	if label == "" || path == "" {
+		return Attribute{}, fmt.Errorf("invalid inventory attribute %q (expecting [label=]path)", spec)
+	}
+	if open := strings.Index(path, "["); open >= 0 && (!strings.HasSuffix(path, "]") || open == 0 || open == len(path)-2) {
+		return Attribute{}, fmt.Errorf("invalid inventory attribute %q (a filter is expecting path[field] or path[field=value])", spec)
+	}
+	return Attribute{Label: label, Path: path}, nil
+}
+
+// ParseNamespaceAttributes parses a list of "namespace:[label=]path" attributes
+func ParseNamespaceAttributes(specs []string) (map[namespace.Namespace][]Attribute, error) {
+	attributes := make(map[namespace.Namespace][]Attribute)
+	for _, spec := range specs {
+		ns, attributeSpec, found := strings.Cut(spec, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid inventory attribute %q (expecting namespace:[label=]path)", spec)
+		}
+		ns = strings.TrimSpace(ns)
+		if err := namespace.ValidateNamespaces([]namespace.Namespace{ns}); err != nil {
+			return nil, err
+		}
+		attribute, err := ParseAttribute(attributeSpec)
+		if err != nil {
+			return nil, err
+		}
+		attributes[ns] = append(attributes[ns], attribute)
+	}
+	return attributes, nil
+}
+
+// Resolve returns the value of the attribute in the document: scalars as is, the number of elements of lists,
+// true for objects, and nil when the value is missing
+func (a Attribute) Resolve(document interface{}) interface{} {
+	path, filter, hasFilter := strings.Cut(strings.TrimSuffix(a.Path, "]"), "[")
+
+	value := lookup(document, path)
+	if !hasFilter {
+		return summarize(value)
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	field, expected, hasExpected := strings.Cut(filter, "=")
+	matching := 0
+	for _, element := range list {
+		fieldValue := lookup(element, field)
+		if hasExpected && fieldValue != nil && fmt.Sprint(fieldValue) == expected {
+			matching++
+		} else if !hasExpected && fieldValue == true {
+			matching++
+		}
+	}
+	return matching
+}
+
+func lookup(document interface{}, path string) interface{} {
+	value := document
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func summarize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case []interface{}:
+		return len(typed)
+	case map[string]interface{}:
+		return true
+	default:
+		return typed
+	}
+}
+
+func mustParse(specs ...string) []Attribute {
+	attributes := make([]Attribute, 0, len(specs))
+	for _, spec := range specs {
+		attribute, err := ParseAttribute(spec)
+		if err != nil {
+			panic(err)
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
+
+var defaultAttributes = map[scm_type.ScmType]map[namespace.Namespace][]Attribute{
+	scm_type.GitHub: {
+		namespace.Organization: mustParse(
+			"plan=organization.plan.name",
+			"two_factor_requirement_enabled=organization.two_factor_requirement_enabled",
+			"saml_enabled=saml_enabled",
+			"hooks=hooks",
+		),
+		namespace.Repository: mustParse(
+			"private=repository.is_private",
+			"private_forking=private_forking.effective",
+			"archived=repository.is_archived",
+			"default_branch=repository.default_branch.Name",
+			"branch_protection=repository.default_branch.branch_protection_rule",
+			"protection_without_reviews=protection_present_but_no_reviews",
+			"rulesets=rules_set",
+			"signatures_branch_protection=required_signatures.branch_protection",
+			"signatures_rulesets=required_signatures.rulesets",
+			"admins=collaborators[permissions.admin]",
+			"collaborators=collaborators_count",
+			"workflows_requesting_id_token=workflows[requests_id_token]",
+			"pushed_at=repository.pushed_at",
+		),
+		namespace.Member: mustParse(
+			"members=members",
+			"admins=members[is_admin]",
+		),
+		namespace.Enterprise: mustParse(
+			"two_factor_required=two_factor_required_setting",
+			"saml_enabled=saml_enabled",
+		),
+		namespace.Actions: mustParse(
+			"allowed_actions=actions_permissions.allowed_actions",
+			"default_workflow_permissions=token_permissions.default_workflow_permissions",
+		),
+		namespace.RunnerGroup: mustParse(
+			"visibility=runner_group.visibility",
+			"allows_public_repositories=runner_group.allows_public_repositories",
+		),
+	},
+	scm_type.GitLab: {
+		namespace.Organization: mustParse(
+			"visibility=visibility",
+			"require_two_factor_authentication=require_two_factor_authentication",
+			"hooks=hooks",
+		),
+		namespace.Repository: mustParse(
+			"visibility=visibility",
+			"archived=archived",
+			"default_branch=default_branch",
+			"protected_branches=protected_branches",
+			"admins=members[access_level=50]",
+			"members=members",
+			"last_activity_at=last_activity_at",
+		),
+		namespace.Member: mustParse(
+			"state=state",
+			"is_admin=is_admin",
+			"two_factor_enabled=two_factor_enabled",
+			"last_activity_on=last_activity_on",
+		),
+		namespace.Enterprise: mustParse(
+			"signup_enabled=signup_enabled",
+			"require_two_factor_authentication=require_two_factor_authentication",
+		),
+	},
+}
+
+// DefaultAttributes returns the attributes that are recorded for the entities of the namespace by default
+func DefaultAttributes(scm scm_type.ScmType, ns namespace.Namespace) []Attribute {
+	return defaultAttributes[scm][ns]
+}
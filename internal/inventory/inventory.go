@@ -0,0 +1,162 @@
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/coverage"
+)
+
+const (
+	FormatJson = "json"
+	FormatCsv  = "csv"
+)
+
+func Formats() []string {
+	return []string{FormatJson, FormatCsv}
+}
+
+// Record lists the key attributes of a collected entity, regardless of the policies results
+type Record struct {
+	Scm        string                 `json:"scm"`
+	Namespace  string                 `json:"namespace"`
+	Name       string                 `json:"name"`
+	Link       string                 `json:"link"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// Inventory records the collected entities of a scan (see --inventory-file).
+// A nil inventory records nothing, so it can be passed down whether or not the inventory is written.
+type Inventory struct {
+	lock       sync.Mutex
+	writer     io.Writer
+	format     string
+	attributes map[namespace.Namespace][]Attribute
+	records    []Record
+}
+
+// NewInventory starts recording the collected entities; the inventory is written to the writer by Flush.
+// The attributes of a namespace replace its default attributes (see DefaultAttributes).
+func NewInventory(writer io.Writer, format string, attributes map[namespace.Namespace][]Attribute) *Inventory {
+	return &Inventory{
+		writer:     writer,
+		format:     format,
+		attributes: attributes,
+	}
+}
+
+// Add records the attributes of the collected entity
+func (i *Inventory) Add(ns namespace.Namespace, entity collected.Entity) {
+	if i == nil {
+		return
+	}
+
+	// the scm is the prefix of the entity ID (see collected.NewEntityID)
+	scm, _, _ := strings.Cut(entity.EntityID(), ":")
+	document, err := coverage.InputDocument(entity)
+	if err != nil {
+		log.Printf("failed to build the inventory record of %s: %v", entity.Name(), err)
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	attributes, ok := i.attributes[ns]
+	if !ok {
+		attributes = DefaultAttributes(scm, ns)
+	}
+	record := Record{
+		Scm:        scm,
+		Namespace:  ns,
+		Name:       entity.Name(),
+		Link:       entity.CanonicalLink(),
+		Attributes: make(map[string]interface{}, len(attributes)),
+	}
+	for _, attribute := range attributes {
+		record.Attributes[attribute.Label] = attribute.Resolve(document)
+	}
+	i.records = append(i.records, record)
+}
+
+// Flush writes the inventory (sorted by namespace and link) to the output
+func (i *Inventory) Flush() {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	sort.SliceStable(i.records, func(a, b int) bool {
+		if i.records[a].Namespace != i.records[b].Namespace {
+			return i.records[a].Namespace < i.records[b].Namespace
+		}
+		return i.records[a].Link < i.records[b].Link
+	})
+
+	var err error
+	if i.format == FormatCsv {
+		err = writeCsv(i.writer, i.records)
+	} else {
+		err = writeJson(i.writer, i.records)
+	}
+	if err != nil {
+		log.Printf("failed to write the inventory: %v", err)
+	}
+}
+
+func writeJson(writer io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// writeCsv writes a row per entity, with a column per attribute of any of the namespaces
+func writeCsv(writer io.Writer, records []Record) error {
+	labels := make(map[string]bool)
+	for _, record := range records {
+		for label := range record.Attributes {
+			labels[label] = true
+		}
+	}
+	columns := make([]string, 0, len(labels))
+	for label := range labels {
+		columns = append(columns, label)
+	}
+	sort.Strings(columns)
+
+	w := csv.NewWriter(writer)
+	if err := w.Write(append([]string{"scm", "namespace", "name", "link"}, columns...)); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := []string{record.Scm, record.Namespace, record.Name, record.Link}
+		for _, column := range columns {
+			value, ok := record.Attributes[column]
+			if !ok || value == nil {
+				row = append(row, "")
+			} else {
+				row = append(row, fmt.Sprint(value))
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
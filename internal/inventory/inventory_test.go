@@ -0,0 +1,82 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+)
+
+func makeRepository(name string, private bool, admins int) githubcollected.Repository {
+	collaborators := []*github.User{{Login: github.String("member"), Permissions: map[string]bool{"admin": false}}}
+	for i := 0; i < admins; i++ {
+		collaborators = append(collaborators, &github.User{Login: github.String("admin"), Permissions: map[string]bool{"admin": true}})
+	}
+	return githubcollected.Repository{
+		Repository: &githubcollected.GitHubQLRepository{
+			Name:      name,
+			Url:       "https://github.com/org/" + name,
+			IsPrivate: private,
+		},
+		Collaborators: collaborators,
+	}
+}
+
+func TestInventoryDefaultAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	inventory := NewInventory(&buf, FormatJson, nil)
+
+	inventory.Add(namespace.Repository, makeRepository("b", true, 2))
+	inventory.Add(namespace.Repository, makeRepository("a", false, 0))
+	inventory.Flush()
+
+	var records []Record
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &records))
+	require.Len(t, records, 2)
+	require.Equal(t, "a", records[0].Name, "expecting the records sorted by link")
+	require.Equal(t, "github", records[0].Scm)
+	require.Equal(t, false, records[0].Attributes["private"])
+	require.Equal(t, true, records[1].Attributes["private"])
+	require.Equal(t, float64(2), records[1].Attributes["admins"])
+	require.Nil(t, records[1].Attributes["branch_protection"], "expecting missing values to be empty")
+}
+
+func TestInventoryCustomAttributesCsv(t *testing.T) {
+	attributes, err := ParseNamespaceAttributes([]string{"repository:repo_name=repository.name", "repository:collaborators", "repository:owners=collaborators[permissions.admin=true]"})
+	require.Nil(t, err)
+
+	var buf bytes.Buffer
+	inventory := NewInventory(&buf, FormatCsv, attributes)
+
+	inventory.Add(namespace.Repository, makeRepository("repo", false, 1))
+	inventory.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Equal(t, []string{
+		"scm,namespace,name,link,collaborators,owners,repo_name",
+		"github,repository,repo,https://github.com/org/repo,2,1,repo",
+	}, lines)
+}
+
+func TestParseAttribute(t *testing.T) {
+	attribute, err := ParseAttribute("members[access_level=50]")
+	require.Nil(t, err)
+	require.Equal(t, Attribute{Label: "members[access_level=50]", Path: "members[access_level=50]"}, attribute)
+
+	attribute, err = ParseAttribute("admins=members[access_level=50]")
+	require.Nil(t, err)
+	require.Equal(t, Attribute{Label: "admins", Path: "members[access_level=50]"}, attribute)
+
+	for _, invalid := range []string{"", "label=", "members[]", "members[access_level", "[x]"} {
+		_, err = ParseAttribute(invalid)
+		require.NotNil(t, err, "expecting %q to be invalid", invalid)
+	}
+
+	_, err = ParseNamespaceAttributes([]string{"repositories:name"})
+	require.NotNil(t, err, "expecting an invalid namespace to be rejected")
+}
@@ -0,0 +1,171 @@
+package opa
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/util"
+)
+
+// ConfigCustomKey holds arbitrary values for user-provided policies (not validated)
+const ConfigCustomKey = "custom"
+
+// configThresholds lists the thresholds the bundled policies read from data.config, with their defaults
+var configThresholds = map[string]float64{
+	"max_repository_admins":       3,
+	"max_organization_admins":     3,
+	"min_approvals":               2,
+	"min_scorecard_score":         7.0,
+	"max_mfa_grace_period_hours":  168,
+	"max_artifact_retention_days": 30,
+	"max_actions_cache_size_mb":   2048,
+	"max_invitation_age_days":     30,
+	"max_outside_collaborators":   10,
+	"max_deploy_key_age_days":     365,
+	"min_password_length":         12,
+}
+
+// configLists lists the lists of names the bundled policies read from data.config (empty by default)
+var configLists = map[string]bool{
+	// repositories that must run a security workflow that is required by the organization
+	"critical_repositories": true,
+	// projects that must have a merge request template (by name or full path)
+	"merge_request_template_projects": true,
+	// checks that must succeed on the default branch HEAD of every repository
+	"security_checks": true,
+	// domains (and their subdomains) that may receive webhooks with sensitive events or the data of project integrations
+	"trusted_webhook_domains": true,
+	// integrations (GitHub App slugs / GitLab integration slugs) that are approved to access the repositories
+	"approved_integrations": true,
+	// deployment environments that are treated as production (in addition to production/prod)
+	"production_environments": true,
+	// repositories that must require a merge queue on their default branch
+	"merge_queue_repositories": true,
+}
+
+func ConfigKeys() []string {
+	keys := make([]string, 0, len(configThresholds)+len(configLists)+1)
+	for k := range configThresholds {
+		keys = append(keys, k)
+	}
+	for k := range configLists {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return append(keys, ConfigCustomKey)
+}
+
+// DefaultConfig returns the data.config document used when no config file is provided
+func DefaultConfig() map[string]interface{} {
+	config := map[string]interface{}{
+		ConfigCustomKey: map[string]interface{}{},
+	}
+	for k, v := range configThresholds {
+		config[k] = v
+	}
+	for k := range configLists {
+		config[k] = []interface{}{}
+	}
+	return config
+}
+
+// LoadConfig reads a json/yaml document that is exposed to the policies as data.config.
+// Missing thresholds keep their default values.
+func LoadConfig(path string) (map[string]interface{}, error) {
+	if path == "" {
+		return DefaultConfig(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policies config: %v", err)
+	}
+
+	var config map[string]interface{}
+	if err = util.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse policies config %s: %v", path, err)
+	}
+	if err = ValidateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid policies config %s: %v", path, err)
+	}
+
+	merged := DefaultConfig()
+	for k, v := range config {
+		merged[k] = v
+	}
+
+	return merged, nil
+}
+
+// ValidateConfig makes sure the document matches the expected shape:
+// known thresholds must be non-negative numbers, lists must contain strings and custom must be an object.
+func ValidateConfig(config map[string]interface{}) error {
+	var errs []string
+	for k, v := range config {
+		if k == ConfigCustomKey {
+			if _, ok := v.(map[string]interface{}); !ok {
+				errs = append(errs, fmt.Sprintf("%s: expecting an object", k))
+			}
+			continue
+		}
+
+		if configLists[k] {
+			if !isStringList(v) {
+				errs = append(errs, fmt.Sprintf("%s: expecting a list of strings", k))
+			}
+			continue
+		}
+
+		if _, ok := configThresholds[k]; !ok {
+			errs = append(errs, fmt.Sprintf("%s: unknown key (expecting one of %v)", k, ConfigKeys()))
+			continue
+		}
+
+		number, ok := v.(json.Number)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: expecting a number", k))
+			continue
+		}
+		if f, err := number.Float64(); err != nil || f < 0 {
+			errs = append(errs, fmt.Sprintf("%s: expecting a non-negative number", k))
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// ConfigLists returns the lists of the config by their keys
+func ConfigLists(config map[string]interface{}) map[string][]string {
+	lists := make(map[string][]string)
+	for key := range configLists {
+		list, ok := config[key].([]interface{})
+		if !ok || !isStringList(list) {
+			continue
+		}
+		for _, item := range list {
+			lists[key] = append(lists[key], item.(string))
+		}
+	}
+	return lists
+}
+
+func isStringList(v interface{}) bool {
+	list, ok := v.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package opa_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := opa.LoadConfig("")
+	require.Nil(t, err)
+	require.Equal(t, opa.DefaultConfig(), config)
+
+	path := writeConfig(t, "config.yaml", "max_repository_admins: 5\ncustom:\n  team: security\n")
+	config, err = opa.LoadConfig(path)
+	require.Nil(t, err)
+	require.Equal(t, json.Number("5"), config["max_repository_admins"])
+	require.Equal(t, float64(2), config["min_approvals"])
+	require.Equal(t, map[string]interface{}{"team": "security"}, config[opa.ConfigCustomKey])
+
+	path = writeConfig(t, "config.json", `{"min_approvals": 1}`)
+	_, err = opa.LoadConfig(path)
+	require.Nil(t, err)
+
+	invalid := []string{
+		`{"min_approvals": "two"}`,
+		`{"min_approvals": -1}`,
+		`{"max_admins": 3}`,
+		`{"custom": [1, 2]}`,
+		`{"critical_repositories": "repo"}`,
+		`{"critical_repositories": [1]}`,
+		`[1, 2]`,
+	}
+	for _, content := range invalid {
+		path = writeConfig(t, "invalid.json", content)
+		_, err = opa.LoadConfig(path)
+		require.NotNilf(t, err, "expected %s to be invalid", content)
+	}
+}
+
+func TestConfigLists(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "security_checks: [CodeQL, semgrep]\napproved_integrations: []\nmin_approvals: 1\n")
+	config, err := opa.LoadConfig(path)
+	require.Nil(t, err)
+
+	lists := opa.ConfigLists(config)
+	require.Equal(t, []string{"CodeQL", "semgrep"}, lists["security_checks"])
+	require.Empty(t, lists["approved_integrations"], "expecting an empty list to have no items")
+	require.Empty(t, lists["trusted_webhook_domains"], "expecting the lists that are not configured to have no items")
+	require.NotContains(t, lists, "min_approvals", "expecting only the lists of the config")
+}
+
+func TestConfigOverridesPolicyThreshold(t *testing.T) {
+	engine, err := opa.Load([]string{}, scm_type.GitHub)
+	require.Nil(t, err)
+
+	admin := map[string]interface{}{"permissions": map[string]interface{}{"admin": true}}
+	input := map[string]interface{}{
+		"collaborators": []interface{}{admin, admin, admin, admin},
+	}
+	violated := func() bool {
+		results, err := engine.Query(context.Background(), namespace.Repository, input)
+		require.Nil(t, err)
+		for _, result := range results {
+			if result.PolicyName == "repository_has_too_many_admins" {
+				return result.IsViolation
+			}
+		}
+		t.Fatal("policy not found in results")
+		return false
+	}
+
+	require.True(t, violated(), "4 admins exceed the default threshold")
+
+	config, err := opa.LoadConfig(writeConfig(t, "config.yaml", "max_repository_admins: 4\n"))
+	require.Nil(t, err)
+	engine.SetConfig(config)
+	require.False(t, violated(), "4 admins are allowed by the config")
+}
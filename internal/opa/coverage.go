@@ -0,0 +1,98 @@
+package opa
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// PolicyInputFields returns the input fields (e.g. "repository.is_private") each rule references,
+// either directly or through the helper rules and functions of its package.
+// The result is keyed by the fully qualified rule name (e.g. "data.repository.forking_allowed_for_repository").
+func PolicyInputFields(modules map[string]*ast.Module) map[string][]string {
+	result := make(map[string][]string)
+
+	for _, module := range modules {
+		pkg := module.Package.Path.String()
+		direct := make(map[string]map[string]bool)
+		deps := make(map[string]map[string]bool)
+
+		for _, rule := range module.Rules {
+			name := rule.Head.Name.String()
+			if direct[name] == nil {
+				direct[name] = make(map[string]bool)
+				deps[name] = make(map[string]bool)
+			}
+			for _, field := range ruleInputFields(rule) {
+				direct[name][field] = true
+			}
+		}
+
+		for _, rule := range module.Rules {
+			name := rule.Head.Name.String()
+			ast.WalkVars(rule, func(v ast.Var) bool {
+				if other := v.String(); other != name && direct[other] != nil {
+					deps[name][other] = true
+				}
+				return false
+			})
+		}
+
+		for name := range direct {
+			fields := make(map[string]bool)
+			collectFields(name, direct, deps, fields, make(map[string]bool))
+			result[pkg+"."+name] = sortedKeys(fields)
+		}
+	}
+
+	return result
+}
+
+func collectFields(name string, direct map[string]map[string]bool, deps map[string]map[string]bool, fields map[string]bool, visited map[string]bool) {
+	if visited[name] {
+		return
+	}
+	visited[name] = true
+
+	for field := range direct[name] {
+		fields[field] = true
+	}
+	for dep := range deps[name] {
+		collectFields(dep, direct, deps, fields, visited)
+	}
+}
+
+// ruleInputFields returns the static prefixes of the input references of the rule (up to the first dynamic element)
+func ruleInputFields(rule *ast.Rule) []string {
+	var fields []string
+	ast.WalkRefs(rule, func(ref ast.Ref) bool {
+		if !ref[0].Equal(ast.InputRootDocument) {
+			return false
+		}
+
+		var path []string
+		for _, term := range ref[1:] {
+			key, ok := term.Value.(ast.String)
+			if !ok {
+				break
+			}
+			path = append(path, string(key))
+		}
+		if len(path) > 0 {
+			fields = append(fields, strings.Join(path, "."))
+		}
+		return false
+	})
+
+	return fields
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
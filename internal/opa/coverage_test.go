@@ -0,0 +1,22 @@
+package opa_test
+
+import (
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyInputFields(t *testing.T) {
+	engine, err := opa.Load([]string{}, scm_type.GitHub)
+	require.Nil(t, err)
+
+	fields := opa.PolicyInputFields(engine.Modules())
+
+	// referenced directly
+	require.Equal(t, []string{"vulnerability_alerts_enabled"},
+		fields["data.repository.vulnerability_alerts_not_enabled"])
+	// referenced through a helper function
+	require.Contains(t, fields["data.repository.security_checks_not_passing_on_default_branch"], "default_branch_checks")
+}
@@ -1,21 +1,25 @@
 package opa
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"os"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/Legit-Labs/legitify/internal/opa/opa_engine"
+	"github.com/Legit-Labs/legitify/internal/version"
 	"github.com/Legit-Labs/legitify/policies"
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/bundle"
 	"github.com/open-policy-agent/opa/loader"
 )
 
-func Load(policyPaths []string, scm scm_type.ScmType) (opa_engine.Enginer, error) {
+func Load(policyPaths []string, scm scm_type.ScmType, filters ...ModulesFilter) (opa_engine.Enginer, error) {
 	loadedPolicies, err := loader.NewFileLoader().
 		WithProcessAnnotation(true).
 		Filtered(policyPaths, isRegoFile)
@@ -39,6 +43,12 @@ func Load(policyPaths []string, scm scm_type.ScmType) (opa_engine.Enginer, error
 		modules[m.Package.Location.File] = m
 	}
 
+	for _, filter := range filters {
+		if err := filter(modules); err != nil {
+			return nil, err
+		}
+	}
+
 	compiler.Compile(modules)
 
 	if compiler.Failed() {
@@ -46,10 +56,39 @@ func Load(policyPaths []string, scm scm_type.ScmType) (opa_engine.Enginer, error
 	}
 
 	engine := opa_engine.NewEnginer(modules, compiler)
+	engine.SetConfig(DefaultConfig())
 
 	return engine, nil
 }
 
+// PoliciesVersion identifies the evaluated policies: the bundled policies by the version of legitify,
+// and the custom policies (if any) by a digest of their files
+func PoliciesVersion(policyPaths []string) (string, error) {
+	bundled := "builtin " + version.Version
+	if len(policyPaths) == 0 {
+		return bundled, nil
+	}
+
+	loadedPolicies, err := loader.NewFileLoader().Filtered(policyPaths, isRegoFile)
+	if err != nil {
+		return bundled, opa_engine.NewErrPolicyLoad(err)
+	}
+
+	names := make([]string, 0, len(loadedPolicies.Modules))
+	for name := range loadedPolicies.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write(loadedPolicies.Modules[name].Raw)
+	}
+
+	return fmt.Sprintf("%s, custom sha256:%s", bundled, hex.EncodeToString(hash.Sum(nil))[:16]), nil
+}
+
 func loadModules(scmType scm_type.ScmType) ([]*ast.Module, error) {
 	switch scmType {
 	case scm_type.GitHub:
@@ -8,22 +8,27 @@ import (
 
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
 	"github.com/open-policy-agent/opa/topdown"
 )
 
 type Enginer interface {
 	Query(ctx context.Context, namespace string, input interface{}) ([]QueryResult, error)
 	SetTracing(enabled bool)
+	SetConfig(config map[string]interface{})
 	Namespaces() []string
 	Modules() map[string]*ast.Module
 	Annotations() *ast.AnnotationSet
 }
 
 func NewEnginer(modules map[string]*ast.Module, compiler *ast.Compiler) Enginer {
-	return &enginer{
+	e := &enginer{
 		modules:  modules,
 		compiler: compiler,
 	}
+	e.SetConfig(map[string]interface{}{})
+	return e
 }
 
 type QueryResult struct {
@@ -38,12 +43,20 @@ type enginer struct {
 	modules       map[string]*ast.Module
 	compiler      *ast.Compiler
 	enableTracing bool
+	store         storage.Store
 }
 
 func (e *enginer) SetTracing(enabled bool) {
 	e.enableTracing = enabled
 }
 
+// SetConfig sets the document the policies can read as data.config
+func (e *enginer) SetConfig(config map[string]interface{}) {
+	e.store = inmem.NewFromObject(map[string]interface{}{
+		"config": config,
+	})
+}
+
 func (engine *enginer) Modules() map[string]*ast.Module {
 	return engine.modules
 }
@@ -94,6 +107,7 @@ func (engine *enginer) buildRegoInstance(namespace string, input interface{}) *r
 		rego.Query(fmt.Sprintf("data.%s", namespace)),
 		rego.Input(input),
 		rego.Compiler(engine.compiler),
+		rego.Store(engine.store),
 		rego.Trace(engine.enableTracing),
 		rego.StrictBuiltinErrors(true),
 		rego.PrintHook(topdown.NewPrintHook(os.Stderr)),
@@ -110,11 +124,16 @@ func (engine *enginer) parseResultsSet(rs rego.ResultSet) []QueryResult {
 
 			for _, m := range matchedPolicies {
 				match := m.fullPolicyName
+				annotations := engine.findAnnotation(match)
+				if annotations == nil {
+					// helper rules of the package (e.g. sets used by several policies) are not policies
+					continue
+				}
 				split := strings.Split(match, ".")
 				current := QueryResult{
 					FullyQualifiedPolicyName: match,
 					PolicyName:               split[len(split)-1],
-					Annotations:              engine.findAnnotation(match),
+					Annotations:              annotations,
 					ExtraData:                m.extraData,
 					IsViolation:              m.violation,
 				}
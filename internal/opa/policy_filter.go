@@ -0,0 +1,82 @@
+package opa
+
+import (
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// OnlyPolicy drops all the policies except the named one, so only that policy is evaluated.
+// The rules the policy depends on are kept, even when they are policies themselves.
+func OnlyPolicy(name string) ModulesFilter {
+	return func(modules map[string]*ast.Module) error {
+		found := false
+		for _, module := range modules {
+			as, errs := ast.BuildAnnotationSet([]*ast.Module{module})
+			if len(errs) > 0 {
+				return errs
+			}
+
+			policies := make(map[*ast.Annotations]string)
+			for _, ref := range as.Flatten() {
+				if rule := ref.GetRule(); rule != nil && ref.Annotations != nil {
+					policies[ref.Annotations] = rule.Head.Name.String()
+				}
+			}
+			if len(policies) == 0 {
+				continue
+			}
+
+			kept := make(map[string]bool)
+			for _, policyName := range policies {
+				if policyName == name {
+					found = true
+					kept = ruleDependencies(module, name)
+					break
+				}
+			}
+
+			dropped := make(map[string]bool)
+			droppedAnnotations := make(map[*ast.Annotations]bool)
+			for annotations, policyName := range policies {
+				if !kept[policyName] {
+					dropped[policyName] = true
+					droppedAnnotations[annotations] = true
+				}
+			}
+			dropRules(module, dropped, droppedAnnotations)
+		}
+
+		if !found {
+			return fmt.Errorf("policy %s was not found", name)
+		}
+		return nil
+	}
+}
+
+// ruleDependencies returns the named rule and the rules of the module it refers to (directly or not)
+func ruleDependencies(module *ast.Module, name string) map[string]bool {
+	rules := make(map[string][]*ast.Rule)
+	for _, rule := range module.Rules {
+		ruleName := rule.Head.Name.String()
+		rules[ruleName] = append(rules[ruleName], rule)
+	}
+
+	dependencies := map[string]bool{name: true}
+	pending := []string{name}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, rule := range rules[current] {
+			ast.WalkVars(rule, func(v ast.Var) bool {
+				if !dependencies[v.String()] && len(rules[v.String()]) > 0 {
+					dependencies[v.String()] = true
+					pending = append(pending, v.String())
+				}
+				return false
+			})
+		}
+	}
+
+	return dependencies
+}
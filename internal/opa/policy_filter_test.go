@@ -0,0 +1,28 @@
+package opa_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnlyPolicy(t *testing.T) {
+	engine, err := opa.Load([]string{}, scm_type.GitHub, opa.OnlyPolicy("vulnerability_alerts_not_enabled"))
+	require.Nil(t, err)
+
+	results, err := engine.Query(context.Background(), namespace.Repository, map[string]interface{}{})
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "vulnerability_alerts_not_enabled", results[0].PolicyName)
+
+	results, err = engine.Query(context.Background(), namespace.Organization, map[string]interface{}{})
+	require.Nil(t, err)
+	require.Empty(t, results)
+
+	_, err = opa.Load([]string{}, scm_type.GitHub, opa.OnlyPolicy("no_such_policy"))
+	require.NotNil(t, err)
+}
@@ -0,0 +1,69 @@
+package opa
+
+import (
+	"fmt"
+
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// ModulesFilter modifies the loaded modules before they are compiled
+type ModulesFilter func(modules map[string]*ast.Module) error
+
+// MinSeverity drops the policies whose declared severity is lower than the threshold, so they are not evaluated at all.
+// Policies without a declared severity are kept.
+func MinSeverity(threshold severity.Severity) ModulesFilter {
+	return func(modules map[string]*ast.Module) error {
+		if !severity.IsValid(threshold) {
+			return fmt.Errorf("invalid severity threshold: %s", threshold)
+		}
+
+		for _, module := range modules {
+			as, errs := ast.BuildAnnotationSet([]*ast.Module{module})
+			if len(errs) > 0 {
+				return errs
+			}
+
+			dropped := make(map[string]bool)
+			droppedAnnotations := make(map[*ast.Annotations]bool)
+			for _, ref := range as.Flatten() {
+				rule := ref.GetRule()
+				if rule == nil || ref.Annotations == nil {
+					continue
+				}
+				policySeverity, _ := ref.Annotations.Custom["severity"].(string)
+				if severity.IsValid(policySeverity) && severity.Less(threshold, policySeverity) {
+					dropped[rule.Head.Name.String()] = true
+					droppedAnnotations[ref.Annotations] = true
+				}
+			}
+			if len(dropped) == 0 {
+				continue
+			}
+
+			dropRules(module, dropped, droppedAnnotations)
+		}
+
+		return nil
+	}
+}
+
+// dropRules removes the rules of the dropped policies and their annotations from the module
+func dropRules(module *ast.Module, dropped map[string]bool, droppedAnnotations map[*ast.Annotations]bool) {
+	// a policy may be defined by several rules (e.g. a default value and its conditions)
+	rules := module.Rules[:0]
+	for _, rule := range module.Rules {
+		if !dropped[rule.Head.Name.String()] {
+			rules = append(rules, rule)
+		}
+	}
+	module.Rules = rules
+
+	annotations := module.Annotations[:0]
+	for _, annotation := range module.Annotations {
+		if !droppedAnnotations[annotation] {
+			annotations = append(annotations, annotation)
+		}
+	}
+	module.Annotations = annotations
+}
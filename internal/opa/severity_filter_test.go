@@ -0,0 +1,33 @@
+package opa_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/opa"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinSeverity(t *testing.T) {
+	engine, err := opa.Load([]string{}, scm_type.GitHub, opa.MinSeverity(severity.High))
+	require.Nil(t, err)
+
+	for _, ref := range engine.Annotations().Flatten() {
+		if policySeverity, ok := ref.Annotations.Custom["severity"].(string); ok {
+			require.Containsf(t, []string{severity.Critical, severity.High}, policySeverity, "%s should not be loaded", ref.Path)
+		}
+	}
+
+	results, err := engine.Query(context.Background(), namespace.Repository, map[string]interface{}{})
+	require.Nil(t, err)
+	require.NotEmpty(t, results)
+	for _, result := range results {
+		require.NotEqual(t, "vulnerability_alerts_not_enabled", result.PolicyName, "a MEDIUM policy should not be evaluated")
+	}
+
+	_, err = opa.Load([]string{}, scm_type.GitHub, opa.MinSeverity("URGENT"))
+	require.NotNil(t, err)
+}
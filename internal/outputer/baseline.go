@@ -0,0 +1,27 @@
+package outputer
+
+import (
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+// FailureSummary counts the failed violations by whether they are in the baseline (see --baseline)
+type FailureSummary struct {
+	New       int
+	Baselined int
+}
+
+// withBaseline marks the failed violation as baselined when its fingerprint is in the baseline, and counts it
+func (s *FailureSummary) withBaseline(violation scheme.Violation, baseline map[string]bool) scheme.Violation {
+	if violation.Status != analyzers.PolicyFailed {
+		return violation
+	}
+	violation.Baselined = baseline[violation.Fingerprint]
+
+	if violation.Baselined {
+		s.Baselined++
+	} else {
+		s.New++
+	}
+	return violation
+}
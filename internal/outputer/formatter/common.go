@@ -1,12 +1,68 @@
 package formatter
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 )
 
 const DefaultOutputIndent = "  "
 
+// Options are the rendering settings of an output, which are passed to the formatter that renders it.
+type Options struct {
+	// MaxViolationsPerPolicy limits the number of violations rendered per policy (0 means unlimited).
+	// only the rendering is affected: summaries and counts are always based on the full list of violations.
+	MaxViolationsPerPolicy int
+	// PassedPolicies is the number of passed policies that were left out of the results (--only-failures),
+	// which is noted in the summaries.
+	PassedPolicies int
+	// JsonIndent is the indentation of the json based outputs (json, sarif); empty means compact.
+	JsonIndent string
+	// Metadata describes the scan the results are of, which is rendered at the top of the output (nil when unknown)
+	Metadata *scheme.Metadata
+	// Collection is the collection of the scan the results are of, e.g. for its missing permissions (nil when unknown)
+	Collection *errlog.CollectionLog
+}
+
+const JsonIndentCompact = "compact"
+
+// ParseJsonIndent accepts a number of spaces or "compact" (which is the same as 0 spaces)
+func ParseJsonIndent(value string) (string, error) {
+	if value == JsonIndentCompact {
+		return "", nil
+	}
+	spaces, err := strconv.Atoi(value)
+	if err != nil || spaces < 0 {
+		return "", fmt.Errorf("invalid json indent: %s (expecting a number of spaces or %s)", value, JsonIndentCompact)
+	}
+	return strings.Repeat(" ", spaces), nil
+}
+
+// marshalJson marshals the value with the indentation (compact when empty), prefixing the lines after the first one
+func marshalJson(v interface{}, prefix string, indent string) ([]byte, error) {
+	if indent == "" {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, prefix, indent)
+}
+
+func passedPoliciesNote(count int) string {
+	return fmt.Sprintf("%d passed policies omitted", count)
+}
+
+// truncateViolations returns the violations to render and the number of violations that were left out.
+func truncateViolations(violations []scheme.Violation, max int) ([]scheme.Violation, int) {
+	if max <= 0 || len(violations) <= max {
+		return violations, 0
+	}
+	return violations[:max], len(violations) - max
+}
+
 func amplifyIndent(depth int) string {
 	return strings.Repeat(DefaultOutputIndent, depth)
 }
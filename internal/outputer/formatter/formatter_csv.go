@@ -3,7 +3,7 @@ package formatter
 import (
 	"encoding/csv"
 	"bytes"
-	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"github.com/Legit-Labs/legitify/internal/analyzers"
@@ -12,11 +12,13 @@ import (
 
 type CsvFormatter struct {
 	colorizer humanColorizer
+	options   Options
 }
 
-func newCSVFormatter() OutputFormatter {
+func newCSVFormatter(options Options) OutputFormatter {
 	return &CsvFormatter{
 		colorizer: humanColorizer{},
+		options:   options,
 	}
 }
 
@@ -44,6 +46,9 @@ func (f *CsvFormatter) csvFailedPolicies(output *scheme.Flattened, csvwriter *cs
 			entityType = (&violation).ViolationEntityType
 			Link = violation.CanonicalLink
 			violationString = entityType + " " + Link
+			if violation.Provider != "" {
+				violationString = violation.Provider + " " + violationString
+			}
 			violationsSummary = append(violationsSummary, violationString)
 		}
 		violationsPolicy := strings.Join([]string(violationsSummary), "\n")
@@ -97,6 +102,12 @@ func (f *CsvFormatter) formatSummary(output *scheme.Flattened, csvwriter *csv.Wr
 		}
 	}
 
+	if f.options.PassedPolicies > 0 {
+		if err := csvwriter.Write([]string{"", "", passedPoliciesNote(f.options.PassedPolicies), "", "", "", ""}); err != nil {
+			panic(err)
+		}
+	}
+
 	if err != nil {
 		panic(err)
 	}
@@ -121,8 +132,7 @@ func (f *CsvFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byte, er
 
 	// Check for errors during flushing
 	if err := csvWriter.Error(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		panic(err)
+		return nil, err
 	}
 
 	csvData := csvBuffer.Bytes()
@@ -133,3 +143,67 @@ func (f *CsvFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byte, er
 func (f *CsvFormatter) IsSchemeSupported(schemeType string) bool {
 	return schemeType == scheme.TypeFlattened
 }
+
+// FormatStream writes the same tables as Format, reading the violations policy by policy
+func (f *CsvFormatter) FormatStream(source scheme.ViolationsSource, failedOnly bool, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	policies := source.Policies()
+
+	if !failedOnly {
+		if err := csvWriter.Write([]string{"#", "Namespace", "Policy", "Severity", "Passed", "Failed", "Skipped"}); err != nil {
+			return err
+		}
+		for i, policyName := range policies {
+			policyInfo := source.PolicyInfo(policyName)
+			row := []string{strconv.Itoa(i + 1), policyInfo.Namespace, policyInfo.Title, policyInfo.Severity,
+				strconv.Itoa(source.StatusCount(policyName, analyzers.PolicyPassed)),
+				strconv.Itoa(source.StatusCount(policyName, analyzers.PolicyFailed)),
+				strconv.Itoa(source.StatusCount(policyName, analyzers.PolicySkipped))}
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+		if f.options.PassedPolicies > 0 {
+			if err := csvWriter.Write([]string{"", "", passedPoliciesNote(f.options.PassedPolicies), "", "", "", ""}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := csvWriter.Write([]string{"#", "Policy Name", "Namespace", "Severity", "Threat", "Violations", "Remediation Steps"}); err != nil {
+		return err
+	}
+	rowNum := 0
+	for _, policyName := range policies {
+		if source.StatusCount(policyName, analyzers.PolicyFailed) == 0 {
+			continue
+		}
+		rowNum++
+		policyInfo := source.PolicyInfo(policyName)
+
+		var violationsSummary []string
+		err := filteredViolations(source, policyName, true, func(violation scheme.Violation) error {
+			violationString := violation.ViolationEntityType + " " + violation.CanonicalLink
+			if violation.Provider != "" {
+				violationString = violation.Provider + " " + violationString
+			}
+			violationsSummary = append(violationsSummary, violationString)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		row := []string{strconv.Itoa(rowNum), policyInfo.PolicyName, policyInfo.Namespace, policyInfo.Severity,
+			strings.Join(policyInfo.Threat, "\n"), strings.Join(violationsSummary, "\n"), strings.Join(policyInfo.RemediationSteps, "\n")}
+		if err = csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	if err := csvWriter.Write([]string{"\n"}); err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
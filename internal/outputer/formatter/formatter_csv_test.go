@@ -12,7 +12,7 @@ func TestFormatCsv(t *testing.T) {
 	sample := scheme_test.SchemeSample()
 
 	for _, f := range []bool{true, false} {
-		bytes, err := formatter.Format(formatter.Csv, formatter.DefaultOutputIndent, sample, f)
+		bytes, err := formatter.Format(formatter.Csv, formatter.Options{}, sample, f)
 		require.Nilf(t, err, "Error formatting csv: %v", err)
 		require.NotNil(t, bytes, "Error formatting csv")
 		require.NotEmpty(t, bytes, "Error formatting csv")
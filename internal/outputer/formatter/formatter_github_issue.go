@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+// githubIssueFormatter renders failed policies as a GitHub issue / PR comment body.
+// Each policy is a collapsible <details> block, and the remediation steps are a task list
+// so that the issue can be used to track the remediation progress.
+type githubIssueFormatter struct {
+	colorizer markdownColorizer
+	options   Options
+}
+
+func newGithubIssueFormatter(options Options) OutputFormatter {
+	return &githubIssueFormatter{
+		colorizer: markdownColorizer{},
+		options:   options,
+	}
+}
+
+var severityEmojis = map[severity.Severity]string{
+	severity.Critical: ":red_circle:",
+	severity.High:     ":orange_circle:",
+	severity.Medium:   ":yellow_circle:",
+	severity.Low:      ":large_blue_circle:",
+	severity.Unknown:  ":white_circle:",
+}
+
+func severityEmoji(s severity.Severity) string {
+	if emoji, ok := severityEmojis[s]; ok {
+		return emoji
+	}
+	return severityEmojis[severity.Unknown]
+}
+
+// Format always renders the failed policies only, since passed or skipped policies have nothing to remediate.
+// When the output contains more than a single entity, a section is rendered per entity.
+func (g *githubIssueFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byte, error) {
+	typedOutput, ok := output.(*scheme.Flattened)
+	if !ok {
+		return nil, UnsupportedScheme{output}
+	}
+
+	failed := typedOutput.OnlyFailedViolations().SortedBySeverity()
+	links := failed.CanonicalLinks()
+
+	var sb strings.Builder
+	sb.WriteString(g.formatMetadata())
+	if len(links) == 0 {
+		sb.WriteString(fmt.Sprintf("%s\n", asMarkdownTitle("legitify: no policy violations found")))
+		return []byte(sb.String()), nil
+	}
+
+	for i, link := range links {
+		if i > 0 {
+			sb.WriteString("\n---\n\n")
+		}
+		sb.Write(g.formatEntity(failed.FilteredByCanonicalLink(link), link))
+	}
+
+	return []byte(sb.String()), nil
+}
+
+func (g *githubIssueFormatter) IsSchemeSupported(schemeType string) bool {
+	return schemeType == scheme.TypeFlattened
+}
+
+// formatMetadata renders the scan metadata as a collapsed block, to keep the focus of the issue on the remediation
+func (g *githubIssueFormatter) formatMetadata() string {
+	metadata := g.options.Metadata
+	if metadata == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<details>\n<summary>Scan metadata</summary>\n\n")
+	sb.WriteString(asMarkdownHeader([]string{"Field", "Value"}))
+	for _, field := range metadata.Fields() {
+		sb.WriteString(asMarkdownRow([]string{field[0], field[1]}))
+	}
+	sb.WriteString("\n</details>\n\n")
+	return sb.String()
+}
+
+func (g *githubIssueFormatter) formatEntity(output *scheme.Flattened, link string) []byte {
+	var sb strings.Builder
+	policies := output.AsOrderedMap().Keys()
+
+	sb.WriteString(fmt.Sprintf("%s\n\n", asMarkdownTitle(fmt.Sprintf("legitify found %d failed policies", len(policies)))))
+	sb.WriteString(fmt.Sprintf("Scanned entity: %s\n\n", link))
+
+	for _, policyName := range policies {
+		sb.WriteString(g.formatPolicy(output.GetPolicyData(policyName)))
+	}
+
+	return []byte(sb.String())
+}
+
+func (g *githubIssueFormatter) formatPolicy(data scheme.OutputData) string {
+	var sb strings.Builder
+	info := data.PolicyInfo
+	pf := newMarkdownPolicyFormatter()
+	pc := newPoliciesContent(pf, g.colorizer, g.options)
+
+	// GitHub only renders markdown inside <details> when it is surrounded by blank lines
+	sb.WriteString("<details>\n")
+	sb.WriteString(fmt.Sprintf("<summary>%s <b>%s</b> (%s)</summary>\n\n", severityEmoji(info.Severity), info.Title, info.Severity))
+
+	sb.WriteString(fmt.Sprintf("%s\n\n", info.Description))
+	sb.WriteString(fmt.Sprintf("**Policy Name:** %s  \n", info.PolicyName))
+	sb.WriteString(fmt.Sprintf("**Namespace:** %s\n\n", info.Namespace))
+
+	if len(info.Threat) > 0 {
+		sb.WriteString(pf.FormatList(0, g.colorizer.asBold("Threat:"), info.Threat, false, true))
+		sb.WriteString("\n")
+	}
+
+	if len(info.RemediationSteps) > 0 {
+		sb.WriteString(fmt.Sprintf("%s\n\n", g.colorizer.asBold("Remediation Steps:")))
+		for _, step := range info.RemediationSteps {
+			sb.WriteString(fmt.Sprintf("- [ ] %s\n", stripListPrefix(step)))
+		}
+		sb.WriteString("\n")
+	}
+
+	shown, hidden := truncateViolations(data.Violations, g.options.MaxViolationsPerPolicy)
+	for _, violation := range shown {
+		violation := violation
+		sb.Write(pc.FormatViolation(&violation))
+	}
+	if hidden > 0 {
+		sb.WriteString(fmt.Sprintf("\n... and %d more\n", hidden))
+	}
+
+	sb.WriteString("\n</details>\n\n")
+
+	return sb.String()
+}
+
+var listPrefixRegex = regexp.MustCompile(`^\s*(\d+\.|[\-\*\+])\s+`)
+
+func stripListPrefix(step string) string {
+	return listPrefixRegex.ReplaceAllString(step, "")
+}
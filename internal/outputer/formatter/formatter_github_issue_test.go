@@ -0,0 +1,24 @@
+package formatter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatGithubIssue(t *testing.T) {
+	sample := scheme_test.SchemeSample()
+
+	for _, f := range []bool{true, false} {
+		bytes, err := formatter.Format(formatter.GithubIssue, formatter.Options{}, sample, f)
+		require.Nilf(t, err, "Error formatting github issue: %v", err)
+		require.NotEmpty(t, bytes, "Error formatting github issue")
+
+		output := string(bytes)
+		require.Equal(t, strings.Count(output, "<details>"), strings.Count(output, "</details>"), "unbalanced details blocks")
+		require.Contains(t, output, "- [ ] ", "expecting remediation steps as a task list")
+	}
+}
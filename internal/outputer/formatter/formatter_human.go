@@ -14,24 +14,26 @@ import (
 
 type HumanFormatter struct {
 	colorizer humanColorizer
+	options   Options
 }
 
-func newHumanFormatter() OutputFormatter {
+func newHumanFormatter(options Options) OutputFormatter {
 	return &HumanFormatter{
 		colorizer: humanColorizer{},
+		options:   options,
 	}
 }
 
 func (f *HumanFormatter) formatSummaryTable(output *scheme.Flattened) []byte {
 	tf := newHumanTableWriter()
-	tw := newTableContent(tf, f.colorizer)
+	tw := newTableContent(tf, f.colorizer, f.options)
 	return tw.FormatSummary(output)
 }
 
 func (f *HumanFormatter) formatFailedPolicies(output *scheme.Flattened) []byte {
 	failedPolicies := output.OnlyFailedViolations()
 	pf := newHumanPolicyFormatter()
-	pc := newPoliciesContent(pf, f.colorizer)
+	pc := newPoliciesContent(pf, f.colorizer, f.options)
 	return pc.FormatFailedPolicies(failedPolicies)
 }
 
@@ -49,7 +51,15 @@ func (f *HumanFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byte,
 
 	failedPolicies = f.formatFailedPolicies(typedOutput)
 
-	return append(failedPolicies, summary...), nil
+	return append(append(f.formatMetadata(), failedPolicies...), summary...), nil
+}
+
+func (f *HumanFormatter) formatMetadata() []byte {
+	if f.options.Metadata == nil {
+		return nil
+	}
+	tc := newTableContent(newHumanTableWriter(), f.colorizer, f.options)
+	return append(tc.FormatMetadata(f.options.Metadata), '\n')
 }
 
 func (f *HumanFormatter) IsSchemeSupported(schemeType string) bool {
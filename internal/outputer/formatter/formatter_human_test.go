@@ -1,6 +1,7 @@
 package formatter_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
@@ -12,9 +13,46 @@ func TestFormatHuman(t *testing.T) {
 	sample := scheme_test.SchemeSample()
 
 	for _, f := range []bool{true, false} {
-		bytes, err := formatter.Format(formatter.Human, formatter.DefaultOutputIndent, sample, f)
+		bytes, err := formatter.Format(formatter.Human, formatter.Options{}, sample, f)
 		require.Nilf(t, err, "Error formatting markdown: %v", err)
 		require.NotNil(t, bytes, "Error formatting markdown")
 		require.NotEmpty(t, bytes, "Error formatting markdown")
 	}
 }
+
+func TestFormatHumanMaxViolationsPerPolicy(t *testing.T) {
+	sample := scheme_test.SchemeSample()
+
+	bytes, err := formatter.Format(formatter.Human, formatter.Options{MaxViolationsPerPolicy: 1}, sample, true)
+	require.Nilf(t, err, "Error formatting human: %v", err)
+	require.Equal(t, 2, strings.Count(string(bytes), "... and 1 more"), "expecting each policy to be truncated")
+
+	bytes, err = formatter.Format(formatter.Human, formatter.Options{MaxViolationsPerPolicy: 2}, sample, true)
+	require.Nilf(t, err, "Error formatting human: %v", err)
+	require.NotContains(t, string(bytes), "... and ", "expecting no truncation when the limit is not exceeded")
+}
+
+func TestFormatHumanBaselined(t *testing.T) {
+	sample := scheme_test.SchemeSample()
+	policyData := sample.GetPolicyData(scheme_test.FullyQualifiedPolicyNameSample())
+	policyData.Violations[1].Baselined = true
+
+	bytes, err := formatter.Format(formatter.Human, formatter.Options{}, sample, false)
+	require.Nilf(t, err, "Error formatting human: %v", err)
+	output := string(bytes)
+	require.Equal(t, 1, strings.Count(output, "Baselined Violations:"), "expecting the baselined violations of the policy in a separate section")
+	require.Equal(t, 3, strings.Count(output, "Violations:"), "expecting the new violations of both policies apart from the baselined one")
+	require.Contains(t, output, "(1 baselined)")
+}
+
+func TestFormatHumanSummaryNamespace(t *testing.T) {
+	sample := scheme_test.SchemeSample()
+	policyData := sample.GetPolicyData(scheme_test.FullyQualifiedPolicyNameSample())
+	policyData.PolicyInfo.Category = "secrets"
+	sample.AsOrderedMap().Set(scheme_test.FullyQualifiedPolicyNameSample(), policyData)
+
+	bytes, err := formatter.Format(formatter.Human, formatter.Options{}, sample, false)
+	require.Nilf(t, err, "Error formatting human: %v", err)
+	require.NotContains(t, string(bytes), "secrets", "expecting the summary to list the namespace of the policy rather than its category")
+	require.Contains(t, string(bytes), policyData.PolicyInfo.Namespace)
+}
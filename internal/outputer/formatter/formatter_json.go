@@ -1,16 +1,20 @@
 package formatter
 
 import (
-	"encoding/json"
+	"io"
 
+	"github.com/Legit-Labs/legitify/internal/analyzers"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 )
 
 type JsonFormatter struct {
+	options Options
 }
 
-func NewJsonFormatter() OutputFormatter {
-	return &JsonFormatter{}
+func NewJsonFormatter(options Options) OutputFormatter {
+	return &JsonFormatter{
+		options: options,
+	}
 }
 
 func (f *JsonFormatter) Format(s scheme.Scheme, failedOnly bool) ([]byte, error) {
@@ -19,8 +23,9 @@ func (f *JsonFormatter) Format(s scheme.Scheme, failedOnly bool) ([]byte, error)
 		return nil, err
 	}
 	typed := scheme.NewTypedMarshalable(schemeType, s)
+	typed.Metadata = f.options.Metadata
 
-	bytes, err := json.MarshalIndent(&typed, "", DefaultOutputIndent)
+	bytes, err := marshalJson(&typed, "", f.options.JsonIndent)
 	if err != nil {
 		return nil, err
 	}
@@ -30,3 +35,115 @@ func (f *JsonFormatter) Format(s scheme.Scheme, failedOnly bool) ([]byte, error)
 func (f *JsonFormatter) IsSchemeSupported(schemeType string) bool {
 	return true
 }
+
+// jsonStreamWriter writes the json output incrementally, keeping the first error
+type jsonStreamWriter struct {
+	writer io.Writer
+	indent string
+	err    error
+}
+
+func (w *jsonStreamWriter) write(str string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.writer, str)
+}
+
+// newline breaks the line and indents the next one (nothing when compact)
+func (w *jsonStreamWriter) newline(depth int) {
+	if w.indent == "" {
+		return
+	}
+	w.write("\n" + amplifyIndentSpecial(depth, w.indent))
+}
+
+func (w *jsonStreamWriter) key(name string) {
+	w.writeValue(0, name)
+	if w.indent == "" {
+		w.write(":")
+	} else {
+		w.write(": ")
+	}
+}
+
+func (w *jsonStreamWriter) writeValue(depth int, v interface{}) {
+	if w.err != nil {
+		return
+	}
+	encoded, err := marshalJson(v, amplifyIndentSpecial(depth, w.indent), w.indent)
+	if err != nil {
+		w.err = err
+		return
+	}
+	_, w.err = w.writer.Write(encoded)
+}
+
+// FormatStream writes the same output as Format for the flattened scheme
+func (f *JsonFormatter) FormatStream(source scheme.ViolationsSource, failedOnly bool, writer io.Writer) error {
+	w := &jsonStreamWriter{writer: writer, indent: f.options.JsonIndent}
+
+	w.write("{")
+	if f.options.Metadata != nil {
+		w.newline(1)
+		w.key("metadata")
+		w.writeValue(1, f.options.Metadata)
+		w.write(",")
+	}
+	w.newline(1)
+	w.key("type")
+	w.writeValue(1, scheme.TypeFlattened)
+	w.write(",")
+	w.newline(1)
+	w.key("content")
+	w.write("{")
+	first := true
+	for _, policyName := range source.Policies() {
+		if failedOnly && source.StatusCount(policyName, analyzers.PolicyFailed) == 0 {
+			continue
+		}
+		if !first {
+			w.write(",")
+		}
+		first = false
+
+		w.newline(2)
+		w.key(policyName)
+		w.write("{")
+		w.newline(3)
+		w.key("policyInfo")
+		w.writeValue(3, source.PolicyInfo(policyName))
+		w.write(",")
+		w.newline(3)
+		w.key("violations")
+		w.write("[")
+
+		firstViolation := true
+		err := filteredViolations(source, policyName, failedOnly, func(violation scheme.Violation) error {
+			if !firstViolation {
+				w.write(",")
+			}
+			firstViolation = false
+			w.newline(4)
+			w.writeValue(4, violation)
+			return w.err
+		})
+		if err != nil {
+			return err
+		}
+		if !firstViolation {
+			w.newline(3)
+		}
+		w.write("]")
+		w.newline(2)
+		w.write("}")
+	}
+	if !first {
+		w.newline(1)
+	}
+	w.write("}")
+	w.newline(0)
+	w.write("}")
+
+	return w.err
+}
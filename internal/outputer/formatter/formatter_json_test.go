@@ -2,8 +2,10 @@ package formatter_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
 	"github.com/stretchr/testify/require"
 )
@@ -12,9 +14,44 @@ func TestFormatJson(t *testing.T) {
 	sample := scheme_test.SchemeSample()
 
 	for _, f := range []bool{true, false} {
-		bytes, err := formatter.Format(formatter.Json, formatter.DefaultOutputIndent, sample, f)
+		bytes, err := formatter.Format(formatter.Json, formatter.Options{}, sample, f)
 		require.Nilf(t, err, "Error formatting json: %v", err)
 		require.NotNil(t, bytes, "Error formatting json")
 		require.NotEmpty(t, bytes, "Error formatting json")
 	}
 }
+
+func TestFormatJsonMetadata(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	metadata := &scheme.Metadata{
+		Tool:      "legitify",
+		Version:   "1.0.0",
+		ScanStart: &start,
+		ScanEnd:   &end,
+		Providers: []scheme.ProviderMetadata{{Scm: "github", Organizations: []string{"org"}, Namespaces: []string{"repository"}}},
+		Policies:  "builtin 1.0.0",
+	}
+
+	bytes, err := formatter.Format(formatter.Json, formatter.Options{Metadata: metadata}, scheme_test.SchemeSample(), false)
+	require.Nil(t, err)
+
+	parsed, err := scheme.UnmarshalMetadata(bytes)
+	require.Nil(t, err)
+	require.Equal(t, metadata, parsed)
+}
+
+func TestParseJsonIndent(t *testing.T) {
+	indent, err := formatter.ParseJsonIndent(formatter.JsonIndentCompact)
+	require.Nil(t, err)
+	require.Equal(t, "", indent)
+
+	indent, err = formatter.ParseJsonIndent("4")
+	require.Nil(t, err)
+	require.Equal(t, "    ", indent)
+
+	for _, invalid := range []string{"-1", "tabs", ""} {
+		_, err = formatter.ParseJsonIndent(invalid)
+		require.NotNilf(t, err, "expecting %q to be invalid", invalid)
+	}
+}
@@ -13,11 +13,13 @@ import (
 
 type markdownFormatter struct {
 	colorizer markdownColorizer
+	options   Options
 }
 
-func newMarkdownFormatter() OutputFormatter {
+func newMarkdownFormatter(options Options) OutputFormatter {
 	return &markdownFormatter{
 		colorizer: markdownColorizer{},
+		options:   options,
 	}
 }
 
@@ -36,23 +38,31 @@ func (m *markdownFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byt
 
 	failedPolicies = m.formatFailedPolicies(typedOutput)
 
-	return append(summary, failedPolicies...), nil
+	return append(append(m.formatMetadata(), summary...), failedPolicies...), nil
 }
 
 func (m *markdownFormatter) IsSchemeSupported(schemeType string) bool {
 	return schemeType == scheme.TypeFlattened
 }
 
+func (m *markdownFormatter) formatMetadata() []byte {
+	if m.options.Metadata == nil {
+		return nil
+	}
+	tc := newTableContent(newMarkdownTableFormatter(), m.colorizer, m.options)
+	return append(tc.FormatMetadata(m.options.Metadata), '\n')
+}
+
 func (m *markdownFormatter) formatSummaryTable(output *scheme.Flattened) []byte {
 	tf := newMarkdownTableFormatter()
-	tw := newTableContent(tf, m.colorizer)
+	tw := newTableContent(tf, m.colorizer, m.options)
 	return tw.FormatSummary(output)
 }
 
 func (m *markdownFormatter) formatFailedPolicies(output *scheme.Flattened) []byte {
 	failedPolicies := output.OnlyFailedViolations()
 	pf := newMarkdownPolicyFormatter()
-	pc := newPoliciesContent(pf, m.colorizer)
+	pc := newPoliciesContent(pf, m.colorizer, m.options)
 	return pc.FormatFailedPolicies(failedPolicies)
 }
 
@@ -12,7 +12,7 @@ func TestFormatMarkdown(t *testing.T) {
 	sample := scheme_test.SchemeSample()
 
 	for _, f := range []bool{true, false} {
-		bytes, err := formatter.Format(formatter.Markdown, formatter.DefaultOutputIndent, sample, f)
+		bytes, err := formatter.Format(formatter.Markdown, formatter.Options{}, sample, f)
 		require.Nilf(t, err, "Error formatting markdown: %v", err)
 		require.NotNil(t, bytes, "Error formatting markdown")
 		require.NotEmpty(t, bytes, "Error formatting markdown")
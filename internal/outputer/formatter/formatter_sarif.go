@@ -1,7 +1,6 @@
 package formatter
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/Legit-Labs/legitify/internal/analyzers"
 	"strings"
@@ -12,13 +11,17 @@ import (
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 )
 
+const sarifFingerprintKey = "legitifyFingerprint/v1"
+
 type sarifFormatter struct {
 	colorizer sarifColorizer
+	options   Options
 }
 
-func newSarifFormatter() OutputFormatter {
+func newSarifFormatter(options Options) OutputFormatter {
 	return &sarifFormatter{
 		colorizer: sarifColorizer{},
+		options:   options,
 	}
 }
 
@@ -49,6 +52,7 @@ func (f *sarifFormatter) Format(s scheme.Scheme, failedOnly bool) ([]byte, error
 	}
 
 	run := sarif.NewRunWithInformationURI("legitify", "https://legitify.dev/")
+	addSarifMetadata(run, f.options.Metadata)
 
 	for _, policyName := range s.AsOrderedMap().Keys() {
 		data := typedOutput.GetPolicyData(policyName)
@@ -83,6 +87,7 @@ func (f *sarifFormatter) Format(s scheme.Scheme, failedOnly bool) ([]byte, error
 				WithLevel(sarifSeverity(policyInfo.Severity)).
 				WithMessage(sarif.NewTextMessage(getViolationMessage(&violation, &policyInfo))).
 				WithHostedViewerUri(violation.CanonicalLink).
+				WithPartialFingerPrints(sarifPartialFingerprints(violation)).
 				AddLocation(
 					sarif.NewLocationWithPhysicalLocation(
 						sarif.NewPhysicalLocation().
@@ -97,13 +102,43 @@ func (f *sarifFormatter) Format(s scheme.Scheme, failedOnly bool) ([]byte, error
 
 	report.AddRun(run)
 
-	bytes, err := json.MarshalIndent(report, "", DefaultOutputIndent)
+	bytes, err := marshalJson(report, "", f.options.JsonIndent)
 	if err != nil {
 		return nil, err
 	}
 	return bytes, nil
 }
 
+// sarifPartialFingerprints lets the consumers (e.g. code scanning) match the results across runs by the violation fingerprint
+// rather than by the location, which is derived from the canonical link.
+func sarifPartialFingerprints(violation scheme.Violation) map[string]interface{} {
+	if violation.Fingerprint == "" {
+		return nil
+	}
+	return map[string]interface{}{sarifFingerprintKey: violation.Fingerprint}
+}
+
+// addSarifMetadata describes the scan by the tool driver info and an invocation of the run
+func addSarifMetadata(run *sarif.Run, metadata *scheme.Metadata) {
+	if metadata == nil {
+		return
+	}
+
+	pb := sarif.NewPropertyBag()
+	pb.Add("commit", metadata.Commit)
+	pb.Add("policies", metadata.Policies)
+	pb.Add("providers", metadata.Providers)
+	run.Tool.Driver.WithVersion(metadata.Version).AttachPropertyBag(pb)
+
+	invocation := run.AddInvocation(true)
+	if metadata.ScanStart != nil {
+		invocation.WithStartTimeUTC(*metadata.ScanStart)
+	}
+	if metadata.ScanEnd != nil {
+		invocation.WithEndTimeUTC(*metadata.ScanEnd)
+	}
+}
+
 func (f *sarifFormatter) IsSchemeSupported(schemeType string) bool {
 	return true
 }
@@ -162,18 +197,18 @@ func sarifSecuritySeverity(s severity.Severity) string {
 }
 
 func getSarifContent() *policiesContent {
-	sFormatter := newSarifFormatter()
+	sFormatter := newSarifFormatter(Options{})
 	typedFormatter := sFormatter.(*sarifFormatter)
 	pf := newSarifPolicyFormatter()
-	pc := newPoliciesContent(pf, typedFormatter.colorizer)
+	pc := newPoliciesContent(pf, typedFormatter.colorizer, Options{})
 	return pc
 }
 
 func getMarkdownContent() *policiesContent {
-	sFormatter := newMarkdownFormatter()
+	sFormatter := newMarkdownFormatter(Options{})
 	typedFormatter := sFormatter.(*markdownFormatter)
 	pf := newMarkdownPolicyFormatter()
-	pc := newPoliciesContent(pf, typedFormatter.colorizer)
+	pc := newPoliciesContent(pf, typedFormatter.colorizer, Options{})
 	return pc
 }
 
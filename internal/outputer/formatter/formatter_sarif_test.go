@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
 	"github.com/qri-io/jsonschema"
 	"github.com/stretchr/testify/require"
@@ -16,8 +18,14 @@ import (
 func TestFormatSarif(t *testing.T) {
 	sample := scheme_test.SchemeSample()
 
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	options := formatter.Options{
+		JsonIndent: formatter.DefaultOutputIndent,
+		Metadata:   &scheme.Metadata{Tool: "legitify", Version: "1.0.0", ScanStart: &start, Policies: "builtin 1.0.0"},
+	}
+
 	for _, f := range []bool{true, false} {
-		bytes, err := formatter.Format(formatter.Sarif, formatter.DefaultOutputIndent, sample, f)
+		bytes, err := formatter.Format(formatter.Sarif, options, sample, f)
 		require.Nilf(t, err, "Error formatting sarif: %v", err)
 		require.NotNil(t, bytes, "Error formatting sarif")
 		require.NotEmpty(t, bytes, "Error formatting sarif")
@@ -43,5 +51,6 @@ func TestFormatSarif(t *testing.T) {
 		}
 
 		require.Emptyf(t, errs, "SARIF output does not match schema: %v", errs)
+		require.Contains(t, string(bytes), `"version": "1.0.0"`, "the tool driver should describe the scan")
 	}
 }
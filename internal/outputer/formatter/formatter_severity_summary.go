@@ -0,0 +1,148 @@
+package formatter
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+// severitySummaryFormatter writes only the aggregated counts of the results as compact json (see --severity-summary-only),
+// the lightest integration point for trend dashboards. The violations themselves are left out.
+type severitySummaryFormatter struct {
+	options Options
+}
+
+func newSeveritySummaryFormatter(options Options) OutputFormatter {
+	return &severitySummaryFormatter{
+		options: options,
+	}
+}
+
+type statusCounts struct {
+	Passed    int `json:"passed"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+	Baselined int `json:"baselined"`
+}
+
+func (c *statusCounts) add(violation scheme.Violation) {
+	switch violation.Status {
+	case analyzers.PolicyPassed:
+		c.Passed++
+	case analyzers.PolicyFailed:
+		c.Failed++
+		if violation.Baselined {
+			c.Baselined++
+		}
+	case analyzers.PolicySkipped:
+		c.Skipped++
+	}
+}
+
+// summaryCoverage is the fraction of the collected entities that were not partially blocked by missing permissions (see --min-coverage)
+type summaryCoverage struct {
+	Percent   float64 `json:"percent"`
+	Collected int     `json:"collected"`
+	Blocked   int     `json:"blocked"`
+}
+
+type severitySummary struct {
+	Metadata   *scheme.Metadata         `json:"metadata,omitempty"`
+	Total      statusCounts             `json:"total"`
+	Severities map[string]*statusCounts `json:"severities"`
+	Namespaces map[string]*statusCounts `json:"namespaces"`
+	Entities   int                      `json:"entities"`
+	// Coverage is omitted when nothing was collected (e.g. when converting the results of a previous scan)
+	Coverage *summaryCoverage `json:"coverage,omitempty"`
+	// PassedPolicies is the number of passed policies that were left out (--only-failures)
+	PassedPolicies int `json:"passedPolicies,omitempty"`
+
+	entities   map[[3]string]bool
+	collection *errlog.CollectionLog
+}
+
+func newSeveritySummary(options Options) *severitySummary {
+	return &severitySummary{
+		Metadata:       options.Metadata,
+		Severities:     make(map[string]*statusCounts),
+		Namespaces:     make(map[string]*statusCounts),
+		PassedPolicies: options.PassedPolicies,
+		entities:       make(map[[3]string]bool),
+		collection:     options.Collection,
+	}
+}
+
+func (s *severitySummary) add(policyInfo scheme.PolicyInfo, violation scheme.Violation) {
+	if s.Severities[policyInfo.Severity] == nil {
+		s.Severities[policyInfo.Severity] = &statusCounts{}
+	}
+	if s.Namespaces[policyInfo.Namespace] == nil {
+		s.Namespaces[policyInfo.Namespace] = &statusCounts{}
+	}
+	s.Total.add(violation)
+	s.Severities[policyInfo.Severity].add(violation)
+	s.Namespaces[policyInfo.Namespace].add(violation)
+	s.entities[[3]string{violation.Provider, violation.ViolationEntityType, violation.CanonicalLink}] = true
+}
+
+func (s *severitySummary) marshal() ([]byte, error) {
+	s.Entities = len(s.entities)
+	if ratio, collected, blocked := s.collection.Coverage(); collected > 0 {
+		s.Coverage = &summaryCoverage{Percent: ratio * 100, Collected: collected, Blocked: blocked}
+	}
+
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes, '\n'), nil
+}
+
+func (f *severitySummaryFormatter) IsSchemeSupported(schemeType string) bool {
+	return schemeType == scheme.TypeFlattened
+}
+
+func (f *severitySummaryFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byte, error) {
+	typedOutput, ok := output.(*scheme.Flattened)
+	if !ok {
+		return nil, UnsupportedScheme{output}
+	}
+
+	summary := newSeveritySummary(f.options)
+	for _, policyName := range typedOutput.AsOrderedMap().Keys() {
+		data := typedOutput.GetPolicyData(policyName)
+		for _, violation := range data.Violations {
+			if failedOnly && violation.Status != analyzers.PolicyFailed {
+				continue
+			}
+			summary.add(data.PolicyInfo, violation)
+		}
+	}
+
+	return summary.marshal()
+}
+
+// FormatStream writes the same output as Format, without keeping the violations in memory
+func (f *severitySummaryFormatter) FormatStream(source scheme.ViolationsSource, failedOnly bool, writer io.Writer) error {
+	summary := newSeveritySummary(f.options)
+	for _, policyName := range source.Policies() {
+		policyInfo := source.PolicyInfo(policyName)
+		err := filteredViolations(source, policyName, failedOnly, func(violation scheme.Violation) error {
+			summary.add(policyInfo, violation)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	output, err := summary.marshal()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(output)
+	return err
+}
@@ -0,0 +1,81 @@
+package formatter_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSeveritySummary(t *testing.T) {
+	violation := func(repo int, status analyzers.PolicyStatus, baselined bool) scheme.Violation {
+		return scheme.Violation{
+			ViolationEntityType: namespace.Repository,
+			CanonicalLink:       fmt.Sprintf("https://github.com/org/repo-%d", repo),
+			Status:              status,
+			Baselined:           baselined,
+		}
+	}
+	sample := scheme.NewFlattenedScheme()
+	sample.AsOrderedMap().Set("data.repository.policy", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{PolicyName: "policy", Namespace: namespace.Repository, Severity: severity.High},
+		Violations: []scheme.Violation{
+			violation(1, analyzers.PolicyFailed, true),
+			violation(2, analyzers.PolicyFailed, false),
+			violation(3, analyzers.PolicyPassed, false),
+		},
+	})
+	sample.AsOrderedMap().Set("data.organization.policy", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{PolicyName: "policy", Namespace: namespace.Organization, Severity: severity.Low},
+		Violations: []scheme.Violation{
+			{ViolationEntityType: namespace.Organization, CanonicalLink: "https://github.com/org", Status: analyzers.PolicySkipped},
+		},
+	})
+
+	output, err := formatter.Format(formatter.SeveritySummary, formatter.Options{}, sample, false)
+	require.Nil(t, err)
+	require.Equal(t, 1, strings.Count(string(output), "\n"), "expecting compact json")
+	require.NotContains(t, string(output), "github.com", "expecting no violations")
+
+	var summary map[string]interface{}
+	require.Nil(t, json.Unmarshal(output, &summary))
+	require.Equal(t, map[string]interface{}{"passed": 1.0, "failed": 2.0, "skipped": 1.0, "baselined": 1.0}, summary["total"])
+	require.Equal(t, map[string]interface{}{
+		severity.High: map[string]interface{}{"passed": 1.0, "failed": 2.0, "skipped": 0.0, "baselined": 1.0},
+		severity.Low:  map[string]interface{}{"passed": 0.0, "failed": 0.0, "skipped": 1.0, "baselined": 0.0},
+	}, summary["severities"])
+	require.Contains(t, summary["namespaces"], namespace.Organization)
+	require.Contains(t, summary["namespaces"], namespace.Repository)
+	require.Equal(t, 4.0, summary["entities"])
+	require.NotContains(t, summary, "coverage", "expecting no coverage when nothing was collected")
+
+	failedOnly, err := formatter.Format(formatter.SeveritySummary, formatter.Options{}, sample, true)
+	require.Nil(t, err)
+	require.Nil(t, json.Unmarshal(failedOnly, &summary))
+	require.Equal(t, 2.0, summary["entities"])
+
+	require.NotNil(t, formatter.ValidateOutputFormat(formatter.SeveritySummary, scheme.TypeGroupBySeverity))
+}
+
+func TestFormatSeveritySummaryCoverage(t *testing.T) {
+	collection := errlog.NewCollectionLog()
+	for i := 0; i < 4; i++ {
+		collection.Add(namespace.Repository)
+	}
+	collection.AddPermIssue(errlog.NewPermIssue("admin", "org/repo", namespace.Repository, "cannot read the branch protection"))
+
+	output, err := formatter.Format(formatter.SeveritySummary, formatter.Options{Collection: collection}, scheme.NewFlattenedScheme(), false)
+	require.Nil(t, err)
+
+	var summary map[string]interface{}
+	require.Nil(t, json.Unmarshal(output, &summary))
+	require.Equal(t, map[string]interface{}{"percent": 75.0, "collected": 4.0, "blocked": 1.0}, summary["coverage"])
+}
@@ -0,0 +1,93 @@
+package formatter
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+// sqliteFormatter writes the results as an SQLite database, for querying them with SQL.
+// The schema is documented in the README and only changes by adding tables and columns.
+// Lists (e.g. threat, owners) are stored as json arrays, which can be queried with the SQLite json functions.
+// The entities are those of the violations in the output; entities that have none (e.g. that no policy applies to,
+// or that passed every policy with --only-failures) are listed by the inventory instead (see --inventory-file).
+type sqliteFormatter struct {
+	options Options
+}
+
+func newSqliteFormatter(options Options) OutputFormatter {
+	return &sqliteFormatter{
+		options: options,
+	}
+}
+
+func (f *sqliteFormatter) IsSchemeSupported(schemeType string) bool {
+	return schemeType == scheme.TypeFlattened
+}
+
+func (f *sqliteFormatter) Format(output scheme.Scheme, failedOnly bool) ([]byte, error) {
+	typedOutput, ok := output.(*scheme.Flattened)
+	if !ok {
+		return nil, UnsupportedScheme{output}
+	}
+
+	db := newSqliteDatabase()
+	metadataTable := db.createTable("metadata", "key TEXT", "value TEXT")
+	policies := db.createTable("policies",
+		"name TEXT", "policy_name TEXT", "title TEXT", "description TEXT", "namespace TEXT", "category TEXT",
+		"severity TEXT", "threat TEXT", "remediation_steps TEXT")
+	entities := db.createTable("entities", "id INTEGER", "provider TEXT", "type TEXT", "link TEXT", "entity_id TEXT", "owners TEXT")
+	violations := db.createTable("violations",
+		"policy TEXT", "entity INTEGER", "status TEXT", "fingerprint TEXT", "baselined INTEGER", "aux TEXT")
+	missingPermissions := db.createTable("missing_permissions", "permission TEXT", "namespace TEXT", "entity TEXT", "effect TEXT")
+
+	if f.options.Metadata != nil {
+		for _, field := range f.options.Metadata.Fields() {
+			metadataTable.insert(field[0], field[1])
+		}
+	}
+
+	entityIDs := make(map[[3]string]int)
+	for _, policyName := range typedOutput.AsOrderedMap().Keys() {
+		data := typedOutput.GetPolicyData(policyName)
+		info := data.PolicyInfo
+		threat, err := json.Marshal(info.Threat)
+		if err != nil {
+			return nil, err
+		}
+		remediation, err := json.Marshal(info.RemediationSteps)
+		if err != nil {
+			return nil, err
+		}
+		policies.insert(policyName, info.PolicyName, info.Title, info.Description, info.Namespace, info.Category,
+			info.Severity, string(threat), string(remediation))
+
+		for _, violation := range data.Violations {
+			key := [3]string{violation.Provider, violation.ViolationEntityType, violation.CanonicalLink}
+			id, ok := entityIDs[key]
+			if !ok {
+				owners, err := json.Marshal(violation.Owners)
+				if err != nil {
+					return nil, err
+				}
+				id = len(entityIDs) + 1
+				entityIDs[key] = id
+				entities.insert(id, violation.Provider, violation.ViolationEntityType, violation.CanonicalLink, violation.EntityID, string(owners))
+			}
+
+			aux, err := json.Marshal(violation.Aux)
+			if err != nil {
+				return nil, err
+			}
+			violations.insert(policyName, id, violation.Status, violation.Fingerprint, violation.Baselined, string(aux))
+		}
+	}
+
+	for _, issue := range f.options.Collection.MissingPermissions() {
+		namespace, entity, _ := strings.Cut(issue.Entity, ":")
+		missingPermissions.insert(issue.Permission, namespace, entity, issue.Effect)
+	}
+
+	return db.bytes()
+}
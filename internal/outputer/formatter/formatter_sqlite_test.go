@@ -0,0 +1,85 @@
+package formatter_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/errlog"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSqlite(t *testing.T) {
+	output, err := formatter.Format(formatter.Sqlite, formatter.Options{}, scheme_test.SchemeSample(), false)
+	require.Nil(t, err)
+	require.Equal(t, "SQLite format 3\x00", string(output[:16]))
+	require.Zero(t, len(output)%4096, "the database must consist of whole pages")
+
+	require.NotNil(t, formatter.ValidateOutputFormat(formatter.Sqlite, scheme.TypeGroupBySeverity))
+}
+
+// TestFormatSqliteQueries verifies the database with the sqlite3 cli (when it is installed)
+func TestFormatSqliteQueries(t *testing.T) {
+	cli, err := exec.LookPath("sqlite3")
+	if err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+
+	// enough violations to span several pages, and an aux that spans several overflow pages
+	violations := []scheme.Violation{}
+	for i := 0; i < 2000; i++ {
+		aux := orderedmap.New()
+		if i == 0 {
+			aux.Set("details", strings.Repeat("x", 20000))
+		}
+		violations = append(violations, scheme.Violation{
+			ViolationEntityType: "repository",
+			CanonicalLink:       fmt.Sprintf("https://github.com/org/repo-%d", i),
+			Aux:                 aux,
+			Status:              analyzers.PolicyFailed,
+			Owners:              []string{"org/team"},
+		})
+	}
+	sample := scheme.NewFlattenedScheme()
+	sample.AsOrderedMap().Set("data.repository.policy", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{Title: "Repository Policy", PolicyName: "policy", Namespace: "repository", Severity: severity.High},
+		Violations: violations,
+	})
+	sample.AsOrderedMap().Set("data.repository.other", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{Title: "Other Policy", PolicyName: "other", Namespace: "repository", Severity: severity.Low},
+		Violations: violations[:1],
+	})
+
+	collection := errlog.NewCollectionLog()
+	collection.AddPermIssue(errlog.PermIssue{Permission: "admin:org", Entity: "organization:org", Effect: "Cannot read the hooks"})
+	output, err := formatter.Format(formatter.Sqlite, formatter.Options{Collection: collection}, sample, false)
+	require.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "scan.db")
+	require.Nil(t, os.WriteFile(path, output, 0644))
+
+	query := func(sql string) string {
+		result, err := exec.Command(cli, path, sql).CombinedOutput()
+		require.Nil(t, err, string(result))
+		return strings.TrimSpace(string(result))
+	}
+
+	require.Equal(t, "ok", query("PRAGMA integrity_check"))
+	require.Equal(t, "metadata\npolicies\nentities\nviolations\nmissing_permissions", query("SELECT name FROM sqlite_master"))
+	require.Equal(t, "2001", query("SELECT count(*) FROM violations"))
+	require.Equal(t, "2000", query("SELECT count(*) FROM entities"))
+	require.Equal(t, "20000", query("SELECT length(json_extract(aux, '$.details')) FROM violations WHERE rowid = 1"))
+	require.Equal(t, "other|Other Policy|LOW|https://github.com/org/repo-0|org/team", query(
+		`SELECT p.policy_name, p.title, p.severity, e.link, json_extract(e.owners, '$[0]')
+		 FROM violations v JOIN policies p ON p.name = v.policy JOIN entities e ON e.id = v.entity
+		 WHERE p.policy_name = 'other'`))
+	require.Equal(t, "admin:org|organization|org|Cannot read the hooks", query("SELECT * FROM missing_permissions"))
+}
@@ -2,7 +2,9 @@ package formatter
 
 import (
 	"fmt"
+	"io"
 
+	"github.com/Legit-Labs/legitify/internal/analyzers"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 )
 
@@ -14,6 +16,10 @@ const (
 	Sarif    FormatName = "sarif"
 	Markdown FormatName = "markdown"
 	Csv		 FormatName = "csv"
+	GithubIssue FormatName = "github-issue"
+	Sqlite   FormatName = "sqlite"
+	// SeveritySummary is selected by --severity-summary-only
+	SeveritySummary FormatName = "severity-summary"
 )
 
 type OutputFormatter interface {
@@ -21,7 +27,7 @@ type OutputFormatter interface {
 	IsSchemeSupported(schemeType string) bool
 }
 
-type NewFormatFunc func() OutputFormatter
+type NewFormatFunc func(options Options) OutputFormatter
 
 var outputFormatters = map[FormatName]NewFormatFunc{
 	Human:    newHumanFormatter,
@@ -29,6 +35,9 @@ var outputFormatters = map[FormatName]NewFormatFunc{
 	Markdown: newMarkdownFormatter,
 	Sarif:    newSarifFormatter,
 	Csv:	  newCSVFormatter,
+	GithubIssue: newGithubIssueFormatter,
+	Sqlite:   newSqliteFormatter,
+	SeveritySummary: newSeveritySummaryFormatter,
 }
 
 func ValidateOutputFormat(outputFormat FormatName, schemeType scheme.SchemeType) error {
@@ -37,7 +46,7 @@ func ValidateOutputFormat(outputFormat FormatName, schemeType scheme.SchemeType)
 		return fmt.Errorf("unsupported output format: %s", outputFormat)
 	}
 
-	formatter := creator()
+	formatter := creator(Options{})
 	if !formatter.IsSchemeSupported(schemeType) {
 		return fmt.Errorf("scheme Type (%s) does not support output format: %s", schemeType, outputFormat)
 	}
@@ -57,13 +66,13 @@ func OutputFormats() []FormatName {
 	return formatNames
 }
 
-func Format(outputFormat FormatName, outputIndent string, scheme scheme.Scheme, failedOnly bool) ([]byte, error) {
+func Format(outputFormat FormatName, options Options, scheme scheme.Scheme, failedOnly bool) ([]byte, error) {
 	outputFormatterCreator := outputFormatters[outputFormat]
 	if outputFormatterCreator == nil {
 		return nil, fmt.Errorf("no output generator for %s", outputFormat)
 	}
 
-	outputFormatter := outputFormatterCreator()
+	outputFormatter := outputFormatterCreator(options)
 
 	output, err := outputFormatter.Format(scheme, failedOnly)
 	if err != nil {
@@ -80,3 +89,42 @@ type UnsupportedScheme struct {
 func (e UnsupportedScheme) Error() string {
 	return fmt.Sprintf("unsupported scheme type: %T", e.scheme)
 }
+
+// StreamFormatter is implemented by formatters that can format results which do not fit in memory,
+// by reading them policy by policy and writing the output incrementally.
+type StreamFormatter interface {
+	FormatStream(source scheme.ViolationsSource, failedOnly bool, writer io.Writer) error
+}
+
+func SupportsStreaming(outputFormat FormatName) bool {
+	creator, ok := outputFormatters[outputFormat]
+	if !ok {
+		return false
+	}
+	_, ok = creator(Options{}).(StreamFormatter)
+	return ok
+}
+
+func FormatStream(outputFormat FormatName, options Options, source scheme.ViolationsSource, failedOnly bool, writer io.Writer) error {
+	creator, ok := outputFormatters[outputFormat]
+	if !ok {
+		return fmt.Errorf("no output generator for %s", outputFormat)
+	}
+
+	streamFormatter, ok := creator(options).(StreamFormatter)
+	if !ok {
+		return fmt.Errorf("output format %s does not support streaming", outputFormat)
+	}
+
+	return streamFormatter.FormatStream(source, failedOnly, writer)
+}
+
+// filteredViolations iterates the violations of the policy, optionally skipping the ones that did not fail
+func filteredViolations(source scheme.ViolationsSource, policyName string, failedOnly bool, f func(violation scheme.Violation) error) error {
+	return source.ForEachViolation(policyName, func(violation scheme.Violation) error {
+		if failedOnly && violation.Status != analyzers.PolicyFailed {
+			return nil
+		}
+		return f(violation)
+	})
+}
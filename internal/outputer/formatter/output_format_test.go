@@ -1,7 +1,10 @@
 package formatter_test
 
 import (
+	"io"
 	"log"
+	"os"
+	"sync"
 	"testing"
 
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
@@ -13,7 +16,7 @@ func TestOutputFormats(t *testing.T) {
 	scheme := scheme_test.SchemeSample()
 
 	for _, name := range formatter.OutputFormats() {
-		output, err := formatter.Format(name, formatter.DefaultOutputIndent, scheme, true)
+		output, err := formatter.Format(name, formatter.Options{}, scheme, true)
 
 		require.Nilf(t, err, "Unexpected error for output format %s: %s", name, err)
 		require.NotNil(t, output, "Expecting output for %s", name)
@@ -37,9 +40,76 @@ func TestOutputFormats(t *testing.T) {
 		case formatter.Csv:
 			// csv has dedicated tests
 			continue
+		case formatter.GithubIssue:
+			// github issue has dedicated tests
+			continue
+		case formatter.Sqlite:
+			// sqlite has dedicated tests
+			continue
+		case formatter.SeveritySummary:
+			// severity summary has dedicated tests
+			continue
 
 		default:
 			t.Fatalf("unexpected format: %s", name)
 		}
 	}
 }
+
+// formatters must only return the formatted output, so that the output can be safely piped
+// while the progress bar and logs are written to stderr.
+func TestOutputFormatsDoNotWriteToStdStreams(t *testing.T) {
+	scheme := scheme_test.SchemeSample()
+
+	for _, name := range formatter.OutputFormats() {
+		for _, failedOnly := range []bool{true, false} {
+			var output []byte
+			var err error
+			stdout, stderr := captureStdStreams(t, func() {
+				output, err = formatter.Format(name, formatter.Options{}, scheme, failedOnly)
+			})
+
+			require.Nilf(t, err, "Unexpected error for output format %s: %s", name, err)
+			require.NotEmpty(t, output, "Expecting output for %s", name)
+			require.Emptyf(t, stdout, "output format %s wrote to stdout", name)
+			require.Emptyf(t, stderr, "output format %s wrote to stderr", name)
+		}
+	}
+}
+
+func captureStdStreams(t *testing.T, f func()) (string, string) {
+	origStdout, origStderr := os.Stdout, os.Stderr
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	require.Nil(t, err)
+	stderrReader, stderrWriter, err := os.Pipe()
+	require.Nil(t, err)
+
+	os.Stdout, os.Stderr = stdoutWriter, stderrWriter
+	defer func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+	}()
+
+	readAll := func(r *os.File, into *string) *sync.WaitGroup {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, _ := io.ReadAll(r)
+			*into = string(data)
+		}()
+		return &wg
+	}
+
+	var stdout, stderr string
+	stdoutDone := readAll(stdoutReader, &stdout)
+	stderrDone := readAll(stderrReader, &stderr)
+
+	f()
+
+	_ = stdoutWriter.Close()
+	_ = stderrWriter.Close()
+	stdoutDone.Wait()
+	stderrDone.Wait()
+
+	return stdout, stderr
+}
@@ -23,14 +23,16 @@ type policiesFormatter interface {
 type policiesContent struct {
 	pf        policiesFormatter
 	colorizer colorizer
+	options   Options
 	sb        strings.Builder
 	depth     int
 }
 
-func newPoliciesContent(pf policiesFormatter, colorizer colorizer) *policiesContent {
+func newPoliciesContent(pf policiesFormatter, colorizer colorizer, options Options) *policiesContent {
 	return &policiesContent{
 		pf:        pf,
 		colorizer: colorizer,
+		options:   options,
 	}
 }
 
@@ -124,19 +126,50 @@ func (pc *policiesContent) bold(text interface{}) string {
 	return pc.colorizer.colorize(themeColorBold, text)
 }
 
+// writeViolations lists the new violations apart from the violations that are in the baseline (see --baseline)
 func (pc *policiesContent) writeViolations(violations []scheme.Violation) {
-	pc.writeLine(pc.pf.FormatSubtitle("Violations:"))
+	var current, baselined []scheme.Violation
+	for _, violation := range violations {
+		if violation.Baselined {
+			baselined = append(baselined, violation)
+		} else {
+			current = append(current, violation)
+		}
+	}
+
+	if len(current) > 0 || len(baselined) == 0 {
+		pc.writeViolationsSection("Violations:", current)
+	}
+	if len(baselined) > 0 {
+		if len(current) > 0 {
+			pc.writeLineBreak()
+		}
+		pc.writeViolationsSection("Baselined Violations:", baselined)
+	}
+}
+
+func (pc *policiesContent) writeViolationsSection(title string, violations []scheme.Violation) {
+	pc.writeLine(pc.pf.FormatSubtitle(title))
 
-	lastIndex := len(violations) - 1
-	for i, violation := range violations {
+	shown, hidden := truncateViolations(violations, pc.options.MaxViolationsPerPolicy)
+	lastIndex := len(shown) - 1
+	for i, violation := range shown {
 		pc.writeViolation(&violation)
 		if i < lastIndex {
 			pc.writeLine(pc.pf.Separator())
 		}
 	}
+
+	if hidden > 0 {
+		pc.writeLine(pc.pf.Separator())
+		pc.writeLine("... and %d more", hidden)
+	}
 }
 
 func (pc *policiesContent) writeViolation(violation *scheme.Violation) {
+	if violation.Provider != "" {
+		pc.writeKeyval("Provider", violation.Provider)
+	}
 	pc.writeKeyval(fmt.Sprintf("Link to %s", violation.ViolationEntityType), violation.CanonicalLink)
 	pc.writeAux(violation.Aux)
 }
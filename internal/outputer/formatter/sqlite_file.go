@@ -0,0 +1,274 @@
+package formatter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// sqliteDatabase builds a database in the SQLite file format (https://www.sqlite.org/fileformat.html).
+// The release binaries are built without cgo, which rules out the sqlite3 driver, and a pure Go port of SQLite
+// is a far larger dependency than this writer, so the database is written directly instead of through a driver.
+// Only the subset of the format the sqlite output needs is written, in a single pass:
+//   - a UTF-8 database with 4096 bytes pages, in the rollback journal mode and without free pages
+//   - rowid tables only (no indexes, WITHOUT ROWID tables or views), whose schema fits in the first page
+//   - rows of integers, texts and nulls, appended with the rowids 1..n (large rows spill to overflow pages)
+//
+// The result is verified with the sqlite3 cli (PRAGMA integrity_check) by the tests when it is installed.
+type sqliteDatabase struct {
+	tables []*sqliteTable
+}
+
+type sqliteTable struct {
+	name    string
+	sql     string
+	records [][]byte
+}
+
+const (
+	sqlitePageSize      = 4096
+	sqliteHeaderSize    = 100
+	sqliteLeafTable     = 0x0d
+	sqliteInteriorTable = 0x05
+	// sqliteVersionNumber is the library version that is recorded as the last one that wrote the file
+	sqliteVersionNumber = 3039004
+)
+
+func newSqliteDatabase() *sqliteDatabase {
+	return &sqliteDatabase{}
+}
+
+// createTable creates a table with the columns (e.g. "name TEXT")
+func (d *sqliteDatabase) createTable(name string, columns ...string) *sqliteTable {
+	table := &sqliteTable{
+		name: name,
+		sql:  fmt.Sprintf("CREATE TABLE %s (%s)", name, strings.Join(columns, ", ")),
+	}
+	d.tables = append(d.tables, table)
+	return table
+}
+
+// insert appends a row of ints, bools (stored as 0/1), strings and nils
+func (t *sqliteTable) insert(values ...interface{}) {
+	t.records = append(t.records, sqliteRecord(values...))
+}
+
+func (d *sqliteDatabase) bytes() ([]byte, error) {
+	w := &sqliteWriter{}
+	w.allocate() // the schema table is rooted at the first page
+
+	var schema [][]byte
+	for _, table := range d.tables {
+		root := w.writeTable(table.records)
+		schema = append(schema, sqliteRecord("table", table.name, table.name, root, table.sql))
+	}
+
+	cells := w.leafCells(schema)
+	if !w.fits(cells, sqliteHeaderSize+8) {
+		return nil, fmt.Errorf("the schema of the database does not fit in a single page")
+	}
+	w.writePage(1, sqliteHeaderSize, sqliteLeafTable, cells, 0)
+	w.writeHeader()
+
+	return w.file(), nil
+}
+
+type sqliteWriter struct {
+	pages [][]byte
+}
+
+func (w *sqliteWriter) allocate() int {
+	w.pages = append(w.pages, make([]byte, sqlitePageSize))
+	return len(w.pages)
+}
+
+func (w *sqliteWriter) page(number int) []byte {
+	return w.pages[number-1]
+}
+
+func (w *sqliteWriter) file() []byte {
+	file := make([]byte, 0, len(w.pages)*sqlitePageSize)
+	for _, page := range w.pages {
+		file = append(file, page...)
+	}
+	return file
+}
+
+func (w *sqliteWriter) writeHeader() {
+	header := w.page(1)[:sqliteHeaderSize]
+	copy(header, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(header[16:], sqlitePageSize)
+	header[18] = 1 // legacy write version
+	header[19] = 1 // legacy read version
+	header[21] = 64
+	header[22] = 32
+	header[23] = 32
+	binary.BigEndian.PutUint32(header[24:], 1) // file change counter
+	binary.BigEndian.PutUint32(header[28:], uint32(len(w.pages)))
+	binary.BigEndian.PutUint32(header[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(header[44:], 4) // schema format
+	binary.BigEndian.PutUint32(header[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(header[92:], 1) // version-valid-for (the file change counter)
+	binary.BigEndian.PutUint32(header[96:], sqliteVersionNumber)
+}
+
+// writeTable writes the b-tree of the table rows and returns its root page
+func (w *sqliteWriter) writeTable(records [][]byte) int {
+	type child struct {
+		page   int
+		maxKey int
+	}
+
+	var level []child
+	cells := w.leafCells(records)
+	for start := 0; start < len(cells) || len(level) == 0; {
+		end := start
+		for end < len(cells) && w.fits(cells[start:end+1], 8) {
+			end++
+		}
+		page := w.allocate()
+		w.writePage(page, 0, sqliteLeafTable, cells[start:end], 0)
+		level = append(level, child{page: page, maxKey: end})
+		start = end
+	}
+
+	for len(level) > 1 {
+		var parents []child
+		for start := 0; start < len(level); {
+			// the last child of each interior page is its right-most pointer (rather than a cell)
+			var interiorCells [][]byte
+			end := start
+			for end < len(level)-1 {
+				cell := binary.BigEndian.AppendUint32(nil, uint32(level[end].page))
+				cell = append(cell, sqliteVarint(uint64(level[end].maxKey))...)
+				if !w.fits(append(interiorCells, cell), 12) {
+					break
+				}
+				interiorCells = append(interiorCells, cell)
+				end++
+			}
+			if end == len(level)-2 && end > start {
+				// leave two children to the last interior page, rather than only its right-most pointer
+				interiorCells = interiorCells[:len(interiorCells)-1]
+				end--
+			}
+			page := w.allocate()
+			w.writePage(page, 0, sqliteInteriorTable, interiorCells, level[end].page)
+			parents = append(parents, child{page: page, maxKey: level[end].maxKey})
+			start = end + 1
+		}
+		level = parents
+	}
+
+	return level[0].page
+}
+
+// leafCells returns the table leaf cells of the records (with the rowids 1..n), spilling large records to overflow pages
+func (w *sqliteWriter) leafCells(records [][]byte) [][]byte {
+	const usable = sqlitePageSize
+	const maxLocal = usable - 35
+	const minLocal = ((usable-12)*32)/255 - 23
+
+	cells := make([][]byte, 0, len(records))
+	for i, record := range records {
+		cell := append(sqliteVarint(uint64(len(record))), sqliteVarint(uint64(i+1))...)
+		if len(record) <= maxLocal {
+			cells = append(cells, append(cell, record...))
+			continue
+		}
+
+		local := minLocal + (len(record)-minLocal)%(usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+		cell = append(cell, record[:local]...)
+		cell = binary.BigEndian.AppendUint32(cell, uint32(w.writeOverflow(record[local:])))
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// writeOverflow writes the payload to a chain of overflow pages and returns the first one
+func (w *sqliteWriter) writeOverflow(payload []byte) int {
+	first := w.allocate()
+	page := first
+	for {
+		n := copy(w.page(page)[4:], payload)
+		payload = payload[n:]
+		if len(payload) == 0 {
+			return first
+		}
+		next := w.allocate()
+		binary.BigEndian.PutUint32(w.page(page), uint32(next))
+		page = next
+	}
+}
+
+func (w *sqliteWriter) fits(cells [][]byte, headerSize int) bool {
+	size := headerSize
+	for _, cell := range cells {
+		size += len(cell) + 2 // the cell and its pointer
+	}
+	return size <= sqlitePageSize
+}
+
+func (w *sqliteWriter) writePage(number int, offset int, pageType byte, cells [][]byte, rightMost int) {
+	page := w.page(number)
+	page[offset] = pageType
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+
+	pointers := offset + 8
+	if pageType == sqliteInteriorTable {
+		binary.BigEndian.PutUint32(page[offset+8:], uint32(rightMost))
+		pointers = offset + 12
+	}
+
+	content := sqlitePageSize
+	for i, cell := range cells {
+		content -= len(cell)
+		copy(page[content:], cell)
+		binary.BigEndian.PutUint16(page[pointers+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+// sqliteRecord encodes the values in the record format (a header of serial types followed by the values)
+func sqliteRecord(values ...interface{}) []byte {
+	var types, body []byte
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			types = append(types, sqliteVarint(0)...)
+		case bool:
+			if v {
+				types = append(types, sqliteVarint(9)...) // the integer 1
+			} else {
+				types = append(types, sqliteVarint(8)...) // the integer 0
+			}
+		case int:
+			types = append(types, sqliteVarint(6)...)
+			body = binary.BigEndian.AppendUint64(body, uint64(v))
+		case string:
+			types = append(types, sqliteVarint(uint64(13+2*len(v)))...)
+			body = append(body, v...)
+		default:
+			panic(fmt.Sprintf("unsupported sqlite value type %T", value))
+		}
+	}
+
+	headerSize := len(types) + 1
+	if len(sqliteVarint(uint64(headerSize))) > 1 {
+		headerSize = len(types) + len(sqliteVarint(uint64(len(types)+2)))
+	}
+	record := append(sqliteVarint(uint64(headerSize)), types...)
+	return append(record, body...)
+}
+
+// sqliteVarint encodes the value as a big-endian variable length integer (values below 2^56)
+func sqliteVarint(value uint64) []byte {
+	encoded := []byte{byte(value & 0x7f)}
+	for value >>= 7; value > 0; value >>= 7 {
+		encoded = append([]byte{byte(value&0x7f) | 0x80}, encoded...)
+	}
+	return encoded
+}
@@ -1,6 +1,8 @@
 package formatter
 
 import (
+	"fmt"
+
 	"github.com/Legit-Labs/legitify/internal/analyzers"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 )
@@ -15,12 +17,14 @@ type tableFormatter interface {
 type tableContent struct {
 	tf        tableFormatter
 	colorizer colorizer
+	options   Options
 }
 
-func newTableContent(tf tableFormatter, colorizer colorizer) *tableContent {
+func newTableContent(tf tableFormatter, colorizer colorizer, options Options) *tableContent {
 	return &tableContent{
 		tf:        tf,
 		colorizer: colorizer,
+		options:   options,
 	}
 }
 
@@ -51,13 +55,16 @@ func (tc *tableContent) FormatSummary(output *scheme.Flattened) []byte {
 		severity := tc.colorizer.colorize(severityToThemeColor(policyInfo.Severity), policyInfo.Severity)
 		namespace := policyInfo.Namespace
 
-		var passed, failed, skipped int
+		var passed, failed, baselined, skipped int
 		for _, violation := range data.Violations {
 			switch violation.Status {
 			case analyzers.PolicyPassed:
 				passed++
 			case analyzers.PolicyFailed:
 				failed++
+				if violation.Baselined {
+					baselined++
+				}
 			case analyzers.PolicySkipped:
 				skipped++
 			}
@@ -65,10 +72,27 @@ func (tc *tableContent) FormatSummary(output *scheme.Flattened) []byte {
 
 		passedStr := tc.countColorize(passed, themeColorSuccess)
 		failedStr := tc.countColorize(failed, themeColorFailure)
+		if baselined > 0 {
+			failedStr += fmt.Sprintf(" (%d baselined)", baselined)
+		}
 		skippedStr := tc.countColorize(skipped, themeColorInteresting)
 
 		tc.tf.WriteRow([]string{rowNum, namespace, title, severity, passedStr, failedStr, skippedStr})
 	}
 
+	if tc.options.PassedPolicies > 0 {
+		tc.tf.WriteRow([]string{"", "", passedPoliciesNote(tc.options.PassedPolicies), "", "", "", ""})
+	}
+
+	return tc.tf.Render()
+}
+
+// FormatMetadata renders the scan metadata as a table of labeled values
+func (tc *tableContent) FormatMetadata(m *scheme.Metadata) []byte {
+	tc.tf.SetTitle(tc.colorizer.colorize(themeColorBold, "Scan Metadata"))
+	tc.tf.SetHeaders([]string{"Field", "Value"})
+	for _, field := range m.Fields() {
+		tc.tf.WriteRow([]string{field[0], field[1]})
+	}
 	return tc.tf.Render()
 }
@@ -0,0 +1,106 @@
+package manifest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// version 1 manifests only signed the digest of the report, leaving the rest of the manifest unsigned
+	currentVersion = 2
+	algorithm      = "HMAC-SHA256"
+)
+
+// Manifest attests the content of an output report.
+// It holds a SHA-256 digest of the report and an HMAC of the manifest (digest included) using a key shared with the
+// auditors, so that they can verify neither the report nor its manifest were altered after they were generated.
+type Manifest struct {
+	Version   int       `json:"version"`
+	File      string    `json:"file"`
+	Size      int       `json:"size"`
+	SHA256    string    `json:"sha256"`
+	Algorithm string    `json:"algorithm"`
+	Signature string    `json:"signature"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func New(file string, content []byte, key []byte) (*Manifest, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("missing manifest key")
+	}
+
+	digest := sha256.Sum256(content)
+
+	m := &Manifest{
+		Version:   currentVersion,
+		File:      file,
+		Size:      len(content),
+		SHA256:    hex.EncodeToString(digest[:]),
+		Algorithm: algorithm,
+		CreatedAt: time.Now().UTC(),
+	}
+	signature, err := m.sign(key)
+	if err != nil {
+		return nil, err
+	}
+	m.Signature = signature
+
+	return m, nil
+}
+
+func Unmarshal(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	return &m, nil
+}
+
+func (m *Manifest) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Verify checks that content is the report attested by the manifest and that the manifest was signed using key.
+func (m *Manifest) Verify(content []byte, key []byte) error {
+	if m.Version != currentVersion {
+		return fmt.Errorf("unsupported manifest version: %d", m.Version)
+	}
+	if m.Algorithm != algorithm {
+		return fmt.Errorf("unsupported manifest algorithm: %s", m.Algorithm)
+	}
+
+	signature, err := m.sign(key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(signature), []byte(m.Signature)) {
+		return fmt.Errorf("manifest signature is invalid")
+	}
+
+	digest := sha256.Sum256(content)
+	if hex.EncodeToString(digest[:]) != m.SHA256 || len(content) != m.Size {
+		return fmt.Errorf("report does not match the manifest")
+	}
+
+	return nil
+}
+
+// sign returns the HMAC of the canonical form of the manifest: its json encoding (in the order of the fields)
+// without the signature
+func (m *Manifest) sign(key []byte) (string, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	canonical, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
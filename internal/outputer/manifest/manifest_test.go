@@ -0,0 +1,49 @@
+package manifest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/outputer/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestVerify(t *testing.T) {
+	report := []byte(`{"policy": "content"}`)
+	key := []byte("secret")
+
+	m, err := manifest.New("report.json", report, key)
+	require.Nilf(t, err, "failed to create manifest: %v", err)
+
+	data, err := m.Marshal()
+	require.Nilf(t, err, "failed to marshal manifest: %v", err)
+	parsed, err := manifest.Unmarshal(data)
+	require.Nilf(t, err, "failed to unmarshal manifest: %v", err)
+
+	require.Nil(t, parsed.Verify(report, key), "expected a valid manifest")
+	require.NotNil(t, parsed.Verify([]byte(`{"policy": "altered"}`), key), "expected altered report to fail verification")
+	require.NotNil(t, parsed.Verify(report, []byte("other")), "expected wrong key to fail verification")
+}
+
+func TestManifestVerifyAlteredManifest(t *testing.T) {
+	report := []byte(`{"policy": "content"}`)
+	key := []byte("secret")
+
+	alterations := map[string]func(m *manifest.Manifest){
+		"file":       func(m *manifest.Manifest) { m.File = "other.json" },
+		"size":       func(m *manifest.Manifest) { m.Size++ },
+		"created at": func(m *manifest.Manifest) { m.CreatedAt = m.CreatedAt.Add(-time.Hour) },
+		"version":    func(m *manifest.Manifest) { m.Version = 1 },
+	}
+	for name, alter := range alterations {
+		m, err := manifest.New("report.json", report, key)
+		require.Nilf(t, err, "failed to create manifest: %v", err)
+		alter(m)
+		require.NotNilf(t, m.Verify(report, key), "expected a manifest with an altered %s to fail verification", name)
+	}
+}
+
+func TestManifestMissingKey(t *testing.T) {
+	_, err := manifest.New("report.json", []byte("report"), nil)
+	require.NotNil(t, err, "expected an error for a missing key")
+}
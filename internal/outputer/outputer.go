@@ -2,35 +2,61 @@ package outputer
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"log"
+	"time"
 
+	"github.com/Legit-Labs/legitify/internal/collected"
 	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
 	"github.com/Legit-Labs/legitify/internal/common/map_utils"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
 	"github.com/Legit-Labs/legitify/internal/enricher"
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme/converter"
+	"github.com/Legit-Labs/legitify/internal/outputer/spill"
 )
 
 type Outputer interface {
 	Digest(inputChannel <-chan enricher.EnrichedData) group_waiter.Waitable
 	Output(writer io.Writer) error
+	// Failures counts the failed violations of the digested results by whether they are in the baseline
+	Failures() FailureSummary
 }
 
-func NewOutputer(ctx context.Context, format formatter.FormatName, schemeType scheme.SchemeType, failedOnly bool) Outputer {
+// Sink is an additional destination for the results (e.g. a webhook), which receives them after the output is written.
+// The results may not fit in memory (see spill.Store), so sinks should read them incrementally.
+type Sink interface {
+	Send(ctx context.Context, violations scheme.ViolationsSource) error
+}
+
+func NewOutputer(ctx context.Context, format formatter.FormatName, schemeType scheme.SchemeType, failedOnly bool, options formatter.Options, sinks ...Sink) Outputer {
 	return &outputer{
+		ctx:        ctx,
 		format:     format,
 		schemeType: schemeType,
 		failedOnly: failedOnly,
+		options:    options,
+		sinks:      sinks,
 	}
 }
 
 // -----------------------------------------------------------------------------
 
 type outputer struct {
+	ctx        context.Context
 	format     formatter.FormatName
 	schemeType scheme.SchemeType
 	failedOnly bool
+	options    formatter.Options
+	sinks      []Sink
+	passed     int
+	failures   FailureSummary
+	sorted     *scheme.Flattened
+	store      *spill.Store
 	output     []byte
 	err        error
 }
@@ -45,56 +71,188 @@ func enrichedDataToPolicyInfo(enrichedData enricher.EnrichedData) scheme.PolicyI
 		Threat:                   enrichedData.Threat,
 		RemediationSteps:         enrichedData.RemediationSteps,
 		Namespace:                enrichedData.Namespace,
+		Category:                 enrichedData.Category,
 	}
 }
 
 func enrichedDataToViolation(enrichedData enricher.EnrichedData) scheme.Violation {
-	return scheme.Violation{
+	violation := scheme.Violation{
 		CanonicalLink:       enrichedData.CanonicalLink,
+		EntityID:            enrichedData.Entity.EntityID(),
 		ViolationEntityType: enrichedData.Entity.ViolationEntityType(),
 		Aux:                 map_utils.ToKeySortedMap(enrichedData.Enrichers),
 		Status:              enrichedData.Status,
+		Provider:            enrichedData.ScmType,
+		Owners:              ownersOf(enrichedData.Entity),
+	}
+	violation.Fingerprint = scheme.Fingerprint(enrichedData.FullyQualifiedPolicyName, violation)
+	return violation
+}
+
+func ownersOf(entity collected.Entity) []string {
+	if owned, ok := entity.(collected.OwnedEntity); ok {
+		return owned.Owners()
+	}
+	return nil
+}
+
+// publicPolicySuffix qualifies the results of a policy on public entities when their severity is raised,
+// since a policy has a single severity.
+const publicPolicySuffix = "@public"
+
+func isPublicEntity(enrichedData enricher.EnrichedData) bool {
+	entity, ok := enrichedData.Entity.(collected.PublicEntity)
+	return ok && entity.IsPublic()
+}
+
+// policyOf returns the name and info of the policy the violation is reported under
+func policyOf(enrichedData enricher.EnrichedData, publicSeverityBump int, catalog i18n.Catalog) (string, scheme.PolicyInfo) {
+	policyName := enrichedData.FullyQualifiedPolicyName
+	policyInfo := enrichedDataToPolicyInfo(enrichedData).Localized(catalog)
+
+	if publicSeverityBump > 0 && isPublicEntity(enrichedData) {
+		policyName += publicPolicySuffix
+		policyInfo.Title += " (Public)"
+		policyInfo.Severity = severity.Raise(policyInfo.Severity, publicSeverityBump)
 	}
+
+	return policyName, policyInfo
 }
 
 func (o *outputer) receiveViolations(inputChannel <-chan enricher.EnrichedData) *scheme.Flattened {
 	violations := scheme.NewFlattenedScheme()
 	asMap := violations.AsOrderedMap()
+	publicSeverityBump := context_utils.GetPublicSeverityBump(o.ctx)
+	profile := scheme.GetProfile(context_utils.GetOutputProfile(o.ctx))
+	catalog := context_utils.GetMessageCatalog(o.ctx)
+	baseline := context_utils.GetBaseline(o.ctx)
 
 	for encrichedData := range inputChannel {
-		policyName := encrichedData.FullyQualifiedPolicyName
+		policyName, policyInfo := policyOf(encrichedData, publicSeverityBump, catalog)
+		if !profile.KeepsPolicy(policyInfo) {
+			continue
+		}
 
 		if _, ok := asMap.Get(policyName); !ok {
-			asMap.Set(policyName, scheme.NewOutputData(enrichedDataToPolicyInfo(encrichedData)))
+			asMap.Set(policyName, scheme.NewOutputData(policyInfo))
 		}
 		preAppend := violations.GetPolicyData(policyName)
 
-		violation := enrichedDataToViolation(encrichedData)
+		violation := o.failures.withBaseline(profile.Violation(enrichedDataToViolation(encrichedData)), baseline)
 		asMap.Set(policyName, scheme.AppendViolations(preAppend, violation))
 	}
+	o.recordScanEnd()
 
 	return violations
 }
 
+// receiveViolationsWithBudget keeps the violations in a store that spills them to disk
+// once they exceed the memory budget.
+func (o *outputer) receiveViolationsWithBudget(inputChannel <-chan enricher.EnrichedData, budget int64) (*spill.Store, error) {
+	store := spill.NewStore(budget)
+	publicSeverityBump := context_utils.GetPublicSeverityBump(o.ctx)
+	profile := scheme.GetProfile(context_utils.GetOutputProfile(o.ctx))
+	catalog := context_utils.GetMessageCatalog(o.ctx)
+	baseline := context_utils.GetBaseline(o.ctx)
+
+	var err error
+	for encrichedData := range inputChannel {
+		if err != nil {
+			continue // drain the channel to avoid blocking the pipeline
+		}
+		policyName, policyInfo := policyOf(encrichedData, publicSeverityBump, catalog)
+		if !profile.KeepsPolicy(policyInfo) {
+			continue
+		}
+		err = store.Add(policyName, policyInfo, o.failures.withBaseline(profile.Violation(enrichedDataToViolation(encrichedData)), baseline))
+	}
+	o.recordScanEnd()
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (o *outputer) formatViolations(violations *scheme.Flattened) {
+	if context_utils.GetOnlyFailures(o.ctx) {
+		violations, o.passed = violations.OnlyFailedPolicies()
+	}
+	sorted := violations.SortedBySeverity()
+
+	if o.failedOnly {
+		sorted = sorted.OnlyFailedViolations()
+	}
+	o.sorted = sorted
+
+	converted, err := converter.Convert(o.schemeType, sorted)
+	if err != nil {
+		o.err = err
+		return
+	}
+
+	o.output, o.err = formatter.Format(o.format, o.formatOptions(), converted, o.failedOnly)
+}
+
+// recordScanEnd records the end time of the scan (the time all the results were received) in its metadata
+func (o *outputer) recordScanEnd() {
+	if o.options.Metadata != nil {
+		end := time.Now()
+		o.options.Metadata.ScanEnd = &end
+	}
+}
+
+// formatOptions adds the number of the passed policies that were left out (--only-failures) to the options of the output
+func (o *outputer) formatOptions() formatter.Options {
+	options := o.options
+	options.PassedPolicies = o.passed
+	return options
+}
+
+func (o *outputer) digestWithBudget(inputChannel <-chan enricher.EnrichedData, budget int64) {
+	store, err := o.receiveViolationsWithBudget(inputChannel, budget)
+	if err != nil {
+		o.err = err
+		return
+	}
+
+	if store.Spilled() && o.schemeType == scheme.TypeFlattened && formatter.SupportsStreaming(o.format) {
+		if context_utils.GetOnlyFailures(o.ctx) {
+			o.passed = store.OnlyFailedPolicies()
+		}
+		o.store = store // formatted while writing the output
+		return
+	}
+	defer store.Close()
+
+	if store.Spilled() {
+		log.Printf("the results exceed the memory budget, but the %s format with the %s scheme does not support streaming. formatting in memory",
+			o.format, o.schemeType)
+	}
+	violations, err := store.Flattened()
+	if err != nil {
+		o.err = err
+		return
+	}
+	o.formatViolations(violations)
+}
+
 func (o *outputer) Digest(inputChannel <-chan enricher.EnrichedData) group_waiter.Waitable {
 	gw := group_waiter.New()
 
 	gw.Do(func() {
 		o.err = nil // zero err to allow reuse of the object
-		violations := o.receiveViolations(inputChannel)
-		sorted := violations.SortedBySeverity()
-
-		if o.failedOnly {
-			sorted = sorted.OnlyFailedViolations()
-		}
+		o.store = nil
+		o.passed = 0
+		o.failures = FailureSummary{}
 
-		converted, err := converter.Convert(o.schemeType, sorted)
-		if err != nil {
-			o.err = err
+		if budget := context_utils.GetMemoryBudget(o.ctx); budget > 0 {
+			o.digestWithBudget(inputChannel, budget)
 			return
 		}
 
-		o.output, o.err = formatter.Format(o.format, formatter.DefaultOutputIndent, converted, o.failedOnly)
+		o.formatViolations(o.receiveViolations(inputChannel))
 	})
 
 	return gw
@@ -105,10 +263,42 @@ func (o *outputer) Output(writer io.Writer) error {
 		return o.err
 	}
 
+	if o.store != nil {
+		return o.outputStream(writer)
+	}
+
 	_, err := writer.Write(o.output)
 	if err != nil {
 		return err
 	}
 
+	return o.sendToSinks(o.sorted)
+}
+
+func (o *outputer) Failures() FailureSummary {
+	return o.failures
+}
+
+func (o *outputer) outputStream(writer io.Writer) error {
+	defer o.store.Close()
+
+	if err := formatter.FormatStream(o.format, o.formatOptions(), o.store, o.failedOnly, writer); err != nil {
+		return err
+	}
+
+	var source scheme.ViolationsSource = o.store
+	if o.failedOnly {
+		source = scheme.OnlyFailedViolations(source)
+	}
+	return o.sendToSinks(source)
+}
+
+func (o *outputer) sendToSinks(source scheme.ViolationsSource) error {
+	for _, sink := range o.sinks {
+		if err := sink.Send(o.ctx, source); err != nil {
+			return fmt.Errorf("failed to send results: %v", err)
+		}
+	}
+
 	return nil
 }
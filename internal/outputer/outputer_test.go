@@ -1,11 +1,28 @@
 package outputer
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
 	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/enricher/enrichers"
 	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
@@ -26,7 +43,7 @@ func TestOutputer(t *testing.T) {
 	data := scheme_test.EnrichedDataSample()
 
 	inputChannel := make(chan enricher.EnrichedData, len(data))
-	outputer := NewOutputer(context.Background(), formatter.Json, scheme.TypeFlattened, false)
+	outputer := NewOutputer(context.Background(), formatter.Json, scheme.TypeFlattened, false, formatter.Options{})
 
 	// Setup a channel to get the output from the Writer mock
 	resultChannel := make(chan []byte, 1)
@@ -57,3 +74,479 @@ func TestOutputer(t *testing.T) {
 	require.Nilf(t, err, "Error deserializing json: %v", err)
 	require.NotEmptyf(t, reversed, "Error deserializing json: %v", err)
 }
+
+func TestOutputerPublicSeverityBump(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	ctx := context_utils.NewContextWithPublicSeverityBump(context.Background(), 1)
+	outputer := NewOutputer(ctx, formatter.Json, scheme.TypeFlattened, false, formatter.Options{})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+
+	parsed, err := scheme.Unmarshal(buf.Bytes())
+	require.Nil(t, err)
+
+	// the organization policy is not affected
+	orgPolicy := parsed.GetPolicyData(scheme_test.FullyQualifiedPolicyNameSample())
+	require.Equal(t, severity.Low, orgPolicy.PolicyInfo.Severity)
+
+	// the repository of the second policy is public
+	_, ok := parsed.AsOrderedMap().Get(scheme_test.FullyQualifiedPolicyNameSample2())
+	require.False(t, ok, "expecting the violations to move to the public entry")
+	repoPolicy := parsed.GetPolicyData(scheme_test.FullyQualifiedPolicyNameSample2() + publicPolicySuffix)
+	require.Equal(t, severity.Critical, repoPolicy.PolicyInfo.Severity)
+	require.Len(t, repoPolicy.Violations, 2)
+}
+
+func TestOutputerEntityID(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	outputer := NewOutputer(context.Background(), formatter.Json, scheme.TypeFlattened, false, formatter.Options{})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+
+	var parsed scheme.TypedScheme[map[string]struct {
+		Violations []struct {
+			CanonicalLink string `json:"canonicalLink"`
+			EntityID      string `json:"entityId"`
+		} `json:"violations"`
+	}]
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &parsed))
+
+	expected := map[string]string{
+		scheme_test.FullyQualifiedPolicyNameSample():  "github:organization:666",
+		scheme_test.FullyQualifiedPolicyNameSample2(): "github:repository:667",
+	}
+	for policyName, entityID := range expected {
+		violations := parsed.Content[policyName].Violations
+		require.Len(t, violations, 2)
+		// the same entity is referenced by different links
+		require.NotEqual(t, violations[0].CanonicalLink, violations[1].CanonicalLink)
+		for _, violation := range violations {
+			require.Equal(t, entityID, violation.EntityID)
+		}
+	}
+}
+
+func TestOutputerFingerprint(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	outputer := NewOutputer(context.Background(), formatter.Json, scheme.TypeFlattened, false, formatter.Options{})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+
+	var parsed scheme.TypedScheme[map[string]struct {
+		Violations []struct {
+			Fingerprint string `json:"fingerprint"`
+		} `json:"violations"`
+	}]
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &parsed))
+
+	policy1 := parsed.Content[scheme_test.FullyQualifiedPolicyNameSample()].Violations
+	policy2 := parsed.Content[scheme_test.FullyQualifiedPolicyNameSample2()].Violations
+	require.Len(t, policy1, 2)
+	require.Len(t, policy2, 2)
+	// the fingerprint does not depend on the link of the entity
+	require.NotEmpty(t, policy1[0].Fingerprint)
+	require.Equal(t, policy1[0].Fingerprint, policy1[1].Fingerprint)
+	require.Equal(t, policy2[0].Fingerprint, policy2[1].Fingerprint)
+	require.NotEqual(t, policy1[0].Fingerprint, policy2[0].Fingerprint)
+}
+
+func TestOutputerBaseline(t *testing.T) {
+	var failures FailureSummary
+	digest := func(ctx context.Context) []byte {
+		data := scheme_test.EnrichedDataSample()
+		inputChannel := make(chan enricher.EnrichedData, len(data))
+		for _, d := range data {
+			inputChannel <- d
+		}
+		close(inputChannel)
+
+		outputer := NewOutputer(ctx, formatter.Json, scheme.TypeFlattened, false, formatter.Options{})
+		outputer.Digest(inputChannel).Wait()
+		var buf bytes.Buffer
+		require.Nil(t, outputer.Output(&buf))
+		failures = outputer.Failures()
+		return buf.Bytes()
+	}
+
+	// a baseline of the first policy only (the violations of each policy share a fingerprint)
+	baseline, err := scheme.ParseBaseline(digest(context.Background()))
+	require.Nil(t, err)
+	require.Len(t, baseline, 2)
+	var parsed scheme.TypedScheme[map[string]struct {
+		Violations []scheme.Violation `json:"violations"`
+	}]
+	require.Nil(t, json.Unmarshal(digest(context.Background()), &parsed))
+	delete(baseline, parsed.Content[scheme_test.FullyQualifiedPolicyNameSample2()].Violations[0].Fingerprint)
+
+	require.Equal(t, FailureSummary{New: 4}, failures, "expecting all the failures to be new without a baseline")
+	require.Nil(t, json.Unmarshal(digest(context_utils.NewContextWithBaseline(context.Background(), baseline)), &parsed))
+
+	for _, violation := range parsed.Content[scheme_test.FullyQualifiedPolicyNameSample()].Violations {
+		require.True(t, violation.Baselined)
+	}
+	for _, violation := range parsed.Content[scheme_test.FullyQualifiedPolicyNameSample2()].Violations {
+		require.False(t, violation.Baselined)
+	}
+	require.Equal(t, FailureSummary{New: 2, Baselined: 2}, failures)
+}
+
+func TestOutputerLocalization(t *testing.T) {
+	messagesPath := t.TempDir()
+	catalogData := `
+policy1:
+  title: Mi política
+  remediationSteps: [haz, eso]
+organization.policy2:
+  title: not the repository policy
+`
+	require.Nil(t, os.WriteFile(filepath.Join(messagesPath, "es.yaml"), []byte(catalogData), 0o600))
+	catalog, err := i18n.Load("es", messagesPath)
+	require.Nil(t, err)
+
+	data := scheme_test.EnrichedDataSample()
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	ctx := context_utils.NewContextWithMessageCatalog(context.Background(), catalog)
+	outputer := NewOutputer(ctx, formatter.Json, scheme.TypeFlattened, false, formatter.Options{})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+
+	var parsed scheme.TypedScheme[map[string]struct {
+		PolicyInfo scheme.PolicyInfo `json:"policyInfo"`
+	}]
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &parsed))
+
+	localized := parsed.Content[scheme_test.FullyQualifiedPolicyNameSample()].PolicyInfo
+	require.Equal(t, "Mi política", localized.Title)
+	require.Equal(t, []string{"haz", "eso"}, localized.RemediationSteps)
+	// texts missing from the catalog fall back to english
+	require.Equal(t, "This is an example policy that checks for a specific pattern in a file", localized.Description)
+
+	// the catalog entry is qualified by another namespace
+	other := parsed.Content[scheme_test.FullyQualifiedPolicyNameSample2()].PolicyInfo
+	require.Equal(t, "My other policy", other.Title)
+
+	_, err = i18n.Load("fr", messagesPath)
+	require.NotNil(t, err, "expecting an error for a language without a catalog")
+}
+
+func TestOutputerOnlyFailures(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+	skipped := data[0]
+	skipped.FullyQualifiedPolicyName += "_skipped"
+	skipped.Title = "Skipped policy"
+	skipped.Status = analyzers.PolicySkipped
+	data = append(data, skipped)
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	var passedTitle string
+	for _, d := range data {
+		if d.FullyQualifiedPolicyName == scheme_test.FullyQualifiedPolicyNameSample2() {
+			d.Status = analyzers.PolicyPassed
+			passedTitle = d.Title
+		}
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	ctx := context_utils.NewContextWithOnlyFailures(context.Background(), true)
+	outputer := NewOutputer(ctx, formatter.Csv, scheme.TypeFlattened, false, formatter.Options{})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+
+	output := buf.String()
+	require.NotEmpty(t, passedTitle)
+	require.NotContains(t, output, passedTitle, "expecting the passed policy to be omitted")
+	require.NotContains(t, output, skipped.Title, "expecting the skipped policy to be omitted")
+	require.Contains(t, output, "1 passed policies omitted", "expecting only the passed policy to be counted")
+}
+
+func TestOutputerScanEnd(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	start := time.Now()
+	metadata := &scheme.Metadata{Tool: "legitify", ScanStart: &start}
+	outputer := NewOutputer(context.Background(), formatter.Json, scheme.TypeFlattened, false, formatter.Options{Metadata: metadata})
+	outputer.Digest(inputChannel).Wait()
+
+	require.NotNil(t, metadata.ScanEnd, "expecting the scan end to be recorded once all the results were received")
+	require.False(t, metadata.ScanEnd.Before(start))
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+	parsed, err := scheme.UnmarshalMetadata(buf.Bytes())
+	require.Nil(t, err)
+	require.True(t, metadata.ScanEnd.Equal(*parsed.ScanEnd))
+}
+
+func TestOutputerPublicProfile(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	ctx := context_utils.NewContextWithOutputProfile(context.Background(), scheme.ProfilePublic)
+	outputer := NewOutputer(ctx, formatter.Json, scheme.TypeFlattened, true, formatter.Options{})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+
+	var parsed scheme.TypedScheme[map[string]struct {
+		Violations []struct {
+			Aux map[string]interface{} `json:"aux"`
+		} `json:"violations"`
+	}]
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &parsed))
+
+	// the low severity policy is below the severity floor of the profile
+	_, ok := parsed.Content[scheme_test.FullyQualifiedPolicyNameSample()]
+	require.False(t, ok, "expecting the low severity policy to be dropped")
+
+	repoPolicy, ok := parsed.Content[scheme_test.FullyQualifiedPolicyNameSample2()]
+	require.True(t, ok, "expecting the high severity policy to be kept")
+	require.NotEmpty(t, repoPolicy.Violations)
+	for _, violation := range repoPolicy.Violations {
+		require.Empty(t, violation.Aux, "expecting the aux to be dropped")
+	}
+}
+
+func formatSample(t *testing.T, data []enricher.EnrichedData, schemeType scheme.SchemeType, jsonIndent string) []byte {
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	for _, d := range data {
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	outputer := NewOutputer(context.Background(), formatter.Json, schemeType, false, formatter.Options{JsonIndent: jsonIndent})
+	outputer.Digest(inputChannel).Wait()
+
+	var buf bytes.Buffer
+	require.Nil(t, outputer.Output(&buf))
+	return buf.Bytes()
+}
+
+func TestOutputerDeterministic(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+	// another violation of the same entity, which differs only by its aux
+	sameEntity := data[0]
+	sameEntity.Enrichers = nil
+	data = append(data, sameEntity)
+
+	reversed := make([]enricher.EnrichedData, len(data))
+	for i, d := range data {
+		reversed[len(data)-1-i] = d
+	}
+
+	for _, indent := range []string{formatter.DefaultOutputIndent, ""} {
+		for _, schemeType := range scheme.SchemeTypes() {
+			expected := formatSample(t, data, schemeType, indent)
+			require.Equalf(t, expected, formatSample(t, data, schemeType, indent), "expecting identical output for identical input (%s)", schemeType)
+			require.Equalf(t, expected, formatSample(t, reversed, schemeType, indent), "expecting the output not to depend on the input order (%s)", schemeType)
+		}
+	}
+
+	require.Contains(t, string(formatSample(t, data, scheme.TypeFlattened, formatter.DefaultOutputIndent)), "\n"+formatter.DefaultOutputIndent+`"type"`)
+	// compact output is a single line
+	require.NotContains(t, string(formatSample(t, data, scheme.TypeFlattened, "")), "\n")
+}
+
+func TestStream(t *testing.T) {
+	data := scheme_test.EnrichedDataSample()
+
+	inputChannel := make(chan enricher.EnrichedData, len(data))
+	failed := 0
+	for i, d := range data {
+		if i == 0 {
+			d.Status = analyzers.PolicyPassed
+		} else if d.Status == analyzers.PolicyFailed {
+			failed++
+		}
+		inputChannel <- d
+	}
+	close(inputChannel)
+
+	var buf bytes.Buffer
+	require.Nil(t, Stream(context.Background(), inputChannel, &buf, true))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, failed, "expecting a line per failed result")
+	for _, line := range lines {
+		var record StreamRecord
+		require.Nil(t, json.Unmarshal(line, &record))
+		require.Equal(t, analyzers.PolicyFailed, record.Violation.Status)
+		require.NotEmpty(t, record.PolicyInfo.PolicyName)
+	}
+}
+
+// blockingWriter blocks the writes until it is released
+type blockingWriter struct {
+	released chan struct{}
+	lock     sync.Mutex
+	buf      bytes.Buffer
+}
+
+func (w *blockingWriter) Write(data []byte) (int, error) {
+	<-w.released
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.Write(data)
+}
+
+func TestStreamOutputerBackpressure(t *testing.T) {
+	sample := scheme_test.EnrichedDataSample()[0]
+	const results = 20
+	const bufferSize = 2
+
+	writer := &blockingWriter{released: make(chan struct{})}
+	out := NewStreamOutputer(context.Background(), writer, false, bufferSize)
+
+	inputChannel := make(chan enricher.EnrichedData)
+	waiter := out.Digest(inputChannel)
+
+	var sent int32
+	go func() {
+		defer close(inputChannel)
+		for i := 0; i < results; i++ {
+			inputChannel <- sample
+			atomic.AddInt32(&sent, 1)
+		}
+	}()
+
+	// the pipeline is blocked once the buffer is full: a result is being written,
+	// the buffer is full and another result is held until there is room in the buffer
+	time.Sleep(100 * time.Millisecond)
+	require.LessOrEqual(t, int(atomic.LoadInt32(&sent)), bufferSize+2)
+
+	close(writer.released)
+	waiter.Wait()
+	require.Nil(t, out.Output(nil))
+	require.Equal(t, results, int(atomic.LoadInt32(&sent)))
+
+	lines := bytes.Split(bytes.TrimSpace(writer.buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, results, "expecting a line per result")
+}
+
+// sinkMock reads the results it receives, and measures the heap retained while it reads them
+type sinkMock struct {
+	source     scheme.ViolationsSource
+	violations int
+	heap       uint64
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func (m *sinkMock) Send(ctx context.Context, violations scheme.ViolationsSource) error {
+	m.source = violations
+	m.heap = heapInUse()
+	for _, policyName := range violations.Policies() {
+		err := violations.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			m.violations++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestOutputerSpillWithSink(t *testing.T) {
+	const results = 4000
+	sample := scheme_test.EnrichedDataSample()[0]
+	sample.Enrichers = map[string]enrichers.Enrichment{
+		"large": enrichers.NewBasicEnrichment(strings.Repeat("x", 1024)),
+	}
+
+	inputChannel := make(chan enricher.EnrichedData)
+	go func() {
+		defer close(inputChannel)
+		for i := 0; i < results; i++ {
+			inputChannel <- sample
+		}
+	}()
+
+	sink := &sinkMock{}
+	ctx := context_utils.NewContextWithMemoryBudget(context.Background(), 64*1024)
+	outputer := NewOutputer(ctx, formatter.Json, scheme.TypeFlattened, false, formatter.Options{}, sink)
+	outputer.Digest(inputChannel).Wait()
+
+	before := heapInUse()
+	require.Nil(t, outputer.Output(io.Discard))
+
+	require.Equal(t, results, sink.violations)
+	_, inMemory := sink.source.(*scheme.Flattened)
+	require.False(t, inMemory, "expecting the sink to read the spilled results")
+	// the results take over 4MB in memory
+	require.Less(t, int64(sink.heap)-int64(before), int64(1<<20), "expecting the results not to be loaded to memory")
+}
+
+func TestProfileRedactsIdentities(t *testing.T) {
+	profile := scheme.GetProfile(scheme.ProfilePublic)
+	member := scheme.Violation{
+		ViolationEntityType: namespace.Member,
+		CanonicalLink:       "https://github.com/octocat",
+		EntityID:            "octocat",
+	}
+
+	redacted := profile.Violation(member)
+	require.NotContains(t, redacted.CanonicalLink, "octocat")
+	require.NotContains(t, redacted.EntityID, "octocat")
+	require.Equal(t, redacted, profile.Violation(member), "expecting the same pseudonym within the run")
+
+	hash := sha256.Sum256([]byte(member.CanonicalLink))
+	require.NotContains(t, redacted.CanonicalLink, hex.EncodeToString(hash[:])[:12], "expecting a keyed pseudonym")
+
+	other := member
+	other.CanonicalLink = "https://github.com/hubot"
+	require.NotEqual(t, redacted.CanonicalLink, profile.Violation(other).CanonicalLink)
+}
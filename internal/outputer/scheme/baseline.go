@@ -0,0 +1,44 @@
+package scheme
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+)
+
+// Baseline is the set of the fingerprints of the accepted violations (e.g. the existing backlog), see Fingerprint
+type Baseline map[string]bool
+
+// ParseBaseline reads the fingerprints of the failed violations of a previous json output.
+// Any output scheme is accepted, since the violations are looked up wherever they are nested.
+func ParseBaseline(data []byte) (Baseline, error) {
+	var parsed interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse the baseline (expecting a json output of a previous scan): %v", err)
+	}
+
+	baseline := make(Baseline)
+	collectFingerprints(parsed, baseline)
+	return baseline, nil
+}
+
+func collectFingerprints(node interface{}, baseline Baseline) {
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		if fingerprint, ok := typed["fingerprint"].(string); ok && fingerprint != "" {
+			// a baseline lists failed violations, but a full output includes the passed and skipped ones as well
+			if status, hasStatus := typed["status"].(string); !hasStatus || status == analyzers.PolicyFailed {
+				baseline[fingerprint] = true
+			}
+			return
+		}
+		for _, value := range typed {
+			collectFingerprints(value, baseline)
+		}
+	case []interface{}:
+		for _, value := range typed {
+			collectFingerprints(value, baseline)
+		}
+	}
+}
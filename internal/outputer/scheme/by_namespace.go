@@ -31,11 +31,23 @@ func policiesSortByNamespaceLess(i, j *orderedmap.Pair) bool {
 		namespace.RunnerGroup:  4,
 	}
 
-	iNamespace := i.Value().(OutputData).PolicyInfo.Namespace
-	jNamespace := j.Value().(OutputData).PolicyInfo.Namespace
+	// the categories (e.g. secrets) are listed after the namespaces
+	const categoryOrder = 5
+	order := func(info PolicyInfo) int {
+		if info.Category != "" {
+			return categoryOrder
+		}
+		return namespaceOrder[info.Namespace]
+	}
+
+	iInfo := i.Value().(OutputData).PolicyInfo
+	jInfo := j.Value().(OutputData).PolicyInfo
 
-	if iNamespace != jNamespace {
-		return namespaceOrder[iNamespace] < namespaceOrder[jNamespace]
+	if iInfo.Section() != jInfo.Section() {
+		if iInfo.Category != "" && jInfo.Category != "" {
+			return iInfo.Category < jInfo.Category
+		}
+		return order(iInfo) < order(jInfo)
 	}
 
 	return policiesSortBySeverityLess(i, j)
@@ -12,7 +12,7 @@ type byNamespaceConverter struct {
 }
 
 func (*byNamespaceConverter) Element(policyInfo scheme.PolicyInfo, violation scheme.Violation) string {
-	return policyInfo.Namespace
+	return policyInfo.Section()
 }
 func (*byNamespaceConverter) NewScheme() groupingScheme {
 	return scheme.NewByNamespace()
@@ -3,6 +3,8 @@ package converter_test
 import (
 	"testing"
 
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme/converter"
 	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
@@ -40,3 +42,28 @@ func TestByNamespaceConverter(t *testing.T) {
 	require.Equalf(t, sample, reversed, "Expecting the same result for both directions: %v\n%v\n",
 		sample, reversed)
 }
+
+func TestByNamespaceConverterCategory(t *testing.T) {
+	sample := scheme.NewFlattenedScheme()
+	sample.AsOrderedMap().Set("data.repository.repository_secret_is_stale", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{PolicyName: "repository_secret_is_stale", Namespace: namespace.Repository, Category: "secrets"},
+		Violations: []scheme.Violation{{CanonicalLink: "https://github.com/org/repo", Status: analyzers.PolicyFailed}},
+	})
+	sample.AsOrderedMap().Set("data.organization.organization_secret_is_stale", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{PolicyName: "organization_secret_is_stale", Namespace: namespace.Organization, Category: "secrets"},
+		Violations: []scheme.Violation{{CanonicalLink: "https://github.com/org", Status: analyzers.PolicyFailed}},
+	})
+	sample.AsOrderedMap().Set("data.repository.code_review_not_required", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{PolicyName: "code_review_not_required", Namespace: namespace.Repository},
+		Violations: []scheme.Violation{{CanonicalLink: "https://github.com/org/repo", Status: analyzers.PolicyFailed}},
+	})
+
+	output, err := converter.Convert(scheme.TypeGroupByNamespace, sample)
+	require.Nil(t, err)
+
+	converted := output.(*scheme.ByNamespace)
+	require.ElementsMatch(t, []string{namespace.Repository, "secrets"}, converted.AsOrderedMap().Keys())
+	require.ElementsMatch(t, []string{"data.repository.repository_secret_is_stale", "data.organization.organization_secret_is_stale"},
+		converted.UnsafeGet("secrets").AsOrderedMap().Keys())
+	require.Equal(t, []string{"data.repository.code_review_not_required"}, converted.UnsafeGet(namespace.Repository).AsOrderedMap().Keys())
+}
@@ -0,0 +1,58 @@
+package scheme
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/common/map_utils"
+	"github.com/Legit-Labs/legitify/internal/enricher/enrichers"
+)
+
+// subjectKeys are the keys (by priority) of the aux list entries that identify the subject of a violation
+// (e.g. the hook or the environment). Display values such as links are left out since they may change.
+var subjectKeys = []string{"id", "name"}
+
+// Fingerprint identifies a violation regardless of how it is displayed: it is derived from the policy,
+// the node ID of the entity and the subjects of the violation, so it stays stable when the canonical link changes
+// (e.g. a repository rename). It falls back to the canonical link for entities without an ID.
+func Fingerprint(fullyQualifiedPolicyName string, violation Violation) string {
+	entity := violation.EntityID
+	if entity == "" {
+		entity = violation.CanonicalLink
+	}
+
+	parts := []string{fullyQualifiedPolicyName, entity}
+	parts = append(parts, violationSubjects(violation)...)
+	hash := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(hash[:])[:32]
+}
+
+// violationSubjects returns the normalized (sorted, lower-cased) identifiers of the aux list entries
+func violationSubjects(violation Violation) []string {
+	if violation.Aux == nil {
+		return nil
+	}
+
+	var subjects []string
+	for _, name := range violation.Aux.Keys() {
+		list, isList := map_utils.UnsafeGetUntyped(violation.Aux, name).(enrichers.GenericListEnrichment)
+		if !isList {
+			continue
+		}
+		for _, entry := range list {
+			entry := entry
+			for _, key := range subjectKeys {
+				if value, found := entry.Get(key); found {
+					if subject, isString := value.(string); isString && subject != "" {
+						subjects = append(subjects, name+"="+strings.ToLower(subject))
+						break
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(subjects)
+	return subjects
+}
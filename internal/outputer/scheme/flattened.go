@@ -61,6 +61,24 @@ func (s *Flattened) FilteredByStatus(status analyzers.PolicyStatus) *Flattened {
 	return s.FilterByViolation(filter)
 }
 
+// OnlyFailedPolicies returns the policies that have at least one failed violation (with all their violations),
+// and the number of passed policies that were left out (the policies that were only skipped are not counted).
+func (s *Flattened) OnlyFailedPolicies() (*Flattened, int) {
+	filteredScheme := NewFlattenedScheme()
+	passed := 0
+
+	for _, policyName := range s.AsOrderedMap().Keys() {
+		outputData := s.GetPolicyData(policyName)
+		if outputData.HasFailures() {
+			filteredScheme.AsOrderedMap().Set(policyName, outputData)
+		} else if outputData.HasPassed() {
+			passed++
+		}
+	}
+
+	return filteredScheme, passed
+}
+
 type ViolationFilter func(violation Violation) bool
 
 func (s *Flattened) FilterByViolation(filter ViolationFilter) *Flattened {
@@ -85,6 +103,30 @@ func (s *Flattened) FilterByViolation(filter ViolationFilter) *Flattened {
 	return filteredScheme
 }
 
+func (s *Flattened) FilteredByCanonicalLink(link string) *Flattened {
+	filter := func(violation Violation) bool {
+		return violation.CanonicalLink == link
+	}
+	return s.FilterByViolation(filter)
+}
+
+// CanonicalLinks returns the distinct canonical links of the violated entities, by order of appearance.
+func (s *Flattened) CanonicalLinks() []string {
+	seen := make(map[string]bool)
+	links := []string{}
+
+	for _, policyName := range s.AsOrderedMap().Keys() {
+		for _, violation := range s.GetPolicyData(policyName).Violations {
+			if !seen[violation.CanonicalLink] {
+				seen[violation.CanonicalLink] = true
+				links = append(links, violation.CanonicalLink)
+			}
+		}
+	}
+
+	return links
+}
+
 // UnmarshalJSON implements unmarshaling for the Flattened scheme (called by json.Unmarshal)
 // by rebuilding the scheme from the ordered maps.
 func (s *Flattened) UnmarshalJSON(data []byte) error {
@@ -107,3 +149,6 @@ func (s *Flattened) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// Unowned is the owner of the violations of entities without owners (see Violation.Owners)
+const Unowned = "unowned"
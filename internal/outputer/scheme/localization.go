@@ -0,0 +1,43 @@
+package scheme
+
+import (
+	"github.com/Legit-Labs/legitify/internal/common/i18n"
+)
+
+// Localized returns the policy info with the texts of the catalog, falling back to the original (English) texts
+func (info PolicyInfo) Localized(catalog i18n.Catalog) PolicyInfo {
+	messages, ok := catalog.Lookup(info.Namespace, info.PolicyName)
+	if !ok {
+		return info
+	}
+
+	if messages.Title != "" {
+		info.Title = messages.Title
+	}
+	if messages.Description != "" {
+		info.Description = messages.Description
+	}
+	if len(messages.Threat) > 0 {
+		info.Threat = messages.Threat
+	}
+	if len(messages.RemediationSteps) > 0 {
+		info.RemediationSteps = messages.RemediationSteps
+	}
+	return info
+}
+
+// Localized returns the results with the policies texts of the catalog
+func (s *Flattened) Localized(catalog i18n.Catalog) *Flattened {
+	if len(catalog) == 0 {
+		return s
+	}
+
+	localized := NewFlattenedScheme()
+	for _, policyName := range s.AsOrderedMap().Keys() {
+		outputData := s.GetPolicyData(policyName)
+		outputData.PolicyInfo = outputData.PolicyInfo.Localized(catalog)
+		localized.AsOrderedMap().Set(policyName, outputData)
+	}
+
+	return localized
+}
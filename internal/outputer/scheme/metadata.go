@@ -0,0 +1,110 @@
+package scheme
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Metadata describes the scan that produced the results, for the traceability of the reports
+type Metadata struct {
+	Tool      string             `json:"tool"`
+	Version   string             `json:"version"`
+	Commit    string             `json:"commit"`
+	ScanStart *time.Time         `json:"scanStart,omitempty"`
+	ScanEnd   *time.Time         `json:"scanEnd,omitempty"`
+	Providers []ProviderMetadata `json:"providers"`
+	// Policies identifies the evaluated policies: the built-in policies of the version, and a digest of the custom policies (if any)
+	Policies string `json:"policies"`
+}
+
+// ProviderMetadata is the scope of the scan in a provider (several providers are scanned with --aggregate)
+type ProviderMetadata struct {
+	Scm           string   `json:"scm"`
+	Endpoint      string   `json:"endpoint,omitempty"`
+	Organizations []string `json:"organizations,omitempty"`
+	Repositories  []string `json:"repositories,omitempty"`
+	Enterprises   []string `json:"enterprises,omitempty"`
+	Namespaces    []string `json:"namespaces"`
+	TokenScopes   []string `json:"tokenScopes,omitempty"`
+	// MaxRepositories is the number of repositories collected per organization when the scan is a sample (see --max-repos)
+	MaxRepositories int `json:"maxRepositories,omitempty"`
+	// APICalls are the totals of the API calls the scan of the provider made until all its results were received (GitHub only)
+	APICalls *APICalls `json:"apiCalls,omitempty"`
+}
+
+// APICalls counts the API calls of a scan by their type, and the retries and rate limit sleeps they required
+type APICalls struct {
+	Rest            int64 `json:"rest"`
+	GraphQL         int64 `json:"graphql"`
+	Cached          int64 `json:"cached"`
+	Retries         int64 `json:"retries"`
+	RateLimitSleeps int64 `json:"rateLimitSleeps"`
+}
+
+func (a APICalls) String() string {
+	return fmt.Sprintf("%d REST, %d GraphQL (%d served from cache), %d retries, %d rate limit sleeps",
+		a.Rest, a.GraphQL, a.Cached, a.Retries, a.RateLimitSleeps)
+}
+
+// Scope summarizes the scanned entities of the provider
+func (p ProviderMetadata) Scope() string {
+	var parts []string
+	if len(p.Organizations) > 0 {
+		parts = append(parts, "organizations: "+strings.Join(p.Organizations, ", "))
+	}
+	if len(p.Repositories) > 0 {
+		parts = append(parts, "repositories: "+strings.Join(p.Repositories, ", "))
+	}
+	if len(p.Enterprises) > 0 {
+		parts = append(parts, "enterprises: "+strings.Join(p.Enterprises, ", "))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "all accessible entities")
+	}
+	parts = append(parts, "namespaces: "+strings.Join(p.Namespaces, ", "))
+	if p.MaxRepositories > 0 {
+		parts = append(parts, fmt.Sprintf("sample: first %d repositories of each organization", p.MaxRepositories))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Fields lists the metadata as labeled values, in the order they are rendered by the textual formats
+func (m *Metadata) Fields() [][2]string {
+	fields := [][2]string{
+		{"Tool", fmt.Sprintf("%s %s (commit %s)", m.Tool, m.Version, m.Commit)},
+	}
+	if m.ScanStart != nil {
+		fields = append(fields, [2]string{"Scan Start", m.ScanStart.UTC().Format(time.RFC3339)})
+	}
+	if m.ScanEnd != nil {
+		fields = append(fields, [2]string{"Scan End", m.ScanEnd.UTC().Format(time.RFC3339)})
+	}
+	for _, provider := range m.Providers {
+		name := provider.Scm
+		if provider.Endpoint != "" {
+			name += " (" + provider.Endpoint + ")"
+		}
+		fields = append(fields, [2]string{"Provider", name}, [2]string{"Scope", provider.Scope()})
+		if len(provider.TokenScopes) > 0 {
+			fields = append(fields, [2]string{"Token Scopes", strings.Join(provider.TokenScopes, ", ")})
+		}
+		if provider.APICalls != nil {
+			fields = append(fields, [2]string{"API Calls", provider.APICalls.String()})
+		}
+	}
+	fields = append(fields, [2]string{"Policies", m.Policies})
+	return fields
+}
+
+// UnmarshalMetadata returns the metadata of a typed json, or nil if it has none (e.g. the output of older versions)
+func UnmarshalMetadata(data []byte) (*Metadata, error) {
+	var typed struct {
+		Metadata *Metadata `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil, fmt.Errorf("failed to parse input: %v", err)
+	}
+	return typed.Metadata, nil
+}
@@ -1,6 +1,8 @@
 package scheme
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/Legit-Labs/legitify/internal/analyzers"
@@ -20,17 +22,36 @@ type PolicyInfo struct {
 	Threat                   []string            `json:"threat"`
 	RemediationSteps         []string            `json:"remediationSteps"`
 	Namespace                namespace.Namespace `json:"namespace"`
+	// Category groups related policies of all namespaces (e.g. "secrets"), as declared by the policy metadata
+	Category string `json:"category,omitempty"`
+}
+
+// Section is the section of the report the policy is listed in: its category when it has one, otherwise its namespace
+func (p PolicyInfo) Section() string {
+	if p.Category != "" {
+		return p.Category
+	}
+	return p.Namespace
 }
 
 type Violation struct { // Must be exported for json marshal
 	ViolationEntityType string                 `json:"violationEntityType"`
 	CanonicalLink       string                 `json:"canonicalLink"`
+	EntityID            string                 `json:"entityId,omitempty"`
+	Fingerprint         string                 `json:"fingerprint,omitempty"`
+	Baselined           bool                   `json:"baselined,omitempty"`
 	Aux                 *orderedmap.OrderedMap `json:"aux"`
 	Status              analyzers.PolicyStatus `json:"status"`
+	Provider            string                 `json:"provider,omitempty"`
+	// Owners are responsible for the remediation of the violated entity (see collected.OwnedEntity)
+	Owners []string `json:"owners,omitempty"`
 }
 
 func newAuxFromMap(m *orderedmap.OrderedMap) (*orderedmap.OrderedMap, error) {
 	newM := orderedmap.New()
+	if m == nil {
+		return newM, nil
+	}
 	for _, name := range m.Keys() {
 		v := map_utils.UnsafeGetUntyped(m, name)
 		if v == nil {
@@ -38,6 +59,11 @@ func newAuxFromMap(m *orderedmap.OrderedMap) (*orderedmap.OrderedMap, error) {
 			continue
 		}
 		enrichment, err := enricher.NewEnricherManager().Parse(name, v)
+		if errors.Is(err, enricher.ErrUnknownEnricher) {
+			// e.g. the output of a newer version: the value is kept as is
+			newM.Set(name, v)
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to enrich %v: %v", name, err)
 		}
@@ -62,6 +88,15 @@ func newViolationFromMap(m *orderedmap.OrderedMap) (*Violation, error) {
 	return &p, nil
 }
 
+// UnmarshalViolation parses a json encoded violation, including its enrichments
+func UnmarshalViolation(data []byte) (*Violation, error) {
+	m := orderedmap.New()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return newViolationFromMap(m)
+}
+
 type OutputData struct { // Must be exported for json marshal
 	PolicyInfo PolicyInfo  `json:"policyInfo"`
 	Violations []Violation `json:"violations"`
@@ -108,6 +143,24 @@ func (o OutputData) Clone() OutputData {
 	return clone
 }
 
+func (o OutputData) HasFailures() bool {
+	for _, violation := range o.Violations {
+		if violation.Status == analyzers.PolicyFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func (o OutputData) HasPassed() bool {
+	for _, violation := range o.Violations {
+		if violation.Status == analyzers.PolicyPassed {
+			return true
+		}
+	}
+	return false
+}
+
 func AppendViolations(o OutputData, violations ...Violation) OutputData {
 	o.Violations = append(o.Violations, violations...)
 	return o
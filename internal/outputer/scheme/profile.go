@@ -0,0 +1,141 @@
+package scheme
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/iancoleman/orderedmap"
+)
+
+type ProfileName = string
+
+const (
+	ProfilePublic   ProfileName = "public"
+	ProfileInternal ProfileName = "internal"
+	ProfileFull     ProfileName = "full"
+
+	DefaultProfile = ProfileFull
+)
+
+// Profile bundles the filtering and redaction applied to the results before they are formatted
+type Profile struct {
+	// MinSeverity drops the policies of a lower severity (empty keeps all policies)
+	MinSeverity severity.Severity
+	// FailedOnly drops the passed/skipped violations
+	FailedOnly bool
+	// DropAux drops the enrichments (e.g. members and admins lists) of the violations
+	DropAux bool
+	// RedactIdentities replaces the links of member entities with a pseudonym (stable within the run)
+	RedactIdentities bool
+}
+
+var profiles = map[ProfileName]Profile{
+	ProfilePublic: {
+		MinSeverity:      severity.Medium,
+		FailedOnly:       true,
+		DropAux:          true,
+		RedactIdentities: true,
+	},
+	ProfileInternal: {
+		FailedOnly: true,
+	},
+	ProfileFull: {},
+}
+
+func Profiles() []ProfileName {
+	return []ProfileName{
+		ProfilePublic,
+		ProfileInternal,
+		ProfileFull,
+	}
+}
+
+func ValidateProfile(name ProfileName) error {
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("invalid output profile: %s (expecting one of %v)", name, Profiles())
+	}
+	return nil
+}
+
+// GetProfile returns the named profile (the full profile for unknown names)
+func GetProfile(name ProfileName) Profile {
+	profile, ok := profiles[name]
+	if !ok {
+		return profiles[DefaultProfile]
+	}
+	return profile
+}
+
+func (p Profile) KeepsPolicy(info PolicyInfo) bool {
+	if p.MinSeverity == "" {
+		return true
+	}
+	return !severity.Less(p.MinSeverity, info.Severity)
+}
+
+func (p Profile) KeepsViolation(violation Violation) bool {
+	return !p.FailedOnly || violation.Status == analyzers.PolicyFailed
+}
+
+// Violation returns the violation as shown by the profile
+func (p Profile) Violation(violation Violation) Violation {
+	if p.DropAux {
+		violation.Aux = orderedmap.New()
+	}
+	if p.RedactIdentities && violation.ViolationEntityType == namespace.Member {
+		violation.CanonicalLink = redact(violation.CanonicalLink)
+		violation.EntityID = redact(violation.EntityID)
+	}
+	return violation
+}
+
+// redactionKey is random per run, so the pseudonyms cannot be reversed by hashing candidate identities
+// (e.g. the members of a public organization), nor linked across runs
+var redactionKey = newRedactionKey()
+
+func newRedactionKey() []byte {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate the redaction key: %v", err))
+	}
+	return key
+}
+
+// redact keeps distinct identities apart (e.g. when grouping by resource) without revealing them
+func redact(value string) string {
+	mac := hmac.New(sha256.New, redactionKey)
+	mac.Write([]byte(value))
+	return "redacted-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// WithProfile returns the results as shown by the profile
+func (s *Flattened) WithProfile(profile Profile) *Flattened {
+	filteredScheme := NewFlattenedScheme()
+
+	for _, policyName := range s.AsOrderedMap().Keys() {
+		outputData := s.GetPolicyData(policyName)
+		if !profile.KeepsPolicy(outputData.PolicyInfo) {
+			continue
+		}
+
+		violations := []Violation{}
+		for _, violation := range outputData.Violations {
+			if profile.KeepsViolation(violation) {
+				violations = append(violations, profile.Violation(violation))
+			}
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		outputData.Violations = violations
+		filteredScheme.AsOrderedMap().Set(policyName, outputData)
+	}
+
+	return filteredScheme
+}
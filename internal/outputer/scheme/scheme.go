@@ -1,8 +1,10 @@
 package scheme
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/iancoleman/orderedmap"
 )
@@ -11,14 +13,36 @@ type Scheme interface {
 	AsOrderedMap() *orderedmap.OrderedMap
 }
 
+// sortOutputData sorts the violations by the violated entity, and the violations of the same entity by their content,
+// so the output does not depend on the order in which the violations were received.
 func sortOutputData(outputData OutputData) OutputData {
+	type keyedViolation struct {
+		key       string
+		violation Violation
+	}
+
+	keyed := make([]keyedViolation, len(outputData.Violations))
+	for i, violation := range outputData.Violations {
+		aux, _ := json.Marshal(violation.Aux)
+		keyed[i] = keyedViolation{
+			key:       strings.Join([]string{violation.ViolationEntityType, violation.Status, violation.Provider, string(aux)}, "\x00"),
+			violation: violation,
+		}
+	}
+
 	less := func(i, j int) bool {
-		iLink := outputData.Violations[i].CanonicalLink
-		jLink := outputData.Violations[j].CanonicalLink
-		return iLink < jLink
+		iLink := keyed[i].violation.CanonicalLink
+		jLink := keyed[j].violation.CanonicalLink
+		if iLink != jLink {
+			return iLink < jLink
+		}
+		return keyed[i].key < keyed[j].key
 	}
 
-	sort.SliceStable(outputData.Violations, less)
+	sort.SliceStable(keyed, less)
+	for i := range keyed {
+		outputData.Violations[i] = keyed[i].violation
+	}
 	return outputData
 }
 
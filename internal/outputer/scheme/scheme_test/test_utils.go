@@ -187,12 +187,14 @@ func SchemeSample() *scheme.Flattened {
 		Violations: []scheme.Violation{
 			{
 				ViolationEntityType: policy_1_entity.ViolationEntityType(),
+				EntityID:            policy_1_entity.EntityID(),
 				CanonicalLink:       first(policy_1_entity.CanonicalLink()),
 				Aux:                 map_utils.ToKeySortedMap(auxSample()),
 				Status:              analyzers.PolicyFailed,
 			},
 			{
 				ViolationEntityType: policy_1_entity.ViolationEntityType(),
+				EntityID:            policy_1_entity.EntityID(),
 				CanonicalLink:       second(policy_1_entity.CanonicalLink()),
 				Aux:                 nil,
 				Status:              analyzers.PolicyFailed,
@@ -205,12 +207,14 @@ func SchemeSample() *scheme.Flattened {
 		Violations: []scheme.Violation{
 			{
 				ViolationEntityType: policy_2_entity.ViolationEntityType(),
+				EntityID:            policy_2_entity.EntityID(),
 				CanonicalLink:       first(policy_2_entity.CanonicalLink()),
 				Aux:                 map_utils.ToKeySortedMap(auxSample2()),
 				Status:              analyzers.PolicyFailed,
 			},
 			{
 				ViolationEntityType: policy_2_entity.ViolationEntityType(),
+				EntityID:            policy_2_entity.EntityID(),
 				CanonicalLink:       second(policy_2_entity.CanonicalLink()),
 				Aux:                 map_utils.ToKeySortedMap(auxSample2()),
 				Status:              analyzers.PolicyFailed,
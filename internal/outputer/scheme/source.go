@@ -0,0 +1,159 @@
+package scheme
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+)
+
+// ViolationsSource provides the results policy by policy, so they can be formatted incrementally
+// when they do not fit in memory as a Flattened scheme.
+type ViolationsSource interface {
+	// Policies returns the fully qualified policy names, sorted by severity
+	Policies() []string
+	PolicyInfo(policyName string) PolicyInfo
+	StatusCount(policyName string, status analyzers.PolicyStatus) int
+	ForEachViolation(policyName string, f func(violation Violation) error) error
+}
+
+// Policies returns the policies by their order in the scheme (the Flattened scheme is a ViolationsSource)
+func (s *Flattened) Policies() []string {
+	return s.AsOrderedMap().Keys()
+}
+
+func (s *Flattened) PolicyInfo(policyName string) PolicyInfo {
+	return s.GetPolicyData(policyName).PolicyInfo
+}
+
+func (s *Flattened) StatusCount(policyName string, status analyzers.PolicyStatus) int {
+	count := 0
+	for _, violation := range s.GetPolicyData(policyName).Violations {
+		if violation.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Flattened) ForEachViolation(policyName string, f func(violation Violation) error) error {
+	for _, violation := range s.GetPolicyData(policyName).Violations {
+		if err := f(violation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type failedViolationsSource struct {
+	ViolationsSource
+}
+
+// OnlyFailedViolations returns the failed violations of the source, without the policies that have none
+func OnlyFailedViolations(source ViolationsSource) ViolationsSource {
+	return &failedViolationsSource{source}
+}
+
+func (s *failedViolationsSource) Policies() []string {
+	var policies []string
+	for _, policyName := range s.ViolationsSource.Policies() {
+		if s.ViolationsSource.StatusCount(policyName, analyzers.PolicyFailed) > 0 {
+			policies = append(policies, policyName)
+		}
+	}
+	return policies
+}
+
+func (s *failedViolationsSource) StatusCount(policyName string, status analyzers.PolicyStatus) int {
+	if status != analyzers.PolicyFailed {
+		return 0
+	}
+	return s.ViolationsSource.StatusCount(policyName, status)
+}
+
+func (s *failedViolationsSource) ForEachViolation(policyName string, f func(violation Violation) error) error {
+	return s.ViolationsSource.ForEachViolation(policyName, func(violation Violation) error {
+		if violation.Status != analyzers.PolicyFailed {
+			return nil
+		}
+		return f(violation)
+	})
+}
+
+// CollectFlattened loads the violations of the source to memory as a Flattened scheme
+func CollectFlattened(source ViolationsSource) (*Flattened, error) {
+	flattened := NewFlattenedScheme()
+	for _, policyName := range source.Policies() {
+		outputData := NewOutputData(source.PolicyInfo(policyName))
+		err := source.ForEachViolation(policyName, func(violation Violation) error {
+			outputData = AppendViolations(outputData, violation)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		flattened.AsOrderedMap().Set(policyName, outputData)
+	}
+	return flattened, nil
+}
+
+// jsonWriter writes json incrementally, keeping the first error
+type jsonWriter struct {
+	writer io.Writer
+	err    error
+}
+
+func (w *jsonWriter) raw(str string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.writer, str)
+}
+
+func (w *jsonWriter) value(v interface{}) {
+	if w.err != nil {
+		return
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		w.err = err
+		return
+	}
+	_, w.err = w.writer.Write(encoded)
+}
+
+// WriteFlattened writes the source as the compact json of a typed Flattened scheme (without metadata),
+// reading a single violation at a time.
+func WriteFlattened(writer io.Writer, source ViolationsSource) error {
+	w := &jsonWriter{writer: writer}
+
+	w.raw(`{"type":`)
+	w.value(TypeFlattened)
+	w.raw(`,"content":{`)
+	for i, policyName := range source.Policies() {
+		if i > 0 {
+			w.raw(",")
+		}
+		w.value(policyName)
+		w.raw(`:{"policyInfo":`)
+		w.value(source.PolicyInfo(policyName))
+		w.raw(`,"violations":[`)
+
+		first := true
+		err := source.ForEachViolation(policyName, func(violation Violation) error {
+			if !first {
+				w.raw(",")
+			}
+			first = false
+			w.value(violation)
+			return w.err
+		})
+		if err != nil {
+			return err
+		}
+		w.raw("]}")
+	}
+	w.raw("}}")
+
+	return w.err
+}
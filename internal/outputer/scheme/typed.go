@@ -8,8 +8,9 @@ import (
 )
 
 type TypedScheme[T any] struct {
-	Type    SchemeType `json:"type"`
-	Content T          `json:"content"`
+	Metadata *Metadata  `json:"metadata,omitempty"`
+	Type     SchemeType `json:"type"`
+	Content  T          `json:"content"`
 }
 
 func NewTyped[T any](t SchemeType, content T) *TypedScheme[T] {
@@ -0,0 +1,254 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/map_utils"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/enricher/enrichers"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/google/go-github/v53/github"
+	"github.com/iancoleman/orderedmap"
+)
+
+const (
+	DefaultCheckRunName = "legitify"
+
+	// GitHub accepts up to 50 annotations per request and limits the size of the output texts
+	checkRunAnnotationsPerRequest = 50
+	checkRunMaxTextLength         = 65535
+	// the unlocated violations beyond it would not fit in the output text anyway
+	checkRunMaxUnlocated = checkRunMaxTextLength / 64
+)
+
+// the aux keys (of the violated sets) that hold the path of the file the violation is found in
+var checkRunPathKeys = []string{"path", "file", "filename", "blob_path"}
+
+var checkRunAnnotationLevels = map[severity.Severity]string{
+	severity.Critical: "failure",
+	severity.High:     "failure",
+	severity.Medium:   "warning",
+	severity.Low:      "notice",
+}
+
+type CheckRunOptions struct {
+	Client *github.Client
+	// Owner and Repo are the repository the check run is created on (e.g. the repository of the pull request)
+	Owner   string
+	Repo    string
+	HeadSHA string
+	Name    string
+}
+
+// CheckRun reports the results as a check run of a commit: the violations found in a file of the repository
+// are annotated inline, and the other violations are listed in the summary.
+type CheckRun struct {
+	opts CheckRunOptions
+}
+
+func NewCheckRun(opts CheckRunOptions) (*CheckRun, error) {
+	if opts.Client == nil {
+		return nil, fmt.Errorf("check run requires a github client")
+	}
+	if opts.Owner == "" || opts.Repo == "" || opts.HeadSHA == "" {
+		return nil, fmt.Errorf("check run requires a repository and a commit sha")
+	}
+	if opts.Name == "" {
+		opts.Name = DefaultCheckRunName
+	}
+
+	return &CheckRun{opts: opts}, nil
+}
+
+// checkRunFinding is a failed violation and the policy it violates
+type checkRunFinding struct {
+	policyInfo scheme.PolicyInfo
+	violation  scheme.Violation
+}
+
+func (c *CheckRun) Send(ctx context.Context, violations scheme.ViolationsSource) error {
+	var annotations []*github.CheckRunAnnotation
+	var unlocated []checkRunFinding
+	failed := 0
+
+	for _, policyName := range violations.Policies() {
+		policyInfo := violations.PolicyInfo(policyName)
+		err := violations.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			if violation.Status != analyzers.PolicyFailed {
+				return nil
+			}
+			failed++
+
+			path, line, ok := c.location(violation)
+			if !ok {
+				if len(unlocated) < checkRunMaxUnlocated {
+					unlocated = append(unlocated, checkRunFinding{policyInfo, violation})
+				}
+				return nil
+			}
+			annotations = append(annotations, checkRunAnnotation(policyInfo, path, line))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	conclusion := "success"
+	if failed > 0 {
+		conclusion = "failure"
+	}
+	output := &github.CheckRunOutput{
+		Title:   github.String(fmt.Sprintf("%d violations found", failed)),
+		Summary: github.String(truncateCheckRunText(checkRunSummary(violations))),
+		Text:    github.String(truncateCheckRunText(checkRunUnlocated(unlocated))),
+	}
+
+	output.Annotations = nextAnnotations(&annotations)
+	run, _, err := c.opts.Client.Checks.CreateCheckRun(ctx, c.opts.Owner, c.opts.Repo, github.CreateCheckRunOptions{
+		Name:       c.opts.Name,
+		HeadSHA:    c.opts.HeadSHA,
+		Status:     github.String("completed"),
+		Conclusion: github.String(conclusion),
+		Output:     output,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create check run: %v", err)
+	}
+
+	// the remaining annotations are appended to the check run in batches
+	for len(annotations) > 0 {
+		output.Annotations = nextAnnotations(&annotations)
+		_, _, err = c.opts.Client.Checks.UpdateCheckRun(ctx, c.opts.Owner, c.opts.Repo, run.GetID(), github.UpdateCheckRunOptions{
+			Name:   c.opts.Name,
+			Output: output,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to annotate check run: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func nextAnnotations(annotations *[]*github.CheckRunAnnotation) []*github.CheckRunAnnotation {
+	size := len(*annotations)
+	if size > checkRunAnnotationsPerRequest {
+		size = checkRunAnnotationsPerRequest
+	}
+	batch := (*annotations)[:size]
+	*annotations = (*annotations)[size:]
+	return batch
+}
+
+// location derives the file of the checked repository the violation is found in, if any
+func (c *CheckRun) location(violation scheme.Violation) (path string, line int, ok bool) {
+	if !strings.HasSuffix(strings.TrimSuffix(violation.CanonicalLink, "/"), "/"+c.opts.Owner+"/"+c.opts.Repo) {
+		return "", 0, false // annotations can only point at the files of the checked repository
+	}
+	if violation.Aux == nil {
+		return "", 0, false
+	}
+
+	for _, name := range violation.Aux.Keys() {
+		list, isList := map_utils.UnsafeGetUntyped(violation.Aux, name).(enrichers.GenericListEnrichment)
+		if !isList {
+			continue
+		}
+		for _, entry := range list {
+			entry := entry
+			for _, key := range checkRunPathKeys {
+				value, found := entry.Get(key)
+				if path, isString := value.(string); found && isString && path != "" {
+					return path, entryLine(&entry), true
+				}
+			}
+		}
+	}
+
+	return "", 0, false
+}
+
+func entryLine(entry *orderedmap.OrderedMap) int {
+	value, found := entry.Get("line")
+	if !found {
+		return 1
+	}
+	switch line := value.(type) {
+	case float64:
+		return int(line)
+	case string:
+		if parsed, err := strconv.Atoi(line); err == nil {
+			return parsed
+		}
+	}
+	return 1
+}
+
+func checkRunAnnotation(policyInfo scheme.PolicyInfo, path string, line int) *github.CheckRunAnnotation {
+	level, ok := checkRunAnnotationLevels[policyInfo.Severity]
+	if !ok {
+		level = "notice"
+	}
+	return &github.CheckRunAnnotation{
+		Path:            github.String(path),
+		StartLine:       github.Int(line),
+		EndLine:         github.Int(line),
+		AnnotationLevel: github.String(level),
+		Title:           github.String(policyInfo.Title),
+		Message:         github.String(policyInfo.Description),
+	}
+}
+
+// checkRunSummary lists the failed policies (by severity) and their number of violations
+func checkRunSummary(violations scheme.ViolationsSource) string {
+	var sb strings.Builder
+	sb.WriteString("| Policy | Severity | Violations |\n| --- | --- | --- |\n")
+
+	rows := 0
+	for _, policyName := range violations.Policies() {
+		failed := violations.StatusCount(policyName, analyzers.PolicyFailed)
+		if failed == 0 {
+			continue
+		}
+		rows++
+		policyInfo := violations.PolicyInfo(policyName)
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d |\n", policyInfo.Title, policyInfo.Severity, failed))
+	}
+
+	if rows == 0 {
+		return "No violations found."
+	}
+	return sb.String()
+}
+
+// checkRunUnlocated lists the violations that cannot be annotated inline
+func checkRunUnlocated(findings []checkRunFinding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Violations without a file location\n\n")
+	for _, finding := range findings {
+		sb.WriteString(fmt.Sprintf("- **%s** (%s): %s\n", finding.policyInfo.Title, finding.policyInfo.Severity, finding.violation.CanonicalLink))
+	}
+	return sb.String()
+}
+
+func truncateCheckRunText(text string) string {
+	const suffix = "\n..."
+	if len(text) <= checkRunMaxTextLength {
+		return text
+	}
+	cut := checkRunMaxTextLength - len(suffix)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + suffix
+}
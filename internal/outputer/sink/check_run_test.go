@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/enricher/enrichers"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/google/go-github/v53/github"
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/require"
+)
+
+type checkRunServerMock struct {
+	lock        sync.Mutex
+	created     []github.CreateCheckRunOptions
+	updates     int
+	annotations []*github.CheckRunAnnotation
+}
+
+func (m *checkRunServerMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/check-runs":
+		var opts github.CreateCheckRunOptions
+		_ = json.NewDecoder(r.Body).Decode(&opts)
+		m.created = append(m.created, opts)
+		m.annotations = append(m.annotations, opts.Output.Annotations...)
+		_ = json.NewEncoder(w).Encode(github.CheckRun{ID: github.Int64(7)})
+	case r.Method == http.MethodPatch && r.URL.Path == "/repos/owner/repo/check-runs/7":
+		var opts github.UpdateCheckRunOptions
+		_ = json.NewDecoder(r.Body).Decode(&opts)
+		m.updates++
+		m.annotations = append(m.annotations, opts.Output.Annotations...)
+		_ = json.NewEncoder(w).Encode(github.CheckRun{ID: github.Int64(7)})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newCheckRunClient(t *testing.T, server *httptest.Server) *github.Client {
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.Nil(t, err)
+	client.BaseURL = baseURL
+	return client
+}
+
+func violationWithPath(link string, path string) scheme.Violation {
+	entry := orderedmap.New()
+	entry.Set("path", path)
+	aux := orderedmap.New()
+	aux.Set(enrichers.WorkflowsList, enrichers.GenericListEnrichment{*entry})
+	return scheme.Violation{CanonicalLink: link, Aux: aux, Status: analyzers.PolicyFailed}
+}
+
+func TestCheckRunAnnotations(t *testing.T) {
+	mock := &checkRunServerMock{}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	violations := []scheme.Violation{
+		{CanonicalLink: "https://github.com/owner/repo", Aux: orderedmap.New(), Status: analyzers.PolicyFailed},
+		violationWithPath("https://github.com/owner/other", ".github/workflows/ci.yml"),
+		{CanonicalLink: "https://github.com/owner/repo", Status: analyzers.PolicyPassed},
+	}
+	for i := 0; i < checkRunAnnotationsPerRequest+1; i++ {
+		violations = append(violations, violationWithPath("https://github.com/owner/repo", fmt.Sprintf(".github/workflows/%d.yml", i)))
+	}
+
+	sample := scheme.NewFlattenedScheme()
+	sample.AsOrderedMap().Set("data.repository.policy", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{Title: "Workflow Policy", PolicyName: "policy", Severity: severity.Medium},
+		Violations: violations,
+	})
+
+	checkRun, err := NewCheckRun(CheckRunOptions{Client: newCheckRunClient(t, server), Owner: "owner", Repo: "repo", HeadSHA: "abc"})
+	require.Nil(t, err)
+	require.Nil(t, checkRun.Send(context.Background(), sample))
+
+	require.Len(t, mock.created, 1)
+	created := mock.created[0]
+	require.Equal(t, DefaultCheckRunName, created.Name)
+	require.Equal(t, "failure", created.GetConclusion())
+	require.Contains(t, created.Output.GetSummary(), "| Workflow Policy | MEDIUM | 53 |")
+
+	// the violations of other repositories or without a file are listed in the text
+	require.Equal(t, 2, strings.Count(created.Output.GetText(), "Workflow Policy"))
+	require.Equal(t, 1, mock.updates, "expecting the annotations to be sent in batches")
+	require.Len(t, mock.annotations, checkRunAnnotationsPerRequest+1)
+	require.Equal(t, "warning", mock.annotations[0].GetAnnotationLevel())
+	require.Equal(t, ".github/workflows/0.yml", mock.annotations[0].GetPath())
+	require.Equal(t, 1, mock.annotations[0].GetStartLine())
+}
+
+func TestCheckRunNoViolations(t *testing.T) {
+	mock := &checkRunServerMock{}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	checkRun, err := NewCheckRun(CheckRunOptions{Client: newCheckRunClient(t, server), Owner: "owner", Repo: "repo", HeadSHA: "abc"})
+	require.Nil(t, err)
+	require.Nil(t, checkRun.Send(context.Background(), scheme.NewFlattenedScheme()))
+	require.Equal(t, "success", mock.created[0].GetConclusion())
+	require.Equal(t, 0, mock.updates)
+
+	_, err = NewCheckRun(CheckRunOptions{Client: newCheckRunClient(t, server), Owner: "owner", Repo: "repo"})
+	require.NotNil(t, err, "expecting the commit sha to be required")
+}
@@ -0,0 +1,362 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+const (
+	jiraTimeout          = 30 * time.Second
+	jiraLabel            = "legitify"
+	jiraMarkerPrefix     = "legitify-"
+	jiraMaxSummaryLength = 255
+	// jiraSearchBatchSize bounds the number of markers per search query (and so the length of its URL)
+	jiraSearchBatchSize = 50
+	jiraSearchPageSize  = 100
+
+	DefaultJiraIssueType   = "Task"
+	DefaultJiraMinSeverity = severity.High
+)
+
+var jiraPriorities = map[severity.Severity]string{
+	severity.Critical: "Highest",
+	severity.High:     "High",
+	severity.Medium:   "Medium",
+	severity.Low:      "Low",
+}
+
+type JiraOptions struct {
+	// URL is the base URL of the Jira instance (e.g. https://example.atlassian.net)
+	URL string
+	// User authenticates together with Token using basic authentication (Jira Cloud).
+	// When empty, Token is used as a personal access token (Jira Server/Data Center).
+	// The issues are searched using the enhanced search API of Jira Cloud, or the search API of Jira Server/Data Center.
+	User        string
+	Token       string
+	ProjectKey  string
+	IssueType   string
+	MinSeverity severity.Severity
+}
+
+// Jira creates an issue per failed violation of the policies of MinSeverity or higher.
+// Each issue is labeled with a marker of the policy and the entity, so the issue is updated by the next scans
+// instead of being duplicated.
+type Jira struct {
+	opts   JiraOptions
+	client *http.Client
+}
+
+func NewJira(opts JiraOptions) (*Jira, error) {
+	if opts.URL == "" || opts.ProjectKey == "" || opts.Token == "" {
+		return nil, fmt.Errorf("jira requires a URL, a project key and a token")
+	}
+	if !severity.IsValid(opts.MinSeverity) {
+		return nil, fmt.Errorf("invalid jira minimum severity: %s", opts.MinSeverity)
+	}
+	if opts.IssueType == "" {
+		opts.IssueType = DefaultJiraIssueType
+	}
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+
+	return &Jira{
+		opts:   opts,
+		client: &http.Client{Timeout: jiraTimeout},
+	}, nil
+}
+
+type jiraFields struct {
+	Project     *jiraKey  `json:"project,omitempty"`
+	IssueType   *jiraName `json:"issuetype,omitempty"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Priority    *jiraName `json:"priority,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraIssue struct {
+	Fields jiraFields `json:"fields"`
+}
+
+type jiraPending struct {
+	policyInfo scheme.PolicyInfo
+	violation  scheme.Violation
+	marker     string
+}
+
+// Send creates or updates the issues of the failed violations. A failure to sync an issue does not stop the others;
+// the failures are reported together once all the violations were handled.
+// The existing issues are searched in batches of markers before they are synced, instead of a search per violation.
+func (j *Jira) Send(ctx context.Context, violations scheme.ViolationsSource) error {
+	var pending []jiraPending
+	for _, policyName := range violations.Policies() {
+		policyInfo := violations.PolicyInfo(policyName)
+		if severity.Less(j.opts.MinSeverity, policyInfo.Severity) {
+			continue
+		}
+
+		err := violations.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			if violation.Status != analyzers.PolicyFailed {
+				return nil
+			}
+			pending = append(pending, jiraPending{
+				policyInfo: policyInfo,
+				violation:  violation,
+				marker:     JiraMarker(policyInfo, violation),
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var failures []string
+	fail := func(p jiraPending, err error) {
+		failures = append(failures, fmt.Sprintf("%s (%s): %v", p.policyInfo.PolicyName, p.violation.CanonicalLink, err))
+	}
+	for start := 0; start < len(pending); start += jiraSearchBatchSize {
+		end := start + jiraSearchBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+		markers := make([]string, 0, len(batch))
+		for _, p := range batch {
+			markers = append(markers, p.marker)
+		}
+
+		existing, err := j.findIssues(ctx, markers)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			for _, p := range batch {
+				fail(p, err)
+			}
+			continue
+		}
+
+		for _, p := range batch {
+			if err := j.upsert(ctx, p, existing); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fail(p, err)
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to sync %d jira issue(s): %s", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// JiraMarker identifies the issue of the policy and the entity
+func JiraMarker(policyInfo scheme.PolicyInfo, violation scheme.Violation) string {
+	entity := violation.EntityID
+	if entity == "" {
+		entity = violation.CanonicalLink
+	}
+	hash := sha256.Sum256([]byte(policyInfo.FullyQualifiedPolicyName + "|" + entity))
+	return jiraMarkerPrefix + hex.EncodeToString(hash[:])[:16]
+}
+
+// upsert updates the existing issue of the marker, or creates it and records its key in existing
+func (j *Jira) upsert(ctx context.Context, p jiraPending, existing map[string]string) error {
+	fields := jiraFields{
+		Summary:     jiraSummary(p.policyInfo, p.violation),
+		Description: jiraDescription(p.policyInfo, p.violation),
+	}
+	if priority, ok := jiraPriorities[p.policyInfo.Severity]; ok {
+		fields.Priority = &jiraName{Name: priority}
+	}
+
+	if key, ok := existing[p.marker]; ok {
+		return j.do(ctx, http.MethodPut, "/rest/api/2/issue/"+url.PathEscape(key), jiraIssue{Fields: fields}, nil)
+	}
+
+	fields.Project = &jiraKey{Key: j.opts.ProjectKey}
+	fields.IssueType = &jiraName{Name: j.opts.IssueType}
+	fields.Labels = []string{jiraLabel, p.marker}
+	var created jiraKey
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", jiraIssue{Fields: fields}, &created); err != nil {
+		return err
+	}
+	if created.Key != "" {
+		existing[p.marker] = created.Key
+	}
+	return nil
+}
+
+type jiraSearchResult struct {
+	Issues []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Labels []string `json:"labels"`
+		} `json:"fields"`
+	} `json:"issues"`
+	// enhanced search (Jira Cloud) paging
+	NextPageToken string `json:"nextPageToken"`
+	IsLast        bool   `json:"isLast"`
+	// search (Jira Server/Data Center) paging
+	StartAt int `json:"startAt"`
+	Total   int `json:"total"`
+}
+
+// findIssues returns the keys of the issues labeled with the markers, by marker
+func (j *Jira) findIssues(ctx context.Context, markers []string) (map[string]string, error) {
+	wanted := make(map[string]bool, len(markers))
+	quoted := make([]string, 0, len(markers))
+	for _, marker := range markers {
+		if !wanted[marker] {
+			wanted[marker] = true
+			quoted = append(quoted, jqlString(marker))
+		}
+	}
+
+	query := url.Values{}
+	query.Set("jql", fmt.Sprintf("project = %s AND labels in (%s)", jqlString(j.opts.ProjectKey), strings.Join(quoted, ", ")))
+	query.Set("fields", "labels")
+	query.Set("maxResults", strconv.Itoa(jiraSearchPageSize))
+
+	// Jira Cloud removed the search API in favor of the enhanced search API, which Jira Server/Data Center lacks
+	path := "/rest/api/2/search"
+	if j.isCloud() {
+		path = "/rest/api/3/search/jql"
+	}
+
+	found := make(map[string]string)
+	for {
+		var result jiraSearchResult
+		if err := j.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &result); err != nil {
+			return nil, err
+		}
+		for _, issue := range result.Issues {
+			for _, label := range issue.Fields.Labels {
+				if _, ok := found[label]; !ok && wanted[label] {
+					found[label] = issue.Key
+				}
+			}
+		}
+
+		if j.isCloud() {
+			if result.IsLast || result.NextPageToken == "" {
+				return found, nil
+			}
+			query.Set("nextPageToken", result.NextPageToken)
+		} else {
+			next := result.StartAt + len(result.Issues)
+			if len(result.Issues) == 0 || next >= result.Total {
+				return found, nil
+			}
+			query.Set("startAt", strconv.Itoa(next))
+		}
+	}
+}
+
+// isCloud reports whether the instance is Jira Cloud, which authenticates with a user and an API token
+func (j *Jira) isCloud() bool {
+	return j.opts.User != ""
+}
+
+// jqlString quotes a value as a JQL string literal
+func jqlString(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+func (j *Jira) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, j.opts.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if j.isCloud() {
+		req.SetBasicAuth(j.opts.User, j.opts.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.opts.Token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		details, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira responded with status %s: %s", resp.Status, strings.TrimSpace(string(details)))
+	}
+	if result == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func jiraSummary(policyInfo scheme.PolicyInfo, violation scheme.Violation) string {
+	summary := []rune(fmt.Sprintf("[legitify] %s: %s", policyInfo.Title, violation.CanonicalLink))
+	if len(summary) > jiraMaxSummaryLength {
+		return string(summary[:jiraMaxSummaryLength-3]) + "..."
+	}
+	return string(summary)
+}
+
+// jiraDescription formats the policy info using the Jira wiki markup
+func jiraDescription(policyInfo scheme.PolicyInfo, violation scheme.Violation) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*Severity:* %s\n", policyInfo.Severity))
+	sb.WriteString(fmt.Sprintf("*Entity (%s):* %s\n\n", violation.ViolationEntityType, violation.CanonicalLink))
+	sb.WriteString(policyInfo.Description + "\n")
+
+	if len(policyInfo.Threat) > 0 {
+		sb.WriteString("\nh3. Threat\n")
+		for _, threat := range policyInfo.Threat {
+			sb.WriteString(threat + "\n")
+		}
+	}
+	if len(policyInfo.RemediationSteps) > 0 {
+		sb.WriteString("\nh3. Remediation\n")
+		for _, step := range policyInfo.RemediationSteps {
+			sb.WriteString(step + "\n")
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\n_Policy: %s_\n", policyInfo.PolicyName))
+	return sb.String()
+}
@@ -0,0 +1,204 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
+	"github.com/stretchr/testify/require"
+)
+
+type jiraServerMock struct {
+	lock     sync.Mutex
+	issues   map[string]jiraFields // by marker label
+	keys     map[string]string     // marker label -> issue key
+	created  int
+	updated  int
+	searches []string // the paths of the search requests
+	auth     []string
+	// failCreates is the number of issue creations to reject before accepting them
+	failCreates int
+	// pageSize limits the issues per search response (0 for unlimited)
+	pageSize int
+}
+
+func newJiraServerMock() *jiraServerMock {
+	return &jiraServerMock{
+		issues: make(map[string]jiraFields),
+		keys:   make(map[string]string),
+	}
+}
+
+func (m *jiraServerMock) search(w http.ResponseWriter, r *http.Request) {
+	m.searches = append(m.searches, r.URL.Path)
+	jql := r.URL.Query().Get("jql")
+
+	var markers []string
+	for marker := range m.keys {
+		if strings.Contains(jql, fmt.Sprintf(`"%s"`, marker)) {
+			markers = append(markers, marker)
+		}
+	}
+	sort.Strings(markers)
+
+	start := 0
+	if token := r.URL.Query().Get("nextPageToken"); token != "" {
+		start, _ = strconv.Atoi(token)
+	} else if startAt := r.URL.Query().Get("startAt"); startAt != "" {
+		start, _ = strconv.Atoi(startAt)
+	}
+	end := len(markers)
+	if m.pageSize > 0 && start+m.pageSize < end {
+		end = start + m.pageSize
+	}
+
+	var issues []map[string]interface{}
+	for _, marker := range markers[start:end] {
+		issues = append(issues, map[string]interface{}{
+			"key":    m.keys[marker],
+			"fields": map[string]interface{}{"labels": []string{jiraLabel, marker}},
+		})
+	}
+	response := map[string]interface{}{"issues": issues, "startAt": start, "total": len(markers), "isLast": end == len(markers)}
+	if end < len(markers) {
+		response["nextPageToken"] = strconv.Itoa(end)
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (m *jiraServerMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.auth = append(m.auth, r.Header.Get("Authorization"))
+
+	switch {
+	case r.Method == http.MethodGet && (r.URL.Path == "/rest/api/2/search" || r.URL.Path == "/rest/api/3/search/jql"):
+		m.search(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		if m.failCreates > 0 {
+			m.failCreates--
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var issue jiraIssue
+		_ = json.NewDecoder(r.Body).Decode(&issue)
+		m.created++
+		marker := issue.Fields.Labels[1]
+		m.keys[marker] = fmt.Sprintf("%s-%d", issue.Fields.Project.Key, m.created)
+		m.issues[marker] = issue.Fields
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"key": m.keys[marker]})
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/"):
+		m.updated++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestJiraCreatesAndUpdatesIssues(t *testing.T) {
+	mock := newJiraServerMock()
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	jira, err := NewJira(JiraOptions{
+		URL:         server.URL + "/",
+		User:        "bot@example.com",
+		Token:       "secret",
+		ProjectKey:  "SEC",
+		MinSeverity: severity.High,
+	})
+	require.Nil(t, err)
+
+	// the LOW policy is below the threshold, and both violations of the HIGH policy are of the same entity
+	sample := scheme_test.SchemeSample()
+	require.Nil(t, jira.Send(context.Background(), sample))
+	require.Equal(t, 1, mock.created)
+	require.Equal(t, 1, mock.updated)
+
+	for _, fields := range mock.issues {
+		require.Equal(t, DefaultJiraIssueType, fields.IssueType.Name)
+		require.Equal(t, "High", fields.Priority.Name)
+		require.Contains(t, fields.Summary, "My other policy")
+		require.Contains(t, fields.Description, "dont")
+	}
+	username, password, ok := (&http.Request{Header: http.Header{"Authorization": {mock.auth[0]}}}).BasicAuth()
+	require.True(t, ok)
+	require.Equal(t, "bot@example.com", username)
+	require.Equal(t, "secret", password)
+
+	// the next scan updates the existing issue instead of duplicating it
+	require.Nil(t, jira.Send(context.Background(), sample))
+	require.Equal(t, 1, mock.created)
+	require.Equal(t, 3, mock.updated)
+	require.Equal(t, []string{"/rest/api/3/search/jql", "/rest/api/3/search/jql"}, mock.searches, "expecting a single search per scan")
+}
+
+func TestJiraMinSeverity(t *testing.T) {
+	mock := newJiraServerMock()
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	jira, err := NewJira(JiraOptions{URL: server.URL, Token: "pat", ProjectKey: "SEC", MinSeverity: severity.Low})
+	require.Nil(t, err)
+
+	require.Nil(t, jira.Send(context.Background(), scheme_test.SchemeSample()))
+	require.Equal(t, 2, mock.created, "expecting an issue per policy and entity")
+	require.Equal(t, "Bearer pat", mock.auth[0])
+	require.Equal(t, []string{"/rest/api/2/search"}, mock.searches)
+
+	_, err = NewJira(JiraOptions{URL: server.URL, Token: "pat", ProjectKey: "SEC", MinSeverity: "URGENT"})
+	require.NotNil(t, err)
+}
+
+func TestJiraContinuesAfterFailedIssue(t *testing.T) {
+	mock := newJiraServerMock()
+	mock.failCreates = 1
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	jira, err := NewJira(JiraOptions{URL: server.URL, Token: "pat", ProjectKey: "SEC", MinSeverity: severity.Low})
+	require.Nil(t, err)
+
+	err = jira.Send(context.Background(), scheme_test.SchemeSample())
+	require.NotNil(t, err, "expecting the failed issue to be reported")
+	require.Contains(t, err.Error(), "failed to sync 1 jira issue(s)")
+	require.Equal(t, 2, mock.created, "expecting the violations after the failed issue to be synced")
+}
+
+func TestJiraSearchPages(t *testing.T) {
+	for _, user := range []string{"", "bot@example.com"} {
+		mock := newJiraServerMock()
+		mock.pageSize = 1
+		server := httptest.NewServer(mock)
+
+		jira, err := NewJira(JiraOptions{URL: server.URL, User: user, Token: "pat", ProjectKey: "SEC", MinSeverity: severity.Low})
+		require.Nil(t, err)
+
+		sample := scheme_test.SchemeSample()
+		require.Nil(t, jira.Send(context.Background(), sample))
+		require.Equal(t, 2, mock.created)
+
+		// both existing issues are found although each search page holds a single issue
+		mock.searches = nil
+		require.Nil(t, jira.Send(context.Background(), sample))
+		require.Equal(t, 2, mock.created, "expecting the issues of the later pages to be updated")
+		require.Len(t, mock.searches, 2)
+		server.Close()
+	}
+}
+
+func TestJqlString(t *testing.T) {
+	require.Equal(t, `"SEC"`, jqlString("SEC"))
+	require.Equal(t, `"a\"b\\c"`, jqlString(`a"b\c`))
+}
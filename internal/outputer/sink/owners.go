@@ -0,0 +1,196 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme/converter"
+)
+
+var ownerFileExtensions = map[formatter.FormatName]string{
+	formatter.Human:           ".txt",
+	formatter.Json:            ".json",
+	formatter.Sarif:           ".sarif",
+	formatter.Markdown:        ".md",
+	formatter.Csv:             ".csv",
+	formatter.GithubIssue:     ".md",
+	formatter.Sqlite:          ".db",
+	formatter.SeveritySummary: ".json",
+}
+
+type OwnersOptions struct {
+	Dir           string
+	Format        formatter.FormatName
+	Scheme        scheme.SchemeType
+	FailedOnly    bool
+	FormatOptions formatter.Options
+}
+
+// Owners writes the results of each owner (e.g. the teams that administer a repository) to a separate file,
+// so each owner gets only the violations of the entities it is responsible for.
+type Owners struct {
+	opts OwnersOptions
+}
+
+func NewOwners(opts OwnersOptions) (*Owners, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("owners output requires a directory")
+	}
+	if err := formatter.ValidateOutputFormat(opts.Format, opts.Scheme); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Owners{opts: opts}, nil
+}
+
+func (o *Owners) Send(ctx context.Context, violations scheme.ViolationsSource) error {
+	partitions, err := ownerPartitions(violations)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if err := o.write(partition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write formats the violations of the owner, streaming them from the results when the format supports it
+func (o *Owners) write(partition *ownerPartition) error {
+	path := filepath.Join(o.opts.Dir, OwnerFileName(partition.owner, o.opts.Format))
+
+	if o.opts.Scheme == scheme.TypeFlattened && formatter.SupportsStreaming(o.opts.Format) {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		err = formatter.FormatStream(o.opts.Format, o.opts.FormatOptions, partition, o.opts.FailedOnly, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	flattened, err := scheme.CollectFlattened(partition)
+	if err != nil {
+		return err
+	}
+	converted, err := converter.Convert(o.opts.Scheme, flattened)
+	if err != nil {
+		return err
+	}
+	output, err := formatter.Format(o.opts.Format, o.opts.FormatOptions, converted, o.opts.FailedOnly)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, output, 0644)
+}
+
+// ownerPartition is the part of the results of a single owner. The violations of an entity with several owners
+// are included in the partition of each owner. It reads the violations from the results, so only the status counts of the owner are kept in memory.
+type ownerPartition struct {
+	scheme.ViolationsSource
+	owner    string
+	policies []string
+	counts   map[string]map[analyzers.PolicyStatus]int
+}
+
+// noOwner is the partition of the violations of entities without owners (the names of the owners are never empty),
+// so an owner that is named like scheme.Unowned does not share its partition
+const noOwner = ""
+
+func violationOwners(violation scheme.Violation) []string {
+	if len(violation.Owners) == 0 {
+		return []string{noOwner}
+	}
+	return violation.Owners
+}
+
+// ownerPartitions partitions the results by the owners of the violated entities, keeping the order of the policies
+// and of the violations
+func ownerPartitions(violations scheme.ViolationsSource) ([]*ownerPartition, error) {
+	var partitions []*ownerPartition
+	byOwner := make(map[string]*ownerPartition)
+
+	for _, policyName := range violations.Policies() {
+		err := violations.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			for _, owner := range violationOwners(violation) {
+				partition, ok := byOwner[owner]
+				if !ok {
+					partition = &ownerPartition{
+						ViolationsSource: violations,
+						owner:            owner,
+						counts:           make(map[string]map[analyzers.PolicyStatus]int),
+					}
+					byOwner[owner] = partition
+					partitions = append(partitions, partition)
+				}
+				if _, ok := partition.counts[policyName]; !ok {
+					partition.counts[policyName] = make(map[analyzers.PolicyStatus]int)
+					partition.policies = append(partition.policies, policyName)
+				}
+				partition.counts[policyName][violation.Status]++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return partitions, nil
+}
+
+func (p *ownerPartition) Policies() []string {
+	return p.policies
+}
+
+func (p *ownerPartition) StatusCount(policyName string, status analyzers.PolicyStatus) int {
+	return p.counts[policyName][status]
+}
+
+func (p *ownerPartition) ForEachViolation(policyName string, f func(violation scheme.Violation) error) error {
+	return p.ViolationsSource.ForEachViolation(policyName, func(violation scheme.Violation) error {
+		for _, owner := range violationOwners(violation) {
+			if owner == p.owner {
+				return f(violation)
+			}
+		}
+		return nil
+	})
+}
+
+// OwnerFileName returns the name of the results file of the owner (e.g. "org_team.json" for the team "org/team").
+// The names of different owners never collide: '/' is replaced by '_', and any other character that is not
+// a letter, a digit, '.' or '-' (including '_') is percent-encoded. The results of the entities without owners
+// are written to "@unowned", which no owner is encoded to.
+func OwnerFileName(owner string, format formatter.FormatName) string {
+	if owner == noOwner {
+		return "@" + scheme.Unowned + ownerFileExtensions[format]
+	}
+
+	var name strings.Builder
+	for _, b := range []byte(owner) {
+		switch {
+		case b == '/':
+			name.WriteByte('_')
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9', b == '.', b == '-':
+			name.WriteByte(b)
+		default:
+			fmt.Fprintf(&name, "%%%02X", b)
+		}
+	}
+	return name.String() + ownerFileExtensions[format]
+}
@@ -0,0 +1,84 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnersWritesFilePerOwner(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "owners")
+
+	sample := scheme.NewFlattenedScheme()
+	sample.AsOrderedMap().Set("data.repository.policy", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{Title: "Repository Policy", PolicyName: "policy", Severity: severity.High},
+		Violations: []scheme.Violation{
+			{CanonicalLink: "https://github.com/org/a", Aux: orderedmap.New(), Status: analyzers.PolicyFailed, Owners: []string{"org/team-a"}},
+			{CanonicalLink: "https://github.com/org/b", Aux: orderedmap.New(), Status: analyzers.PolicyFailed, Owners: []string{"org/team-a", "org/team-b"}},
+		},
+	})
+	sample.AsOrderedMap().Set("data.organization.policy", scheme.OutputData{
+		PolicyInfo: scheme.PolicyInfo{Title: "Organization Policy", PolicyName: "policy", Severity: severity.Low},
+		Violations: []scheme.Violation{
+			{CanonicalLink: "https://github.com/org", Aux: orderedmap.New(), Status: analyzers.PolicyFailed},
+			{CanonicalLink: "https://github.com/unowned", Aux: orderedmap.New(), Status: analyzers.PolicyFailed, Owners: []string{scheme.Unowned}},
+		},
+	})
+
+	owners, err := NewOwners(OwnersOptions{Dir: dir, Format: formatter.Json, Scheme: scheme.TypeFlattened})
+	require.Nil(t, err)
+	require.Nil(t, owners.Send(context.Background(), sample))
+
+	read := func(owner string) map[string]struct {
+		Violations []scheme.Violation `json:"violations"`
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, OwnerFileName(owner, formatter.Json)))
+		require.Nil(t, err, owner)
+		var parsed scheme.TypedScheme[map[string]struct {
+			Violations []scheme.Violation `json:"violations"`
+		}]
+		require.Nil(t, json.Unmarshal(data, &parsed))
+		return parsed.Content
+	}
+
+	teamA := read("org/team-a")
+	require.Len(t, teamA, 1)
+	require.Len(t, teamA["data.repository.policy"].Violations, 2)
+
+	teamB := read("org/team-b")
+	require.Len(t, teamB["data.repository.policy"].Violations, 1)
+	require.Equal(t, "https://github.com/org/b", teamB["data.repository.policy"].Violations[0].CanonicalLink)
+
+	unowned := read(noOwner)
+	require.Len(t, unowned, 1)
+	require.Len(t, unowned["data.organization.policy"].Violations, 1)
+	require.Equal(t, "https://github.com/org", unowned["data.organization.policy"].Violations[0].CanonicalLink)
+
+	namedUnowned := read(scheme.Unowned)
+	require.Len(t, namedUnowned["data.organization.policy"].Violations, 1, "expecting an owner named unowned to have its own file")
+	require.Equal(t, "https://github.com/unowned", namedUnowned["data.organization.policy"].Violations[0].CanonicalLink)
+}
+
+func TestOwnerFileName(t *testing.T) {
+	require.Equal(t, "org_team.json", OwnerFileName("org/team", formatter.Json))
+	require.Equal(t, "group_sub.md", OwnerFileName("group/sub", formatter.Markdown))
+	require.Equal(t, "@unowned.json", OwnerFileName(noOwner, formatter.Json))
+	require.Equal(t, "unowned.json", OwnerFileName(scheme.Unowned, formatter.Json))
+
+	owners := []string{"org/team", "org_team", "org:team", "org\\team", "org%2Fteam", "@unowned", noOwner, scheme.Unowned}
+	names := make(map[string]string)
+	for _, owner := range owners {
+		name := OwnerFileName(owner, formatter.Json)
+		require.NotContains(t, names, name, "expecting %q and %q not to share a file", owner, names[name])
+		names[name] = owner
+	}
+}
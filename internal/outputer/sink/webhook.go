@@ -0,0 +1,155 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Legit-Labs/legitify/internal/common/utils"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+const (
+	// WebhookBatch sends all the results in a single request (same content as the json output format)
+	WebhookBatch = "batch"
+	// WebhookPerEvent sends a request per violation
+	WebhookPerEvent = "event"
+)
+
+func WebhookModes() []string {
+	return []string{WebhookBatch, WebhookPerEvent}
+}
+
+func ValidateWebhookMode(mode string) error {
+	for _, m := range WebhookModes() {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid webhook mode: %s (expecting one of %v)", mode, WebhookModes())
+}
+
+const (
+	webhookTimeout        = 30 * time.Second
+	webhookInitialBackoff = time.Second
+)
+
+type WebhookOptions struct {
+	URL     string
+	Headers map[string]string
+	Mode    string
+	Retries int
+}
+
+// ViolationEvent is the payload of a single violation when using the per-event mode
+type ViolationEvent struct {
+	PolicyInfo scheme.PolicyInfo `json:"policyInfo"`
+	Violation  scheme.Violation  `json:"violation"`
+}
+
+type Webhook struct {
+	opts   WebhookOptions
+	client *http.Client
+}
+
+func NewWebhook(opts WebhookOptions) (*Webhook, error) {
+	if err := ValidateWebhookMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	if opts.Retries < 0 {
+		return nil, fmt.Errorf("webhook retries must be non-negative (got %d)", opts.Retries)
+	}
+
+	return &Webhook{
+		opts:   opts,
+		client: &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+func (w *Webhook) Send(ctx context.Context, violations scheme.ViolationsSource) error {
+	if w.opts.Mode == WebhookBatch {
+		// the payload is streamed from the results (again on each attempt) rather than held in memory
+		return w.post(ctx, func() io.Reader {
+			reader, writer := io.Pipe()
+			go func() {
+				writer.CloseWithError(scheme.WriteFlattened(writer, violations))
+			}()
+			return reader
+		})
+	}
+
+	for _, policyName := range violations.Policies() {
+		policyInfo := violations.PolicyInfo(policyName)
+		err := violations.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			payload, err := json.Marshal(ViolationEvent{
+				PolicyInfo: policyInfo,
+				Violation:  violation,
+			})
+			if err != nil {
+				return err
+			}
+			return w.post(ctx, func() io.Reader {
+				return bytes.NewReader(payload)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// post sends the payload (a new reader of it on each attempt)
+func (w *Webhook) post(ctx context.Context, payload func() io.Reader) error {
+	attempt := 0
+	backoff := webhookInitialBackoff
+
+	return utils.Retry(func() (bool, error) {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		attempt++
+
+		return w.doPost(ctx, payload())
+	}, w.opts.Retries+1, "send results to webhook")
+}
+
+func (w *Webhook) doPost(ctx context.Context, payload io.Reader) (shouldRetry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.URL, payload)
+	if err != nil {
+		if closer, ok := payload.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// network errors are usually transient
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("webhook responded with status %s", resp.Status)
+	shouldRetry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return shouldRetry, err
+}
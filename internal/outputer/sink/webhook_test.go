@@ -0,0 +1,112 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme/scheme_test"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookServerMock struct {
+	lock     sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	failures int
+}
+
+func (m *webhookServerMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	m.bodies = append(m.bodies, body)
+	m.headers = append(m.headers, r.Header.Clone())
+}
+
+func countViolations(s *scheme.Flattened) int {
+	count := 0
+	for _, policyName := range s.AsOrderedMap().Keys() {
+		count += len(s.GetPolicyData(policyName).Violations)
+	}
+	return count
+}
+
+func TestWebhookBatch(t *testing.T) {
+	mock := &webhookServerMock{failures: 1}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Mode:    WebhookBatch,
+		Retries: 1,
+	})
+	require.Nil(t, err)
+
+	sample := scheme_test.SchemeSample()
+	err = webhook.Send(context.Background(), sample)
+	require.Nil(t, err)
+
+	require.Len(t, mock.bodies, 1, "expecting a single request after the retry")
+	require.Equal(t, "Bearer secret", mock.headers[0].Get("Authorization"))
+	require.Equal(t, "application/json", mock.headers[0].Get("Content-Type"))
+
+	parsed, err := scheme.Unmarshal(mock.bodies[0])
+	require.Nil(t, err)
+	require.Equal(t, countViolations(sample), countViolations(parsed))
+}
+
+func TestWebhookPerEvent(t *testing.T) {
+	mock := &webhookServerMock{}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:  server.URL,
+		Mode: WebhookPerEvent,
+	})
+	require.Nil(t, err)
+
+	sample := scheme_test.SchemeSample()
+	err = webhook.Send(context.Background(), sample)
+	require.Nil(t, err)
+
+	require.Len(t, mock.bodies, countViolations(sample))
+	for _, body := range mock.bodies {
+		var event map[string]interface{}
+		require.Nil(t, json.Unmarshal(body, &event))
+		require.Contains(t, event, "policyInfo")
+		require.Contains(t, event, "violation")
+	}
+}
+
+func TestWebhookGivesUpAfterRetries(t *testing.T) {
+	mock := &webhookServerMock{failures: 2}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:     server.URL,
+		Mode:    WebhookBatch,
+		Retries: 1,
+	})
+	require.Nil(t, err)
+
+	err = webhook.Send(context.Background(), scheme_test.SchemeSample())
+	require.NotNil(t, err)
+	require.Empty(t, mock.bodies)
+}
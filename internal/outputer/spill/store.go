@@ -0,0 +1,286 @@
+package spill
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+// MaxOpenFiles is the number of spill files that are kept open for writing at once.
+// The least recently written file is closed (and reopened when needed) beyond it,
+// so results of many policies do not exhaust the file descriptors.
+const MaxOpenFiles = 64
+
+type policyEntry struct {
+	info         scheme.PolicyInfo
+	violations   []scheme.Violation
+	statusCounts map[analyzers.PolicyStatus]int
+	path         string
+	file         *os.File
+	writer       *bufio.Writer
+	lastWritten  int64
+}
+
+// Store accumulates the violations in memory up to the memory budget.
+// Once the budget is exceeded, the violations are spilled to a temporary file per policy,
+// from which they are read back incrementally (see scheme.ViolationsSource).
+// The budget is estimated by the json size of the violations.
+type Store struct {
+	budget   int64
+	used     int64
+	order    []string
+	policies map[string]*policyEntry
+	dir      string
+	files    int
+	open     map[*policyEntry]bool
+	writes   int64
+}
+
+func NewStore(budgetBytes int64) *Store {
+	return &Store{
+		budget:   budgetBytes,
+		policies: make(map[string]*policyEntry),
+		open:     make(map[*policyEntry]bool),
+	}
+}
+
+func (s *Store) Spilled() bool {
+	return s.dir != ""
+}
+
+func (s *Store) Add(policyName string, info scheme.PolicyInfo, violation scheme.Violation) error {
+	entry, ok := s.policies[policyName]
+	if !ok {
+		entry = &policyEntry{
+			info:         info,
+			statusCounts: make(map[analyzers.PolicyStatus]int),
+		}
+		s.policies[policyName] = entry
+		s.order = append(s.order, policyName)
+	}
+	entry.statusCounts[violation.Status]++
+
+	if s.Spilled() {
+		return s.writeViolation(entry, violation)
+	}
+
+	encoded, err := json.Marshal(violation)
+	if err != nil {
+		return err
+	}
+	entry.violations = append(entry.violations, violation)
+	s.used += int64(len(encoded))
+
+	if s.used > s.budget {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill moves all the in-memory violations to the temporary files
+func (s *Store) spill() error {
+	dir, err := os.MkdirTemp("", "legitify-spill-")
+	if err != nil {
+		return fmt.Errorf("failed to create spill directory: %v", err)
+	}
+	s.dir = dir
+
+	for _, policyName := range s.order {
+		entry := s.policies[policyName]
+		for _, violation := range entry.violations {
+			if err := s.writeViolation(entry, violation); err != nil {
+				return err
+			}
+		}
+		entry.violations = nil
+	}
+	s.used = 0
+
+	return nil
+}
+
+func (s *Store) writeViolation(entry *policyEntry, violation scheme.Violation) error {
+	if entry.file == nil {
+		if err := s.openPolicyFile(entry); err != nil {
+			return err
+		}
+	}
+	s.writes++
+	entry.lastWritten = s.writes
+
+	encoded, err := json.Marshal(violation)
+	if err != nil {
+		return err
+	}
+	if _, err = entry.writer.Write(encoded); err != nil {
+		return err
+	}
+	return entry.writer.WriteByte('\n')
+}
+
+// openPolicyFile opens the spill file of the policy for appending, closing the least recently written file
+// when too many are open.
+func (s *Store) openPolicyFile(entry *policyEntry) error {
+	if len(s.open) >= MaxOpenFiles {
+		var oldest *policyEntry
+		for open := range s.open {
+			if oldest == nil || open.lastWritten < oldest.lastWritten {
+				oldest = open
+			}
+		}
+		if err := s.closePolicyFile(oldest); err != nil {
+			return err
+		}
+	}
+
+	if entry.path == "" {
+		s.files++
+		entry.path = filepath.Join(s.dir, strconv.Itoa(s.files)+".jsonl")
+	}
+	file, err := os.OpenFile(entry.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open spill file: %v", err)
+	}
+	entry.file = file
+	entry.writer = bufio.NewWriter(file)
+	s.open[entry] = true
+	return nil
+}
+
+func (s *Store) closePolicyFile(entry *policyEntry) error {
+	if entry.file == nil {
+		return nil
+	}
+	delete(s.open, entry)
+	err := entry.writer.Flush()
+	if closeErr := entry.file.Close(); err == nil {
+		err = closeErr
+	}
+	entry.file = nil
+	entry.writer = nil
+	return err
+}
+
+func (s *Store) Policies() []string {
+	sorted := make([]string, len(s.order))
+	copy(sorted, s.order)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iSev := s.policies[sorted[i]].info.Severity
+		jSev := s.policies[sorted[j]].info.Severity
+		if iSev != jSev {
+			return severity.Less(iSev, jSev)
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+func (s *Store) PolicyInfo(policyName string) scheme.PolicyInfo {
+	return s.policies[policyName].info
+}
+
+func (s *Store) StatusCount(policyName string, status analyzers.PolicyStatus) int {
+	return s.policies[policyName].statusCounts[status]
+}
+
+// OnlyFailedPolicies drops the policies without failed violations and returns the number of the passed ones among them.
+func (s *Store) OnlyFailedPolicies() int {
+	kept := make([]string, 0, len(s.order))
+	passed := 0
+	for _, policyName := range s.order {
+		entry := s.policies[policyName]
+		if entry.statusCounts[analyzers.PolicyFailed] > 0 {
+			kept = append(kept, policyName)
+			continue
+		}
+		if entry.statusCounts[analyzers.PolicyPassed] > 0 {
+			passed++
+		}
+		_ = s.closePolicyFile(entry)
+		delete(s.policies, policyName)
+	}
+	s.order = kept
+	return passed
+}
+
+// ForEachViolation calls f for each violation of the policy, by order of arrival.
+func (s *Store) ForEachViolation(policyName string, f func(violation scheme.Violation) error) error {
+	entry := s.policies[policyName]
+	if !s.Spilled() {
+		for _, violation := range entry.violations {
+			if err := f(violation); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if entry.path == "" {
+		return nil // no violations were written
+	}
+	if entry.writer != nil {
+		if err := entry.writer.Flush(); err != nil {
+			return err
+		}
+	}
+	file, err := os.Open(entry.path)
+	if err != nil {
+		return fmt.Errorf("failed to read spill file: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		violation, err := scheme.UnmarshalViolation(line)
+		if err != nil {
+			return fmt.Errorf("failed to read spilled violation: %v", err)
+		}
+		if err := f(*violation); err != nil {
+			return err
+		}
+	}
+}
+
+// Flattened returns the results as a Flattened scheme (i.e. loads all the violations to memory).
+func (s *Store) Flattened() (*scheme.Flattened, error) {
+	flattened := scheme.NewFlattenedScheme()
+	for _, policyName := range s.order {
+		outputData := scheme.NewOutputData(s.PolicyInfo(policyName))
+		err := s.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			outputData = scheme.AppendViolations(outputData, violation)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		flattened.AsOrderedMap().Set(policyName, outputData)
+	}
+	return flattened, nil
+}
+
+// Close removes the spill files
+func (s *Store) Close() error {
+	if !s.Spilled() {
+		return nil
+	}
+	for entry := range s.open {
+		_ = s.closePolicyFile(entry)
+	}
+	return os.RemoveAll(s.dir)
+}
@@ -0,0 +1,192 @@
+package spill
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"testing"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/common/severity"
+	"github.com/Legit-Labs/legitify/internal/outputer/formatter"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+	"github.com/iancoleman/orderedmap"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	syntheticPolicies            = 5
+	syntheticViolationsPerPolicy = 2000
+)
+
+var syntheticSeverities = []severity.Severity{severity.Low, severity.Critical, severity.Medium, severity.High, severity.Low}
+
+func syntheticStore(t *testing.T, budget int64) *Store {
+	store := NewStore(budget)
+	for i := 0; i < syntheticViolationsPerPolicy; i++ {
+		for p := 0; p < syntheticPolicies; p++ {
+			policyName := fmt.Sprintf("data.repository.policy_%d", p)
+			info := scheme.PolicyInfo{
+				Title:                    fmt.Sprintf("Policy %d", p),
+				PolicyName:               fmt.Sprintf("policy_%d", p),
+				FullyQualifiedPolicyName: policyName,
+				Severity:                 syntheticSeverities[p],
+				Namespace:                namespace.Repository,
+			}
+			status := analyzers.PolicyPassed
+			if i%2 == 0 {
+				status = analyzers.PolicyFailed
+			}
+			violation := scheme.Violation{
+				ViolationEntityType: namespace.Repository,
+				CanonicalLink:       fmt.Sprintf("https://github.com/org/repo-%d", i),
+				Aux:                 orderedmap.New(),
+				Status:              status,
+			}
+			require.Nil(t, store.Add(policyName, info, violation))
+		}
+	}
+	return store
+}
+
+func countViolations(s *scheme.Flattened) int {
+	count := 0
+	for _, policyName := range s.AsOrderedMap().Keys() {
+		count += len(s.GetPolicyData(policyName).Violations)
+	}
+	return count
+}
+
+func TestStoreInMemory(t *testing.T) {
+	store := syntheticStore(t, 1<<30)
+	defer store.Close()
+
+	require.False(t, store.Spilled())
+	flattened, err := store.Flattened()
+	require.Nil(t, err)
+	require.Equal(t, syntheticPolicies*syntheticViolationsPerPolicy, countViolations(flattened))
+}
+
+func TestStoreSpillsLargeResults(t *testing.T) {
+	store := syntheticStore(t, 64*1024)
+	require.True(t, store.Spilled())
+
+	policies := store.Policies()
+	require.Len(t, policies, syntheticPolicies)
+	require.Equal(t, severity.Critical, store.PolicyInfo(policies[0]).Severity, "expecting policies to be sorted by severity")
+
+	for _, policyName := range policies {
+		require.Equal(t, syntheticViolationsPerPolicy/2, store.StatusCount(policyName, analyzers.PolicyFailed))
+
+		count := 0
+		err := store.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			count++
+			return nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, syntheticViolationsPerPolicy, count)
+	}
+
+	dir := store.dir
+	require.Nil(t, store.Close())
+	require.NoDirExists(t, dir)
+}
+
+func TestStreamedJsonMatchesInMemoryFormat(t *testing.T) {
+	store := syntheticStore(t, 64*1024)
+	defer store.Close()
+	require.True(t, store.Spilled())
+
+	for _, failedOnly := range []bool{true, false} {
+		var streamed bytes.Buffer
+		err := formatter.FormatStream(formatter.Json, formatter.Options{}, store, failedOnly, &streamed)
+		require.Nil(t, err)
+
+		parsed, err := scheme.Unmarshal(streamed.Bytes())
+		require.Nilf(t, err, "streamed output is not a valid flattened scheme: %v", err)
+
+		flattened, err := store.Flattened()
+		require.Nil(t, err)
+		expected := flattened.SortedBySeverity()
+		if failedOnly {
+			expected = expected.OnlyFailedViolations()
+		}
+
+		require.Equal(t, expected.AsOrderedMap().Keys(), parsed.AsOrderedMap().Keys())
+		require.Equal(t, countViolations(expected), countViolations(parsed))
+	}
+}
+
+func TestStreamedCsv(t *testing.T) {
+	store := syntheticStore(t, 64*1024)
+	defer store.Close()
+
+	var streamed bytes.Buffer
+	err := formatter.FormatStream(formatter.Csv, formatter.Options{}, store, false, &streamed)
+	require.Nil(t, err)
+
+	reader := csv.NewReader(&streamed)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.Nil(t, err)
+
+	// summary header + policies, failed header + policies, trailing separator
+	require.Len(t, records, 2*(syntheticPolicies+1)+1)
+}
+
+func TestStreamedSeveritySummaryMatchesInMemoryFormat(t *testing.T) {
+	store := syntheticStore(t, 64*1024)
+	defer store.Close()
+	require.True(t, store.Spilled())
+
+	var streamed bytes.Buffer
+	require.Nil(t, formatter.FormatStream(formatter.SeveritySummary, formatter.Options{}, store, false, &streamed))
+
+	flattened, err := store.Flattened()
+	require.Nil(t, err)
+	expected, err := formatter.Format(formatter.SeveritySummary, formatter.Options{}, flattened.SortedBySeverity(), false)
+	require.Nil(t, err)
+	require.Equal(t, string(expected), streamed.String())
+}
+
+func TestStreamingSupport(t *testing.T) {
+	require.True(t, formatter.SupportsStreaming(formatter.Json))
+	require.True(t, formatter.SupportsStreaming(formatter.Csv))
+	require.True(t, formatter.SupportsStreaming(formatter.SeveritySummary))
+	require.False(t, formatter.SupportsStreaming(formatter.Human))
+}
+
+func TestStoreLimitsOpenFiles(t *testing.T) {
+	const policies = 3 * MaxOpenFiles
+	const violationsPerPolicy = 20
+
+	store := NewStore(1024)
+	defer store.Close()
+	for i := 0; i < violationsPerPolicy; i++ {
+		for p := 0; p < policies; p++ {
+			policyName := fmt.Sprintf("data.repository.policy_%d", p)
+			violation := scheme.Violation{
+				CanonicalLink: fmt.Sprintf("https://github.com/org/repo-%d", i),
+				Aux:           orderedmap.New(),
+				Status:        analyzers.PolicyFailed,
+			}
+			require.Nil(t, store.Add(policyName, scheme.PolicyInfo{FullyQualifiedPolicyName: policyName}, violation))
+			require.LessOrEqual(t, len(store.open), MaxOpenFiles)
+		}
+	}
+	require.True(t, store.Spilled())
+
+	for _, policyName := range store.Policies() {
+		var links []string
+		err := store.ForEachViolation(policyName, func(violation scheme.Violation) error {
+			links = append(links, violation.CanonicalLink)
+			return nil
+		})
+		require.Nil(t, err)
+		require.Len(t, links, violationsPerPolicy, "expecting the violations written before the file was closed")
+		for i, link := range links {
+			require.Equal(t, fmt.Sprintf("https://github.com/org/repo-%d", i), link, "expecting the order of arrival")
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package outputer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Legit-Labs/legitify/internal/analyzers"
+	"github.com/Legit-Labs/legitify/internal/common/group_waiter"
+	"github.com/Legit-Labs/legitify/internal/context_utils"
+	"github.com/Legit-Labs/legitify/internal/enricher"
+	"github.com/Legit-Labs/legitify/internal/outputer/scheme"
+)
+
+// StreamRecord is a single line of the streamed (NDJSON) results
+type StreamRecord struct {
+	PolicyInfo scheme.PolicyInfo `json:"policyInfo"`
+	Violation  scheme.Violation  `json:"violation"`
+}
+
+// Stream writes each result as a json line as soon as it is received, instead of keeping the results in memory.
+// Since the results are not aggregated, they are neither sorted nor grouped by a scheme.
+func Stream(ctx context.Context, inputChannel <-chan enricher.EnrichedData, writer io.Writer, failedOnly bool) error {
+	return stream(ctx, inputChannel, writer, failedOnly, &FailureSummary{})
+}
+
+// stream is Stream, counting the failed violations in failures
+func stream(ctx context.Context, inputChannel <-chan enricher.EnrichedData, writer io.Writer, failedOnly bool, failures *FailureSummary) error {
+	publicSeverityBump := context_utils.GetPublicSeverityBump(ctx)
+	profile := scheme.GetProfile(context_utils.GetOutputProfile(ctx))
+	catalog := context_utils.GetMessageCatalog(ctx)
+	baseline := context_utils.GetBaseline(ctx)
+	encoder := json.NewEncoder(writer)
+
+	var err error
+	for enrichedData := range inputChannel {
+		if err != nil {
+			continue // drain the channel to avoid blocking the pipeline
+		}
+		if failedOnly && enrichedData.Status != analyzers.PolicyFailed {
+			continue
+		}
+
+		_, policyInfo := policyOf(enrichedData, publicSeverityBump, catalog)
+		if !profile.KeepsPolicy(policyInfo) {
+			continue
+		}
+
+		err = encoder.Encode(StreamRecord{
+			PolicyInfo: policyInfo,
+			Violation:  failures.withBaseline(profile.Violation(enrichedDataToViolation(enrichedData)), baseline),
+		})
+	}
+
+	return err
+}
+
+// DefaultStreamBuffer is the number of results the stream output may lag behind the pipeline
+const DefaultStreamBuffer = 1024
+
+// NewStreamOutputer returns an outputer that writes each result to the writer as a json line (see Stream)
+// while the scan is still running. The writer may lag behind the pipeline by at most bufferSize results:
+// beyond that, the pipeline (and therefore the collection) is blocked until the writer catches up,
+// so a slow writer does not accumulate the results in memory.
+// Sinks are not supported since they require the complete results.
+func NewStreamOutputer(ctx context.Context, writer io.Writer, failedOnly bool, bufferSize int) Outputer {
+	return &streamOutputer{
+		ctx:        ctx,
+		writer:     writer,
+		failedOnly: failedOnly,
+		bufferSize: bufferSize,
+	}
+}
+
+type streamOutputer struct {
+	ctx        context.Context
+	writer     io.Writer
+	failedOnly bool
+	bufferSize int
+	failures   FailureSummary
+	err        error
+}
+
+func (o *streamOutputer) Digest(inputChannel <-chan enricher.EnrichedData) group_waiter.Waitable {
+	buffered := make(chan enricher.EnrichedData, o.bufferSize)
+	go func() {
+		defer close(buffered)
+		for enrichedData := range inputChannel {
+			buffered <- enrichedData
+		}
+	}()
+
+	gw := group_waiter.New()
+	gw.Do(func() {
+		o.failures = FailureSummary{}
+		o.err = stream(o.ctx, buffered, o.writer, o.failedOnly, &o.failures)
+	})
+	return gw
+}
+
+// Output reports the error of the stream; the results were already written to the writer of the outputer
+func (o *streamOutputer) Output(writer io.Writer) error {
+	if o.err != nil {
+		return fmt.Errorf("failed to stream the results: %v", o.err)
+	}
+	return nil
+}
+
+func (o *streamOutputer) Failures() FailureSummary {
+	return o.failures
+}
@@ -0,0 +1,83 @@
+package protection
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/Legit-Labs/legitify/internal/collected"
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+)
+
+// Record is the effective protection of the default branch of a repository.
+// Protection is nil when it was not collected (e.g. the repository has no branches, or the protection sources
+// could not be read); every protection is reported as not enforced when the repository has no protection at all.
+type Record struct {
+	Name          string                               `json:"name"`
+	Link          string                               `json:"link"`
+	DefaultBranch string                               `json:"default_branch,omitempty"`
+	Protection    *githubcollected.EffectiveProtection `json:"protection"`
+}
+
+// Report records the effective protection of the collected repositories of a scan (see --protection-report-file).
+// A nil report records nothing, so it can be passed down whether or not the report is written.
+type Report struct {
+	lock    sync.Mutex
+	writer  io.Writer
+	records []Record
+}
+
+// NewReport starts recording the protection of the collected repositories; the report is written to the writer by Flush
+func NewReport(writer io.Writer) *Report {
+	return &Report{
+		writer: writer,
+	}
+}
+
+// Add records the effective protection of the entity, when it is a GitHub repository
+func (r *Report) Add(entity collected.Entity) {
+	repository, ok := entity.(githubcollected.Repository)
+	if !ok || r == nil {
+		return
+	}
+
+	record := Record{
+		Name:       repository.Name(),
+		Link:       repository.CanonicalLink(),
+		Protection: repository.EffectiveProtection,
+	}
+	if branch := repository.Repository.DefaultBranchRef; branch != nil && branch.Name != nil {
+		record.DefaultBranch = *branch.Name
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.records = append(r.records, record)
+}
+
+// Flush writes the report (sorted by link) to the output
+func (r *Report) Flush() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	records := r.records
+	if records == nil {
+		records = []Record{}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Link < records[j].Link
+	})
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err == nil {
+		_, err = r.writer.Write(append(data, '\n'))
+	}
+	if err != nil {
+		log.Printf("failed to write the protection report: %v", err)
+	}
+}
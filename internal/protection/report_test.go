@@ -0,0 +1,59 @@
+package protection
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func makeRepository(name string, protection *githubcollected.EffectiveProtection) githubcollected.Repository {
+	return githubcollected.Repository{
+		Repository: &githubcollected.GitHubQLRepository{
+			Name:             name,
+			Url:              "https://github.com/org/" + name,
+			DefaultBranchRef: &githubcollected.GitHubQLBranch{Name: github.String("main")},
+		},
+		EffectiveProtection: protection,
+	}
+}
+
+func TestProtectionReport(t *testing.T) {
+	var buf bytes.Buffer
+	report := NewReport(&buf)
+
+	protected := &githubcollected.EffectiveProtection{RequiredApprovingReviews: 2}
+	protected.RequiredReviews.Enforce(githubcollected.ProtectionSource{Type: githubcollected.ProtectionSourceBranchProtection})
+	protected.RequiredReviews.Enforce(githubcollected.NewRulesetProtectionSource("Organization", "main branch"))
+
+	report.Add(makeRepository("b", protected))
+	report.Add(makeRepository("a", nil))
+	report.Add(gitlab_collected.Repository{Project: &gitlab.Project{}}) // not a github repository
+	report.Flush()
+
+	var records []Record
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &records))
+	require.Len(t, records, 2)
+	require.Equal(t, "a", records[0].Name)
+	require.Nil(t, records[0].Protection)
+
+	require.Equal(t, "b", records[1].Name)
+	require.Equal(t, "main", records[1].DefaultBranch)
+	require.True(t, records[1].Protection.RequiredReviews.Enforced)
+	require.Equal(t, []githubcollected.ProtectionSource{
+		{Type: "branch_protection"},
+		{Type: "organization_ruleset", Name: "main branch"},
+	}, records[1].Protection.RequiredReviews.Sources)
+	require.False(t, records[1].Protection.RequiredSignatures.Enforced)
+}
+
+func TestProtectionReportDisabled(t *testing.T) {
+	var report *Report
+	report.Add(makeRepository("a", nil))
+	report.Flush()
+}
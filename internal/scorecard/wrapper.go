@@ -3,11 +3,13 @@ package scorecard
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 
-	"github.com/ossf/scorecard/v4/checker"
+	"github.com/ossf/scorecard/v4/clients"
+	"github.com/ossf/scorecard/v4/clients/githubrepo"
+	"github.com/ossf/scorecard/v4/clients/ossfuzz"
 	docs "github.com/ossf/scorecard/v4/docs/checks"
-	sclog "github.com/ossf/scorecard/v4/log"
 	"github.com/ossf/scorecard/v4/pkg"
 	"github.com/ossf/scorecard/v4/policy"
 )
@@ -22,13 +24,49 @@ type Result struct {
 	Result pkg.ScorecardResult `json:"result"`
 }
 
-func Calculate(ctx context.Context, repoUrl string, isPrivate bool) (*Result, error) {
-	logger := sclog.NewLogger(sclog.DebugLevel)
-	repo, repoClient, fuzzClient, ciiClient, vulnClient, err := checker.GetClients(ctx, repoUrl, "", logger)
+// DefaultConcurrency is the number of repositories whose scorecard is calculated at the same time
+const DefaultConcurrency = 2
 
+// Runner calculates the scorecard of repositories through the given transport (the transport of the collectors' client),
+// so the scorecard checks share the tokens and rate limit state of the collection.
+// The number of concurrent calculations is limited, since each one makes many API calls and could starve the collection.
+type Runner struct {
+	transport http.RoundTripper
+	slots     chan struct{}
+	calculate func(ctx context.Context, repoUrl string, isPrivate bool, transport http.RoundTripper) (*Result, error)
+}
+
+func NewRunner(transport http.RoundTripper, concurrency int) *Runner {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	return &Runner{
+		transport: transport,
+		slots:     make(chan struct{}, concurrency),
+		calculate: calculate,
+	}
+}
+
+func (r *Runner) Calculate(ctx context.Context, repoUrl string, isPrivate bool) (*Result, error) {
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-r.slots }()
+
+	return r.calculate(ctx, repoUrl, isPrivate, r.transport)
+}
+
+func calculate(ctx context.Context, repoUrl string, isPrivate bool, transport http.RoundTripper) (*Result, error) {
+	repo, err := githubrepo.MakeGithubRepo(repoUrl)
 	if err != nil {
 		return nil, err
 	}
+	repoClient := githubrepo.CreateGithubRepoClientWithTransport(ctx, transport)
+	fuzzClient := ossfuzz.CreateOSSFuzzClient(ossfuzz.StatusURL)
+	ciiClient := clients.DefaultCIIBestPracticesClient()
+	vulnClient := clients.DefaultVulnerabilitiesClient()
 
 	defer func() {
 		err = repoClient.Close()
@@ -0,0 +1,84 @@
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunnerLimitsConcurrency(t *testing.T) {
+	const concurrency = 2
+	const calculations = 6
+
+	var running, maxRunning int32
+	release := make(chan struct{})
+	runner := NewRunner(http.DefaultTransport, concurrency)
+	runner.calculate = func(ctx context.Context, repoUrl string, isPrivate bool, transport http.RoundTripper) (*Result, error) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			highest := atomic.LoadInt32(&maxRunning)
+			if current <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, current) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return &Result{Score: 10}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < calculations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := runner.Calculate(context.Background(), "github.com/org/repo", false); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+
+	// wait until the available slots are taken before releasing the calculations
+	for atomic.LoadInt32(&running) < concurrency {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if maxRunning != concurrency {
+		t.Fatalf("expected at most %d concurrent calculations, got %d", concurrency, maxRunning)
+	}
+}
+
+func TestRunnerDefaultConcurrency(t *testing.T) {
+	runner := NewRunner(http.DefaultTransport, 0)
+	if cap(runner.slots) != DefaultConcurrency {
+		t.Fatalf("expected the default concurrency %d, got %d", DefaultConcurrency, cap(runner.slots))
+	}
+}
+
+func TestRunnerCanceledWhileWaiting(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	runner := NewRunner(http.DefaultTransport, 1)
+	runner.calculate = func(ctx context.Context, repoUrl string, isPrivate bool, transport http.RoundTripper) (*Result, error) {
+		<-release
+		return &Result{}, nil
+	}
+
+	go func() {
+		_, _ = runner.Calculate(context.Background(), "github.com/org/busy", false)
+	}()
+	for len(runner.slots) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := runner.Calculate(ctx, "github.com/org/repo", false); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the calculation to be canceled, got %v", err)
+	}
+}
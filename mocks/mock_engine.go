@@ -93,6 +93,18 @@ func (mr *MockEnginerMockRecorder) Query(ctx, namespace, input interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockEnginer)(nil).Query), ctx, namespace, input)
 }
 
+// SetConfig mocks base method.
+func (m *MockEnginer) SetConfig(config map[string]interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConfig", config)
+}
+
+// SetConfig indicates an expected call of SetConfig.
+func (mr *MockEnginerMockRecorder) SetConfig(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConfig", reflect.TypeOf((*MockEnginer)(nil).SetConfig), config)
+}
+
 // SetTracing mocks base method.
 func (m *MockEnginer) SetTracing(enabled bool) {
 	m.ctrl.T.Helper()
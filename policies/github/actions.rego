@@ -98,3 +98,25 @@ default actions_can_approve_pull_requests := true
 actions_can_approve_pull_requests := false {
 	not input.token_permissions.can_approve_pull_request_reviews
 }
+
+# METADATA
+# scope: rule
+# title: Workflows Of Fork Pull Requests From External Contributors Should Require Approval
+# description: Your organization does not require approval to run the workflows of pull requests from forks of all external contributors. Workflows of fork pull requests run code that is controlled by the contributor, so it is recommended to require a maintainer approval before running them.
+# custom:
+#   requiredEnrichers: [organizationId]
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the org's settings page
+#     - 3. Enter 'Actions - General' tab
+#     - 4. Under 'Approval for running fork pull request workflows from contributors'
+#     - 5. Select 'Require approval for all external contributors'
+#     - 6. Click 'Save'
+#   severity: MEDIUM
+#   requiredScopes: [admin:org]
+#   threat: An attacker can open a pull request from a fork with a modified workflow, which runs automatically in your CI/CD pipeline without anyone reviewing it, and abuse it to exfiltrate data, consume your runners or compromise self-hosted runners.
+default fork_pr_workflows_do_not_require_approval := false
+
+fork_pr_workflows_do_not_require_approval := true {
+	input.fork_pr_approval.approval_policy != "all_external_contributors"
+}
@@ -10,3 +10,46 @@ ssl_enabled(hook) {
 has_secret(hook) {
     "secret" in object.keys(hook.config)
 }
+
+# events that expose (or allow to infer) the organization members, permissions and security findings
+sensitive_events := {
+    "*",
+    "code_scanning_alert",
+    "dependabot_alert",
+    "deploy_key",
+    "member",
+    "membership",
+    "organization",
+    "repository",
+    "repository_vulnerability_alert",
+    "secret_scanning_alert",
+    "security_advisory",
+    "team",
+    "team_add",
+}
+
+subscribed_sensitive_events(hook) := {event | some event in hook.events; event in sensitive_events}
+
+events_list(hook) := concat(",", sort(hook.events)) {
+    is_array(hook.events)
+} else := ""
+
+# the URL the payloads are delivered to (hook.url is the API URL of the hook itself)
+payload_url(hook) := hook.config.url
+
+url_host(url) := lower(regex.find_all_string_submatch_n("^[a-zA-Z][a-zA-Z0-9+.-]*://([^/:?#]+)", url, 1)[0][1])
+
+# a payload URL is trusted when its host is one of data.config.trusted_webhook_domains or a subdomain of one
+trusted_url(url) {
+    host := url_host(url)
+    some index
+    domain := lower(data.config.trusted_webhook_domains[index])
+    host == domain
+}
+
+trusted_url(url) {
+    host := url_host(url)
+    some index
+    domain := lower(data.config.trusted_webhook_domains[index])
+    endswith(host, concat("", [".", domain]))
+}
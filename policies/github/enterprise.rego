@@ -183,6 +183,7 @@ enable_ghas_for_new_orgs := false {
 # description: Enable GitHub Advanced Security secret scanning to alert on sensitive data that exists in your enterprise. Secrets shouldn’t be hard-coded in to your repositories as they will be retrievable by anyone with access to the repository.
 # custom:
 #   severity: MEDIUM
+#   category: secrets
 #   remediationSteps:
 #     - 1. Make sure you are an enterprise owner
 #     - 2. Go to the Enterprise Settings page
@@ -201,6 +202,7 @@ enable_secret_scanning_for_new_orgs := false {
 # description: The enterprise should prevent sensitive data from being pushed to all repositories, to prevent it from being exposed to anyone with access to the repository.
 # custom:
 #   severity: MEDIUM
+#   category: secrets
 #   remediationSteps:
 #     - 1. Make sure you are an enterprise owner
 #     - 2. Go to the Enterprise Settings page
@@ -4,7 +4,7 @@ import data.common.members as memberUtils
 # METADATA
 # scope: rule
 # title: Organization Should Have Fewer Than Three Owners
-# description: Organization owners are highly privileged and could create great damage if they are compromised. It is recommended to limit the number of Organizational Admins to the minimum needed (recommended maximum 3 owners).
+# description: Organization owners are highly privileged and could create great damage if they are compromised. It is recommended to limit the number of Organizational Admins to the minimum needed (3 owners by default, configurable as max_organization_admins).
 # custom:
 #   remediationSteps:
 #     - 1. Make sure you have admin permissions
@@ -21,7 +21,7 @@ default organization_has_too_many_admins := true
 
 organization_has_too_many_admins := false {
 	admins := count([member | member := input.members[_]; member.is_admin == true])
-	admins <= 3
+	admins <= data.config.max_organization_admins
 }
 
 # METADATA
@@ -163,6 +163,7 @@ organization_not_using_single_sign_on := false {
 #      - 5. Sort secrets by 'Last Updated'
 #      - 6. Regenerate every secret older than one year and add the new value to GitHub's secret manager
 #   severity: MEDIUM
+#   category: secrets
 #   requiredScopes: [admin:org, repo]
 #   threat: Sensitive data may have been inadvertently made public in the past, and an attacker who holds this data may gain access to your current CI and services. In addition, there may be old or unnecessary tokens that have not been inspected and can be used to access sensitive information.
 organization_secret_is_stale[stale] := true{
@@ -174,3 +175,292 @@ organization_secret_is_stale[stale] := true{
     "update date" : time.format(secret.updated_at),
     }
 }
+
+# METADATA
+# scope: rule
+# title: Organization Should Use SSH Certificate Authorities
+# description: The organization has no SSH certificate authority configured, so members access its repositories over SSH using long-lived SSH keys. An SSH certificate authority issues short-lived certificates to members, which makes it easier to control and revoke SSH access centrally.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Under the 'Security' title on the left, choose 'Authentication security'
+#     - 4. Under 'SSH certificate authorities', click 'New CA'
+#     - 5. Paste the public key of your certificate authority and click 'Add CA'
+#   requiredScopes: [admin:org]
+#   threat:
+#     - Long-lived SSH keys are rarely rotated and remain valid after they leak (e.g. from a lost laptop), allowing an attacker to access the organization's repositories until the key is manually revoked.
+default organization_not_using_ssh_certificate_authorities := false
+
+organization_not_using_ssh_certificate_authorities := true {
+	is_array(input.ssh_certificate_authorities)
+	count(input.ssh_certificate_authorities) == 0
+}
+
+# METADATA
+# scope: rule
+# title: Members Should Not Be Allowed To Fork Private Repositories
+# description: The organization allows members to fork its private repositories. Forks are owned by the members, so the organization loses control over the code once it is forked (e.g. when the member leaves the organization).
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Enter 'Member privileges' tab
+#     - 4. Under 'Repository forking', uncheck 'Allow forking of private repositories'
+#     - 5. Click 'Save'
+#   requiredScopes: [admin:org]
+#   threat:
+#     - A member could fork private repositories to a personal account and keep access to the code after leaving the organization, or inadvertently expose it.
+default members_can_fork_private_repositories := false
+
+members_can_fork_private_repositories := true {
+	input.member_privileges.members_can_fork_private_repositories == true
+}
+
+# METADATA
+# scope: rule
+# title: Critical Repositories Should Run A Required Workflow
+# description: Some of the critical repositories (configured as critical_repositories) are not covered by an active required workflow of the organization. Required workflows make sure a mandated workflow (e.g. a security scan) runs on every pull request of the repositories they apply to, and cannot be skipped by the repository maintainers.
+# custom:
+#   requiredEnrichers: [repositoriesList]
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Under the 'Code, planning, and automation' title on the left, choose 'Actions' -> 'General'
+#     - 4. Under 'Required workflows', click 'Add workflow' or edit an existing workflow
+#     - 5. Select the security workflow and add the critical repositories to the selected repositories (or apply it to all repositories)
+#   severity: MEDIUM
+#   requiredScopes: [admin:org]
+#   prerequisites: [premium]
+#   threat: A maintainer (or an attacker who compromised one) can remove or modify the security workflow of a critical repository, allowing vulnerable or malicious code to be merged without the checks mandated by the organization.
+critical_repository_missing_required_workflow[violated] := true {
+	is_array(input.required_workflows)
+	some index
+	repository := data.config.critical_repositories[index]
+	not covered_by_required_workflow(repository)
+	violated := {
+		"name": repository,
+	}
+}
+
+covered_by_required_workflow(_) {
+	some index
+	workflow := input.required_workflows[index]
+	workflow.state == "active"
+	workflow.scope == "all"
+}
+
+covered_by_required_workflow(repository) {
+	some index
+	workflow := input.required_workflows[index]
+	workflow.state == "active"
+	workflow.selected_repositories[_] == repository
+}
+
+# METADATA
+# scope: rule
+# title: Classic Personal Access Tokens Without Expiration Should Not Be Authorized For The Organization
+# description: Classic personal access tokens that never expire are authorized for SAML single sign-on to access the organization. Classic tokens are granted to all the organizations and repositories their owner can access, and cannot be scoped down to specific repositories. Note that GitHub does not expose the personal access token settings of the organization, so this policy only covers the tokens that were authorized for single sign-on.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Under the 'Security' title on the left, choose 'Authentication security'
+#     - 4. Revoke the SAML authorization of the tokens without expiration, and ask their owners to replace them with tokens that expire (preferably fine-grained tokens)
+#   severity: MEDIUM
+#   requiredScopes: [admin:org]
+#   prerequisites: [premium]
+#   threat: A leaked classic token never expires and grants access to every repository of the organization its owner can access, allowing an attacker to persist in the organization until the token is revoked manually.
+organization_has_classic_tokens_without_expiration[violated] := true {
+	is_array(input.classic_tokens)
+	some index
+	token := input.classic_tokens[index]
+	not token.expires_at
+	violated := {
+		"name": token.title,
+		"login": token.login,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Organization Members Should Link Their SSO Identity
+# description: Some members of the organization have not linked their SAML single sign-on identity. Although SAML single sign-on is enabled, the access of these members is not governed by the identity provider, so deprovisioning a user in the identity provider does not revoke their access to the organization.
+# custom:
+#   requiredEnrichers: [usersList]
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Under the 'Security' title on the left, choose 'Authentication security'
+#     - 4. Check 'Require SAML SSO authentication for all members of the organization', which removes the members that have not linked their identity
+#     - 5. Alternatively, ask the listed members to link their identity, or remove them from the organization
+#   severity: HIGH
+#   requiredScopes: [admin:org]
+#   prerequisites: [premium]
+#   threat: A member whose access is not tied to the identity provider keeps their access after leaving the company or being disabled in the identity provider, and is not subject to its authentication policies (e.g. MFA), so a compromised account can access the organization's repositories unnoticed.
+organization_members_not_linked_to_sso[violated] := true {
+	is_array(input.members_without_sso)
+	some index
+	violated := {"login": input.members_without_sso[index]}
+}
+
+# METADATA
+# scope: rule
+# title: Organization Should Not Have Too Many Outside Collaborators
+# description: The organization has more outside collaborators than the allowed threshold (10 by default, configurable as max_outside_collaborators). Outside collaborators are not members of the organization, so they are not subject to its SSO and membership policies, and their access is granted repository by repository, which makes it hard to review.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization 'People' page
+#     - 3. Select 'Outside collaborators' and remove the collaborators that no longer need access, or invite them to become members
+#     - 4. Restrict inviting outside collaborators to owners ('Member privileges' -> 'Repository outside collaborators', or the enterprise policy)
+#   severity: LOW
+#   requiredScopes: [read:org]
+#   threat: Outside collaborators keep their access until it is removed from each repository. An attacker who compromises the account of a former contractor can access the repositories they were invited to, without being subject to the organization's authentication requirements.
+default organization_has_too_many_outside_collaborators := false
+
+organization_has_too_many_outside_collaborators := true {
+	input.outside_collaborators_count > data.config.max_outside_collaborators
+}
+
+# METADATA
+# scope: rule
+# title: Pending Organization Invitations Should Not Be Left Open
+# description: Invitations to join the organization have been pending for longer than the allowed period (30 days by default, configurable as max_invitation_age_days). Invitations that are left open may be accepted long after the invitee's need for access has expired, or by whoever gains control of the invited account or email address.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization 'People' page
+#     - 3. Select 'Invitations' and cancel the invitations that are no longer needed
+#   severity: LOW
+#   requiredScopes: [admin:org]
+#   threat: An attacker who takes over an invited account or email address can accept a stale invitation and gain access to the organization's repositories, without anyone re-evaluating whether that access is still needed.
+organization_invitation_is_stale[violated] := true {
+	is_array(input.invitations)
+	some index
+	invitation := input.invitations[index]
+	time.now_ns() - invitation.created_at > ((data.config.max_invitation_age_days * 24) * 3600) * 1000000000
+	violated := {
+		"name": invitation.invitee,
+		"inviter": invitation.inviter,
+		"role": invitation.role,
+		"creation date": time.format(invitation.created_at),
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Pending Organization Invitations Should Not Grant The Owner Role
+# description: Users have been invited to join the organization as owners. Owners have full administrative access to the organization and all of its repositories, so granting the owner role should be a deliberate decision made after the user has joined, rather than part of an invitation that may be accepted by whoever controls the invited account or email address.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization 'People' page
+#     - 3. Select 'Invitations', cancel the owner invitations and re-invite the users as members
+#   severity: MEDIUM
+#   requiredScopes: [admin:org]
+#   threat: An attacker who takes over an invited account or email address can accept the invitation and immediately gain full administrative control over the organization.
+organization_invitation_grants_owner_role[violated] := true {
+	is_array(input.invitations)
+	some index
+	invitation := input.invitations[index]
+	invitation.role == "admin"
+	violated := {
+		"name": invitation.invitee,
+		"inviter": invitation.inviter,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Packages Of Non-Public Repositories Should Not Be Public
+# description: The organization has public packages that are linked to private or internal repositories. The artifacts built from non-public code, such as container images and libraries, are usually internal too, and publishing them publicly exposes their content (e.g. source code, configuration and embedded credentials) to anyone.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions on the package
+#     - 2. Go to the organization 'Packages' page and select the package
+#     - 3. Select 'Package settings'
+#     - 4. Under 'Danger Zone', select 'Change visibility' and make the package private or internal
+#   severity: MEDIUM
+#   requiredScopes: [read:packages]
+#   threat: Anyone can download the public packages of the organization. An attacker can extract internal code, configuration or credentials from an artifact that was meant to be internal, and use them to attack the organization.
+organization_has_public_packages_of_non_public_repositories[violated] := true {
+	is_array(input.packages)
+	some index
+	pkg := input.packages[index]
+	pkg.visibility == "public"
+	pkg.repository_visibility != "public"
+	violated := {
+		"name": pkg.name,
+		"type": pkg.package_type,
+		"repository": pkg.repository,
+		"link": pkg.link,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Self-Hosted Runners Should Not Be Available To Public Repositories
+# description: The organization has runner groups that allow public repositories to use their self-hosted runners. Anyone can open a pull request to a public repository, so the workflows that run on these runners may execute code that was written by outside contributors.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Select Actions ➝ Runner groups and press on the violating runner group
+#     - 4. Uncheck 'Allow public repositories'
+#   severity: HIGH
+#   requiredScopes: [admin:org]
+#   threat: Self-hosted runners are usually part of the organization's private network and are not ephemeral. An attacker can open a pull request to a public repository with a workflow that runs on a self-hosted runner, and use it to persist on the runner, steal the credentials of other workflows, or move laterally inside the network.
+organization_runner_group_allows_public_repositories[violated] := true {
+	is_array(input.runner_groups)
+	some index
+	group := input.runner_groups[index]
+	group.allows_public_repositories
+	count(group.runners) > 0
+	violated := {
+		"name": group.name,
+		"visibility": group.visibility,
+		"runners": group.runners,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Organization Should Protect The Default Branches With A Ruleset
+# description: The organization has no active ruleset that targets the default branches of its repositories. An organization ruleset applies the same protection (e.g. required reviews and status checks) to every repository it targets, and cannot be weakened by the repository administrators.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Under 'Code, planning, and automation', select 'Repository' -> 'Rulesets'
+#     - 4. Create a new branch ruleset and set its enforcement status to 'Active'
+#     - 5. Under 'Target branches', add the default branch and configure the required rules
+#     - 6. Press 'Create'
+#   severity: LOW
+#   requiredScopes: [admin:org]
+#   prerequisites: [premium]
+#   threat: Every repository administrator decides on the protection of their default branch, so a repository whose protection was weakened or never configured allows changes to reach production without review.
+default organization_missing_default_branch_ruleset := false
+
+organization_missing_default_branch_ruleset := true {
+	is_array(input.rulesets)
+	not has_default_branch_ruleset
+}
+
+has_default_branch_ruleset {
+	some index
+	ruleset := input.rulesets[index]
+	ruleset.enforcement == "active"
+	ruleset.target == "branch"
+	targets_default_branch(ruleset)
+}
+
+targets_default_branch(ruleset) {
+	ruleset.conditions.ref_name.include[_] == "~DEFAULT_BRANCH"
+}
+
+targets_default_branch(ruleset) {
+	ruleset.conditions.ref_name.include[_] == "~ALL"
+}
@@ -32,8 +32,9 @@ repository_not_maintained := false {
 # METADATA
 # scope: rule
 # title: Repository Should Have Fewer Than Three Admins
-# description: Repository admins are highly privileged and could create great damage if they are compromised. It is recommended to limit the number of Repository Admins to the minimum required (recommended maximum 3 admins).
+# description: Repository admins are highly privileged and could create great damage if they are compromised. It is recommended to limit the number of Repository Admins (including teams with admin permission) to the minimum required (3 admins by default, configurable as max_repository_admins).
 # custom:
+#   requiredEnrichers: [adminsList]
 #   severity: LOW
 #   remediationSteps:
 #     - 1. Make sure you have admin permissions
@@ -49,7 +50,8 @@ default repository_has_too_many_admins := true
 
 repository_has_too_many_admins := false {
 	admins := [admin | admin := input.collaborators[_]; admin.permissions.admin]
-	count(admins) <= 3
+	teams := [team | team := input.teams[_]; team.permission == "admin"]
+	count(admins) + count(teams) <= data.config.max_repository_admins
 }
 
 # METADATA
@@ -77,6 +79,7 @@ repository_webhook_no_secret[violated] := true {
 	violated := {
 		"name": hook.name,
 		"url": hook.url,
+		"events": webhookUtils.events_list(hook),
 	}
 }
 
@@ -106,6 +109,127 @@ repository_webhook_doesnt_require_ssl[violated] := true {
 	violated := {
 		"name": hook.name,
 		"url": hook.url,
+		"events": webhookUtils.events_list(hook),
+	}
+}
+
+# METADATA
+# scope: rule
+# title: GitHub Pages Should Enforce HTTPS
+# description: The GitHub Pages site of the repository can be served over plain HTTP. Without HTTPS enforcement, visitors of the site may load its content over an unencrypted connection, which can be read and modified in transit.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Select 'Pages'
+#     - 4. Check 'Enforce HTTPS'
+#   requiredScopes: [repo]
+#   threat: An attacker on the network path of a visitor (e.g. on a public Wi-Fi) can tamper with the site served over HTTP, for example to inject malicious scripts or to replace download links and installation instructions.
+default pages_https_not_enforced := false
+
+pages_https_not_enforced := true {
+	input.pages.https_enforced == false
+}
+
+# METADATA
+# scope: rule
+# title: GitHub Pages Should Be Built From A Protected Branch
+# description: The GitHub Pages site of the repository is built from a branch that is not protected. Anyone who can push to the branch can change the published site without a review.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Select 'Branches' and add a branch protection rule (or a ruleset) for the source branch of the site
+#     - 4. Alternatively, select 'Pages' and build the site with a GitHub Actions workflow that runs on a protected branch
+#   requiredScopes: [repo]
+#   threat: A user with write access, or an attacker with a compromised account or token, can push malicious content to the source branch and have it published on the organization's site immediately, e.g. to distribute malware or phishing pages from a trusted domain.
+default pages_built_from_unprotected_branch := false
+
+pages_built_from_unprotected_branch := true {
+	input.pages.build_type != "workflow"
+	input.pages.source_branch_protected == false
+}
+
+# METADATA
+# scope: rule
+# title: Deploy Keys Should Be Read-Only
+# description: The repository has deploy keys with write access. A deploy key is not tied to a user, so it is not covered by the organization's member policies (e.g. 2FA, SSO and offboarding), and a writable key can push code to the repository, including to branches that are not protected.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Select 'Deploy keys'
+#     - 4. Delete the writable keys and add them again without 'Allow write access' (or replace them with a GitHub App if write access is needed)
+#   requiredScopes: [repo]
+#   threat: Anyone who obtains the private key of a writable deploy key, e.g. from a CI server or a deployment machine, can push malicious code to the repository without authenticating as any user.
+repository_deploy_key_is_writable[violated] := true {
+	is_array(input.deploy_keys)
+	some index
+	key := input.deploy_keys[index]
+	key.read_only == false
+	violated := {
+		"title": key.title,
+		"creation date": time.format(key.created_at),
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Deploy Keys Should Be Rotated
+# description: The repository has deploy keys that are older than the allowed period (365 days by default, configurable as max_deploy_key_age_days). Deploy keys never expire, so old keys are likely to be copied to machines and pipelines that no longer need them.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Select 'Deploy keys'
+#     - 4. Delete the old keys, and add new keys where access is still needed
+#   requiredScopes: [repo]
+#   threat: A deploy key that has been in use for a long time has likely been copied to many places, so it is more likely to have leaked, and a leaked key keeps granting access to the repository until it is deleted.
+repository_deploy_key_is_stale[violated] := true {
+	is_array(input.deploy_keys)
+	some index
+	key := input.deploy_keys[index]
+	key.created_at > 0
+	time.now_ns() - key.created_at > ((data.config.max_deploy_key_age_days * 24) * 3600) * 1000000000
+	violated := {
+		"title": key.title,
+		"read only": key.read_only,
+		"creation date": time.format(key.created_at),
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Webhooks Receiving Sensitive Events Should Target Trusted Domains
+# description: Webhooks subscribed to sensitive events (e.g. member, team, organization or security alerts) deliver their payloads to a URL outside the trusted domains (configured as trusted_webhook_domains; the policy is skipped when none is configured). These payloads reveal the members, permissions and security findings of the repository.
+# custom:
+#   requiredEnrichers: [hooksList]
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you can manage webhooks for the repository
+#     - 2. Go to the repository settings page
+#     - 3. Select 'Webhooks'
+#     - 4. Press on the webhook
+#     - 5. Remove the sensitive events that are not needed by the receiving service, or delete the webhook if the endpoint is not trusted
+#     - 6. Click 'Update webhook'
+#   requiredScopes: [read:repo_hook, repo]
+#   threat: An untrusted (or compromised) endpoint receives a continuous feed of membership changes and security alerts, which helps an attacker map targets for social engineering and exploit vulnerabilities before they are fixed.
+repository_webhook_sensitive_events_untrusted_url[violated] := true {
+	count(data.config.trusted_webhook_domains) > 0
+	some index
+	hook := input.hooks[index]
+	events := webhookUtils.subscribed_sensitive_events(hook)
+	count(events) > 0
+	url := webhookUtils.payload_url(hook)
+	not webhookUtils.trusted_url(url)
+	violated := {
+		"name": hook.name,
+		"url": url,
+		"events": concat(",", sort(events)),
 	}
 }
 
@@ -129,10 +253,23 @@ forking_allowed_for_repository := false {
 	input.repository.allow_forking == false
 }
 
+# the organization does not allow forking private repositories, regardless of the repository setting
+forking_allowed_for_repository := false {
+	input.private_forking.effective == false
+}
+
+# active_rule is a rule of an active ruleset: the rules of rulesets in "evaluate" (dry-run) mode do not block anything,
+# so they are not considered protection
+active_rule[rule] {
+	some index
+	rule := input.rules_set[index]
+	rule.enforcement == "active"
+}
+
 # METADATA
 # scope: rule
 # title: Default Branch Should Be Protected
-# description: Branch protection is not enabled for this repository’s default branch. Protecting branches ensures new code changes must go through a controlled merge process and allows enforcement of code review as well as other security tests. This issue is raised if the default branch protection is turned off.
+# description: Branch protection is not enabled for this repository’s default branch. Protecting branches ensures new code changes must go through a controlled merge process and allows enforcement of code review as well as other security tests. This issue is raised if the default branch protection is turned off, or if it does not require any approving review (a protection that requires no review does not count as protected).
 # custom:
 #   remediationSteps:
 #     - 1. Make sure you have admin permissions
@@ -151,12 +288,14 @@ default missing_default_branch_protection := true
 
 missing_default_branch_protection := false {
 	not is_null(input.repository.default_branch.branch_protection_rule)
+	not input.protection_present_but_no_reviews
 }
 
 missing_default_branch_protection := false {
-    some index
-    rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
     rule.type == "pull_request"
+    not input.protection_present_but_no_reviews
 }
 
 # METADATA
@@ -183,8 +322,8 @@ missing_default_branch_protection_deletion := false {
 }
 
 missing_default_branch_protection_deletion := false {
-    some index
-    rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
     rule.type == "deletion"
 }
 
@@ -213,8 +352,8 @@ missing_default_branch_protection_force_push := false {
 }
 
 missing_default_branch_protection_force_push := false {
-    some index
-    rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
     rule.type == "non_fast_forward"
 }
 
@@ -244,8 +383,8 @@ requires_status_checks := false {
 }
 
 requires_status_checks := false {
-    some index
-    rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
     rule.type == "required_status_checks"
     count(rule.parameters.required_status_checks) > 0
 }
@@ -277,13 +416,46 @@ requires_branches_up_to_date_before_merge := false {
 }
 
 requires_branches_up_to_date_before_merge := false {
-    some index
-    rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
     rule.type == "required_status_checks"
     count(rule.parameters.required_status_checks) > 0
     rule.parameters.strict_required_status_checks_policy
 }
 
+# METADATA
+# scope: rule
+# title: Default Branch Should Require Deployments To Succeed Before Merge
+# description: Branch protection can require changes to be successfully deployed to specific environments (e.g. staging) before they can be merged into the default branch. Requiring a successful deployment makes sure the changes were verified in a pre-production environment before reaching the main branch.
+# custom:
+#   remediationSteps:
+#     - "Note: The remediation steps apply to legacy branch protections, rules set-based protection should be updated from the rules set page"
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repo's settings page
+#     - 3. Enter 'Branches' tab
+#     - 4. Under 'Branch protection rules'
+#     - 5. Click 'Edit' on the default branch rule
+#     - 6. Check 'Require deployments to succeed before merging'
+#     - 7. Select the environments that must be successfully deployed to (e.g. staging)
+#     - 8. Click 'Save changes'
+#   severity: LOW
+#   requiredScopes: [repo]
+#   prerequisites: [has_branch_protection_permission]
+#   threat: Changes that were never deployed to a pre-production environment may reach the default branch, and from there production, without being verified.
+default requires_deployments_before_merge := true
+
+requires_deployments_before_merge := false {
+	input.repository.default_branch.branch_protection_rule.requires_deployments
+	count(input.repository.default_branch.branch_protection_rule.required_deployment_environments) > 0
+}
+
+requires_deployments_before_merge := false {
+	some rule
+	active_rule[rule]
+	rule.type == "required_deployments"
+	count(rule.parameters.required_deployment_environments) > 0
+}
+
 # METADATA
 # scope: rule
 # title: Default Branch Should Require New Code Changes After Approval To Be Re-Approved
@@ -310,8 +482,8 @@ dismisses_stale_reviews := false {
 }
 
 dismisses_stale_reviews := false {
-    some index
-	rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
 	rule.type == "pull_request"
 	rule.parameters.dismiss_stale_reviews_on_push
 }
@@ -343,8 +515,8 @@ code_review_not_required := false {
 }
 
 code_review_not_required := false {
-    some index
-	rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
 	rule.type == "pull_request"
 	rule.parameters.required_approving_review_count >= 1
 }
@@ -364,7 +536,7 @@ code_review_not_required := false {
 #     - 5. Click 'Edit' on the default branch rule
 #     - 6. Check 'Require a pull request before merging'
 #     - 7. Check 'Require approvals'
-#     - 8. Set 'Required number of approvals before merging' to 2 or more
+#     - 8. Set 'Required number of approvals before merging' to the required number of approvals or more (2 by default, configurable as min_approvals)
 #     - 9. Click 'Save changes'
 #   severity: MEDIUM
 #   requiredScopes: [repo]
@@ -375,14 +547,14 @@ code_review_not_required := false {
 default code_review_by_two_members_not_required := true
 
 code_review_by_two_members_not_required := false {
-	 input.repository.default_branch.branch_protection_rule.required_approving_review_count >= 2
+	 input.repository.default_branch.branch_protection_rule.required_approving_review_count >= data.config.min_approvals
 }
 
 code_review_by_two_members_not_required := false {
-    some index
-	rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
 	rule.type == "pull_request"
-	rule.parameters.required_approving_review_count >= 2
+	rule.parameters.required_approving_review_count >= data.config.min_approvals
 }
 
 # METADATA
@@ -411,12 +583,57 @@ code_review_not_limited_to_code_owners := false {
 }
 
 code_review_not_limited_to_code_owners := false {
-    some index
-	rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
+	rule.type == "pull_request"
+	rule.parameters.require_code_owner_review
+}
+
+requires_code_owner_reviews {
+	input.repository.default_branch.branch_protection_rule.requires_code_owner_reviews
+}
+
+requires_code_owner_reviews {
+	some rule
+	active_rule[rule]
 	rule.type == "pull_request"
 	rule.parameters.require_code_owner_review
 }
 
+# codeowners_has_rules is true when the CODEOWNERS file has a line that is not blank or a comment
+codeowners_has_rules {
+	lines := split(input.codeowners.content, "\n")
+	some index
+	line := trim_space(lines[index])
+	line != ""
+	not startswith(line, "#")
+}
+
+# METADATA
+# scope: rule
+# title: Code Owners Review Should Be Backed By A CODEOWNERS File
+# description: The default branch requires a review from the code owners, but the repository has no CODEOWNERS file (in the .github directory, the root or the docs directory of the default branch), or the file does not define any owners. Without code owners, the requirement has no effect and pull requests can be approved by any reviewer with write access.
+# custom:
+#   remediationSteps:
+#     - 1. Add a CODEOWNERS file to the .github directory of the default branch
+#     - 2. Assign owners (users or teams with write access) to the paths of the repository, e.g. '* @org/maintainers'
+#     - 3. Merge the file to the default branch
+#   severity: MEDIUM
+#   requiredScopes: [repo]
+#   threat: The repository appears to restrict approvals to the code owners, while any contributor with write access can actually approve changes, including changes to sensitive code that should be reviewed by its owners.
+default code_owners_review_required_without_codeowners := false
+
+code_owners_review_required_without_codeowners := true {
+	requires_code_owner_reviews
+	input.codeowners.exists == false
+}
+
+code_owners_review_required_without_codeowners := true {
+	requires_code_owner_reviews
+	input.codeowners.exists
+	not codeowners_has_rules
+}
+
 # METADATA
 # scope: rule
 # title: Default Branch Should Require Linear History
@@ -442,8 +659,8 @@ non_linear_history := false {
 }
 
 non_linear_history := false {
-    some index
-	rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
 	rule.type == "required_linear_history"
 }
 
@@ -472,8 +689,8 @@ no_conversation_resolution := false {
 }
 
 no_conversation_resolution := false {
-    some index
-	rule := input.rules_set[index]
+    some rule
+    active_rule[rule]
 	rule.type == "pull_request"
 	rule.parameters.required_review_thread_resolution
 }
@@ -499,12 +716,19 @@ no_conversation_resolution := false {
 default no_signed_commits := true
 
 no_signed_commits := false {
+	input.required_signatures.effective
+}
+
+# the requirement of each protection source is only consolidated when the sources are collected
+no_signed_commits := false {
+	not input.required_signatures
 	input.repository.default_branch.branch_protection_rule.requires_commit_signatures
 }
 
 no_signed_commits := false {
-    some index
-	rule := input.rules_set[index]
+	not input.required_signatures
+	some rule
+	active_rule[rule]
 	rule.type == "required_signatures"
 }
 
@@ -603,7 +827,7 @@ ghas_dependency_review_not_enabled := false {
 # METADATA
 # scope: rule
 # title: OSSF Scorecard Score Should Be Above 7
-# description: Scorecard is an open-source tool from the OSSF that helps to assess the security posture of repositories. A low scorecard score means your repository may be at risk.
+# description: Scorecard is an open-source tool from the OSSF that helps to assess the security posture of repositories. A score that is not above the minimum score (7 by default, configurable as min_scorecard_score) means your repository may be at risk.
 # custom:
 #    requiredEnrichers: [scorecard]
 #    remediationSteps: 
@@ -618,7 +842,7 @@ ghas_dependency_review_not_enabled := false {
 default scorecard_score_too_low := true
 
 scorecard_score_too_low := false {
-	input.scorecard.score > 7.0
+	input.scorecard.score > data.config.min_scorecard_score
 }
 
 # METADATA
@@ -665,6 +889,54 @@ actions_can_approve_pull_requests := false{
 	not input.actions_token_permissions.can_approve_pull_request_reviews
 }
 
+# METADATA
+# scope: rule
+# title: Workflows Of Fork Pull Requests From External Contributors Should Require Approval
+# description: The repository does not require approval to run the workflows of pull requests from forks of all external contributors. Workflows of fork pull requests run code that is controlled by the contributor, so it is recommended to require a maintainer approval before running them.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Enter 'Actions - General' tab
+#     - 4. Under 'Approval for running fork pull request workflows from contributors'
+#     - 5. Select 'Require approval for all external contributors'
+#     - 6. Click 'Save'
+#   severity: MEDIUM
+#   requiredScopes: [repo]
+#   threat: An attacker can open a pull request from a fork with a modified workflow, which runs automatically in your CI/CD pipeline without anyone reviewing it, and abuse it to exfiltrate data, consume your runners or compromise self-hosted runners.
+default fork_pr_workflows_do_not_require_approval := false
+
+fork_pr_workflows_do_not_require_approval := true {
+	input.actions_fork_pr_approval.approval_policy != "all_external_contributors"
+}
+
+# METADATA
+# scope: rule
+# title: Public Repository Should Not Have Self-Hosted Runners
+# description: Self-hosted runners are registered to this public repository. Anyone can fork a public repository and open a pull request, so the workflows that run on these runners may execute code written by anyone. Unlike GitHub-hosted runners, self-hosted runners are not guaranteed to run each job on a fresh, isolated machine.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Enter 'Actions - Runners' tab
+#     - 4. Remove the self-hosted runners, and use GitHub-hosted runners for the workflows of the repository
+#   severity: HIGH
+#   requiredScopes: [repo]
+#   prerequisites: [self_hosted_runners_enabled]
+#   threat: An attacker can open a pull request from a fork with a workflow that runs on the self-hosted runner, and use it to persist on the machine, steal the secrets of later workflow runs, or move laterally into the network the runner is part of.
+public_repository_has_self_hosted_runners[violated] := true {
+	not input.repository.is_private
+	is_array(input.self_hosted_runners)
+	some index
+	runner := input.self_hosted_runners[index]
+	violated := {
+		"name": runner.name,
+		"os": runner.os,
+		"status": runner.status,
+		"labels": runner.labels,
+	}
+}
+
 # METADATA
 # scope: rule
 # title: Users Are Allowed To Bypass Ruleset Rules
@@ -682,13 +954,35 @@ actions_can_approve_pull_requests := false{
 default users_allowed_to_bypass_ruleset := true
 
 users_allowed_to_bypass_ruleset := false {
-    count(input.rules_set) == 0
+    count(active_rule) == 0
 }
 
 users_allowed_to_bypass_ruleset := false {
-    some index
-    rule := input.rules_set[index]
-    count(rule.ruleset.bypass_actors) == 0
+    some rule
+    active_rule[rule]
+    count(object.get(rule.ruleset, "bypass_actors", [])) == 0
+}
+
+# METADATA
+# scope: rule
+# title: Repository Should Require A Merge Queue
+# description: The repository is configured as requiring a merge queue (configured as merge_queue_repositories), but no active ruleset of its default branch requires one. A merge queue makes sure every pull request is merged only after the required checks passed against the latest state of the branch, which prevents incompatible changes from breaking high-traffic branches.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Under 'Code and automation', select 'Rules -> Rulesets'
+#     - 4. Create (or edit) an active ruleset that targets the default branch
+#     - 5. Check 'Require merge queue' and configure the merge method and the required checks
+#     - 6. Press 'Save changes'
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: Pull requests that passed their checks separately may break the default branch once merged together, e.g. disabling a security check, and the breakage is only noticed after it reached the branch.
+default merge_queue_not_required := false
+
+merge_queue_not_required := true {
+    data.config.merge_queue_repositories[_] == input.repository.name
+    input.merge_queue_required == false
 }
 
 # METADATA
@@ -705,6 +999,7 @@ users_allowed_to_bypass_ruleset := false {
 #      - 5. Sort secrets by 'Last Updated'
 #      - 6. Regenerate every secret older than one year and add the new value to GitHub's secret manager
 #   severity: MEDIUM
+#   category: secrets
 #   requiredScopes: [repo]
 #   threat: Sensitive data may have been inadvertently made public in the past, and an attacker who holds this data may gain access to your current CI and services. In addition, there may be old or unnecessary tokens that have not been inspected and can be used to access sensitive information.
 repository_secret_is_stale[stale] := true{
@@ -728,6 +1023,7 @@ repository_secret_is_stale[stale] := true{
 #     - 2. Under the 'Security' title on the left, select 'Code security and analysis'
 #     - 3. Under 'Secret scanning', click 'Enable'
 #   severity: MEDIUM
+#   category: secrets
 #   requiredScopes: [repo]
 #   prerequisites: [advanced_security]
 #   threat: Exposed secrets increases the risk of sensitive information such as API keys, passwords, and tokens being disclosed, leading to unauthorized access to systems and services, and data breaches.
@@ -735,4 +1031,460 @@ default secret_scanning_not_enabled := true
 
 secret_scanning_not_enabled := false{
     input.security_and_analysis.secret_scanning.status == "enabled"
-}
\ No newline at end of file
+}
+
+# METADATA
+# scope: rule
+# title: Secret Scanning Validity Checks Should Be Enabled
+# description: Repository should have secret scanning validity checks enabled. Validity checks verify with the secret provider whether a detected secret is still active, so that leaked secrets that are still usable are prioritized and revoked quickly.
+# custom:
+#   remediationSteps:
+#     - 1. Go to the repository settings page
+#     - 2. Under the 'Security' title on the left, select 'Code security and analysis'
+#     - 3. Under 'Secret scanning', check 'Automatically verify if a secret is valid by sending it to the relevant partner'
+#   severity: LOW
+#   category: secrets
+#   requiredScopes: [repo]
+#   prerequisites: [advanced_security]
+#   threat: Without validity checks, a leaked secret that is still active looks the same as one that was already revoked. The triage of secret scanning alerts takes longer, leaving active secrets exposed for an attacker to use.
+default secret_scanning_validity_checks_not_enabled := false
+
+secret_scanning_validity_checks_not_enabled := true {
+    input.secret_scanning_features.validity_checks == "disabled"
+}
+
+# METADATA
+# scope: rule
+# title: Dependency Ecosystems Should Have A Lockfile
+# description: Some of the dependency ecosystems used by the repository are not pinned by a lockfile (e.g. package.json without package-lock.json, Cargo.toml without Cargo.lock). Lockfiles pin the exact versions and integrity hashes of all direct and transitive dependencies, making builds reproducible and verifiable.
+# custom:
+#   remediationSteps:
+#     - 1. Generate a lockfile using the package manager of the ecosystem (e.g. 'npm install', 'cargo generate-lockfile', 'poetry lock')
+#     - 2. Commit the lockfile to the repository
+#     - 3. Make sure your CI installs dependencies from the lockfile (e.g. 'npm ci')
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: Without a lockfile, every build may resolve different versions of the dependencies. An attacker that publishes a malicious version of a dependency (or takes over one) can get it installed automatically in the next build.
+default dependency_ecosystem_missing_lockfile := true
+
+dependency_ecosystem_missing_lockfile := false {
+	missing := [ecosystem | ecosystem := input.dependency_ecosystems[_]; ecosystem.lockfile_supported; not ecosystem.has_lockfile]
+	count(missing) == 0
+}
+
+# METADATA
+# scope: rule
+# title: Template Repositories Should Not Contain Secrets
+# description: The repository is a template and has repository secrets configured. Repositories generated from a template copy its content and configuration, so secrets and misconfigurations in a template tend to propagate to every repository created from it. It is recommended to keep template repositories free of secrets and provide them through organization secrets or environments instead.
+# custom:
+#   requiredEnrichers: [secretsList]
+#   remediationSteps:
+#     - 1. Enter your repository's landing page
+#     - 2. Go to the settings tab
+#     - 3. Under the 'Security' title on the left, choose 'Secrets and variables'
+#     - 4. Click 'Actions'
+#     - 5. Remove the repository secrets, and provide them using organization or environment secrets where needed
+#   severity: MEDIUM
+#   category: secrets
+#   requiredScopes: [repo]
+#   threat: Every repository created from the template may reuse or reference the same credentials, widening the exposure of these secrets and making it harder to track and rotate them.
+template_repository_has_secrets[secret] := true {
+	input.repository.is_template
+	some index
+	secret := {"name": input.repository_secrets[index].name}
+}
+
+# the GitHub Apps that are approved to access the organization's repositories (by app slug),
+# configured as data.config.approved_integrations
+approved_integrations := {slug | slug := data.config.approved_integrations[_]}
+
+# METADATA
+# scope: rule
+# title: Repository Should Not Be Accessible By Unapproved Integrations
+# description: GitHub Apps that are not in the list of approved integrations (configured as approved_integrations; the policy is skipped when none is configured) have access to the repository. Integrations can read and sometimes modify the repository code and settings on behalf of third parties, so it is recommended to restrict the installed integrations to the ones approved by your organization.
+# custom:
+#   requiredEnrichers: [integrationsList]
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the organization settings page
+#     - 3. Under the 'Integrations' title on the left, choose 'GitHub Apps'
+#     - 4. Click 'Configure' next to the unapproved integration
+#     - 5. Remove the repository from the integration's repository access, or uninstall the integration
+#   severity: MEDIUM
+#   requiredScopes: [admin:org]
+#   threat: An unapproved integration with access to the repository may leak its code or be used to tamper with it. A compromise of the integration's vendor exposes every repository it was granted access to.
+repository_has_unapproved_integrations[integration] := true {
+	count(approved_integrations) > 0
+	some index
+	app := input.integrations[index]
+	not approved_integrations[app.app_slug]
+	integration := {"name": app.app_slug, "repository_selection": app.repository_selection}
+}
+
+# METADATA
+# scope: rule
+# title: Inactive Repository Should Be Archived
+# description: The repository has not been pushed to for over a year and its default branch is not protected. Inactive repositories that are left writable keep accumulating access and outdated dependencies without anyone reviewing them. It is recommended to archive repositories that are no longer in use, which makes them read-only while keeping their history.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repository settings page
+#     - 3. Under the 'Danger Zone' section, click 'Archive this repository'
+#     - 4. Confirm the archival
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: A forgotten repository that is still writable can be modified by any of its collaborators without drawing attention, e.g. to plant malicious code that is later consumed by other projects.
+default repository_archival_candidate := false
+
+repository_archival_candidate := true {
+	not input.repository.is_archived
+	not input.repository.is_locked
+	not is_null(input.repository.pushed_at)
+	ns := time.parse_rfc3339_ns(input.repository.pushed_at)
+	diff := time.diff(time.now_ns(), ns)
+	yearIndex := 0
+	diff[yearIndex] > 0
+	not default_branch_protected
+}
+
+default_branch_protected {
+	not is_null(input.repository.default_branch.branch_protection_rule)
+	not input.protection_present_but_no_reviews
+}
+
+default_branch_protected {
+	some rule
+	active_rule[rule]
+	rule.type == "pull_request"
+	not input.protection_present_but_no_reviews
+}
+
+# METADATA
+# scope: rule
+# title: Active Repository Should Use A Dependency Update Tool
+# description: The repository was pushed to in the past year, but it has neither a Dependabot version updates configuration (.github/dependabot.yml) nor a Renovate configuration (e.g. renovate.json). A dependency update tool opens pull requests that keep the dependencies up to date, so fixes of vulnerabilities are adopted as part of the regular maintenance.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have write permissions
+#     - 2. Add a Dependabot configuration file (.github/dependabot.yml) that enables version updates for the ecosystems of the repository
+#     - 3. Alternatively, install the Renovate app and add a Renovate configuration file (renovate.json)
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: Dependencies that are not updated regularly fall behind their fixed versions, so the repository keeps using dependencies with known vulnerabilities that attackers can exploit.
+default repository_has_no_dependency_update_tool := false
+
+repository_has_no_dependency_update_tool := true {
+	not input.repository.is_archived
+	not is_null(input.repository.pushed_at)
+	ns := time.parse_rfc3339_ns(input.repository.pushed_at)
+	diff := time.diff(time.now_ns(), ns)
+	yearIndex := 0
+	diff[yearIndex] == 0
+	files := input.dependency_management_files
+	not files.has_dependabot_config
+	not files.has_renovate_config
+}
+
+# METADATA
+# scope: rule
+# title: Dependabot Security Updates Should Be Enabled
+# description: Dependabot alerts are enabled for this repository, but Dependabot security updates are disabled (or paused). Enabling security updates makes Dependabot open pull requests that upgrade vulnerable dependencies, so known vulnerabilities are remediated rather than only reported.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repo's settings page
+#     - 3. Enter 'Code security and analysis' tab
+#     - 4. Set 'Dependabot security updates' as Enabled
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: Vulnerable dependencies that are only alerted on depend on someone manually upgrading them, so known vulnerabilities may stay exploitable in the code for a long time.
+default dependabot_security_updates_not_enabled := false
+
+dependabot_security_updates_not_enabled := true {
+	not input.repository.is_archived
+	input.vulnerability_alerts_enabled
+	not automated_security_fixes_active
+}
+
+automated_security_fixes_active {
+	input.automated_security_fixes.enabled
+	not input.automated_security_fixes.paused
+}
+
+automated_security_fixes_active {
+	# deliberately ignoring nil value (in case this data is unavailable)
+	not input.automated_security_fixes
+}
+
+# METADATA
+# scope: rule
+# title: Public Repository Should Enable Private Vulnerability Reporting
+# description: Private vulnerability reporting is disabled for this public repository. Enabling it allows security researchers to report vulnerabilities privately to the maintainers, instead of disclosing them publicly in an issue or not reporting them at all.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repo's settings page
+#     - 3. Enter 'Code security and analysis' tab
+#     - 4. Set 'Private vulnerability reporting' as Enabled
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: A researcher who finds a vulnerability has no private channel to report it, so it may be disclosed publicly before a fix is available and exploited by attackers.
+default private_vulnerability_reporting_not_enabled := false
+
+private_vulnerability_reporting_not_enabled := true {
+	not input.repository.is_private
+	input.private_vulnerability_reporting_enabled == false
+}
+
+# METADATA
+# scope: rule
+# title: Private Repository Should Limit Actions Artifact And Log Retention
+# description: The GitHub Actions artifacts and logs of this private repository are retained for longer than the allowed period (30 days by default, configurable as max_artifact_retention_days). Build artifacts and logs of private repositories may contain sensitive outputs, and retaining them for long periods increases their exposure.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repo's settings page
+#     - 3. Under the 'Code and automation' title on the left, choose 'Actions' -> 'General'
+#     - 4. Under 'Artifact and log retention', set a shorter retention period
+#     - 5. Click 'Save'
+#   severity: LOW
+#   requiredScopes: [repo]
+#   prerequisites: [actions_storage_enabled]
+#   threat: An attacker who gains read access to the repository (e.g. via a leaked token) can download old build artifacts and logs that may contain secrets, internal binaries or customer data.
+default actions_artifact_retention_too_long := false
+
+actions_artifact_retention_too_long := true {
+	input.repository.is_private
+	input.actions_artifact_retention.days > data.config.max_artifact_retention_days
+}
+
+# METADATA
+# scope: rule
+# title: Private Repository Should Limit Actions Cache Usage
+# description: The GitHub Actions caches of this private repository are larger than the allowed size (configurable as max_actions_cache_size_mb). Caches keep build outputs (e.g. dependencies, compiled files and tool state) between workflow runs, may contain sensitive data, and are restored by any workflow of the repository, including workflows of pull requests.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have write permissions
+#     - 2. Go to the repo's 'Actions' tab
+#     - 3. Under 'Management' on the left, choose 'Caches'
+#     - 4. Delete the caches that are no longer needed
+#     - 5. Limit the paths the workflows cache (e.g. dependencies only) and avoid caching build outputs that may contain secrets
+#   severity: LOW
+#   requiredScopes: [repo]
+#   prerequisites: [actions_storage_enabled]
+#   threat: An attacker who gains access to a workflow of the repository can read the cached build outputs, which may contain secrets, or poison the caches that later workflow runs restore.
+default actions_cache_usage_too_large := false
+
+actions_cache_usage_too_large := true {
+	input.repository.is_private
+	input.actions_cache_usage.active_caches_size_in_bytes > data.config.max_actions_cache_size_mb * 1024 * 1024
+}
+
+# METADATA
+# scope: rule
+# title: Security Checks Should Pass On The Default Branch
+# description: One of the security checks (configured as security_checks) did not succeed on the latest commit of the default branch, or was not reported at all. A failing or missing security check means the code on the default branch was not actually verified by the security workflow.
+# custom:
+#   remediationSteps:
+#     - 1. Go to the repo's main page and open the checks of the latest commit of the default branch
+#     - 2. Make sure the security workflow is configured to run on the default branch
+#     - 3. Fix the findings (or the workflow errors) that cause the check to fail
+#     - 4. Consider adding the check to the required status checks of the default branch protection
+#   severity: MEDIUM
+#   requiredScopes: [repo]
+#   threat: Vulnerable or malicious code may reach the default branch unnoticed while the security workflow is failing or not running at all.
+default security_checks_not_passing_on_default_branch := false
+
+security_checks_not_passing_on_default_branch := true {
+	is_array(input.default_branch_checks)
+	some index
+	name := data.config.security_checks[index]
+	not default_branch_check_succeeded(name)
+}
+
+default_branch_check_succeeded(name) {
+	some index
+	check := input.default_branch_checks[index]
+	check.name == name
+	check.conclusion == "SUCCESS"
+}
+
+# METADATA
+# scope: rule
+# title: Production Environments Should Not Allow Administrators To Bypass Protection Rules
+# description: A production deployment environment (named production/prod, or configured as production_environments) allows repository administrators to bypass its protection rules, such as required reviewers and wait timers. Administrators can then deploy to production without the review the environment is meant to enforce.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repo's settings page
+#     - 3. Enter 'Environments' tab
+#     - 4. Select the production environment
+#     - 5. Uncheck 'Allow administrators to bypass configured protection rules'
+#     - 6. Click 'Save protection rules'
+#   severity: MEDIUM
+#   requiredScopes: [repo]
+#   threat: A repository administrator (or an attacker who compromised one) can deploy unreviewed code to production, bypassing the reviewers of the environment.
+production_environment_allows_admin_bypass[violated] := true {
+	is_array(input.environments)
+	some index
+	environment := input.environments[index]
+	production_environment(environment.name)
+	environment.can_admins_bypass == true
+	violated := {
+		"name": environment.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Production Environments Should Require Reviewers
+# description: A production deployment environment (named production/prod, or configured as production_environments) does not require a reviewer to approve the deployments. Any workflow that references the environment (e.g. from any branch that passes its deployment branch policy) deploys to production and gets its secrets without anyone's approval. A wait timer alone delays the deployment but does not review it.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Go to the repo's settings page
+#     - 3. Enter 'Environments' tab
+#     - 4. Select the production environment
+#     - 5. Check 'Required reviewers' and add at least one reviewer
+#     - 6. Click 'Save protection rules'
+#   severity: MEDIUM
+#   requiredScopes: [repo]
+#   threat: A user (or a compromised workflow) that can run workflows in the repository can deploy unreviewed code to production and read the production secrets.
+production_environment_missing_required_reviewers[violated] := true {
+	is_array(input.environments)
+	some index
+	environment := input.environments[index]
+	production_environment(environment.name)
+	not environment_requires_reviewers(environment)
+	violated := {
+		"name": environment.name,
+	}
+}
+
+environment_requires_reviewers(environment) {
+	rule := environment.protection_rules[_]
+	rule.type == "required_reviewers"
+	count(rule.reviewers) > 0
+}
+
+production_environment(name) {
+	lower(name) == {"production", "prod"}[_]
+}
+
+production_environment(name) {
+	data.config.production_environments[_] == name
+}
+
+# METADATA
+# scope: rule
+# title: Release Assets Should Have Provenance
+# description: The latest release of the repository publishes assets, but none of them has a build provenance - neither an artifact attestation nor an in-toto provenance asset (e.g. generated by the SLSA GitHub generator). Without a provenance, consumers cannot verify where and how the assets were built.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have admin permissions
+#     - 2. Build the release assets in a GitHub Actions workflow
+#     - 3. Add the actions/attest-build-provenance action to the workflow (or use the SLSA GitHub generator) to generate a provenance of the assets
+#     - 4. Optionally, sign the assets (e.g. with sigstore cosign) and publish the signatures along with the assets
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: An attacker that compromises the release process (or the account of a maintainer) can replace the release assets with malicious ones, and the consumers of the release have no way to tell that the assets were not built from the repository sources.
+default repository_release_has_no_provenance := false
+
+repository_release_has_no_provenance := true {
+	release := input.latest_release
+	release.assets_count > count(release.signature_assets)
+	count(release.provenance_assets) == 0
+	not release.has_attestations
+}
+
+# METADATA
+# scope: rule
+# title: Workflows Should Grant The OIDC Token Permission Only To The Jobs That Need It
+# description: Some of the workflows grant the 'id-token' write permission at the workflow level (or grant 'write-all') to several jobs, so every job that does not set its own permissions can request an OIDC token, even when only one of them authenticates to the cloud provider. Cloud providers that federate with GitHub Actions may trust these tokens to grant access to cloud resources, depending on how loose their trust policy is.
+# custom:
+#   requiredEnrichers: [workflowsList]
+#   remediationSteps:
+#     - 1. Open the workflow file in the '.github/workflows' directory
+#     - 2. Remove 'id-token: write' (or 'write-all') from the workflow level 'permissions' key
+#     - 3. Add 'id-token: write' to the 'permissions' key of the jobs that authenticate to the cloud provider only
+#     - 4. Make sure the trust policy of the cloud provider is restricted to the expected repository, branch and environment
+#   severity: LOW
+#   requiredScopes: [repo]
+#   threat: Any step of any job of the workflow (including third party actions) can request an OIDC token and exchange it for cloud credentials. A compromised action or a malicious pull request that reaches one of the jobs can then access the cloud resources the trust policy allows.
+workflow_grants_id_token_to_all_jobs[violated] := true {
+	some index
+	workflow := input.workflows[index]
+	workflow_level_id_token(workflow.permissions)
+
+	# the jobs that set their own permissions do not inherit the workflow level permissions
+	inheriting_jobs := workflow.jobs - count(object.get(workflow, "job_permissions", {}))
+	inheriting_jobs > 1
+	violated := {
+		"path": workflow.path,
+	}
+}
+
+workflow_level_id_token(permissions) {
+	permissions["id-token"] == "write"
+}
+
+workflow_level_id_token(permissions) {
+	permissions["*"] == "write-all"
+}
+
+# METADATA
+# scope: rule
+# title: Workflows Triggered By pull_request_target Should Not Check Out The Pull Request Code
+# description: Some of the workflows are triggered by pull_request_target and check out the head of the pull request. Unlike pull_request, the pull_request_target trigger runs in the context of the base repository, with access to its secrets and a GITHUB_TOKEN with write permissions, even for pull requests from forks. Checking out the pull request code in such a workflow runs untrusted code (e.g. build scripts and dependencies) with these privileges.
+# custom:
+#   requiredEnrichers: [workflowsList]
+#   remediationSteps:
+#     - 1. Open the workflow file in the '.github/workflows' directory
+#     - 2. Trigger the workflow by pull_request instead of pull_request_target, if it does not need the secrets or write permissions
+#     - 3. Otherwise, split the workflow - build and test the pull request code in a pull_request workflow, and handle its results in a separate workflow_run workflow
+#   severity: HIGH
+#   requiredScopes: [repo]
+#   prerequisites: [workflow_contents_enabled]
+#   threat: Anyone can open a pull request from a fork with malicious build scripts or dependencies. The workflow runs them with the secrets of the repository and a write token, allowing the attacker to exfiltrate the secrets, push code to the repository or poison its releases ("pwn request").
+pull_request_target_workflow_checks_out_pr_head[violated] := true {
+	is_array(input.workflows)
+	some index
+	workflow := input.workflows[index]
+	workflow_triggered_by(workflow.content, "pull_request_target")
+	some job
+	step := workflow.content.jobs[job].steps[_]
+	startswith(step.uses, "actions/checkout")
+	checks_out_pr_head(step["with"].ref)
+	violated := {
+		"path": workflow.path,
+		"job": job,
+	}
+}
+
+workflow_triggered_by(content, event) {
+	content.on == event
+}
+
+workflow_triggered_by(content, event) {
+	is_array(content.on)
+	content.on[_] == event
+}
+
+workflow_triggered_by(content, event) {
+	is_object(content.on)
+	some trigger
+	_ = content.on[trigger]
+	trigger == event
+}
+
+checks_out_pr_head(ref) {
+	contains(ref, "github.event.pull_request.head")
+}
+
+checks_out_pr_head(ref) {
+	contains(ref, "github.head_ref")
+}
+
+checks_out_pr_head(ref) {
+	contains(ref, "refs/pull/")
+}
@@ -0,0 +1,43 @@
+package common.integrations
+
+# the active integrations that are not one of data.config.approved_integrations (none when no integration is approved)
+unapproved[violated] := true {
+	count(data.config.approved_integrations) > 0
+	is_array(input.integrations)
+	some index
+	integration := input.integrations[index]
+	not approved(integration.slug)
+	violated := {
+		"name": integration.slug,
+		"title": integration.title,
+	}
+}
+
+approved(slug) {
+	data.config.approved_integrations[_] == slug
+}
+
+# the hosts the integrations send data to that are not trusted (none when no domain is trusted)
+untrusted_hosts[violated] := true {
+	count(data.config.trusted_webhook_domains) > 0
+	is_array(input.integrations)
+	some index
+	integration := input.integrations[index]
+	host := integration.hosts[_]
+	not trusted_host(host)
+	violated := {
+		"name": integration.slug,
+		"host": host,
+	}
+}
+
+# a host is trusted when it is one of data.config.trusted_webhook_domains or a subdomain of one
+trusted_host(host) {
+	domain := lower(data.config.trusted_webhook_domains[_])
+	host == domain
+}
+
+trusted_host(host) {
+	domain := lower(data.config.trusted_webhook_domains[_])
+	endswith(host, concat("", [".", domain]))
+}
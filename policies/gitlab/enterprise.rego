@@ -222,3 +222,64 @@ default unauthenticated_signup_enabled := true
 unauthenticated_signup_enabled := false {
     not input.signup_enabled
 }
+
+# METADATA
+# scope: rule
+# title: Password Minimum Length Should Be Long Enough
+# description: The minimum length of the passwords of the users of the server is shorter than the recommended length (12 characters by default, configurable as min_password_length). Short passwords are easier to guess and to crack.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Go to the admin page: Menu -> Admin
+#     - 2. Press Settings -> General
+#     - 3. Expand 'Sign-up restrictions' section
+#     - 4. Set 'Minimum password length (number of characters)' to the recommended length or more
+#     - 5. Press 'Save Changes'
+#   threat:
+#     - An attacker can guess or brute force the short passwords of the users (e.g. with password spraying) and take over their accounts.
+default password_minimum_length_too_short := true
+
+password_minimum_length_too_short := false {
+	input.minimum_password_length >= data.config.min_password_length
+}
+
+# METADATA
+# scope: rule
+# title: Two-Factor Authentication Grace Period Of The Server Should Not Be Too Long
+# description: Two-factor authentication is enforced for all the users of the server, but the users are allowed to postpone its setup for longer than the allowed grace period (168 hours by default, configurable as max_mfa_grace_period_hours). The grace period should be as short as possible.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Go to the admin page: Menu -> Admin
+#     - 2. Press Settings -> General
+#     - 3. Expand 'Sign-in restrictions' section
+#     - "4. In the box titled: 'Two-factor grace period', enter a number of hours no greater than the allowed grace period (preferably 0)"
+#     - 5. Press 'Save Changes'
+#   threat:
+#     - Until two-factor authentication is set up, a user account is protected by its password only. An attacker that obtains the password during the grace period can take over the account and set up two-factor authentication by themselves.
+default two_factor_grace_period_too_long := false
+
+two_factor_grace_period_too_long := true {
+	input.require_two_factor_authentication
+	input.two_factor_grace_period > data.config.max_mfa_grace_period_hours
+}
+
+# METADATA
+# scope: rule
+# title: System Hooks Should Not Be Allowed To Be Sent To The Local Network
+# description: System hooks are allowed to send requests to the local network of the server. Allowing requests to the local network exposes the internal services to Server Side Request Forgery (SSRF) by the administrators of the system hooks.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Go to the admin page: Menu -> Admin
+#     - 2. Press Settings -> Network
+#     - 3. Expand 'Outbound requests' section
+#     - 4. Un toggle 'Allow requests to the local network from system hooks'
+#     - 5. Press 'Save Changes'
+#   threat:
+#     - An attacker that takes over an administrator account can configure a system hook that sends requests to internal services that are otherwise unreachable from the internet.
+default system_hooks_are_allowed_to_be_sent_to_local_network := true
+
+system_hooks_are_allowed_to_be_sent_to_local_network := false {
+	not input.allow_local_requests_from_system_hooks
+}
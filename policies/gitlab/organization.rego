@@ -1,5 +1,7 @@
 package organization
 
+import data.common.integrations as integrationUtils
+
 # METADATA
 # scope: rule
 # title: Two-Factor Authentication Should Be Enforced For The Group
@@ -85,19 +87,155 @@ group_does_not_enforce_branch_protection_by_default := false {
 # METADATA
 # scope: rule
 # title: Two-Factor Authentication Grace Period Should Not Be Longer Than One Week
-# description: New members added to your group are allowed longer than a week to enable MFA. The time frame should be lowered to one week or less.
+# description: New members added to your group are allowed longer than the allowed grace period (168 hours by default, configurable as max_mfa_grace_period_hours) to enable MFA. The time frame should be lowered to the allowed grace period or less.
 # custom:
 #   severity: MEDIUM
 #   remediationSteps:
 #     - 1. Go to the group page
 #     - 2. Press Settings -> General
 #     - 3. Expand 'Permissions and group features'
-#     - "4. In the box titled: 'Delay 2FA enforcement (hours)', enter a number under 168 (preferably 0)"
+#     - "4. In the box titled: 'Delay 2FA enforcement (hours)', enter a number of hours no greater than the allowed grace period (preferably 0)"
 #     - 5. Press 'Save Changes'
 #   threat:
 #     - Any new group member effectively acts as an attack surface until two-factor authentication is enabled. The risk is compounded as new members may be more vulnerable to phishing and identity theft attacks.
 default group_allows_excessive_mfa_grace_period := true
 
 group_allows_excessive_mfa_grace_period := false{
-	input.two_factor_grace_period <= 168
+	input.two_factor_grace_period <= data.config.max_mfa_grace_period_hours
+}
+
+# METADATA
+# scope: rule
+# title: Group Deploy Tokens Should Have An Expiration Date
+# description: An active deploy token of the group never expires. Group deploy tokens grant access to all the projects of the group, and a token without an expiration date remains valid long after it is no longer needed.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the group page
+#     - 3. Press Settings -> Repository
+#     - 4. Expand 'Deploy tokens'
+#     - 5. Revoke the tokens without an expiration date and create new tokens with an expiration date
+#   threat: A leaked group deploy token grants access to the repositories or registries of every project of the group indefinitely, until someone notices and revokes it manually.
+group_deploy_token_never_expires[violated] := true {
+	is_array(input.deploy_tokens)
+	some index
+	token := input.deploy_tokens[index]
+	active_deploy_token(token)
+	is_null(token.expires_at)
+	violated := {
+		"name": token.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Group Deploy Tokens Should Not Have Write Scopes
+# description: An active deploy token of the group can write to the repositories or the registries of the group projects. Deploy tokens are meant for automated read access (e.g. cloning or pulling images), while write access should be limited to identities that are subject to the project protections.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the group page
+#     - 3. Press Settings -> Repository
+#     - 4. Expand 'Deploy tokens'
+#     - 5. Revoke the tokens with write scopes (write_repository, write_registry, write_package_registry) and create new tokens with read scopes only
+#   threat: Anyone holding the token can push code or publish tampered images and packages to every project of the group, bypassing the review of the changes.
+group_deploy_token_has_write_scope[violated] := true {
+	is_array(input.deploy_tokens)
+	some index
+	token := input.deploy_tokens[index]
+	active_deploy_token(token)
+	startswith(token.scopes[_], "write_")
+	violated := {
+		"name": token.name,
+	}
+}
+
+active_deploy_token(token) {
+	not token.revoked
+	not token.expired
+}
+
+# METADATA
+# scope: rule
+# title: Group Access Tokens Should Have An Expiration Date
+# description: An active access token of the group never expires. Group access tokens act as a member of the group in all its projects, and a token without an expiration date remains valid long after it is no longer needed.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the group page
+#     - 3. Press Settings -> Access Tokens
+#     - 4. Revoke the tokens without an expiration date and create new tokens with an expiration date
+#   threat: A leaked group access token grants access to every project of the group indefinitely, until someone notices and revokes it manually.
+group_access_token_never_expires[violated] := true {
+	is_array(input.access_tokens)
+	some index
+	token := input.access_tokens[index]
+	token.active
+	is_null(token.expires_at)
+	violated := {
+		"name": token.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Group Access Tokens Should Not Have The API Scope
+# description: An active access token of the group has the 'api' scope, which grants complete read and write access to the API of the group and its projects. Tokens should be limited to the scopes they need (e.g. read_api, read_repository).
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the group page
+#     - 3. Press Settings -> Access Tokens
+#     - 4. Revoke the tokens with the 'api' scope and create new tokens with narrower scopes
+#   threat: Anyone holding the token can change the settings, the code and the pipelines of every project of the group, with the access level of the token.
+group_access_token_has_api_scope[violated] := true {
+	is_array(input.access_tokens)
+	some index
+	token := input.access_tokens[index]
+	token.active
+	token.scopes[_] == "api"
+	violated := {
+		"name": token.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Group Should Not Have Unapproved Integrations
+# description: The group has active integrations that are not in the list of approved integrations (configured as approved_integrations; the policy is skipped when none is configured). Group integrations are inherited by every project of the group, so they receive the events of all its projects.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the group page
+#     - 3. Press Settings -> Integrations
+#     - 4. Disable the unapproved integrations
+#   threat: An unapproved integration may leak the code, issues or pipeline data of every project of the group to a third party. A compromise of the integration's vendor exposes all of them.
+group_has_unapproved_integrations[violated] := true {
+	integrationUtils.unapproved[violated]
+}
+
+# METADATA
+# scope: rule
+# title: Group Integrations Should Only Send Data To Trusted Hosts
+# description: An active integration of the group is configured with a URL whose host is outside the trusted domains (configured as trusted_webhook_domains; the policy is skipped when none is configured). The integration is inherited by the projects of the group, so the events of all of them are delivered to that host.
+# custom:
+#   severity: HIGH
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the group page
+#     - 3. Press Settings -> Integrations
+#     - 4. Reconfigure the integration to use a trusted host, or disable it
+#     - 5. If the host is trusted, add its domain to trusted_webhook_domains in the policies config
+#   threat: An integration that points to an external or attacker-controlled host leaks the activity of every project of the group, and may be used to exfiltrate code and pipeline data without the project members noticing.
+group_integration_untrusted_host[violated] := true {
+	integrationUtils.untrusted_hosts[violated]
 }
@@ -1,5 +1,7 @@
 package repository
 
+import data.common.integrations as integrationUtils
+
 # METADATA
 # scope: rule
 # title: Project Should Be Updated At Least Quarterly
@@ -27,8 +29,9 @@ project_not_maintained := false {
 # METADATA
 # scope: rule
 # title: Project Should Have Fewer Than Three Owners
-# description: Projects owners are highly privileged and could create great damage if they are compromised. It is recommended to limit the number of Project Owners to the minimum required (recommended maximum 3 admins).
+# description: Projects owners are highly privileged and could create great damage if they are compromised. It is recommended to limit the number of Project Owners to the minimum required (3 admins by default, configurable as max_repository_admins).
 # custom:
+#   requiredEnrichers: [adminsList]
 #   severity: LOW
 #   remediationSteps:
 #     - 1. Make sure you have owner permissions
@@ -41,7 +44,7 @@ default project_has_too_many_admins := true
 
 project_has_too_many_admins := false {
 	admins := [admin | admin := input.members[_]; admin.access_level == 50]
-	count(admins) <= 3
+	count(admins) <= data.config.max_repository_admins
 }
 
 # METADATA
@@ -88,6 +91,16 @@ missing_default_branch_protection := false {
 	count(default_protected_branches) > 0
 }
 
+# covers protected branches that match the default branch by a wildcard (e.g. 'release/*' or '*')
+missing_default_branch_protection := false {
+	input.default_branch_protection.protected
+}
+
+# a project without branches has nothing to protect
+missing_default_branch_protection := false {
+	input.empty_repo
+}
+
 # METADATA
 # scope: rule
 # title: Default Branch Should Not Allow Force Pushes
@@ -247,7 +260,7 @@ code_review_not_required := false {
 #     - 2. Go to the repo's settings page
 #     - 3. Enter 'Merge Requests' tab
 #     - 4. Under 'Merge request approvals', Click 'Add approval rule' on the default branch rule
-#     - 5. Select 'Approvals required' and enter at least 2 approvers
+#     - 5. Select 'Approvals required' and enter at least the required number of approvals (2 by default, configurable as min_approvals)
 #     - 6. Select 'Add approvers' and select the desired members
 #     - 7. Click 'Add approval rule'
 #   severity: MEDIUM
@@ -257,7 +270,7 @@ code_review_not_required := false {
 default code_review_by_two_members_not_required := true
 
 code_review_by_two_members_not_required := false {
-	input.minimum_required_approvals >= 2
+	input.minimum_required_approvals >= data.config.min_approvals
 }
 
 # METADATA
@@ -341,3 +354,292 @@ default repository_dismiss_stale_reviews := true
 repository_dismiss_stale_reviews := false {
 	input.approval_configuration.reset_approvals_on_push
 }
+
+# METADATA
+# scope: rule
+# title: Project Should Have A Compliance Framework Assigned
+# description: Compliance frameworks label projects that have specific compliance requirements and can enforce a compliance pipeline on them. Projects that hold production code should be assigned a compliance framework so that the required controls are applied and can be audited.
+# custom:
+#   remediationSteps:
+#     - 1. Make sure you have owner permissions
+#     - 2. Go to the project's settings -> General page
+#     - 3. Expand 'Compliance frameworks'
+#     - 4. Select the compliance framework required by your organization
+#     - 5. Click 'Save changes'
+#   severity: LOW
+#   prerequisites: [premium]
+#   threat: Projects without a compliance framework are not subject to the compliance pipeline and controls defined by the organization, and may reach production without the required checks.
+default project_missing_compliance_framework := false
+
+project_missing_compliance_framework := true {
+	is_array(input.compliance_frameworks_info)
+	count(input.compliance_frameworks_info) == 0
+}
+
+# METADATA
+# scope: rule
+# title: Project Should Require Linear History
+# description: The project merge method creates merge commits. Requiring fast-forward merges (or rebasing before every merge) keeps the history linear, which makes it easier to review, audit and revert changes.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Merge Requests'
+#     - 4. Under 'Merge method', select 'Fast-forward merge' or 'Merge commit with semi-linear history'
+#     - 5. Click 'Save changes'
+#   threat: Having a non-linear history makes it harder to reverse changes, making recovery from bugs and security risks slower and more difficult.
+default non_linear_history := false
+
+non_linear_history := true {
+	method := input.merge_settings.merge_method
+	method != ""
+	not linear_merge_methods[method]
+}
+
+linear_merge_methods := {"ff", "rebase_merge"}
+
+# METADATA
+# scope: rule
+# title: Project Container Registry Should Not Be Public
+# description: The container registry of the project is visible to everyone. Container images often include internal code, configuration and sometimes secrets that were not meant to be published, even when the project itself is intended to be public.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'General' and expand 'Visibility, project features, permissions'
+#     - 4. Under 'Container registry', select 'Only Project Members' (or disable the registry if it is not used)
+#     - 5. Click 'Save changes'
+#   threat: Anyone can pull the project images and inspect their layers for internal code, credentials or vulnerable dependencies to exploit.
+default container_registry_is_public := false
+
+container_registry_is_public := true {
+	input.registry_settings.container_registry_visibility == "public"
+}
+
+# METADATA
+# scope: rule
+# title: Project Container Registry Should Have A Cleanup Policy
+# description: The container registry of the project is enabled without a cleanup policy, so old and unused images (including images with known vulnerabilities or leaked secrets) are kept indefinitely.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Packages and registries'
+#     - 4. Under 'Cleanup policies', click 'Set cleanup rules' and enable the cleanup policy
+#     - 5. Click 'Save'
+#   threat: Stale images accumulate over time; an image with a vulnerable dependency or a leaked secret remains available to anyone with access to the registry long after it was replaced.
+default container_registry_missing_cleanup_policy := false
+
+container_registry_missing_cleanup_policy := true {
+	input.registry_settings.container_registry_enabled
+	not input.registry_settings.cleanup_policy_enabled
+}
+
+# METADATA
+# scope: rule
+# title: Project Deploy Tokens Should Have An Expiration Date
+# description: An active deploy token of the project never expires. Deploy tokens are often stored in external systems (e.g. CI servers or clusters), and a token without an expiration date remains valid long after it is no longer needed.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Repository' and expand 'Deploy tokens'
+#     - 4. Revoke the tokens without an expiration date and create new tokens with an expiration date
+#   threat: A leaked deploy token grants access to the project repository or registries indefinitely, until someone notices and revokes it manually.
+project_deploy_token_never_expires[violated] := true {
+	is_array(input.deploy_tokens)
+	some index
+	token := input.deploy_tokens[index]
+	active_deploy_token(token)
+	is_null(token.expires_at)
+	violated := {
+		"name": token.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Project Deploy Tokens Should Not Have Write Scopes
+# description: An active deploy token of the project can write to the repository or the registries. Deploy tokens are meant for automated read access (e.g. cloning or pulling images), while write access should be limited to identities that are subject to the project protections.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Repository' and expand 'Deploy tokens'
+#     - 4. Revoke the tokens with write scopes (write_repository, write_registry, write_package_registry) and create new tokens with read scopes only
+#   threat: Anyone holding the token can push code or publish tampered images and packages to the project, bypassing the review of the changes.
+project_deploy_token_has_write_scope[violated] := true {
+	is_array(input.deploy_tokens)
+	some index
+	token := input.deploy_tokens[index]
+	active_deploy_token(token)
+	startswith(token.scopes[_], "write_")
+	violated := {
+		"name": token.name,
+	}
+}
+
+active_deploy_token(token) {
+	not token.revoked
+	not token.expired
+}
+
+# METADATA
+# scope: rule
+# title: Project Access Tokens Should Have An Expiration Date
+# description: An active access token of the project never expires. Project access tokens are often stored in external systems (e.g. CI servers or bots), and a token without an expiration date remains valid long after it is no longer needed.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Access Tokens'
+#     - 4. Revoke the tokens without an expiration date and create new tokens with an expiration date
+#   threat: A leaked project access token grants access to the project indefinitely, until someone notices and revokes it manually.
+project_access_token_never_expires[violated] := true {
+	is_array(input.access_tokens)
+	some index
+	token := input.access_tokens[index]
+	token.active
+	is_null(token.expires_at)
+	violated := {
+		"name": token.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Project Access Tokens Should Not Have The API Scope
+# description: An active access token of the project has the 'api' scope, which grants complete read and write access to the API of the project. Tokens should be limited to the scopes they need (e.g. read_api, read_repository).
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Access Tokens'
+#     - 4. Revoke the tokens with the 'api' scope and create new tokens with narrower scopes
+#   threat: Anyone holding the token can change the settings, the code and the pipelines of the project, with the access level of the token.
+project_access_token_has_api_scope[violated] := true {
+	is_array(input.access_tokens)
+	some index
+	token := input.access_tokens[index]
+	token.active
+	token.scopes[_] == "api"
+	violated := {
+		"name": token.name,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Project CI/CD Variables That Hold Secrets Should Be Masked
+# description: Some of the CI/CD variables of the project are named like secrets (e.g. tokens, passwords or keys) but are not masked. The values of variables that are not masked are printed as is in the job logs whenever a job echoes them, e.g. in a debug message or a failed command.
+# custom:
+#   severity: MEDIUM
+#   category: secrets
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'CI/CD' and expand 'Variables'
+#     - 4. Edit each of the listed variables, check 'Mask variable' and click 'Update variable'
+#     - 5. Rotate the secrets that may already appear in job logs
+#   threat: Anyone who can read the job logs of the project (e.g. every member with the reporter role, or everyone on public projects) can obtain the secrets and use them to access the systems they protect.
+project_has_unmasked_secret_ci_variables[violated] := true {
+	is_array(input.ci_variables)
+	some index
+	variable := input.ci_variables[index]
+	not variable.masked
+	regex.match(`(?i)(token|secret|passw(or)?d|api_?key|private_?key|credential)`, variable.key)
+	violated := {
+		"key": variable.key,
+		"environment scope": variable.environment_scope,
+	}
+}
+
+# METADATA
+# scope: rule
+# title: Project Should Not Have Unapproved Integrations
+# description: The project has active integrations that are not in the list of approved integrations (configured as approved_integrations; the policy is skipped when none is configured). Integrations such as chat notifications, issue trackers or external CI systems receive the project events and sometimes act on the project, so it is recommended to restrict them to the ones approved by your organization.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Integrations'
+#     - 4. Disable the unapproved integrations (integrations inherited from the group are managed in the group's settings)
+#   threat: An unapproved integration may leak the project code, issues or pipeline data to a third party. A compromise of the integration's vendor exposes every project it is enabled on.
+project_has_unapproved_integrations[violated] := true {
+	integrationUtils.unapproved[violated]
+}
+
+# METADATA
+# scope: rule
+# title: Project Integrations Should Only Send Data To Trusted Hosts
+# description: An active integration of the project is configured with a URL whose host is outside the trusted domains (configured as trusted_webhook_domains; the policy is skipped when none is configured). The project events (e.g. pushes, merge requests and pipelines) are delivered to that host.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'Integrations'
+#     - 4. Reconfigure the integration to use a trusted host, or disable it
+#     - 5. If the host is trusted, add its domain to trusted_webhook_domains in the policies config
+#   threat: An integration that points to an external or attacker-controlled host leaks the project activity, and may be used to exfiltrate code and pipeline data without the project members noticing.
+project_integration_untrusted_host[violated] := true {
+	integrationUtils.untrusted_hosts[violated]
+}
+
+# METADATA
+# scope: rule
+# title: Project Should Limit The Access Of CI/CD Job Tokens
+# description: The project accepts the CI/CD job tokens of any other project the job's user can access, since the job token access of the project is not limited to an allowlist. A pipeline of another project can then read the project's repository, packages and artifacts, or trigger its pipelines.
+# custom:
+#   severity: MEDIUM
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Go to the project's settings page
+#     - 3. Select 'CI/CD' and expand 'Token Access'
+#     - 4. Enable 'Limit access to this project' and add the projects that require access to the allowlist
+#   threat: A compromised pipeline of any project (e.g. through a malicious dependency) can use its job token to move laterally to the project, reading its code and packages or pushing malicious packages.
+default project_job_token_access_not_limited := false
+
+project_job_token_access_not_limited := true {
+	input.job_token_scope.inbound_enabled == false
+}
+
+# METADATA
+# scope: rule
+# title: Project Should Have A Merge Request Description Template
+# description: The project is configured as requiring a merge request template (configured as merge_request_template_projects, by name or full path), but it has neither a merge request description template nor a default merge request template. A template guides the authors to describe the change, its testing and its security impact, which makes the review of changes to important projects consistent and auditable.
+# custom:
+#   severity: LOW
+#   remediationSteps:
+#     - 1. Make sure you have maintainer permissions
+#     - 2. Add a merge request template to the project's repository (.gitlab/merge_request_templates/Default.md), or to the group's templates project
+#     - 3. Optionally, set it as the default template - go to the project's settings page, select 'Merge requests' and fill 'Default description template for merge requests'
+#   threat:
+#     - Changes to important projects are merged without the information the review requires (e.g. the testing and the security impact of the change), so risky changes are more likely to be approved unnoticed.
+default project_missing_merge_request_template := false
+
+project_missing_merge_request_template := true {
+	merge_request_template_required
+	count(input.description_templates.merge_request_templates) == 0
+	input.description_templates.has_default_merge_request_template == false
+}
+
+merge_request_template_required {
+	data.config.merge_request_template_projects[_] == input.name
+}
+
+merge_request_template_required {
+	data.config.merge_request_template_projects[_] == input.path_with_namespace
+}
@@ -15,10 +15,11 @@ type organizationActionsMockConfiguration struct {
 	enabledRepositories    *string
 	tokenDefaultPermission string
 	workflowsCanApprovePRs bool
+	forkPRApprovalPolicy   *string
 }
 
 func newOrganizationActionsMock(config organizationActionsMockConfiguration) githubcollected.OrganizationActions {
-	return githubcollected.OrganizationActions{
+	actions := githubcollected.OrganizationActions{
 		Organization: defaultOrg,
 		ActionsPermissions: &github.ActionsPermissions{
 			EnabledRepositories: config.enabledRepositories,
@@ -29,11 +30,17 @@ func newOrganizationActionsMock(config organizationActionsMockConfiguration) git
 			CanApprovePullRequestReviews: &config.workflowsCanApprovePRs,
 		},
 	}
+	if config.forkPRApprovalPolicy != nil {
+		actions.ForkPullRequestApproval = &types.ForkPullRequestApproval{ApprovalPolicy: config.forkPRApprovalPolicy}
+	}
+	return actions
 }
 
 func TestActions(t *testing.T) {
 	all := "all"
 	selected := "selected"
+	firstTimeContributors := "first_time_contributors"
+	allExternalContributors := "all_external_contributors"
 	tests := []struct {
 		name             string
 		policyName       string
@@ -109,6 +116,28 @@ func TestActions(t *testing.T) {
 				tokenDefaultPermission: "read",
 			},
 		},
+		{
+			name:             "fork pull request workflows of first time contributors only require approval",
+			policyName:       "fork_pr_workflows_do_not_require_approval",
+			shouldBeViolated: true,
+			args: organizationActionsMockConfiguration{
+				forkPRApprovalPolicy: &firstTimeContributors,
+			},
+		},
+		{
+			name:             "fork pull request workflows of all external contributors require approval",
+			policyName:       "fork_pr_workflows_do_not_require_approval",
+			shouldBeViolated: false,
+			args: organizationActionsMockConfiguration{
+				forkPRApprovalPolicy: &allExternalContributors,
+			},
+		},
+		{
+			name:             "fork pull request workflows approval setting is not available",
+			policyName:       "fork_pr_workflows_do_not_require_approval",
+			shouldBeViolated: false,
+			args:             organizationActionsMockConfiguration{},
+		},
 	}
 
 	for _, test := range tests {
@@ -2,9 +2,12 @@ package test
 
 import (
 	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	"github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 	"github.com/Legit-Labs/legitify/internal/common/scm_type"
 	"testing"
+
+	"github.com/xanzy/go-gitlab"
 )
 
 func makeEnterpriseForPolicy(policy string) githubcollected.Enterprise {
@@ -46,7 +49,41 @@ func TestEnterpriseNotificationRestrictionPolicy(t *testing.T) {
 	}
 }
 
-func enterpriseTestTemplate(t *testing.T, name string, mockData githubcollected.Enterprise, testedPolicyName string, expectFailure bool, scmType scm_type.ScmType) {
+func TestServerPasswordMinimumLength(t *testing.T) {
+	name := "Server password minimum length should be long enough"
+	testedPolicyName := "password_minimum_length_too_short"
+
+	options := map[int]bool{
+		8:  true,
+		12: false,
+		16: false,
+	}
+
+	for length, expectFailure := range options {
+		mockData := gitlab_collected.NewServer("url", &gitlab.Settings{MinimumPasswordLength: length})
+		enterpriseTestTemplate(t, name, mockData, testedPolicyName, expectFailure, scm_type.GitLab)
+	}
+}
+
+func TestServerTwoFactorGracePeriod(t *testing.T) {
+	name := "Server two-factor authentication grace period should not be too long"
+	testedPolicyName := "two_factor_grace_period_too_long"
+
+	enterpriseTestTemplate(t, name, gitlab_collected.NewServer("url", &gitlab.Settings{RequireTwoFactorAuthentication: true, TwoFactorGracePeriod: 720}), testedPolicyName, true, scm_type.GitLab)
+	enterpriseTestTemplate(t, name, gitlab_collected.NewServer("url", &gitlab.Settings{RequireTwoFactorAuthentication: true, TwoFactorGracePeriod: 48}), testedPolicyName, false, scm_type.GitLab)
+	// not applicable when two-factor authentication is not enforced (see require_two_factor_authentication_not_globally_enforced)
+	enterpriseTestTemplate(t, name, gitlab_collected.NewServer("url", &gitlab.Settings{TwoFactorGracePeriod: 720}), testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestServerSystemHooksLocalRequests(t *testing.T) {
+	name := "Server system hooks should not be allowed to be sent to the local network"
+	testedPolicyName := "system_hooks_are_allowed_to_be_sent_to_local_network"
+
+	enterpriseTestTemplate(t, name, gitlab_collected.NewServer("url", &gitlab.Settings{AllowLocalRequestsFromSystemHooks: true}), testedPolicyName, true, scm_type.GitLab)
+	enterpriseTestTemplate(t, name, gitlab_collected.NewServer("url", &gitlab.Settings{AllowLocalRequestsFromSystemHooks: false}), testedPolicyName, false, scm_type.GitLab)
+}
+
+func enterpriseTestTemplate(t *testing.T, name string, mockData interface{}, testedPolicyName string, expectFailure bool, scmType scm_type.ScmType) {
 	ns := namespace.Enterprise
 	PolicyTestTemplate(t, name, mockData, ns, testedPolicyName, expectFailure, scmType)
 }
@@ -45,9 +45,17 @@ func AssertQueryResult(opaResults []opa_engine.QueryResult, policyName string, s
 }
 
 func PolicyTestTemplate(t *testing.T, name string, mockData interface{}, ns namespace.Namespace, testedPolicyName string, expectFailure bool, scmType scm_type.ScmType) {
+	PolicyTestTemplateWithConfig(t, name, mockData, ns, testedPolicyName, nil, expectFailure, scmType)
+}
+
+// PolicyTestTemplateWithConfig queries the policies with the given configuration (see --policies-config), or with the default configuration when it is nil
+func PolicyTestTemplateWithConfig(t *testing.T, name string, mockData interface{}, ns namespace.Namespace, testedPolicyName string, config map[string]interface{}, expectFailure bool, scmType scm_type.ScmType) {
 	t.Run(name, func(t *testing.T) {
 		engine, err := opa.Load([]string{}, scmType)
 		require.Nil(t, err, "failed initializing opa client")
+		if config != nil {
+			engine.SetConfig(config)
+		}
 		ctx := context.Background()
 		result, err := engine.Query(ctx, ns, mockData)
 		require.Nil(t, err, "failed query")
@@ -2,20 +2,33 @@ package test
 
 import (
 	"github.com/Legit-Labs/legitify/internal/common/scm_type"
+	"github.com/Legit-Labs/legitify/internal/opa"
 	"github.com/google/go-github/v53/github"
+	"github.com/xanzy/go-gitlab"
 	"testing"
 	"time"
 
 	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
+	gitlabcollected "github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
 )
 
 type organizationMockConfiguration struct {
-	config     map[string]interface{}
-	ssoEnabled *bool
-	name       string
-	url        string
-	secrets    []*githubcollected.OrganizationSecret
+	config      map[string]interface{}
+	ssoEnabled  *bool
+	name        string
+	url         string
+	secrets     []*githubcollected.OrganizationSecret
+	sshCAs      []*githubcollected.SSHCertificateAuthority
+	privileges  *githubcollected.MemberPrivileges
+	workflows   []*githubcollected.RequiredWorkflow
+	tokens      []*githubcollected.ClassicToken
+	invitations []*githubcollected.OrganizationInvitation
+	packages    []*githubcollected.OrganizationPackage
+	withoutSSO  []string
+	outside     *int
+	runners     []*githubcollected.OrganizationRunnerGroup
+	rulesets    []*github.Ruleset
 }
 
 func newOrganizationMock(config organizationMockConfiguration) githubcollected.Organization {
@@ -42,6 +55,17 @@ func newOrganizationMock(config organizationMockConfiguration) githubcollected.O
 		SamlEnabled:  &samlEnabledMockResult,
 		Hooks:        hooks,
 		OrgSecrets:   orgSecrets,
+
+		SSHCertificateAuthorities: config.sshCAs,
+		MemberPrivileges:          config.privileges,
+		RequiredWorkflows:         config.workflows,
+		ClassicTokens:             config.tokens,
+		Invitations:               config.invitations,
+		Packages:                  config.packages,
+		MembersWithoutSSO:         config.withoutSSO,
+		OutsideCollaboratorsCount: config.outside,
+		RunnerGroups:              config.runners,
+		Rulesets:                  config.rulesets,
 	}
 }
 
@@ -170,6 +194,239 @@ func TestOrganization(t *testing.T) {
 				secrets: nil,
 			},
 		},
+		{
+			name:             "Organization has no ssh certificate authorities",
+			policyName:       "organization_not_using_ssh_certificate_authorities",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				sshCAs: []*githubcollected.SSHCertificateAuthority{},
+			},
+		},
+		{
+			name:             "Organization has ssh certificate authorities",
+			policyName:       "organization_not_using_ssh_certificate_authorities",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				sshCAs: []*githubcollected.SSHCertificateAuthority{
+					{
+						ID:  1,
+						Key: "ssh-ed25519 AAAA",
+					},
+				},
+			},
+		},
+		{
+			name:             "Organization ssh certificate authorities are not collected",
+			policyName:       "organization_not_using_ssh_certificate_authorities",
+			shouldBeViolated: false,
+			args:             organizationMockConfiguration{},
+		},
+		{
+			name:             "Members can fork private repositories",
+			policyName:       "members_can_fork_private_repositories",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				privileges: &githubcollected.MemberPrivileges{
+					MembersCanForkPrivateRepositories: &boolTrue,
+				},
+			},
+		},
+		{
+			name:             "Members cannot fork private repositories",
+			policyName:       "members_can_fork_private_repositories",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				privileges: &githubcollected.MemberPrivileges{
+					BlockedUsersCount:                 3,
+					MembersCanForkPrivateRepositories: &boolFalse,
+				},
+			},
+		},
+		{
+			name:             "Private repositories forking setting is not collected",
+			policyName:       "members_can_fork_private_repositories",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				privileges: &githubcollected.MemberPrivileges{},
+			},
+		},
+		{
+			name:             "Classic token without expiration is authorized",
+			policyName:       "organization_has_classic_tokens_without_expiration",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				tokens: []*githubcollected.ClassicToken{
+					{Login: "octocat", Title: "ci", Scopes: []string{"repo"}},
+				},
+			},
+		},
+		{
+			name:             "Classic tokens with expiration are authorized",
+			policyName:       "organization_has_classic_tokens_without_expiration",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				tokens: []*githubcollected.ClassicToken{
+					{Login: "octocat", Title: "ci", Scopes: []string{"repo"}, ExpiresAt: "2026-01-01T00:00:00Z"},
+				},
+			},
+		},
+		{
+			name:             "Classic tokens were not collected",
+			policyName:       "organization_has_classic_tokens_without_expiration",
+			shouldBeViolated: false,
+			args:             organizationMockConfiguration{},
+		},
+		{
+			name:             "Members did not link their SSO identity",
+			policyName:       "organization_members_not_linked_to_sso",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				ssoEnabled: &boolTrue,
+				withoutSSO: []string{"octocat"},
+			},
+		},
+		{
+			name:             "All the members linked their SSO identity",
+			policyName:       "organization_members_not_linked_to_sso",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				ssoEnabled: &boolTrue,
+				withoutSSO: []string{},
+			},
+		},
+		{
+			name:             "SSO identities were not collected",
+			policyName:       "organization_members_not_linked_to_sso",
+			shouldBeViolated: false,
+			args:             organizationMockConfiguration{},
+		},
+		{
+			name:             "Invitation is pending for too long",
+			policyName:       "organization_invitation_is_stale",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				invitations: []*githubcollected.OrganizationInvitation{
+					{Invitee: "octocat", Inviter: "admin", Role: "direct_member", CreatedAt: github.Int(int(time.Now().AddDate(0, 0, -45).UnixNano()))},
+				},
+			},
+		},
+		{
+			name:             "Invitation is recent",
+			policyName:       "organization_invitation_is_stale",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				invitations: []*githubcollected.OrganizationInvitation{
+					{Invitee: "octocat", Inviter: "admin", Role: "direct_member", CreatedAt: github.Int(int(time.Now().AddDate(0, 0, -1).UnixNano()))},
+				},
+			},
+		},
+		{
+			name:             "Invitation without a creation time",
+			policyName:       "organization_invitation_is_stale",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				invitations: []*githubcollected.OrganizationInvitation{
+					{Invitee: "octocat", Inviter: "admin", Role: "direct_member"},
+				},
+			},
+		},
+		{
+			name:             "Invitation grants the owner role",
+			policyName:       "organization_invitation_grants_owner_role",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				invitations: []*githubcollected.OrganizationInvitation{
+					{Invitee: "octocat@example.com", Inviter: "admin", Role: "admin"},
+				},
+			},
+		},
+		{
+			name:             "Invitation grants the member role",
+			policyName:       "organization_invitation_grants_owner_role",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				invitations: []*githubcollected.OrganizationInvitation{
+					{Invitee: "octocat", Inviter: "admin", Role: "direct_member", CreatedAt: github.Int(int(time.Now().UnixNano()))},
+				},
+			},
+		},
+		{
+			name:             "Public package of a private repository",
+			policyName:       "organization_has_public_packages_of_non_public_repositories",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				packages: []*githubcollected.OrganizationPackage{
+					{Name: "image", PackageType: "container", Visibility: "public", Repository: "org/repo", RepositoryVisibility: "private"},
+				},
+			},
+		},
+		{
+			name:             "Public package of a public repository",
+			policyName:       "organization_has_public_packages_of_non_public_repositories",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				packages: []*githubcollected.OrganizationPackage{
+					{Name: "image", PackageType: "container", Visibility: "public", Repository: "org/repo", RepositoryVisibility: "public"},
+					{Name: "library", PackageType: "npm", Visibility: "private", Repository: "org/internal", RepositoryVisibility: "internal"},
+				},
+			},
+		},
+		{
+			name:             "Packages were not collected",
+			policyName:       "organization_has_public_packages_of_non_public_repositories",
+			shouldBeViolated: false,
+			args:             organizationMockConfiguration{},
+		},
+		{
+			name:             "Runner group with runners allows public repositories",
+			policyName:       "organization_runner_group_allows_public_repositories",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				runners: []*githubcollected.OrganizationRunnerGroup{
+					{Name: "Default", Visibility: "all", Default: true, Runners: []string{"builder"}},
+					{Name: "open-source", Visibility: "selected", AllowsPublicRepositories: true, Runners: []string{"oss-1", "oss-2"}},
+				},
+			},
+		},
+		{
+			name:             "Runner group without runners allows public repositories",
+			policyName:       "organization_runner_group_allows_public_repositories",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				runners: []*githubcollected.OrganizationRunnerGroup{
+					{Name: "Default", Visibility: "all", Default: true, AllowsPublicRepositories: true, Runners: []string{}},
+					{Name: "private", Visibility: "selected", Runners: []string{"builder"}},
+				},
+			},
+		},
+		{
+			name:             "Organization has no active default branch ruleset",
+			policyName:       "organization_missing_default_branch_ruleset",
+			shouldBeViolated: true,
+			args: organizationMockConfiguration{
+				rulesets: []*github.Ruleset{
+					newOrganizationRuleset("evaluate", "branch", "~DEFAULT_BRANCH"),
+					newOrganizationRuleset("active", "tag", "~ALL"),
+					newOrganizationRuleset("active", "branch", "refs/heads/release"),
+				},
+			},
+		},
+		{
+			name:             "Organization has an active default branch ruleset",
+			policyName:       "organization_missing_default_branch_ruleset",
+			shouldBeViolated: false,
+			args: organizationMockConfiguration{
+				rulesets: []*github.Ruleset{
+					newOrganizationRuleset("active", "branch", "~DEFAULT_BRANCH"),
+				},
+			},
+		},
+		{
+			name:             "Organization rulesets are not collected",
+			policyName:       "organization_missing_default_branch_ruleset",
+			shouldBeViolated: false,
+			args:             organizationMockConfiguration{},
+		},
 	}
 
 	for _, test := range tests {
@@ -177,3 +434,171 @@ func TestOrganization(t *testing.T) {
 			namespace.Organization, test.policyName, test.shouldBeViolated, scm_type.GitHub)
 	}
 }
+
+func newOrganizationRuleset(enforcement, target, ref string) *github.Ruleset {
+	return &github.Ruleset{
+		Name:        ref,
+		Target:      &target,
+		Enforcement: enforcement,
+		Conditions: &github.RulesetConditions{
+			RefName: &github.RulesetRefConditionParameters{Include: []string{ref}, Exclude: []string{}},
+		},
+	}
+}
+
+func TestOrganizationCriticalRepositoriesRequiredWorkflow(t *testing.T) {
+	policyName := "critical_repository_missing_required_workflow"
+	selected := &githubcollected.RequiredWorkflow{
+		Name:                 "security",
+		State:                "active",
+		Scope:                "selected",
+		SelectedRepositories: []string{"api"},
+	}
+	all := &githubcollected.RequiredWorkflow{
+		Name:  "security",
+		State: "active",
+		Scope: "all",
+	}
+	inactive := &githubcollected.RequiredWorkflow{
+		Name:  "security",
+		State: "inactive",
+		Scope: "all",
+	}
+
+	tests := []struct {
+		name             string
+		workflows        []*githubcollected.RequiredWorkflow
+		shouldBeViolated bool
+	}{
+		{name: "critical repository is not selected", workflows: []*githubcollected.RequiredWorkflow{selected}, shouldBeViolated: true},
+		{name: "required workflow is inactive", workflows: []*githubcollected.RequiredWorkflow{inactive}, shouldBeViolated: true},
+		{name: "no required workflows", workflows: []*githubcollected.RequiredWorkflow{}, shouldBeViolated: true},
+		{name: "required workflow applies to all repositories", workflows: []*githubcollected.RequiredWorkflow{all}, shouldBeViolated: false},
+		{name: "required workflows were not collected", workflows: nil, shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["critical_repositories"] = []interface{}{"api", "payments"}
+
+	for _, test := range tests {
+		mock := newOrganizationMock(organizationMockConfiguration{workflows: test.workflows})
+		PolicyTestTemplateWithConfig(t, test.name, mock, namespace.Organization, policyName, config, test.shouldBeViolated, scm_type.GitHub)
+	}
+
+	// without critical repositories, there is nothing to verify
+	PolicyTestTemplate(t, "no critical repositories", newOrganizationMock(organizationMockConfiguration{workflows: []*githubcollected.RequiredWorkflow{}}),
+		namespace.Organization, policyName, false, scm_type.GitHub)
+}
+
+func TestOrganizationOutsideCollaborators(t *testing.T) {
+	policyName := "organization_has_too_many_outside_collaborators"
+	count := func(n int) *int { return &n }
+
+	tests := []struct {
+		name             string
+		outside          *int
+		maxOutside       float64
+		shouldBeViolated bool
+	}{
+		{name: "more outside collaborators than the default", outside: count(11), shouldBeViolated: true},
+		{name: "outside collaborators within the default", outside: count(10), shouldBeViolated: false},
+		{name: "more outside collaborators than configured", outside: count(3), maxOutside: 2, shouldBeViolated: true},
+		{name: "outside collaborators were not collected", outside: nil, shouldBeViolated: false},
+	}
+
+	for _, test := range tests {
+		var config map[string]interface{}
+		if test.maxOutside > 0 {
+			config = opa.DefaultConfig()
+			config["max_outside_collaborators"] = test.maxOutside
+		}
+
+		mock := newOrganizationMock(organizationMockConfiguration{outside: test.outside})
+		PolicyTestTemplateWithConfig(t, test.name, mock, namespace.Organization, policyName, config, test.shouldBeViolated, scm_type.GitHub)
+	}
+}
+
+func TestGitlabGroupDeployTokens(t *testing.T) {
+	makeMockData := func(tokens ...gitlabcollected.DeployToken) gitlabcollected.Organization {
+		return gitlabcollected.Organization{
+			Group:        &gitlab.Group{},
+			DeployTokens: tokens,
+		}
+	}
+
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	neverExpires := gitlabcollected.DeployToken{Name: "ci", Scopes: []string{"read_repository"}}
+	writeScope := gitlabcollected.DeployToken{Name: "publish", Scopes: []string{"write_package_registry"}, ExpiresAt: &expiresAt}
+	expired := gitlabcollected.DeployToken{Name: "old", Scopes: []string{"write_repository"}, Expired: true}
+
+	PolicyTestTemplate(t, "group deploy token never expires", makeMockData(neverExpires),
+		namespace.Organization, "group_deploy_token_never_expires", true, scm_type.GitLab)
+	PolicyTestTemplate(t, "group deploy tokens expire", makeMockData(writeScope, expired),
+		namespace.Organization, "group_deploy_token_never_expires", false, scm_type.GitLab)
+	PolicyTestTemplate(t, "group deploy token has write scope", makeMockData(writeScope),
+		namespace.Organization, "group_deploy_token_has_write_scope", true, scm_type.GitLab)
+	PolicyTestTemplate(t, "group deploy tokens are read only", makeMockData(neverExpires, expired),
+		namespace.Organization, "group_deploy_token_has_write_scope", false, scm_type.GitLab)
+}
+
+func TestGitlabGroupAccessTokens(t *testing.T) {
+	makeMockData := func(tokens ...gitlabcollected.AccessToken) gitlabcollected.Organization {
+		return gitlabcollected.Organization{
+			Group:        &gitlab.Group{},
+			AccessTokens: tokens,
+		}
+	}
+
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	neverExpires := gitlabcollected.AccessToken{Name: "bot", Scopes: []string{"read_api"}, Active: true}
+	apiScope := gitlabcollected.AccessToken{Name: "automation", Scopes: []string{"api"}, ExpiresAt: &expiresAt, Active: true}
+	inactive := gitlabcollected.AccessToken{Name: "old", Scopes: []string{"api"}}
+
+	PolicyTestTemplate(t, "group access token never expires", makeMockData(neverExpires),
+		namespace.Organization, "group_access_token_never_expires", true, scm_type.GitLab)
+	PolicyTestTemplate(t, "group access tokens expire", makeMockData(apiScope, inactive),
+		namespace.Organization, "group_access_token_never_expires", false, scm_type.GitLab)
+	PolicyTestTemplate(t, "group access token has api scope", makeMockData(apiScope),
+		namespace.Organization, "group_access_token_has_api_scope", true, scm_type.GitLab)
+	PolicyTestTemplate(t, "group access tokens are narrowly scoped", makeMockData(neverExpires, inactive),
+		namespace.Organization, "group_access_token_has_api_scope", false, scm_type.GitLab)
+}
+
+func TestGitlabGroupIntegrations(t *testing.T) {
+	makeMockData := func(integrations ...gitlabcollected.Integration) gitlabcollected.Organization {
+		return gitlabcollected.Organization{
+			Group:        &gitlab.Group{},
+			Integrations: integrations,
+		}
+	}
+
+	slack := gitlabcollected.Integration{Slug: "slack", Title: "Slack notifications", Hosts: []string{"hooks.slack.com"}}
+	jenkins := gitlabcollected.Integration{Slug: "jenkins", Title: "Jenkins", Hosts: []string{"ci.attacker.io"}}
+
+	tests := []struct {
+		name             string
+		policyName       string
+		group            gitlabcollected.Organization
+		unconfigured     bool
+		shouldBeViolated bool
+	}{
+		{name: "unapproved integration", policyName: "group_has_unapproved_integrations", group: makeMockData(slack, jenkins), shouldBeViolated: true},
+		{name: "approved integrations", policyName: "group_has_unapproved_integrations", group: makeMockData(slack), shouldBeViolated: false},
+		{name: "no approved integrations configured", policyName: "group_has_unapproved_integrations", group: makeMockData(jenkins), unconfigured: true, shouldBeViolated: false},
+		{name: "integration with an untrusted host", policyName: "group_integration_untrusted_host", group: makeMockData(slack, jenkins), shouldBeViolated: true},
+		{name: "integrations with trusted hosts", policyName: "group_integration_untrusted_host", group: makeMockData(slack), shouldBeViolated: false},
+		{name: "no trusted domains configured", policyName: "group_integration_untrusted_host", group: makeMockData(jenkins), unconfigured: true, shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["approved_integrations"] = []interface{}{"slack"}
+	config["trusted_webhook_domains"] = []interface{}{"slack.com"}
+
+	for _, test := range tests {
+		testConfig := config
+		if test.unconfigured {
+			testConfig = nil
+		}
+		PolicyTestTemplateWithConfig(t, test.name, test.group, namespace.Organization, test.policyName, testConfig, test.shouldBeViolated, scm_type.GitLab)
+	}
+}
@@ -1,6 +1,8 @@
 package test
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -10,8 +12,12 @@ import (
 
 	githubcollected "github.com/Legit-Labs/legitify/internal/collected/github"
 	gitlabcollected "github.com/Legit-Labs/legitify/internal/collected/gitlab_collected"
+	githubcollectors "github.com/Legit-Labs/legitify/internal/collectors/github"
 	"github.com/Legit-Labs/legitify/internal/common/namespace"
+	"github.com/Legit-Labs/legitify/internal/opa"
 	"github.com/google/go-github/v53/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/require"
 )
 
 func repositoryTestTemplate(t *testing.T, name string, mockData interface{}, testedPolicyName string, expectFailure bool, scmType scm_type.ScmType) {
@@ -64,6 +70,21 @@ func TestRepositoryBranchProtection(t *testing.T) {
 	}
 }
 
+func TestRepositoryBranchProtectionWithoutReviews(t *testing.T) {
+	name := "repository should have branch protection that requires reviews"
+	testedPolicyName := "missing_default_branch_protection"
+	makeMockData := func(withoutReviews bool) githubcollected.Repository {
+		repo := makeRepoForBranchProtection(githubcollected.GitHubQLBranchProtectionRule{
+			RequiredApprovingReviewCount: github.Int(0),
+		})
+		repo.ProtectionWithoutReviews = github.Bool(withoutReviews)
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(true), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(false), testedPolicyName, false, scm_type.GitHub)
+}
+
 func TestRepositoryForcePush(t *testing.T) {
 	name := "repository should have branch protection: force push"
 	testedPolicyName := "missing_default_branch_protection_force_push"
@@ -214,6 +235,24 @@ func TestRepositoryBranchesUpToDate(t *testing.T) {
 		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, !flag, scm_type.GitHub)
 	}
 }
+func TestRepositoryRequiresDeployments(t *testing.T) {
+	name := "repository should require deployments to succeed before merge"
+	testedPolicyName := "requires_deployments_before_merge"
+	makeMockData := func(flag bool) githubcollected.Repository {
+		var environments []string
+		if flag {
+			environments = []string{"staging"}
+		}
+		return makeRepoForBranchProtection(githubcollected.GitHubQLBranchProtectionRule{
+			RequiresDeployments:            github.Bool(flag),
+			RequiredDeploymentEnvironments: environments,
+		})
+	}
+	for _, flag := range bools {
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, !flag, scm_type.GitHub)
+	}
+}
+
 func TestRepositorySignedCommits(t *testing.T) {
 	name := "signed commits should be enabled"
 	testedPolicyName := "no_signed_commits"
@@ -225,6 +264,16 @@ func TestRepositorySignedCommits(t *testing.T) {
 	for _, flag := range bools {
 		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, !flag, scm_type.GitHub)
 	}
+
+	// the consolidated requirement of the protection sources takes precedence (e.g. over rulesets in evaluate mode)
+	evaluated := makeMockData(false)
+	evaluated.RulesSet = []*types.RepositoryRule{{Type: "required_signatures", Enforcement: "evaluate"}}
+	evaluated.RequiredSignatures = &githubcollected.RequiredSignatures{BranchProtection: github.Bool(false), Rulesets: github.Bool(false)}
+	repositoryTestTemplate(t, name, evaluated, testedPolicyName, true, scm_type.GitHub)
+
+	required := makeMockData(false)
+	required.RequiredSignatures = &githubcollected.RequiredSignatures{BranchProtection: github.Bool(false), Rulesets: github.Bool(true), Effective: true}
+	repositoryTestTemplate(t, name, required, testedPolicyName, false, scm_type.GitHub)
 }
 func TestRepositoryVulnerabilityAlerts(t *testing.T) {
 	name := "vulnerability alerts not enabled"
@@ -309,6 +358,70 @@ func TestRepositoryActionsSettingsActionsCanApprovePullRequests(t *testing.T) {
 	}
 }
 
+func TestRepositoryActionsForkPullRequestWorkflowsApproval(t *testing.T) {
+	name := "repository fork pull request workflows do not require approval"
+	testedPolicyName := "fork_pr_workflows_do_not_require_approval"
+	makeMockData := func(policy *string) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		if policy != nil {
+			repo.ActionsForkPRApproval = &types.ForkPullRequestApproval{ApprovalPolicy: policy}
+		}
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(github.String("first_time_contributors_new_to_github")), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(github.String("first_time_contributors")), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(github.String("all_external_contributors")), testedPolicyName, false, scm_type.GitHub)
+	// not applicable when the setting is unavailable
+	repositoryTestTemplate(t, name, makeMockData(nil), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryDependencyUpdateTool(t *testing.T) {
+	name := "active repository has no dependency update tool"
+	testedPolicyName := "repository_has_no_dependency_update_tool"
+	makeMockData := func(pushedAt time.Time, files *githubcollected.DependencyManagementFiles) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", PushedAt: &githubv4.DateTime{Time: pushedAt}})
+		repo.DependencyManagementFiles = files
+		return repo
+	}
+	recently := time.Now().AddDate(0, -1, 0)
+
+	repositoryTestTemplate(t, name, makeMockData(recently, &githubcollected.DependencyManagementFiles{HasFundingFile: true}), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(recently, &githubcollected.DependencyManagementFiles{HasDependabotConfig: true}), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(recently, &githubcollected.DependencyManagementFiles{HasRenovateConfig: true}), testedPolicyName, false, scm_type.GitHub)
+	// not applicable for inactive repositories, nor when the files were not collected
+	repositoryTestTemplate(t, name, makeMockData(time.Now().AddDate(-2, 0, 0), &githubcollected.DependencyManagementFiles{}), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(recently, nil), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryReleaseProvenance(t *testing.T) {
+	name := "latest release assets have no provenance"
+	testedPolicyName := "repository_release_has_no_provenance"
+	makeMockData := func(release *githubcollected.LatestRelease) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.LatestRelease = release
+		return repo
+	}
+	makeRelease := func(assetsCount int, signatures []string, provenance []string, hasAttestations bool) *githubcollected.LatestRelease {
+		return &githubcollected.LatestRelease{
+			TagName:          "v1.0.0",
+			AssetsCount:      assetsCount,
+			SignatureAssets:  signatures,
+			ProvenanceAssets: provenance,
+			HasAttestations:  hasAttestations,
+		}
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(makeRelease(2, []string{}, []string{}, false)), testedPolicyName, true, scm_type.GitHub)
+	// signatures alone are not a provenance
+	repositoryTestTemplate(t, name, makeMockData(makeRelease(2, []string{"app.tar.gz.sig"}, []string{}, false)), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(makeRelease(2, []string{}, []string{"multiple.intoto.jsonl"}, false)), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(makeRelease(2, []string{}, []string{}, true)), testedPolicyName, false, scm_type.GitHub)
+	// not applicable for releases without assets, nor for repositories without releases
+	repositoryTestTemplate(t, name, makeMockData(makeRelease(0, []string{}, []string{}, false)), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(nil), testedPolicyName, false, scm_type.GitHub)
+}
+
 func TestRepositoryWithNoStaleSecrets(t *testing.T) {
 	name := "repository has no secrets"
 	testedPolicyName := "repository_secret_is_stale"
@@ -393,6 +506,565 @@ func TestRepositorySecretScanning(t *testing.T) {
 	}
 }
 
+func TestRepositorySecretScanningValidityChecks(t *testing.T) {
+	name := "repository secret scanning validity checks are disabled"
+	testedPolicyName := "secret_scanning_validity_checks_not_enabled"
+	makeMockData := func(flag string) githubcollected.Repository {
+		return githubcollected.Repository{
+			SecretScanningFeatures: &githubcollected.SecretScanningFeatures{ValidityChecks: &flag},
+		}
+	}
+
+	options := map[bool]string{
+		false: "enabled",
+		true:  "disabled",
+	}
+
+	for _, expectFailure := range bools {
+		flag := options[expectFailure]
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitHub)
+	}
+
+	// the sub-features are not reported by every server and plan
+	repositoryTestTemplate(t, name, githubcollected.Repository{}, testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryDependencyEcosystemMissingLockfile(t *testing.T) {
+	name := "repository dependency ecosystem is missing a lockfile"
+	testedPolicyName := "dependency_ecosystem_missing_lockfile"
+	makeMockData := func(flag []githubcollected.DependencyEcosystem) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.DependencyEcosystems = flag
+		return repo
+	}
+
+	options := map[bool][]githubcollected.DependencyEcosystem{
+		false: {
+			{Ecosystem: "npm", Manifests: []string{"package.json"}, Lockfiles: []string{"package-lock.json"}, LockfileSupported: true, HasLockfile: true},
+			{Ecosystem: "maven", Manifests: []string{"pom.xml"}, LockfileSupported: false},
+			{Ecosystem: "go", Manifests: []string{"go.mod", "go.sum"}, LockfileSupported: false},
+		},
+		true: {
+			{Ecosystem: "cargo", Manifests: []string{"Cargo.toml"}, LockfileSupported: true, HasLockfile: false},
+		},
+	}
+
+	for _, expectFailure := range bools {
+		flag := options[expectFailure]
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryTemplateWithSecrets(t *testing.T) {
+	name := "template repository has secrets"
+	testedPolicyName := "template_repository_has_secrets"
+	makeMockData := func(isTemplate bool) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", IsTemplate: isTemplate})
+		repo.RepoSecrets = []*githubcollected.RepositorySecret{
+			{
+				Name:      "test1",
+				UpdatedAt: int(time.Now().UnixNano()),
+			},
+		}
+		return repo
+	}
+
+	options := map[bool]bool{
+		false: false,
+		true:  true,
+	}
+
+	for _, expectFailure := range bools {
+		flag := options[expectFailure]
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryUnapprovedIntegrations(t *testing.T) {
+	testedPolicyName := "repository_has_unapproved_integrations"
+	makeMockData := func(slugs ...string) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		for i, slug := range slugs {
+			repo.Integrations = append(repo.Integrations, githubcollected.RepositoryIntegration{
+				AppSlug:             slug,
+				AppID:               int64(i + 1),
+				InstallationID:      int64(i + 100),
+				RepositorySelection: "selected",
+			})
+		}
+		return repo
+	}
+
+	tests := []struct {
+		name             string
+		repo             githubcollected.Repository
+		unconfigured     bool
+		shouldBeViolated bool
+	}{
+		{name: "unapproved integration", repo: makeMockData("dependabot", "some-app"), shouldBeViolated: true},
+		{name: "approved integrations", repo: makeMockData("dependabot"), shouldBeViolated: false},
+		{name: "no integrations", repo: makeMockData(), shouldBeViolated: false},
+		{name: "no approved integrations configured", repo: makeMockData("some-app"), unconfigured: true, shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["approved_integrations"] = []interface{}{"dependabot"}
+
+	for _, test := range tests {
+		testConfig := config
+		if test.unconfigured {
+			testConfig = nil
+		}
+		PolicyTestTemplateWithConfig(t, test.name, test.repo, namespace.Repository, testedPolicyName, testConfig, test.shouldBeViolated, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryArchivalCandidate(t *testing.T) {
+	name := "inactive repository should be archived"
+	testedPolicyName := "repository_archival_candidate"
+	makeMockData := func(pushedAt time.Time) githubcollected.Repository {
+		return makeRepo(githubcollected.GitHubQLRepository{
+			Name:     "REPO",
+			PushedAt: &githubv4.DateTime{Time: pushedAt},
+		})
+	}
+
+	options := map[bool]time.Time{
+		false: time.Now().AddDate(0, -1, 0),
+		true:  time.Now().AddDate(-2, 0, 0),
+	}
+
+	for _, expectFailure := range bools {
+		flag := options[expectFailure]
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryTooManyAdmins(t *testing.T) {
+	name := "repository has too many admins"
+	testedPolicyName := "repository_has_too_many_admins"
+	admin := &github.User{Permissions: map[string]bool{"admin": true}}
+	adminTeam := &github.Team{Permission: github.String("admin")}
+	makeMockData := func(collaborators []*github.User, teams []*github.Team) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.Collaborators = collaborators
+		repo.Teams = teams
+		return repo
+	}
+
+	options := map[bool]githubcollected.Repository{
+		false: makeMockData([]*github.User{admin, admin}, []*github.Team{adminTeam}),
+		true:  makeMockData([]*github.User{admin, admin, admin}, []*github.Team{adminTeam}),
+	}
+
+	for _, expectFailure := range bools {
+		repositoryTestTemplate(t, name, options[expectFailure], testedPolicyName, expectFailure, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryRulesetBypass(t *testing.T) {
+	name := "users are allowed to bypass ruleset rules"
+	testedPolicyName := "users_allowed_to_bypass_ruleset"
+	makeMockData := func(enforcement string) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.RulesSet = []*types.RepositoryRule{
+			{
+				Type:        "deletion",
+				Ruleset:     &github.Ruleset{BypassActors: []*github.BypassActor{{ActorType: github.String("Team")}}},
+				Enforcement: "active",
+			},
+			{
+				Type:        "non_fast_forward",
+				Ruleset:     &github.Ruleset{BypassActors: []*github.BypassActor{}},
+				Enforcement: enforcement,
+			},
+		}
+		return repo
+	}
+
+	// a ruleset in evaluate mode does not block anyone, so it cannot compensate for the bypassable one
+	options := map[bool]string{
+		false: "active",
+		true:  "evaluate",
+	}
+
+	for _, expectFailure := range bools {
+		repositoryTestTemplate(t, name, makeMockData(options[expectFailure]), testedPolicyName, expectFailure, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryRulesetEnforcement(t *testing.T) {
+	makeMockData := func(ruleType string, parameters string, enforcement string) githubcollected.Repository {
+		repo := makeRepoForBranch(githubcollected.GitHubQLBranch{})
+		rawParameters := json.RawMessage(parameters)
+		repo.RulesSet = []*types.RepositoryRule{{
+			Type:        ruleType,
+			Parameters:  &rawParameters,
+			Ruleset:     &github.Ruleset{BypassActors: []*github.BypassActor{}},
+			Enforcement: enforcement,
+		}}
+		return repo
+	}
+
+	tests := []struct {
+		policyName string
+		ruleType   string
+		parameters string
+	}{
+		{policyName: "missing_default_branch_protection", ruleType: "pull_request", parameters: `{"required_approving_review_count": 1}`},
+		{policyName: "requires_deployments_before_merge", ruleType: "required_deployments", parameters: `{"required_deployment_environments": ["staging"]}`},
+		{policyName: "requires_status_checks", ruleType: "required_status_checks", parameters: `{"required_status_checks": [{"context": "build"}]}`},
+		{policyName: "code_review_not_required", ruleType: "pull_request", parameters: `{"required_approving_review_count": 1}`},
+		{policyName: "code_review_not_limited_to_code_owners", ruleType: "pull_request", parameters: `{"require_code_owner_review": true}`},
+		{policyName: "non_linear_history", ruleType: "required_linear_history", parameters: `{}`},
+	}
+
+	// only the rules of active rulesets protect the branch: rulesets in evaluate (dry-run) mode or disabled ones do not block anything
+	enforcements := map[string]bool{
+		"active":   false,
+		"evaluate": true,
+		"disabled": true,
+	}
+
+	for _, test := range tests {
+		for enforcement, expectFailure := range enforcements {
+			name := fmt.Sprintf("%s ruleset rule in %s mode", test.ruleType, enforcement)
+			repositoryTestTemplate(t, name, makeMockData(test.ruleType, test.parameters, enforcement), test.policyName, expectFailure, scm_type.GitHub)
+		}
+	}
+}
+
+func TestRepositoryDependabotSecurityUpdates(t *testing.T) {
+	name := "dependabot security updates should be enabled"
+	testedPolicyName := "dependabot_security_updates_not_enabled"
+	makeMockData := func(alerts *bool, fixes *types.AutomatedSecurityFixes) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.VulnerabilityAlertsEnabled = alerts
+		repo.AutomatedSecurityFixes = fixes
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(github.Bool(true), &types.AutomatedSecurityFixes{Enabled: false}), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(github.Bool(true), &types.AutomatedSecurityFixes{Enabled: true, Paused: true}), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(github.Bool(true), &types.AutomatedSecurityFixes{Enabled: true}), testedPolicyName, false, scm_type.GitHub)
+	// not applicable when alerts are disabled (covered by vulnerability_alerts_not_enabled), nor when the setting is unavailable
+	repositoryTestTemplate(t, name, makeMockData(github.Bool(false), &types.AutomatedSecurityFixes{Enabled: false}), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(github.Bool(true), nil), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryPrivateVulnerabilityReporting(t *testing.T) {
+	name := "public repository should enable private vulnerability reporting"
+	testedPolicyName := "private_vulnerability_reporting_not_enabled"
+	makeMockData := func(isPrivate bool, enabled *bool) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", IsPrivate: isPrivate})
+		repo.PrivateVulnerabilityReporting = enabled
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(false, github.Bool(false)), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(false, github.Bool(true)), testedPolicyName, false, scm_type.GitHub)
+	// not applicable for private repositories, nor when the setting is unavailable
+	repositoryTestTemplate(t, name, makeMockData(true, github.Bool(false)), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(false, nil), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryActionsArtifactRetention(t *testing.T) {
+	name := "private repository should limit actions artifact and log retention"
+	testedPolicyName := "actions_artifact_retention_too_long"
+	makeMockData := func(isPrivate bool, days *int) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", IsPrivate: isPrivate})
+		if days != nil {
+			repo.ActionsArtifactRetention = &types.ArtifactAndLogRetention{Days: days}
+		}
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(true, github.Int(90)), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(true, github.Int(30)), testedPolicyName, false, scm_type.GitHub)
+	// not applicable for public repositories, nor when the retention was not collected
+	repositoryTestTemplate(t, name, makeMockData(false, github.Int(90)), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(true, nil), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryActionsCacheUsage(t *testing.T) {
+	name := "private repository should limit actions cache usage"
+	testedPolicyName := "actions_cache_usage_too_large"
+	makeMockData := func(isPrivate bool, sizeMB *int64) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", IsPrivate: isPrivate})
+		if sizeMB != nil {
+			repo.ActionsCacheUsage = &github.ActionsCacheUsage{FullName: "org/REPO", ActiveCachesSizeInBytes: *sizeMB * 1024 * 1024, ActiveCachesCount: 3}
+		}
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(true, github.Int64(4096)), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(true, github.Int64(2048)), testedPolicyName, false, scm_type.GitHub)
+	// not applicable for public repositories, nor when the cache usage was not collected
+	repositoryTestTemplate(t, name, makeMockData(false, github.Int64(4096)), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(true, nil), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryAllowForking(t *testing.T) {
+	name := "private repository should not be forkable"
+	testedPolicyName := "forking_allowed_for_repository"
+	// the effective setting is reconciled by the collector (see TestWithPrivateForking)
+	makeMockData := func(repoAllows bool, orgAllows *bool, effective bool) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", IsPrivate: true, ForkingAllowed: repoAllows})
+		repo.PrivateForking = &githubcollected.PrivateForking{
+			Organization: orgAllows,
+			Repository:   repoAllows,
+			Effective:    effective,
+		}
+		return repo
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(true, github.Bool(true), true), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(true, nil, true), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData(false, github.Bool(true), false), testedPolicyName, false, scm_type.GitHub)
+	// the organization setting overrides the repository one
+	repositoryTestTemplate(t, name, makeMockData(true, github.Bool(false), false), testedPolicyName, false, scm_type.GitHub)
+}
+
+func TestRepositorySecurityChecksOnDefaultBranch(t *testing.T) {
+	policyName := "security_checks_not_passing_on_default_branch"
+	makeMockData := func(checks []githubcollected.StatusCheck) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.DefaultBranchChecks = checks
+		return repo
+	}
+	check := func(name string, conclusion string) githubcollected.StatusCheck {
+		return githubcollected.StatusCheck{Name: name, Type: "check_run", Conclusion: conclusion}
+	}
+
+	tests := []struct {
+		name             string
+		checks           []githubcollected.StatusCheck
+		shouldBeViolated bool
+	}{
+		{name: "security check failed", checks: []githubcollected.StatusCheck{check("CodeQL", "FAILURE")}, shouldBeViolated: true},
+		{name: "security check is missing", checks: []githubcollected.StatusCheck{check("build", "SUCCESS")}, shouldBeViolated: true},
+		{name: "no checks reported", checks: []githubcollected.StatusCheck{}, shouldBeViolated: true},
+		{name: "security check succeeded", checks: []githubcollected.StatusCheck{check("build", "FAILURE"), check("CodeQL", "SUCCESS")}, shouldBeViolated: false},
+		{name: "checks were not collected", checks: nil, shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["security_checks"] = []interface{}{"CodeQL"}
+
+	for _, test := range tests {
+		PolicyTestTemplateWithConfig(t, test.name, makeMockData(test.checks), namespace.Repository, policyName, config, test.shouldBeViolated, scm_type.GitHub)
+	}
+
+	// without configured security checks, there is nothing to verify
+	repositoryTestTemplate(t, "no security checks", makeMockData([]githubcollected.StatusCheck{}), policyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryWebhookSensitiveEventsUntrustedUrl(t *testing.T) {
+	policyName := "repository_webhook_sensitive_events_untrusted_url"
+	makeMockData := func(url string, events ...string) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.Hooks = []*github.Hook{{
+			Name:   github.String("web"),
+			URL:    github.String("https://api.github.com/repos/owner/REPO/hooks/1"),
+			Config: map[string]interface{}{"url": url},
+			Events: events,
+		}}
+		return repo
+	}
+
+	tests := []struct {
+		name             string
+		repo             githubcollected.Repository
+		unconfigured     bool
+		shouldBeViolated bool
+	}{
+		{name: "sensitive events to an untrusted domain", repo: makeMockData("https://hooks.attacker.io/x", "push", "member"), shouldBeViolated: true},
+		{name: "all events to an untrusted domain", repo: makeMockData("https://hooks.attacker.io/x", "*"), shouldBeViolated: true},
+		{name: "lookalike of a trusted domain", repo: makeMockData("https://notexample.com/x", "team"), shouldBeViolated: true},
+		{name: "sensitive events to a trusted subdomain", repo: makeMockData("https://ci.Example.com:8443/hook", "member"), shouldBeViolated: false},
+		{name: "no sensitive events", repo: makeMockData("https://hooks.attacker.io/x", "push", "pull_request"), shouldBeViolated: false},
+		{name: "no trusted domains configured", repo: makeMockData("https://hooks.attacker.io/x", "member"), unconfigured: true, shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["trusted_webhook_domains"] = []interface{}{"example.com"}
+
+	for _, test := range tests {
+		testConfig := config
+		if test.unconfigured {
+			testConfig = nil
+		}
+		PolicyTestTemplateWithConfig(t, test.name, test.repo, namespace.Repository, policyName, testConfig, test.shouldBeViolated, scm_type.GitHub)
+	}
+}
+
+func TestCodeOwnersReviewRequiredWithoutCodeowners(t *testing.T) {
+	policyName := "code_owners_review_required_without_codeowners"
+	makeMockData := func(requiresCodeOwnerReviews bool, codeowners *githubcollected.Codeowners) githubcollected.Repository {
+		repo := makeRepoForBranchProtection(githubcollected.GitHubQLBranchProtectionRule{RequiresCodeOwnerReviews: &requiresCodeOwnerReviews})
+		repo.Codeowners = codeowners
+		return repo
+	}
+	missing := &githubcollected.Codeowners{Exists: false}
+	empty := &githubcollected.Codeowners{Exists: true, Path: ".github/CODEOWNERS", Content: "# owners are defined later\n\n"}
+	defined := &githubcollected.Codeowners{Exists: true, Path: "CODEOWNERS", Content: "# default owners\n* @org/maintainers\n"}
+
+	repositoryTestTemplate(t, "code owners review without a CODEOWNERS file", makeMockData(true, missing), policyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, "code owners review with an empty CODEOWNERS file", makeMockData(true, empty), policyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, "code owners review with a CODEOWNERS file", makeMockData(true, defined), policyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, "no code owners review", makeMockData(false, missing), policyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, "CODEOWNERS was not collected", makeMockData(true, nil), policyName, false, scm_type.GitHub)
+
+	rulesetRepo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+	parameters := json.RawMessage(`{"require_code_owner_review": true}`)
+	rulesetRepo.RulesSet = []*types.RepositoryRule{{
+		Type:        "pull_request",
+		Parameters:  &parameters,
+		Ruleset:     &github.Ruleset{BypassActors: []*github.BypassActor{}},
+		Enforcement: "active",
+	}}
+	rulesetRepo.Codeowners = missing
+	repositoryTestTemplate(t, "ruleset code owners review without a CODEOWNERS file", rulesetRepo, policyName, true, scm_type.GitHub)
+
+	// a ruleset in evaluate mode does not require anything
+	rulesetRepo.RulesSet[0].Enforcement = "evaluate"
+	repositoryTestTemplate(t, "evaluate mode ruleset code owners review without a CODEOWNERS file", rulesetRepo, policyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryPages(t *testing.T) {
+	makeMockData := func(pages *githubcollected.Pages) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.Pages = pages
+		return repo
+	}
+
+	tests := []struct {
+		name             string
+		policyName       string
+		pages            *githubcollected.Pages
+		shouldBeViolated bool
+	}{
+		{name: "https is not enforced", policyName: "pages_https_not_enforced", pages: &githubcollected.Pages{BuildType: "legacy", SourceBranch: "gh-pages", CNAME: "docs.example.com", HTTPSEnforced: github.Bool(false)}, shouldBeViolated: true},
+		{name: "https enforcement is not reported", policyName: "pages_https_not_enforced", pages: &githubcollected.Pages{BuildType: "legacy", SourceBranch: "gh-pages"}, shouldBeViolated: false},
+		{name: "https is enforced", policyName: "pages_https_not_enforced", pages: &githubcollected.Pages{BuildType: "workflow", HTTPSEnforced: github.Bool(true)}, shouldBeViolated: false},
+		{name: "pages is disabled", policyName: "pages_https_not_enforced", pages: nil, shouldBeViolated: false},
+		{name: "built from an unprotected branch", policyName: "pages_built_from_unprotected_branch", pages: &githubcollected.Pages{BuildType: "legacy", SourceBranch: "gh-pages", SourceBranchProtected: github.Bool(false)}, shouldBeViolated: true},
+		{name: "built from a protected branch", policyName: "pages_built_from_unprotected_branch", pages: &githubcollected.Pages{BuildType: "legacy", SourceBranch: "main", SourceBranchProtected: github.Bool(true)}, shouldBeViolated: false},
+		{name: "built by a workflow", policyName: "pages_built_from_unprotected_branch", pages: &githubcollected.Pages{BuildType: "workflow", HTTPSEnforced: github.Bool(true)}, shouldBeViolated: false},
+		{name: "source branch protection is unknown", policyName: "pages_built_from_unprotected_branch", pages: &githubcollected.Pages{BuildType: "legacy", SourceBranch: "gh-pages"}, shouldBeViolated: false},
+	}
+
+	for _, test := range tests {
+		repositoryTestTemplate(t, test.name, makeMockData(test.pages), test.policyName, test.shouldBeViolated, scm_type.GitHub)
+	}
+}
+
+func TestRepositoryDeployKeys(t *testing.T) {
+	makeMockData := func(keys ...githubcollected.DeployKey) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.DeployKeys = keys
+		return repo
+	}
+	daysAgo := func(days int) int {
+		return int(time.Now().AddDate(0, 0, -days).UnixNano())
+	}
+
+	tests := []struct {
+		name             string
+		policyName       string
+		repo             githubcollected.Repository
+		shouldBeViolated bool
+	}{
+		{name: "writable deploy key", policyName: "repository_deploy_key_is_writable", repo: makeMockData(githubcollected.DeployKey{Title: "ci", ReadOnly: true}, githubcollected.DeployKey{Title: "deploy", ReadOnly: false}), shouldBeViolated: true},
+		{name: "read-only deploy keys", policyName: "repository_deploy_key_is_writable", repo: makeMockData(githubcollected.DeployKey{Title: "ci", ReadOnly: true}), shouldBeViolated: false},
+		{name: "deploy keys were not collected", policyName: "repository_deploy_key_is_writable", repo: makeMockData(), shouldBeViolated: false},
+		{name: "old deploy key", policyName: "repository_deploy_key_is_stale", repo: makeMockData(githubcollected.DeployKey{Title: "ci", ReadOnly: true, CreatedAt: daysAgo(400)}), shouldBeViolated: true},
+		{name: "recent deploy key", policyName: "repository_deploy_key_is_stale", repo: makeMockData(githubcollected.DeployKey{Title: "ci", ReadOnly: true, CreatedAt: daysAgo(30)}), shouldBeViolated: false},
+		{name: "unknown creation date", policyName: "repository_deploy_key_is_stale", repo: makeMockData(githubcollected.DeployKey{Title: "ci", ReadOnly: true}), shouldBeViolated: false},
+	}
+
+	for _, test := range tests {
+		repositoryTestTemplate(t, test.name, test.repo, test.policyName, test.shouldBeViolated, scm_type.GitHub)
+	}
+
+	// the allowed age is configurable
+	config := opa.DefaultConfig()
+	config["max_deploy_key_age_days"] = 14
+	PolicyTestTemplateWithConfig(t, "deploy key older than configured", makeMockData(githubcollected.DeployKey{Title: "ci", ReadOnly: true, CreatedAt: daysAgo(30)}),
+		namespace.Repository, "repository_deploy_key_is_stale", config, true, scm_type.GitHub)
+}
+
+func TestPublicRepositorySelfHostedRunners(t *testing.T) {
+	policyName := "public_repository_has_self_hosted_runners"
+	makeMockData := func(isPrivate bool, runners []githubcollected.SelfHostedRunner) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO", IsPrivate: isPrivate})
+		repo.SelfHostedRunners = runners
+		return repo
+	}
+	runners := []githubcollected.SelfHostedRunner{{Name: "builder", OS: "linux", Status: "online", Labels: []string{"self-hosted", "linux"}}}
+
+	repositoryTestTemplate(t, "public repository with a self-hosted runner", makeMockData(false, runners), policyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, "private repository with a self-hosted runner", makeMockData(true, runners), policyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, "public repository without self-hosted runners", makeMockData(false, []githubcollected.SelfHostedRunner{}), policyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, "runners were not collected", makeMockData(false, nil), policyName, false, scm_type.GitHub)
+}
+
+func TestProductionEnvironmentAllowsAdminBypass(t *testing.T) {
+	policyName := "production_environment_allows_admin_bypass"
+	makeMockData := func(name string, canAdminsBypass bool) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.Environments = []*github.Environment{{
+			Name:            github.String(name),
+			CanAdminsBypass: github.Bool(canAdminsBypass),
+		}}
+		return repo
+	}
+
+	tests := []struct {
+		name             string
+		repo             githubcollected.Repository
+		shouldBeViolated bool
+	}{
+		{name: "admins can bypass production", repo: makeMockData("Production", true), shouldBeViolated: true},
+		{name: "admins can bypass a configured production environment", repo: makeMockData("live", true), shouldBeViolated: true},
+		{name: "admins cannot bypass production", repo: makeMockData("prod", false), shouldBeViolated: false},
+		{name: "admins can bypass staging", repo: makeMockData("staging", true), shouldBeViolated: false},
+		{name: "environments were not collected", repo: makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"}), shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["production_environments"] = []interface{}{"live"}
+
+	for _, test := range tests {
+		PolicyTestTemplateWithConfig(t, test.name, test.repo, namespace.Repository, policyName, config, test.shouldBeViolated, scm_type.GitHub)
+	}
+}
+
+func TestProductionEnvironmentMissingRequiredReviewers(t *testing.T) {
+	name := "production environment should require reviewers"
+	testedPolicyName := "production_environment_missing_required_reviewers"
+	makeMockData := func(environmentName string, rules ...*github.ProtectionRule) githubcollected.Repository {
+		repo := makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"})
+		repo.Environments = []*github.Environment{{
+			Name:            github.String(environmentName),
+			ProtectionRules: rules,
+		}}
+		return repo
+	}
+
+	reviewers := &github.ProtectionRule{
+		Type:      github.String("required_reviewers"),
+		Reviewers: []*github.RequiredReviewer{{Type: github.String("Team")}},
+	}
+	waitTimer := &github.ProtectionRule{Type: github.String("wait_timer"), WaitTimer: github.Int(30)}
+
+	repositoryTestTemplate(t, name, makeMockData("production"), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData("Prod", waitTimer), testedPolicyName, true, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData("production", waitTimer, reviewers), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeMockData("staging"), testedPolicyName, false, scm_type.GitHub)
+	repositoryTestTemplate(t, name, makeRepo(githubcollected.GitHubQLRepository{Name: "REPO"}), testedPolicyName, false, scm_type.GitHub)
+}
+
 func TestGitlabRepositoryTooManyAdmins(t *testing.T) {
 	name := "Project Has Too Many Owners"
 	testedPolicyName := "project_has_too_many_admins"
@@ -741,3 +1413,475 @@ func TestGitlabRepositoryDismissStaleReviews(t *testing.T) {
 		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitLab)
 	}
 }
+
+func TestGitlabRepositoryMissingComplianceFramework(t *testing.T) {
+	name := "Project Missing Compliance Framework"
+	testedPolicyName := "project_missing_compliance_framework"
+
+	makeMockData := func(flag []gitlabcollected.ComplianceFramework) gitlabcollected.Repository {
+		return gitlabcollected.Repository{Project: &gitlab2.Project{}, ComplianceFrameworksInfo: flag}
+	}
+
+	options := map[bool][]gitlabcollected.ComplianceFramework{
+		false: {{ID: "gid://gitlab/ComplianceManagement::Framework/1", Name: "SOC2"}},
+		true:  {},
+	}
+
+	for _, expectFailure := range bools {
+		flag := options[expectFailure]
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitLab)
+	}
+
+	// the frameworks are not collected without the required permissions
+	repositoryTestTemplate(t, name, makeMockData(nil), testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryNonLinearHistory(t *testing.T) {
+	name := "Project Should Require Linear History"
+	testedPolicyName := "non_linear_history"
+
+	makeMockData := func(mergeMethod string) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project: &gitlab2.Project{},
+			MergeSettings: &gitlabcollected.MergeSettings{
+				MergeMethod:  mergeMethod,
+				SquashOption: "default_off",
+			},
+		}
+	}
+
+	options := map[bool]string{
+		false: "ff",
+		true:  "merge",
+	}
+
+	for _, expectFailure := range bools {
+		flag := options[expectFailure]
+		repositoryTestTemplate(t, name, makeMockData(flag), testedPolicyName, expectFailure, scm_type.GitLab)
+	}
+
+	repositoryTestTemplate(t, name, makeMockData("rebase_merge"), testedPolicyName, false, scm_type.GitLab)
+	// the merge settings are not collected without the required permissions
+	repositoryTestTemplate(t, name, gitlabcollected.Repository{Project: &gitlab2.Project{}}, testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryContainerRegistry(t *testing.T) {
+	makeMockData := func(settings *gitlabcollected.RegistrySettings) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:          &gitlab2.Project{},
+			RegistrySettings: settings,
+		}
+	}
+
+	public := &gitlabcollected.RegistrySettings{ContainerRegistryEnabled: true, ContainerRegistryVisibility: "public", CleanupPolicyEnabled: true}
+	private := &gitlabcollected.RegistrySettings{ContainerRegistryEnabled: true, ContainerRegistryVisibility: "private", CleanupPolicyEnabled: false}
+	disabled := &gitlabcollected.RegistrySettings{ContainerRegistryVisibility: "disabled"}
+
+	name := "Project Container Registry Should Not Be Public"
+	testedPolicyName := "container_registry_is_public"
+	repositoryTestTemplate(t, name, makeMockData(public), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(private), testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(nil), testedPolicyName, false, scm_type.GitLab)
+
+	name = "Project Container Registry Should Have A Cleanup Policy"
+	testedPolicyName = "container_registry_missing_cleanup_policy"
+	repositoryTestTemplate(t, name, makeMockData(private), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(public), testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(disabled), testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryDeployTokens(t *testing.T) {
+	makeMockData := func(tokens ...gitlabcollected.DeployToken) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:      &gitlab2.Project{},
+			DeployTokens: tokens,
+		}
+	}
+
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	neverExpires := gitlabcollected.DeployToken{Name: "ci", Scopes: []string{"read_repository"}}
+	writeScope := gitlabcollected.DeployToken{Name: "publish", Scopes: []string{"read_registry", "write_registry"}, ExpiresAt: &expiresAt}
+	revoked := gitlabcollected.DeployToken{Name: "old", Scopes: []string{"write_repository"}, Revoked: true}
+
+	name := "Project Deploy Tokens Should Have An Expiration Date"
+	testedPolicyName := "project_deploy_token_never_expires"
+	repositoryTestTemplate(t, name, makeMockData(neverExpires), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(writeScope, revoked), testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, gitlabcollected.Repository{Project: &gitlab2.Project{}}, testedPolicyName, false, scm_type.GitLab)
+
+	name = "Project Deploy Tokens Should Not Have Write Scopes"
+	testedPolicyName = "project_deploy_token_has_write_scope"
+	repositoryTestTemplate(t, name, makeMockData(writeScope), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(neverExpires, revoked), testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryAccessTokens(t *testing.T) {
+	makeMockData := func(tokens ...gitlabcollected.AccessToken) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:      &gitlab2.Project{},
+			AccessTokens: tokens,
+		}
+	}
+
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	neverExpires := gitlabcollected.AccessToken{Name: "bot", Scopes: []string{"read_repository"}, Active: true}
+	apiScope := gitlabcollected.AccessToken{Name: "release", Scopes: []string{"api"}, ExpiresAt: &expiresAt, Active: true}
+	revoked := gitlabcollected.AccessToken{Name: "old", Scopes: []string{"api"}, Revoked: true}
+
+	name := "Project Access Tokens Should Have An Expiration Date"
+	testedPolicyName := "project_access_token_never_expires"
+	repositoryTestTemplate(t, name, makeMockData(neverExpires), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(apiScope, revoked), testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, gitlabcollected.Repository{Project: &gitlab2.Project{}}, testedPolicyName, false, scm_type.GitLab)
+
+	name = "Project Access Tokens Should Not Have The API Scope"
+	testedPolicyName = "project_access_token_has_api_scope"
+	repositoryTestTemplate(t, name, makeMockData(apiScope), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(neverExpires, revoked), testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryUnmaskedSecretCIVariables(t *testing.T) {
+	name := "Project CI/CD Variables That Hold Secrets Should Be Masked"
+	testedPolicyName := "project_has_unmasked_secret_ci_variables"
+	makeMockData := func(variables ...gitlabcollected.CIVariable) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:     &gitlab2.Project{},
+			CIVariables: variables,
+		}
+	}
+
+	unmaskedToken := gitlabcollected.CIVariable{Key: "DEPLOY_TOKEN", VariableType: "env_var", EnvironmentScope: "*"}
+	unmaskedPassword := gitlabcollected.CIVariable{Key: "db_password", VariableType: "file", EnvironmentScope: "production"}
+	maskedToken := gitlabcollected.CIVariable{Key: "NPM_TOKEN", VariableType: "env_var", Masked: true, EnvironmentScope: "*"}
+	unmaskedSetting := gitlabcollected.CIVariable{Key: "DEPLOY_REGION", VariableType: "env_var", EnvironmentScope: "*"}
+
+	repositoryTestTemplate(t, name, makeMockData(unmaskedToken), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(maskedToken, unmaskedPassword), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(maskedToken, unmaskedSetting), testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, gitlabcollected.Repository{Project: &gitlab2.Project{}, CIVariables: []gitlabcollected.CIVariable{}}, testedPolicyName, false, scm_type.GitLab)
+	// the variables were not collected
+	repositoryTestTemplate(t, name, gitlabcollected.Repository{Project: &gitlab2.Project{}}, testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryDefaultBranchProtectionReconciliation(t *testing.T) {
+	name := "Default Branch Is Not Protected (reconciled)"
+	testedPolicyName := "missing_default_branch_protection"
+
+	wildcardProtected := gitlabcollected.Repository{
+		Project:           &gitlab2.Project{DefaultBranch: "release/v1"},
+		ProtectedBranches: []*gitlab2.ProtectedBranch{{Name: "release/*"}},
+		DefaultBranchProtection: &gitlabcollected.DefaultBranchProtection{
+			Name:      "release/v1",
+			Protected: true,
+			MatchedBy: "release/*",
+		},
+	}
+	emptyRepository := gitlabcollected.Repository{
+		Project: &gitlab2.Project{EmptyRepo: true},
+	}
+	unprotected := gitlabcollected.Repository{
+		Project:           &gitlab2.Project{DefaultBranch: "main"},
+		ProtectedBranches: []*gitlab2.ProtectedBranch{{Name: "release/*"}},
+		DefaultBranchProtection: &gitlabcollected.DefaultBranchProtection{
+			Name:      "main",
+			Protected: false,
+		},
+	}
+
+	repositoryTestTemplate(t, name, wildcardProtected, testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, emptyRepository, testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, unprotected, testedPolicyName, true, scm_type.GitLab)
+}
+
+func TestGitlabRepositoryIntegrations(t *testing.T) {
+	makeMockData := func(integrations ...gitlabcollected.Integration) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:      &gitlab2.Project{},
+			Integrations: integrations,
+		}
+	}
+
+	slack := gitlabcollected.Integration{Slug: "slack", Title: "Slack notifications", Hosts: []string{"hooks.slack.com"}}
+	jira := gitlabcollected.Integration{Slug: "jira", Title: "Jira", Hosts: []string{"jira.example.com"}}
+	jenkins := gitlabcollected.Integration{Slug: "jenkins", Title: "Jenkins", Hosts: []string{"ci.attacker.io"}}
+
+	tests := []struct {
+		name             string
+		policyName       string
+		repo             gitlabcollected.Repository
+		unconfigured     bool
+		shouldBeViolated bool
+	}{
+		{name: "unapproved integration", policyName: "project_has_unapproved_integrations", repo: makeMockData(slack, jenkins), shouldBeViolated: true},
+		{name: "approved integrations", policyName: "project_has_unapproved_integrations", repo: makeMockData(slack, jira), shouldBeViolated: false},
+		{name: "integrations were not collected", policyName: "project_has_unapproved_integrations", repo: makeMockData(), shouldBeViolated: false},
+		{name: "no approved integrations configured", policyName: "project_has_unapproved_integrations", repo: makeMockData(slack, jenkins), unconfigured: true, shouldBeViolated: false},
+		{name: "integration with an untrusted host", policyName: "project_integration_untrusted_host", repo: makeMockData(jira, jenkins), shouldBeViolated: true},
+		{name: "integrations with trusted hosts", policyName: "project_integration_untrusted_host", repo: makeMockData(slack, jira), shouldBeViolated: false},
+		{name: "no trusted domains configured", policyName: "project_integration_untrusted_host", repo: makeMockData(jira, jenkins), unconfigured: true, shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["approved_integrations"] = []interface{}{"slack", "jira"}
+	config["trusted_webhook_domains"] = []interface{}{"slack.com", "example.com"}
+
+	for _, test := range tests {
+		testConfig := config
+		if test.unconfigured {
+			testConfig = nil
+		}
+		PolicyTestTemplateWithConfig(t, test.name, test.repo, namespace.Repository, test.policyName, testConfig, test.shouldBeViolated, scm_type.GitLab)
+	}
+}
+
+func TestGitlabProjectJobTokenAccessNotLimited(t *testing.T) {
+	name := "Project job token access is not limited to an allowlist"
+	testedPolicyName := "project_job_token_access_not_limited"
+
+	makeMockData := func(scope *gitlabcollected.JobTokenScope) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:       &gitlab2.Project{},
+			JobTokenScope: scope,
+		}
+	}
+
+	repositoryTestTemplate(t, name, makeMockData(&gitlabcollected.JobTokenScope{InboundEnabled: gitlab2.Bool(false)}), testedPolicyName, true, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(&gitlabcollected.JobTokenScope{InboundEnabled: gitlab2.Bool(true), InboundAllowlist: []string{"group/project"}}), testedPolicyName, false, scm_type.GitLab)
+	// older instances only have the outbound scope
+	repositoryTestTemplate(t, name, makeMockData(&gitlabcollected.JobTokenScope{OutboundEnabled: true}), testedPolicyName, false, scm_type.GitLab)
+	repositoryTestTemplate(t, name, makeMockData(nil), testedPolicyName, false, scm_type.GitLab)
+}
+
+func TestRepositoryMergeQueueNotRequired(t *testing.T) {
+	policyName := "merge_queue_not_required"
+	makeMockData := func(name string, required *bool) githubcollected.Repository {
+		return githubcollected.Repository{
+			Repository:         &githubcollected.GitHubQLRepository{Name: name},
+			MergeQueueRequired: required,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		repo             githubcollected.Repository
+		shouldBeViolated bool
+	}{
+		{name: "configured repository without a merge queue", repo: makeMockData("monorepo", github.Bool(false)), shouldBeViolated: true},
+		{name: "configured repository with a merge queue", repo: makeMockData("monorepo", github.Bool(true)), shouldBeViolated: false},
+		{name: "rulesets were not collected", repo: makeMockData("monorepo", nil), shouldBeViolated: false},
+		{name: "repository that is not configured", repo: makeMockData("other", github.Bool(false)), shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["merge_queue_repositories"] = []interface{}{"monorepo"}
+
+	for _, test := range tests {
+		PolicyTestTemplateWithConfig(t, test.name, test.repo, namespace.Repository, policyName, config, test.shouldBeViolated, scm_type.GitHub)
+	}
+}
+
+func TestGitlabProjectMissingMergeRequestTemplate(t *testing.T) {
+	policyName := "project_missing_merge_request_template"
+	makeMockData := func(name string, templates *gitlabcollected.DescriptionTemplates) gitlabcollected.Repository {
+		return gitlabcollected.Repository{
+			Project:              &gitlab2.Project{Name: name, PathWithNamespace: "group/" + name},
+			DescriptionTemplates: templates,
+		}
+	}
+
+	noTemplates := &gitlabcollected.DescriptionTemplates{IssueTemplates: []string{"Bug"}, MergeRequestTemplates: []string{}}
+	namedTemplate := &gitlabcollected.DescriptionTemplates{IssueTemplates: []string{}, MergeRequestTemplates: []string{"Default"}}
+	defaultTemplate := &gitlabcollected.DescriptionTemplates{IssueTemplates: []string{}, MergeRequestTemplates: []string{}, HasDefaultMergeRequestTemplate: true}
+
+	tests := []struct {
+		name             string
+		repo             gitlabcollected.Repository
+		shouldBeViolated bool
+	}{
+		{name: "configured project without templates", repo: makeMockData("api", noTemplates), shouldBeViolated: true},
+		{name: "configured project (by full path) without templates", repo: makeMockData("payments", noTemplates), shouldBeViolated: true},
+		{name: "configured project with a merge request template", repo: makeMockData("api", namedTemplate), shouldBeViolated: false},
+		{name: "configured project with a default merge request template", repo: makeMockData("api", defaultTemplate), shouldBeViolated: false},
+		{name: "templates were not collected", repo: makeMockData("api", nil), shouldBeViolated: false},
+		{name: "project that is not configured", repo: makeMockData("docs", noTemplates), shouldBeViolated: false},
+	}
+
+	config := opa.DefaultConfig()
+	config["merge_request_template_projects"] = []interface{}{"api", "group/payments"}
+
+	for _, test := range tests {
+		PolicyTestTemplateWithConfig(t, test.name, test.repo, namespace.Repository, policyName, config, test.shouldBeViolated, scm_type.GitLab)
+	}
+
+	// without configured projects, there is nothing to verify
+	repositoryTestTemplate(t, "no configured projects", makeMockData("api", noTemplates), policyName, false, scm_type.GitLab)
+
+	// the critical repositories of the required workflows policy do not require templates
+	critical := opa.DefaultConfig()
+	critical["critical_repositories"] = []interface{}{"api"}
+	PolicyTestTemplateWithConfig(t, "critical repositories are not configured projects", makeMockData("api", noTemplates),
+		namespace.Repository, policyName, critical, false, scm_type.GitLab)
+}
+
+func TestPullRequestTargetWorkflowChecksOutPRHead(t *testing.T) {
+	policyName := "pull_request_target_workflow_checks_out_pr_head"
+	makeMockData := func(content string) githubcollected.Repository {
+		document, err := githubcollectors.DecodeWorkflow([]byte(content))
+		require.Nil(t, err)
+		return githubcollected.Repository{
+			Repository: &githubcollected.GitHubQLRepository{Name: "REPO"},
+			Workflows:  []githubcollected.Workflow{{Path: ".github/workflows/ci.yml", Content: document}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		content          string
+		shouldBeViolated bool
+	}{
+		{
+			name: "pull_request_target checks out the pull request head",
+			content: `
+on:
+  pull_request_target:
+    types: [opened]
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: make`,
+			shouldBeViolated: true,
+		},
+		{
+			name: "pull_request_target trigger list checks out the head ref",
+			content: `
+on: [push, pull_request_target]
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.head_ref }}`,
+			shouldBeViolated: true,
+		},
+		{
+			name: "pull_request_target checks out the base",
+			content: `
+on: pull_request_target
+jobs:
+  label:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/labeler@v4`,
+			shouldBeViolated: false,
+		},
+		{
+			name: "pull_request checks out the pull request head",
+			content: `
+on:
+  pull_request:
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}`,
+			shouldBeViolated: false,
+		},
+	}
+
+	for _, test := range tests {
+		repositoryTestTemplate(t, test.name, makeMockData(test.content), policyName, test.shouldBeViolated, scm_type.GitHub)
+	}
+
+	// the contents of the workflows were not collected
+	repositoryTestTemplate(t, "workflow contents were not collected", githubcollected.Repository{
+		Repository: &githubcollected.GitHubQLRepository{Name: "REPO"},
+		Workflows:  []githubcollected.Workflow{{Path: ".github/workflows/ci.yml"}},
+	}, policyName, false, scm_type.GitHub)
+}
+
+func TestRepositoryWorkflowGrantsIDTokenToAllJobs(t *testing.T) {
+	policyName := "workflow_grants_id_token_to_all_jobs"
+	makeMockData := func(workflows ...githubcollected.Workflow) githubcollected.Repository {
+		return githubcollected.Repository{
+			Repository: &githubcollected.GitHubQLRepository{Name: "REPO"},
+			Workflows:  workflows,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		repo             githubcollected.Repository
+		shouldBeViolated bool
+	}{
+		{
+			name: "workflow level id-token permission",
+			repo: makeMockData(githubcollected.Workflow{
+				Path:            ".github/workflows/deploy.yml",
+				Permissions:     map[string]string{"contents": "read", "id-token": "write"},
+				Jobs:            2,
+				RequestsIDToken: true,
+			}),
+			shouldBeViolated: true,
+		},
+		{
+			name: "workflow level id-token permission of a single job",
+			repo: makeMockData(githubcollected.Workflow{
+				Path:            ".github/workflows/deploy.yml",
+				Permissions:     map[string]string{"contents": "read", "id-token": "write"},
+				Jobs:            1,
+				RequestsIDToken: true,
+			}),
+			shouldBeViolated: false,
+		},
+		{
+			name: "workflow level id-token permission inherited by a single job",
+			repo: makeMockData(githubcollected.Workflow{
+				Path:            ".github/workflows/deploy.yml",
+				Permissions:     map[string]string{"id-token": "write"},
+				Jobs:            2,
+				JobPermissions:  map[string]map[string]string{"test": {"contents": "read"}},
+				RequestsIDToken: true,
+			}),
+			shouldBeViolated: false,
+		},
+		{
+			name: "workflow level write-all permission",
+			repo: makeMockData(githubcollected.Workflow{
+				Path:            ".github/workflows/deploy.yml",
+				Permissions:     map[string]string{"*": "write-all"},
+				Jobs:            3,
+				RequestsIDToken: true,
+			}),
+			shouldBeViolated: true,
+		},
+		{
+			name: "job level id-token permission",
+			repo: makeMockData(githubcollected.Workflow{
+				Path:            ".github/workflows/deploy.yml",
+				Permissions:     map[string]string{"contents": "read"},
+				Jobs:            2,
+				JobPermissions:  map[string]map[string]string{"deploy": {"id-token": "write"}},
+				RequestsIDToken: true,
+			}),
+			shouldBeViolated: false,
+		},
+		{
+			name:             "workflow without permissions",
+			repo:             makeMockData(githubcollected.Workflow{Path: ".github/workflows/ci.yml"}),
+			shouldBeViolated: false,
+		},
+		{
+			name:             "workflows were not collected",
+			repo:             makeMockData(),
+			shouldBeViolated: false,
+		},
+	}
+
+	for _, test := range tests {
+		repositoryTestTemplate(t, test.name, test.repo, policyName, test.shouldBeViolated, scm_type.GitHub)
+	}
+}